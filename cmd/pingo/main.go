@@ -0,0 +1,9 @@
+// Command pingo monitors the reachability and the latency of a list
+// of targets from a terminal ui, as a daemon or without ui.
+package main
+
+import "github.com/jeamon/pingo/internal/ui"
+
+func main() {
+	ui.Main()
+}
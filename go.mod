@@ -2,7 +2,14 @@ module github.com/jeamon/pingo
 
 go 1.17
 
-require github.com/jroimartin/gocui v0.5.0
+require (
+	github.com/jroimartin/gocui v0.5.0
+	github.com/mattn/go-sqlite3 v1.14.10
+	github.com/oschwald/maxminddb-golang v1.8.0
+	golang.org/x/net v0.11.0
+	golang.org/x/sys v0.10.0
+	golang.org/x/text v0.13.0
+)
 
 require (
 	github.com/mattn/go-runewidth v0.0.9 // indirect
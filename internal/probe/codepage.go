@@ -0,0 +1,73 @@
+package probe
+
+import (
+	"io"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/korean"
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/encoding/traditionalchinese"
+)
+
+// UTF8 is the code page of outputs already encoded in UTF-8.
+const UTF8 = 65001
+
+// codepages are the encodings of the OEM and ANSI code pages the
+// windows commands may print their outputs with.
+var codepages = map[int]encoding.Encoding{
+	437:   charmap.CodePage437,
+	850:   charmap.CodePage850,
+	852:   charmap.CodePage852,
+	855:   charmap.CodePage855,
+	858:   charmap.CodePage858,
+	860:   charmap.CodePage860,
+	862:   charmap.CodePage862,
+	863:   charmap.CodePage863,
+	865:   charmap.CodePage865,
+	866:   charmap.CodePage866,
+	874:   charmap.Windows874,
+	932:   japanese.ShiftJIS,
+	936:   simplifiedchinese.GBK,
+	949:   korean.EUCKR,
+	950:   traditionalchinese.Big5,
+	1250:  charmap.Windows1250,
+	1251:  charmap.Windows1251,
+	1252:  charmap.Windows1252,
+	1253:  charmap.Windows1253,
+	1254:  charmap.Windows1254,
+	1255:  charmap.Windows1255,
+	1256:  charmap.Windows1256,
+	1257:  charmap.Windows1257,
+	1258:  charmap.Windows1258,
+	20866: charmap.KOI8R,
+	21866: charmap.KOI8U,
+	28591: charmap.ISO8859_1,
+	28592: charmap.ISO8859_2,
+	28595: charmap.ISO8859_5,
+	28597: charmap.ISO8859_7,
+	28605: charmap.ISO8859_15,
+	54936: simplifiedchinese.GB18030,
+}
+
+// Decoder returns a reader converting into UTF-8 the outputs of a
+// command printed with a code page. The 0 code page stands for the
+// one of the console. UTF-8 and unknown code pages are read as is.
+func Decoder(r io.Reader, codepage int) io.Reader {
+	if codepage == 0 {
+		codepage = ConsoleCodePage()
+	}
+	enc, ok := codepages[codepage]
+	if !ok {
+		return r
+	}
+	return enc.NewDecoder().Reader(r)
+}
+
+// KnownCodePage tells whether the outputs printed with a
+// code page can be decoded.
+func KnownCodePage(codepage int) bool {
+	_, ok := codepages[codepage]
+	return ok || codepage == 0 || codepage == UTF8
+}
@@ -0,0 +1,217 @@
+package probe
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net"
+	"strings"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// DNSTypes lists the records types a DNSProber can query.
+var DNSTypes = []string{"A", "AAAA", "NS", "SOA", "MX", "TXT", "PTR", "CNAME"}
+
+var dnsTypes = map[string]dnsmessage.Type{
+	"A": dnsmessage.TypeA, "AAAA": dnsmessage.TypeAAAA, "NS": dnsmessage.TypeNS, "SOA": dnsmessage.TypeSOA,
+	"MX": dnsmessage.TypeMX, "TXT": dnsmessage.TypeTXT, "PTR": dnsmessage.TypePTR, "CNAME": dnsmessage.TypeCNAME,
+}
+
+// DNSProber sends a real DNS query each second to a target server
+// and measures its response time. A timeout or a server failure
+// (any rcode other than NOERROR and NXDOMAIN) is a failed request.
+// The outputs lines are formatted like the system ping ones.
+type DNSProber struct {
+	Target string
+	// name and records type to query, root NS by default.
+	QName string
+	QType string
+	// Count 0 queries forever and Timeout defaults to 2 seconds.
+	Options Options
+
+	results chan Sample
+	cancel  context.CancelFunc
+	done    chan struct{}
+}
+
+// NewDNSProber returns a prober querying a DNS server.
+func NewDNSProber(target, qname, qtype string, opts Options) *DNSProber {
+	return &DNSProber{Target: target, QName: qname, QType: qtype, Options: opts}
+}
+
+// Start checks the query then sends it in background.
+func (p *DNSProber) Start(ctx context.Context) error {
+	if p.QName == "" {
+		p.QName = "."
+	}
+	if p.QType == "" {
+		p.QType = "NS"
+	}
+	qtype, ok := dnsTypes[strings.ToUpper(p.QType)]
+	if !ok {
+		return errors.New("unsupported dns query type " + p.QType)
+	}
+	fqdn := p.QName
+	if !strings.HasSuffix(fqdn, ".") {
+		fqdn += "."
+	}
+	name, err := dnsmessage.NewName(fqdn)
+	if err != nil {
+		return err
+	}
+	if net.ParseIP(p.Target) == nil {
+		return errors.New("invalid dns server address " + p.Target)
+	}
+
+	timeout := time.Duration(p.Options.Timeout) * time.Second
+	if timeout <= 0 {
+		timeout = 2 * time.Second
+	}
+
+	ctx, p.cancel = context.WithCancel(ctx)
+	p.results = make(chan Sample, 100)
+	p.done = make(chan struct{})
+	query := fmt.Sprintf("query=%s %s", name.String(), strings.ToUpper(p.QType))
+
+	go func() {
+		defer close(p.done)
+		defer close(p.results)
+		if !p.send(ctx, Sample{Target: p.Target, Time: time.Now(), RTT: -1, Line: headerLine(p.Target, query), Informational: true}) {
+			return
+		}
+
+		interval := time.Second
+		if p.Options.Interval > 0 {
+			interval = time.Duration(p.Options.Interval) * time.Millisecond
+		}
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for i := 0; p.Options.Count == 0 || i < p.Options.Count; i++ {
+			if i > 0 {
+				select {
+				case <-ticker.C:
+				case <-ctx.Done():
+					return
+				}
+			}
+
+			sp := Sample{Target: p.Target, Time: time.Now(), RTT: -1}
+			rtt, rcode, err := exchange(ctx, p.Target, name, qtype, timeout)
+			switch {
+			case ctx.Err() != nil:
+				return
+			case err != nil:
+				sp.Line = fmt.Sprintf("Request to %s failed: %v %s", p.Target, err, query)
+			case rcode != dnsmessage.RCodeSuccess && rcode != dnsmessage.RCodeNameError:
+				sp.Line = fmt.Sprintf("Request to %s failed: rcode=%s %s", p.Target, rcodeName(rcode), query)
+			default:
+				sp.RTT, sp.Success = rtt, true
+				sp.Line = replyLine(p.Target, rtt, fmt.Sprintf("rcode=%s %s", rcodeName(rcode), query))
+			}
+			if !p.send(ctx, sp) {
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+// send delivers a sample unless the probing is cancelled.
+func (p *DNSProber) send(ctx context.Context, sp Sample) bool {
+	select {
+	case p.results <- sp:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// Stop cancels the queries and waits for the prober to end.
+func (p *DNSProber) Stop() {
+	if p.cancel == nil {
+		return
+	}
+	p.cancel()
+	<-p.done
+}
+
+// Results returns the queries results.
+func (p *DNSProber) Results() <-chan Sample {
+	return p.results
+}
+
+// exchange sends a single query over udp and returns the response
+// time in milliseconds and its rcode.
+func exchange(ctx context.Context, server string, name dnsmessage.Name, qtype dnsmessage.Type, timeout time.Duration) (int, dnsmessage.RCode, error) {
+	id := uint16(rand.Intn(1 << 16))
+	b := dnsmessage.NewBuilder(nil, dnsmessage.Header{ID: id, RecursionDesired: true})
+	b.EnableCompression()
+	if err := b.StartQuestions(); err != nil {
+		return 0, 0, err
+	}
+	if err := b.Question(dnsmessage.Question{Name: name, Type: qtype, Class: dnsmessage.ClassINET}); err != nil {
+		return 0, 0, err
+	}
+	msg, err := b.Finish()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "udp", net.JoinHostPort(server, "53"))
+	if err != nil {
+		return 0, 0, err
+	}
+	defer conn.Close()
+	deadline, _ := ctx.Deadline()
+	conn.SetDeadline(deadline)
+
+	start := time.Now()
+	if _, err = conn.Write(msg); err != nil {
+		return 0, 0, err
+	}
+
+	buf := make([]byte, 4096)
+	for {
+		n, err := conn.Read(buf)
+		if err != nil {
+			if ne, ok := err.(net.Error); ok && ne.Timeout() {
+				return 0, 0, errors.New("timeout")
+			}
+			return 0, 0, err
+		}
+		rtt := int(time.Since(start) / time.Millisecond)
+
+		var parser dnsmessage.Parser
+		h, err := parser.Start(buf[:n])
+		if err != nil || h.ID != id || !h.Response {
+			// ignore unrelated or malformed datagrams.
+			continue
+		}
+		return rtt, h.RCode, nil
+	}
+}
+
+// rcodeName returns the usual name of a response code.
+func rcodeName(rcode dnsmessage.RCode) string {
+	switch rcode {
+	case dnsmessage.RCodeSuccess:
+		return "NOERROR"
+	case dnsmessage.RCodeFormatError:
+		return "FORMERR"
+	case dnsmessage.RCodeServerFailure:
+		return "SERVFAIL"
+	case dnsmessage.RCodeNameError:
+		return "NXDOMAIN"
+	case dnsmessage.RCodeNotImplemented:
+		return "NOTIMP"
+	case dnsmessage.RCodeRefused:
+		return "REFUSED"
+	}
+	return fmt.Sprintf("RCODE%d", rcode)
+}
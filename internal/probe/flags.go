@@ -0,0 +1,50 @@
+package probe
+
+import "strconv"
+
+// linuxTimeoutArgs returns the flag of the linux ping waiting
+// for each reply, in seconds.
+func linuxTimeoutArgs(seconds int) []string {
+	return []string{"-W", strconv.Itoa(seconds)}
+}
+
+// bsdTimeoutArgs returns the flag of the macOS and FreeBSD ping
+// waiting for each reply. Their -W takes milliseconds, their -t
+// being the whole run timeout.
+func bsdTimeoutArgs(seconds int) []string {
+	return []string{"-W", strconv.Itoa(seconds * 1000)}
+}
+
+// openbsdTimeoutArgs returns the flag of the OpenBSD ping waiting
+// for each reply, in seconds with -w since it has no -W.
+func openbsdTimeoutArgs(seconds int) []string {
+	return []string{"-w", strconv.Itoa(seconds)}
+}
+
+// pingArgs builds the arguments of the unix ping commands with the
+// timeout flag of the system. The target comes last since the BSD
+// and macOS getopt stop parsing the options at the first operand.
+func pingArgs(target string, opts Options, timeoutArgs func(int) []string) []string {
+	var args []string
+	if opts.Count > 0 {
+		args = append(args, "-c", strconv.Itoa(opts.Count))
+	}
+
+	if opts.Interval > 0 {
+		args = append(args, "-i", strconv.FormatFloat(float64(opts.Interval)/1000, 'f', -1, 64))
+	}
+
+	if opts.Timeout > 0 {
+		args = append(args, timeoutArgs(opts.Timeout)...)
+	}
+
+	if opts.Size > 0 {
+		args = append(args, "-s", strconv.Itoa(opts.Size))
+	}
+
+	if opts.Pattern != "" {
+		args = append(args, "-p", opts.Pattern)
+	}
+
+	return append(args, target)
+}
@@ -0,0 +1,7 @@
+//go:build darwin || freebsd || dragonfly
+// +build darwin freebsd dragonfly
+
+package probe
+
+// timeout flag of the macOS and FreeBSD ping, in milliseconds.
+var timeoutArgs = bsdTimeoutArgs
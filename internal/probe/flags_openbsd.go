@@ -0,0 +1,7 @@
+//go:build openbsd
+// +build openbsd
+
+package probe
+
+// timeout flag of the OpenBSD ping, in seconds.
+var timeoutArgs = openbsdTimeoutArgs
@@ -0,0 +1,7 @@
+//go:build !windows && !darwin && !freebsd && !dragonfly && !openbsd
+// +build !windows,!darwin,!freebsd,!dragonfly,!openbsd
+
+package probe
+
+// timeout flag of the linux ping, in seconds.
+var timeoutArgs = linuxTimeoutArgs
@@ -0,0 +1,34 @@
+package probe
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPingArgsOrder(t *testing.T) {
+	opts := Options{Count: 5, Interval: 500, Timeout: 2, Size: 100, Pattern: "ff"}
+	tests := []struct {
+		name    string
+		timeout func(int) []string
+		want    []string
+	}{
+		{"linux", linuxTimeoutArgs, []string{"-c", "5", "-i", "0.5", "-W", "2", "-s", "100", "-p", "ff", "8.8.8.8"}},
+		{"darwin-freebsd", bsdTimeoutArgs, []string{"-c", "5", "-i", "0.5", "-W", "2000", "-s", "100", "-p", "ff", "8.8.8.8"}},
+		{"openbsd", openbsdTimeoutArgs, []string{"-c", "5", "-i", "0.5", "-w", "2", "-s", "100", "-p", "ff", "8.8.8.8"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := pingArgs("8.8.8.8", opts, tt.timeout); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("pingArgs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPingArgsTargetOnly(t *testing.T) {
+	for _, timeout := range []func(int) []string{linuxTimeoutArgs, bsdTimeoutArgs, openbsdTimeoutArgs} {
+		if got := pingArgs("::1", Options{}, timeout); !reflect.DeepEqual(got, []string{"::1"}) {
+			t.Errorf("pingArgs() = %v, want [::1]", got)
+		}
+	}
+}
@@ -0,0 +1,296 @@
+package probe
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net"
+	"time"
+
+	"golang.org/x/net/icmp"
+	"golang.org/x/net/ipv4"
+	"golang.org/x/net/ipv6"
+)
+
+// sockets an ICMPProber can send its echo requests with.
+const (
+	// raw sockets, allowed to root, to a process with the CAP_NET_RAW
+	// capability or to a windows administrator.
+	ICMPRAW = "raw"
+	// datagram sockets allowed to unprivileged users on macOS and on
+	// linux within the net.ipv4.ping_group_range sysctl.
+	ICMPDGRAM = "unprivileged"
+)
+
+// ICMPMode returns the kind of ICMP sockets the process may open,
+// raw sockets being preferred. It is empty when none is allowed so
+// the system ping command must be used.
+func ICMPMode() string {
+	if c, err := icmp.ListenPacket("ip4:icmp", "0.0.0.0"); err == nil {
+		c.Close()
+		return ICMPRAW
+	}
+	if c, err := icmp.ListenPacket("udp4", "0.0.0.0"); err == nil {
+		c.Close()
+		return ICMPDGRAM
+	}
+	return ""
+}
+
+// ICMPProber sends ICMP echo requests itself without the system ping
+// command, through the raw or datagram sockets of its Mode. Each
+// request waits for its reply before the next one. The outputs lines
+// are formatted like the system ping ones.
+type ICMPProber struct {
+	Target string
+	Mode   string
+	// Count 0 pings forever and Timeout defaults to 2 seconds.
+	Options Options
+
+	results chan Sample
+	cancel  context.CancelFunc
+	done    chan struct{}
+}
+
+// NewICMPProber returns a prober sending echo requests through the
+// sockets of mode.
+func NewICMPProber(target, mode string, opts Options) *ICMPProber {
+	return &ICMPProber{Target: target, Mode: mode, Options: opts}
+}
+
+// icmpConn is an ICMP endpoint of the family of a target.
+type icmpConn struct {
+	*icmp.PacketConn
+	dst      net.Addr
+	protocol int
+	echo     icmp.Type
+	reply    icmp.Type
+}
+
+// listenICMP opens the ICMP endpoint of the family of ip.
+func listenICMP(ip net.IP, mode string) (*icmpConn, error) {
+	network, address := "ip4:icmp", "0.0.0.0"
+	c := &icmpConn{protocol: 1, echo: ipv4.ICMPTypeEcho, reply: ipv4.ICMPTypeEchoReply}
+	if ip.To4() == nil {
+		network, address = "ip6:ipv6-icmp", "::"
+		c.protocol, c.echo, c.reply = 58, ipv6.ICMPTypeEchoRequest, ipv6.ICMPTypeEchoReply
+	}
+	c.dst = &net.IPAddr{IP: ip}
+	if mode == ICMPDGRAM {
+		network = "udp4"
+		if c.protocol == 58 {
+			network = "udp6"
+		}
+		c.dst = &net.UDPAddr{IP: ip}
+	}
+
+	var err error
+	if c.PacketConn, err = icmp.ListenPacket(network, address); err != nil {
+		return nil, err
+	}
+	// the ttl of the replies is unknown where not supported.
+	if p := c.IPv4PacketConn(); p != nil {
+		p.SetControlMessage(ipv4.FlagTTL, true)
+	}
+	if p := c.IPv6PacketConn(); p != nil {
+		p.SetControlMessage(ipv6.FlagHopLimit, true)
+	}
+	return c, nil
+}
+
+// readReply reads a datagram and returns its size and the ttl
+// or hop limit of its ip packet, 0 when unknown.
+func (c *icmpConn) readReply(b []byte) (int, int, net.Addr, error) {
+	if p := c.IPv4PacketConn(); p != nil {
+		n, cm, src, err := p.ReadFrom(b)
+		if cm != nil {
+			return n, cm.TTL, src, err
+		}
+		return n, 0, src, err
+	}
+	if p := c.IPv6PacketConn(); p != nil {
+		n, cm, src, err := p.ReadFrom(b)
+		if cm != nil {
+			return n, cm.HopLimit, src, err
+		}
+		return n, 0, src, err
+	}
+	n, src, err := c.ReadFrom(b)
+	return n, 0, src, err
+}
+
+// Start opens the socket then sends the requests in background.
+func (p *ICMPProber) Start(ctx context.Context) error {
+	ip := net.ParseIP(p.Target)
+	if ip == nil {
+		addr, err := net.ResolveIPAddr("ip", p.Target)
+		if err != nil {
+			return err
+		}
+		ip = addr.IP
+	}
+
+	payload := make([]byte, 56)
+	if p.Options.Size > 0 {
+		payload = make([]byte, p.Options.Size)
+	}
+	if p.Options.Pattern != "" {
+		pattern, err := hex.DecodeString(p.Options.Pattern)
+		if err != nil || len(pattern) == 0 {
+			return errors.New("invalid payload pattern " + p.Options.Pattern)
+		}
+		for i := range payload {
+			payload[i] = pattern[i%len(pattern)]
+		}
+	}
+
+	conn, err := listenICMP(ip, p.Mode)
+	if err != nil {
+		return err
+	}
+
+	timeout := time.Duration(p.Options.Timeout) * time.Second
+	if timeout <= 0 {
+		timeout = 2 * time.Second
+	}
+
+	ctx, p.cancel = context.WithCancel(ctx)
+	p.results = make(chan Sample, 100)
+	p.done = make(chan struct{})
+	id := rand.Intn(1 << 16)
+
+	go func() {
+		defer close(p.done)
+		defer close(p.results)
+		defer conn.Close()
+		defer p.cancel()
+		// unblocks the pending read once stopped.
+		go func() {
+			<-ctx.Done()
+			conn.SetReadDeadline(time.Now())
+		}()
+
+		// with the icmp and ip headers.
+		overhead := 28
+		if ip.To4() == nil {
+			overhead = 48
+		}
+		details := fmt.Sprintf("(%s) %d(%d) bytes of data over %s icmp sockets.", ip, len(payload), len(payload)+overhead, p.Mode)
+		if !p.send(ctx, Sample{Target: p.Target, Time: time.Now(), RTT: -1, Line: headerLine(p.Target, details), Informational: true}) {
+			return
+		}
+
+		interval := time.Second
+		if p.Options.Interval > 0 {
+			interval = time.Duration(p.Options.Interval) * time.Millisecond
+		}
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for seq := 1; p.Options.Count == 0 || seq <= p.Options.Count; seq++ {
+			if seq > 1 {
+				select {
+				case <-ticker.C:
+				case <-ctx.Done():
+					return
+				}
+			}
+
+			sp := Sample{Target: p.Target, Time: time.Now(), RTT: -1}
+			rtt, ttl, size, err := exchangeEcho(conn, id, seq, payload, timeout)
+			switch {
+			case ctx.Err() != nil:
+				return
+			case err != nil:
+				sp.Line = fmt.Sprintf("Request to %s failed: %v icmp_seq=%d", p.Target, err, seq)
+			default:
+				sp.RTT, sp.Success, sp.TTL, sp.Size = rtt, true, ttl, size
+				sp.Line = replyLine(p.Target, rtt, fmt.Sprintf("icmp_seq=%d ttl=%d bytes=%d", seq, ttl, size))
+			}
+			if !p.send(ctx, sp) {
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+// send delivers a sample unless the probing is cancelled.
+func (p *ICMPProber) send(ctx context.Context, sp Sample) bool {
+	select {
+	case p.results <- sp:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// Stop cancels the requests and waits for the prober to end.
+func (p *ICMPProber) Stop() {
+	if p.cancel == nil {
+		return
+	}
+	p.cancel()
+	<-p.done
+}
+
+// Results returns the requests results.
+func (p *ICMPProber) Results() <-chan Sample {
+	return p.results
+}
+
+// exchangeEcho sends an echo request and waits for its reply. It
+// returns the round-trip time in milliseconds, the ttl and the size
+// in bytes of the reply.
+func exchangeEcho(c *icmpConn, id, seq int, payload []byte, timeout time.Duration) (int, int, int, error) {
+	msg := icmp.Message{Type: c.echo, Body: &icmp.Echo{ID: id, Seq: seq & 0xffff, Data: payload}}
+	b, err := msg.Marshal(nil)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+
+	start := time.Now()
+	c.SetReadDeadline(start.Add(timeout))
+	if _, err = c.WriteTo(b, c.dst); err != nil {
+		return 0, 0, 0, err
+	}
+
+	buf := make([]byte, 65536)
+	for {
+		n, ttl, src, err := c.readReply(buf)
+		if err != nil {
+			if ne, ok := err.(net.Error); ok && ne.Timeout() {
+				return 0, 0, 0, errors.New("timeout")
+			}
+			return 0, 0, 0, err
+		}
+		rtt := int(time.Since(start) / time.Millisecond)
+
+		reply, err := icmp.ParseMessage(c.protocol, buf[:n])
+		if err != nil || reply.Type != c.reply || !sameHost(src, c.dst) {
+			// ignore the other icmp messages received by raw sockets.
+			continue
+		}
+		echo, ok := reply.Body.(*icmp.Echo)
+		// datagram sockets replace the id by their port.
+		if !ok || echo.Seq != seq&0xffff || (c.dst.Network() == "ip" && echo.ID != id) {
+			continue
+		}
+		return rtt, ttl, n, nil
+	}
+}
+
+// sameHost tells whether two addresses have the same ip.
+func sameHost(a, b net.Addr) bool {
+	ip := func(addr net.Addr) net.IP {
+		switch v := addr.(type) {
+		case *net.IPAddr:
+			return v.IP
+		case *net.UDPAddr:
+			return v.IP
+		}
+		return nil
+	}
+	return ip(a).Equal(ip(b))
+}
@@ -0,0 +1,59 @@
+//go:build !windows
+// +build !windows
+
+package probe
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// ParseReply extracts the round-trip time in milliseconds of a ping
+// output line. It returns -1 and false for lines to ignore (header
+// and summary) and -1 and true for a failed request.
+func ParseReply(output string) (int, bool) {
+	indexT := strings.Index(output, "time=")
+	if indexT > 0 {
+		indexM := strings.Index(output, " ms")
+		if indexM < indexT {
+			return -1, true
+		}
+		// milliseconds with decimals like time=12.345 ms on macOS.
+		value, _ := strconv.ParseFloat(output[(indexT+5):indexM], 64)
+		return int(value + 0.5), false
+	}
+
+	// ignore these outputs entries (rtt on linux, round-trip on BSD systems).
+	if strings.HasPrefix(output, "PING") || strings.HasPrefix(output, "---") ||
+		strings.HasPrefix(output, "rtt") || strings.HasPrefix(output, "round-trip") ||
+		strings.Contains(output, "%") {
+		return -1, false
+	}
+
+	return -1, true
+}
+
+// headerLine and replyLine format the outputs of other probe types
+// the way the system ping does so ParseReply reads them as well.
+func headerLine(target, details string) string {
+	return fmt.Sprintf("PING %s %s", target, details)
+}
+
+func replyLine(target string, rtt int, details string) string {
+	return fmt.Sprintf("Reply from %s: time=%d ms %s", target, rtt, details)
+}
+
+// Command builds the system ping command of a target. The flag of
+// the timeout depends on the system.
+func Command(ctx context.Context, target string, opts Options) *exec.Cmd {
+	return exec.CommandContext(ctx, "ping", pingArgs(target, opts, timeoutArgs)...)
+}
+
+// ConsoleCodePage returns the code page of the outputs of the
+// commands, always UTF-8 on unix systems.
+func ConsoleCodePage() int {
+	return UTF8
+}
@@ -0,0 +1,88 @@
+//go:build windows
+// +build windows
+
+package probe
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// ParseReply extracts the round-trip time in milliseconds of a ping
+// output line. It returns -1 and false for lines to ignore (header
+// and summary) and -1 and true for a failed request.
+func ParseReply(output string) (int, bool) {
+	for _, marker := range []string{"time=", "time<"} {
+		indexT := strings.Index(output, marker)
+		if indexT > 0 {
+			indexM := strings.Index(output, "ms")
+			if indexM < indexT {
+				return -1, true
+			}
+			value := output[(indexT + 5):indexM]
+			response, _ := strconv.Atoi(value)
+			return response, false
+		}
+	}
+
+	// ignore these outputs entries.
+	if strings.HasPrefix(output, "Pinging") || strings.HasPrefix(output, "Ping") ||
+		strings.HasPrefix(output, "Packets") || strings.HasPrefix(output, "Approximate") ||
+		strings.HasPrefix(output, "Minimum") {
+		return -1, false
+	}
+
+	return -1, true
+}
+
+// headerLine and replyLine format the outputs of other probe types
+// the way the system ping does so ParseReply reads them as well.
+func headerLine(target, details string) string {
+	return fmt.Sprintf("Pinging %s %s", target, details)
+}
+
+func replyLine(target string, rtt int, details string) string {
+	return fmt.Sprintf("Reply from %s: time=%dms %s", target, rtt, details)
+}
+
+// Command builds the system ping command of a target.
+// The timeout is converted into milliseconds.
+func Command(ctx context.Context, target string, opts Options) *exec.Cmd {
+	args := []string{target}
+	if opts.Count > 0 {
+		args = append(args, "-n", strconv.Itoa(opts.Count))
+	} else {
+		args = append(args, "-t")
+	}
+
+	if opts.Timeout > 0 {
+		args = append(args, "-w", strconv.Itoa(opts.Timeout*1000))
+	}
+
+	if opts.Size > 0 {
+		args = append(args, "-l", strconv.Itoa(opts.Size))
+	}
+
+	return exec.CommandContext(ctx, "ping", args...)
+}
+
+var (
+	kernel32               = syscall.NewLazyDLL("kernel32.dll")
+	procGetConsoleOutputCP = kernel32.NewProc("GetConsoleOutputCP")
+	procGetOEMCP           = kernel32.NewProc("GetOEMCP")
+)
+
+// ConsoleCodePage returns the code page the commands print their
+// outputs with : the one of the console if attached to any (like
+// after chcp) or the OEM code page of the system otherwise.
+func ConsoleCodePage() int {
+	if cp, _, _ := procGetConsoleOutputCP.Call(); cp != 0 {
+		return int(cp)
+	}
+	cp, _, _ := procGetOEMCP.Call()
+	return int(cp)
+}
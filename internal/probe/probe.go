@@ -0,0 +1,90 @@
+// Package probe runs the probes of the pingo program against its
+// targets : the system ping command, ICMP echo requests sent through
+// its own sockets and DNS queries. Each reply is parsed into a Sample
+// delivered by a Prober.
+package probe
+
+import (
+	"context"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// Options defines the ping command parameters. Zero values
+// keep the system ping defaults and Count 0 pings forever.
+type Options struct {
+	// number of requests to send.
+	Count int
+	// milliseconds between two requests (a second by default). It is
+	// ignored by the windows ping which has no such option.
+	Interval int
+	// seconds to wait for each reply.
+	Timeout int
+	// payload size in bytes.
+	Size int
+	// hexadecimal bytes filling the payload (up to 16 bytes). It is
+	// ignored on windows whose ping has no such option.
+	Pattern string
+}
+
+// Sample is the result of a single request. RTT is
+// in milliseconds and -1 when the request failed.
+type Sample struct {
+	Target  string
+	Time    time.Time
+	RTT     int
+	Success bool
+	// raw output line of the probe if any.
+	Line string
+	// the line carries no result (header or summary).
+	Informational bool
+	// time to live and size in bytes of the reply, 0 when unknown.
+	TTL  int
+	Size int
+}
+
+var (
+	// ttl of a reply like ttl=64 (unix) or TTL=117 (windows).
+	ttlField = regexp.MustCompile(`(?i)\bttl[=:](\d+)`)
+	// size of a reply like "64 bytes from" (unix) or bytes=32 (windows).
+	sizeField = regexp.MustCompile(`(\d+) bytes from|bytes=(\d+)`)
+)
+
+// ParseDetails returns the ttl and the size in bytes of a reply
+// output line, 0 for the ones not found.
+func ParseDetails(line string) (int, int) {
+	var ttl, size int
+	if m := ttlField.FindStringSubmatch(line); m != nil {
+		ttl, _ = strconv.Atoi(m[1])
+	}
+	if m := sizeField.FindStringSubmatch(line); m != nil {
+		size, _ = strconv.Atoi(m[1] + m[2])
+	}
+	return ttl, size
+}
+
+// Ping runs the system ping command against target and calls handle
+// with each output line and its sample, nil for lines without result.
+// It returns once the command ends or the context is cancelled.
+func Ping(ctx context.Context, target string, opts Options, handle func(line string, s *Sample)) error {
+	p := NewExecProber(target, opts)
+	if err := p.Start(ctx); err != nil {
+		return err
+	}
+	defer p.Stop()
+
+	for sp := range p.Results() {
+		if sp.Informational {
+			handle(sp.Line, nil)
+			continue
+		}
+		sp := sp
+		handle(sp.Line, &sp)
+	}
+
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return p.Err()
+}
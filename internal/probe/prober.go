@@ -0,0 +1,180 @@
+package probe
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Prober runs probes against a target and delivers their results.
+// Results is valid once started and is closed when the probing ends,
+// either by itself (bounded count) or after Stop or ctx cancellation.
+// Other probe types or test fakes only need to implement it.
+type Prober interface {
+	Start(ctx context.Context) error
+	Stop()
+	Results() <-chan Sample
+}
+
+// ExecProber runs an external command, by default the system ping,
+// and turns each of its output lines into a sample.
+type ExecProber struct {
+	Target string
+	// Cmd builds the command to run.
+	Cmd func(ctx context.Context) *exec.Cmd
+	// Track, if set, is called once the command started and the
+	// returned function once it exited, to follow the processes.
+	Track func(cmd *exec.Cmd) func()
+	// ProbeTimeout, if set, is the longest wait of an output line.
+	// Each silent period counts as a failed request so a hung command
+	// still reports its target as unreachable.
+	ProbeTimeout time.Duration
+	// RunTimeout, if set, kills the command once it ran that long.
+	RunTimeout time.Duration
+	// CodePage of the command outputs, 0 for the console one.
+	CodePage int
+
+	results chan Sample
+	cancel  context.CancelFunc
+	done    chan struct{}
+	lock    sync.Mutex
+	err     error
+}
+
+// NewExecProber returns a prober running the system ping command.
+func NewExecProber(target string, opts Options) *ExecProber {
+	return &ExecProber{
+		Target: target,
+		Cmd: func(ctx context.Context) *exec.Cmd {
+			return Command(ctx, target, opts)
+		},
+	}
+}
+
+// Start runs the command in background.
+func (p *ExecProber) Start(ctx context.Context) error {
+	if p.RunTimeout > 0 {
+		ctx, p.cancel = context.WithTimeout(ctx, p.RunTimeout)
+	} else {
+		ctx, p.cancel = context.WithCancel(ctx)
+	}
+	cmd := p.Cmd(ctx)
+	outpipe, err := cmd.StdoutPipe()
+	if err != nil {
+		p.cancel()
+		return err
+	}
+	// combined outputs.
+	cmd.Stderr = cmd.Stdout
+
+	if err = cmd.Start(); err != nil {
+		p.cancel()
+		return err
+	}
+	release := func() {}
+	if p.Track != nil {
+		release = p.Track(cmd)
+	}
+
+	lines := make(chan Sample)
+	go func() {
+		defer close(lines)
+		scanner := bufio.NewScanner(Decoder(outpipe, p.CodePage))
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" {
+				continue
+			}
+
+			sp := Sample{Target: p.Target, Time: time.Now(), Line: line}
+			rtt, failed := ParseReply(line)
+			sp.RTT, sp.Success = rtt, rtt != -1
+			sp.Informational = rtt == -1 && !failed
+			if sp.Success {
+				sp.TTL, sp.Size = ParseDetails(line)
+			}
+
+			// keep reading once stopped so the process can exit.
+			select {
+			case lines <- sp:
+			case <-ctx.Done():
+			}
+		}
+
+		err := cmd.Wait()
+		release()
+		p.lock.Lock()
+		p.err = err
+		p.lock.Unlock()
+	}()
+
+	// results end as soon as stopped even if a child process
+	// still holds the output pipe.
+	p.results = make(chan Sample, 100)
+	p.done = make(chan struct{})
+	go func() {
+		defer close(p.done)
+		defer close(p.results)
+		var silence <-chan time.Time
+		var timer *time.Timer
+		if p.ProbeTimeout > 0 {
+			timer = time.NewTimer(p.ProbeTimeout)
+			defer timer.Stop()
+			silence = timer.C
+		}
+		for {
+			var sp Sample
+			select {
+			case line, ok := <-lines:
+				if !ok {
+					return
+				}
+				sp = line
+				if timer != nil && !timer.Stop() {
+					<-timer.C
+				}
+			case t := <-silence:
+				sp = Sample{Target: p.Target, Time: t, RTT: -1,
+					Line: fmt.Sprintf("No reply from %s within %s", p.Target, p.ProbeTimeout)}
+			case <-ctx.Done():
+				return
+			}
+			if timer != nil {
+				timer.Reset(p.ProbeTimeout)
+			}
+			select {
+			case p.results <- sp:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+// Stop kills the command and closes the results.
+func (p *ExecProber) Stop() {
+	if p.cancel == nil {
+		return
+	}
+	p.cancel()
+	if p.done != nil {
+		<-p.done
+	}
+}
+
+// Results returns the samples channel.
+func (p *ExecProber) Results() <-chan Sample {
+	return p.results
+}
+
+// Err returns the command exit error once it ended by itself.
+func (p *ExecProber) Err() error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return p.err
+}
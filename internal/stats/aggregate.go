@@ -0,0 +1,69 @@
+package stats
+
+import "time"
+
+// Aggregate summarizes the samples of a target taken within a minute,
+// kept once they are dropped from the samples history.
+type Aggregate struct {
+	// start of the minute.
+	Time  time.Time
+	Sent  int
+	Fails int
+	// round-trip times in ms of the replies.
+	Min int
+	Max int
+	Sum int
+}
+
+// Replies returns the number of successful requests of the minute.
+func (a Aggregate) Replies() int {
+	return a.Sent - a.Fails
+}
+
+// Avg returns the average round-trip time of the replies of the minute.
+func (a Aggregate) Avg() int {
+	if a.Replies() == 0 {
+		return 0
+	}
+	return a.Sum / a.Replies()
+}
+
+// Loss returns the percentage of failed requests of the minute.
+func (a Aggregate) Loss() float64 {
+	if a.Sent == 0 {
+		return 0
+	}
+	return float64(a.Fails) * 100 / float64(a.Sent)
+}
+
+// Add accounts a sample into the aggregate of its minute.
+func (a *Aggregate) Add(sp Sample) {
+	a.Sent++
+	if !sp.Success {
+		a.Fails++
+		return
+	}
+	if a.Replies() == 1 || sp.RTT < a.Min {
+		a.Min = sp.RTT
+	}
+	if sp.RTT > a.Max {
+		a.Max = sp.RTT
+	}
+	a.Sum += sp.RTT
+}
+
+// Downsample folds samples into the per-minute aggregates following
+// minutes and returns them without the oldest ones beyond limit.
+func Downsample(minutes []Aggregate, samples []Sample, limit int) []Aggregate {
+	for _, sp := range samples {
+		minute := sp.Time.Truncate(time.Minute)
+		if n := len(minutes); n == 0 || !minutes[n-1].Time.Equal(minute) {
+			minutes = append(minutes, Aggregate{Time: minute})
+		}
+		minutes[len(minutes)-1].Add(sp)
+	}
+	if len(minutes) > limit {
+		minutes = minutes[len(minutes)-limit:]
+	}
+	return minutes
+}
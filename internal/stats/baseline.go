@@ -0,0 +1,38 @@
+package stats
+
+import "math"
+
+// MINDEVIATION is the smallest deviation in ms considered, so a very
+// stable target does not see an anomaly in each millisecond of jitter.
+const MINDEVIATION = 1.0
+
+// Baseline is the learned latency profile of a target : the moving
+// average and variance of its replies times.
+type Baseline struct {
+	Count    int
+	Mean     float64
+	Variance float64
+}
+
+// Deviation returns the standard deviation of the baseline.
+func (b *Baseline) Deviation() float64 {
+	return math.Max(math.Sqrt(b.Variance), MINDEVIATION)
+}
+
+// Sigmas returns how many deviations a reply time is from the mean.
+func (b *Baseline) Sigmas(rtt float64) float64 {
+	return (rtt - b.Mean) / b.Deviation()
+}
+
+// Learn accounts a reply time : averaged over all replies until the
+// window is filled then exponentially weighted over the window.
+func (b *Baseline) Learn(rtt float64, window int) {
+	b.Count++
+	alpha := 1 / float64(b.Count)
+	if b.Count > window {
+		alpha = 1 / float64(window)
+	}
+	delta := rtt - b.Mean
+	b.Mean += alpha * delta
+	b.Variance = (1 - alpha) * (b.Variance + alpha*delta*delta)
+}
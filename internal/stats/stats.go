@@ -0,0 +1,144 @@
+// Package stats holds the typed results of the probes of a target and
+// their summaries. The prober loop feeds Samples and the statistics
+// views, reports and exports read Summaries.
+//
+//	var s stats.Summary
+//	s.Add(stats.Sample{Time: time.Now(), RTT: 12, Seq: 1, Success: true})
+//	lastMinute := s.Window(time.Now().Add(-time.Minute), time.Now())
+//	fmt.Println(lastMinute.Loss(), lastMinute.Quantile(0.95))
+package stats
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// Sample is the result of a single request. RTT is
+// in milliseconds and -1 when the request failed.
+type Sample struct {
+	Time time.Time `json:"time"`
+	RTT  int       `json:"rtt"`
+	// number of the request into its run, from 1.
+	Seq     int  `json:"seq"`
+	Success bool `json:"success"`
+}
+
+// Summary accounts the samples of a target. It keeps them
+// so a time window or the quantiles can be computed.
+type Summary struct {
+	Sent  int
+	Fails int
+	// round-trip times in milliseconds.
+	Min  int
+	Max  int
+	Last int
+	sum  int
+
+	samples []Sample
+}
+
+// Summarize builds the summary of a list of samples.
+func Summarize(samples []Sample) Summary {
+	var s Summary
+	for _, sp := range samples {
+		s.Add(sp)
+	}
+	return s
+}
+
+// Add accounts a sample into the summary and keeps it for the
+// windows and quantiles.
+func (s *Summary) Add(sp Sample) {
+	s.samples = append(s.samples, sp)
+	s.Account(sp)
+}
+
+// Account updates the counters with a sample without keeping it,
+// for endless series where only the totals are needed.
+func (s *Summary) Account(sp Sample) {
+	s.Sent++
+	if !sp.Success {
+		s.Fails++
+		return
+	}
+
+	if s.Replies() == 1 || sp.RTT < s.Min {
+		s.Min = sp.RTT
+	}
+	if sp.RTT > s.Max {
+		s.Max = sp.RTT
+	}
+	s.Last = sp.RTT
+	s.sum += sp.RTT
+}
+
+// Merge accounts the samples of another summary, like the
+// ones of successive runs of a target.
+func (s *Summary) Merge(o Summary) {
+	for _, sp := range o.samples {
+		s.Add(sp)
+	}
+}
+
+// Window returns the summary of the samples taken
+// from the start time included to the end excluded.
+func (s Summary) Window(from, to time.Time) Summary {
+	var w Summary
+	for _, sp := range s.samples {
+		if !sp.Time.Before(from) && sp.Time.Before(to) {
+			w.Add(sp)
+		}
+	}
+	return w
+}
+
+// Quantile returns the nearest-rank q-th quantile (0 to 1)
+// of the round-trip times of the replies, 0 without reply : the
+// smallest time greater than or equal to q of the replies times.
+func (s Summary) Quantile(q float64) int {
+	rtts := make([]int, 0, s.Replies())
+	for _, sp := range s.samples {
+		if sp.Success {
+			rtts = append(rtts, sp.RTT)
+		}
+	}
+	if len(rtts) == 0 {
+		return 0
+	}
+	sort.Ints(rtts)
+	rank := int(math.Ceil(q*float64(len(rtts)))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	if rank >= len(rtts) {
+		rank = len(rtts) - 1
+	}
+	return rtts[rank]
+}
+
+// Samples returns the accounted samples in their order.
+func (s Summary) Samples() []Sample {
+	return s.samples
+}
+
+// Replies returns the number of successful requests.
+func (s Summary) Replies() int {
+	return s.Sent - s.Fails
+}
+
+// Avg returns the average round-trip time of the replies.
+func (s Summary) Avg() int {
+	if s.Replies() == 0 {
+		return 0
+	}
+	return s.sum / s.Replies()
+}
+
+// Loss returns the percentage of failed requests.
+func (s Summary) Loss() float64 {
+	if s.Sent == 0 {
+		return 0
+	}
+	return float64(s.Fails) * 100 / float64(s.Sent)
+}
@@ -0,0 +1,133 @@
+package stats
+
+import (
+	"testing"
+	"time"
+)
+
+// replies returns the successful samples of round-trip times taken
+// one second apart from t0.
+func replies(t0 time.Time, rtts ...int) []Sample {
+	samples := make([]Sample, len(rtts))
+	for i, rtt := range rtts {
+		samples[i] = Sample{Time: t0.Add(time.Duration(i) * time.Second), RTT: rtt, Seq: i + 1, Success: true}
+	}
+	return samples
+}
+
+func TestQuantile(t *testing.T) {
+	t0 := time.Now()
+	ten := Summarize(replies(t0, 100, 90, 80, 70, 60, 50, 40, 30, 20, 10))
+	tests := []struct {
+		name string
+		s    Summary
+		q    float64
+		want int
+	}{
+		{"no reply", Summary{}, 0.5, 0},
+		{"only fails", Summarize([]Sample{{Time: t0, RTT: -1}, {Time: t0, RTT: -1}}), 0.5, 0},
+		{"single", Summarize(replies(t0, 42)), 0.95, 42},
+		{"zero", ten, 0, 10},
+		{"tenth", ten, 0.1, 10},
+		{"above tenth", ten, 0.11, 20},
+		{"median", ten, 0.5, 50},
+		{"p90", ten, 0.9, 90},
+		{"p91", ten, 0.91, 100},
+		{"p95", ten, 0.95, 100},
+		{"max", ten, 1, 100},
+		{"fails ignored", Summarize(append(replies(t0, 10, 20), Sample{Time: t0, RTT: -1})), 0.5, 10},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.s.Quantile(tt.q); got != tt.want {
+				t.Errorf("Quantile(%v) = %d, want %d", tt.q, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWindow(t *testing.T) {
+	t0 := time.Now()
+	s := Summarize(replies(t0, 10, 20, 30, 40))
+	tests := []struct {
+		name     string
+		from, to time.Time
+		want     []int
+	}{
+		{"all", t0, t0.Add(4 * time.Second), []int{10, 20, 30, 40}},
+		{"from included", t0.Add(time.Second), t0.Add(2 * time.Second), []int{20}},
+		{"to excluded", t0, t0.Add(3 * time.Second), []int{10, 20, 30}},
+		{"empty range", t0.Add(time.Second), t0.Add(time.Second), nil},
+		{"before", t0.Add(-time.Minute), t0, nil},
+		{"after", t0.Add(4 * time.Second), t0.Add(time.Minute), nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := s.Window(tt.from, tt.to)
+			if w.Sent != len(tt.want) || len(w.Samples()) != len(tt.want) {
+				t.Fatalf("window of %d samples, want %d", len(w.Samples()), len(tt.want))
+			}
+			for i, sp := range w.Samples() {
+				if sp.RTT != tt.want[i] {
+					t.Errorf("sample %d rtt %d, want %d", i, sp.RTT, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestMerge(t *testing.T) {
+	t0 := time.Now()
+	tests := []struct {
+		name                          string
+		a, b                          []Sample
+		sent, fails, min, max, avg, p int
+	}{
+		{"empty into empty", nil, nil, 0, 0, 0, 0, 0, 0},
+		{"into empty", nil, replies(t0, 20, 40), 2, 0, 20, 40, 30, 40},
+		{"empty into", replies(t0, 20, 40), nil, 2, 0, 20, 40, 30, 40},
+		{"runs", replies(t0, 30, 50), append(replies(t0, 10), Sample{Time: t0, RTT: -1}), 4, 1, 10, 50, 30, 50},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := Summarize(tt.a)
+			s.Merge(Summarize(tt.b))
+			if s.Sent != tt.sent || s.Fails != tt.fails || s.Min != tt.min || s.Max != tt.max || s.Avg() != tt.avg {
+				t.Errorf("merged sent %d fails %d min %d max %d avg %d, want %d %d %d %d %d",
+					s.Sent, s.Fails, s.Min, s.Max, s.Avg(), tt.sent, tt.fails, tt.min, tt.max, tt.avg)
+			}
+			if got := s.Quantile(1); got != tt.p {
+				t.Errorf("merged max quantile %d, want %d", got, tt.p)
+			}
+		})
+	}
+}
+
+func TestLoss(t *testing.T) {
+	fail := Sample{RTT: -1}
+	ok := Sample{RTT: 10, Success: true}
+	tests := []struct {
+		name    string
+		samples []Sample
+		want    float64
+	}{
+		{"no request", nil, 0},
+		{"no loss", []Sample{ok, ok}, 0},
+		{"all lost", []Sample{fail, fail}, 100},
+		{"quarter", []Sample{ok, fail, ok, ok}, 25},
+		{"third", []Sample{ok, ok, fail}, 100.0 / 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Summarize(tt.samples).Loss(); got != tt.want {
+				t.Errorf("Loss() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+	var s Summary
+	s.Account(fail)
+	s.Account(ok)
+	if s.Loss() != 50 || len(s.Samples()) != 0 {
+		t.Errorf("accounted loss %v with %d samples, want 50 without", s.Loss(), len(s.Samples()))
+	}
+}
@@ -0,0 +1,12 @@
+//go:build sqlite
+// +build sqlite
+
+package store
+
+import (
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func init() {
+	driver = "sqlite3"
+}
@@ -0,0 +1,334 @@
+// Package store persists the targets of the pingo program with their
+// configs, samples and alert events into a SQLite database so they
+// survive across program runs. The SQLite driver is only compiled in
+// with the sqlite build tag.
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const schema = `
+CREATE TABLE IF NOT EXISTS targets (
+	ip        TEXT PRIMARY KEY,
+	requests  INTEGER NOT NULL DEFAULT 0,
+	threshold INTEGER NOT NULL DEFAULT 0,
+	timeout   INTEGER NOT NULL DEFAULT 0,
+	size      INTEGER NOT NULL DEFAULT 0,
+	backup    INTEGER NOT NULL DEFAULT 0
+);
+CREATE TABLE IF NOT EXISTS samples (
+	ip      TEXT NOT NULL,
+	time    INTEGER NOT NULL,
+	success INTEGER NOT NULL,
+	rtt     INTEGER NOT NULL
+);
+CREATE INDEX IF NOT EXISTS samples_ip_time ON samples (ip, time);
+CREATE TABLE IF NOT EXISTS events (
+	ip        TEXT NOT NULL,
+	time      INTEGER NOT NULL,
+	state     TEXT NOT NULL,
+	escalated INTEGER NOT NULL DEFAULT 0
+);
+`
+
+// migrations are the columns added to the targets table over
+// time. The missing ones are added on each start.
+var migrations = []struct {
+	column     string
+	definition string
+}{
+	{"maxhops", "INTEGER NOT NULL DEFAULT 0"},
+	{"queries", "INTEGER NOT NULL DEFAULT 0"},
+	{"protocol", "TEXT NOT NULL DEFAULT ''"},
+	{"numeric", "INTEGER NOT NULL DEFAULT 0"},
+	{"probe", "TEXT NOT NULL DEFAULT ''"},
+	{"qname", "TEXT NOT NULL DEFAULT ''"},
+	{"qtype", "TEXT NOT NULL DEFAULT ''"},
+	{"mac", "TEXT NOT NULL DEFAULT ''"},
+	{"broadcast", "TEXT NOT NULL DEFAULT ''"},
+	{"iperf", "INTEGER NOT NULL DEFAULT 0"},
+	{"pattern", "TEXT NOT NULL DEFAULT ''"},
+	{"interval", "INTEGER NOT NULL DEFAULT 0"},
+	{"maxloss", "INTEGER NOT NULL DEFAULT 0"},
+	{"label", "TEXT NOT NULL DEFAULT ''"},
+	{"tags", "TEXT NOT NULL DEFAULT ''"},
+}
+
+var (
+	// driver is set when the program is built with sqlite tag.
+	driver string
+
+	// ErrNoSQLite is returned by Open without the sqlite driver.
+	ErrNoSQLite = errors.New("sqlite support not compiled in (build with -tags sqlite)")
+)
+
+// Target is a persisted target with its config.
+type Target struct {
+	IP        string
+	Requests  int
+	Threshold int
+	Timeout   int
+	Size      int
+	Backup    bool
+	MaxHops   int
+	Queries   int
+	Protocol  string
+	Numeric   bool
+	Probe     string
+	QName     string
+	QType     string
+	MAC       string
+	Broadcast string
+	Iperf     int
+	Pattern   string
+	Interval  int
+	MaxLoss   int
+	Label     string
+	Tags      string
+}
+
+// Sample is a persisted probe result. RTT is in milliseconds.
+type Sample struct {
+	IP      string
+	Time    time.Time
+	Success bool
+	RTT     int
+}
+
+// Event is a persisted fired alert.
+type Event struct {
+	IP        string
+	Time      time.Time
+	State     string
+	Escalated bool
+}
+
+// DB is an opened store.
+type DB struct {
+	db *sql.DB
+	// age of the expired samples and events, 0 when kept forever.
+	retention time.Duration
+}
+
+// Open opens (or creates) the SQLite database file and
+// migrates its tables to the current schema.
+func Open(path string, retention time.Duration) (*DB, error) {
+	if driver == "" {
+		return nil, ErrNoSQLite
+	}
+
+	db, err := sql.Open(driver, path)
+	if err != nil {
+		return nil, err
+	}
+	// sqlite does not support concurrent writers.
+	db.SetMaxOpenConns(1)
+
+	if _, err = db.Exec(schema); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	if err = migrate(db); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("migration failed: %v", err)
+	}
+	return &DB{db: db, retention: retention}, nil
+}
+
+// migrate adds the columns of the targets table missing
+// from a database created by a previous version.
+func migrate(db *sql.DB) error {
+	rows, err := db.Query("PRAGMA table_info(targets)")
+	if err != nil {
+		return err
+	}
+	columns := make(map[string]bool)
+	for rows.Next() {
+		var cid, notnull, pk int
+		var name, kind string
+		var value sql.NullString
+		if err = rows.Scan(&cid, &name, &kind, &notnull, &value, &pk); err != nil {
+			rows.Close()
+			return err
+		}
+		columns[name] = true
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return err
+	}
+
+	for _, m := range migrations {
+		if columns[m.column] {
+			continue
+		}
+		if _, err = db.Exec("ALTER TABLE targets ADD COLUMN " + m.column + " " + m.definition); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// millis returns the stored form of a time.
+func millis(t time.Time) int64 {
+	return t.UnixNano() / int64(time.Millisecond)
+}
+
+// fromMillis returns the time of a stored one.
+func fromMillis(ms int64) time.Time {
+	return time.Unix(0, ms*int64(time.Millisecond))
+}
+
+// Prune removes the samples and events older than the retention.
+func (st *DB) Prune() error {
+	if st.retention <= 0 {
+		return nil
+	}
+	before := millis(time.Now().Add(-st.retention))
+	if _, err := st.db.Exec("DELETE FROM samples WHERE time < ?", before); err != nil {
+		return fmt.Errorf("expired samples: %v", err)
+	}
+	if _, err := st.db.Exec("DELETE FROM events WHERE time < ?", before); err != nil {
+		return fmt.Errorf("expired events: %v", err)
+	}
+	return nil
+}
+
+// Targets returns all persisted targets.
+func (st *DB) Targets() ([]Target, error) {
+	rows, err := st.db.Query("SELECT ip, requests, threshold, timeout, size, backup, maxhops, queries, protocol, numeric, probe, qname, qtype, mac, broadcast, iperf, pattern, interval, maxloss, label, tags FROM targets")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var targets []Target
+	for rows.Next() {
+		var t Target
+		if err = rows.Scan(&t.IP, &t.Requests, &t.Threshold, &t.Timeout, &t.Size, &t.Backup,
+			&t.MaxHops, &t.Queries, &t.Protocol, &t.Numeric, &t.Probe, &t.QName, &t.QType,
+			&t.MAC, &t.Broadcast, &t.Iperf, &t.Pattern, &t.Interval, &t.MaxLoss, &t.Label, &t.Tags); err != nil {
+			return nil, err
+		}
+		targets = append(targets, t)
+	}
+	return targets, rows.Err()
+}
+
+// Samples returns at most limit latest samples of an ip between
+// two dates in chronological order. It serves historical queries.
+func (st *DB) Samples(ip string, from, to time.Time, limit int) ([]Sample, error) {
+	rows, err := st.db.Query("SELECT time, success, rtt FROM samples WHERE ip = ? AND time BETWEEN ? AND ? ORDER BY time DESC LIMIT ?",
+		ip, millis(from), millis(to), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var samples []Sample
+	for rows.Next() {
+		var ms int64
+		sp := Sample{IP: ip}
+		if err = rows.Scan(&ms, &sp.Success, &sp.RTT); err != nil {
+			return nil, err
+		}
+		sp.Time = fromMillis(ms)
+		samples = append(samples, sp)
+	}
+	// the rows were selected from the latest.
+	for i, j := 0, len(samples)-1; i < j; i, j = i+1, j-1 {
+		samples[i], samples[j] = samples[j], samples[i]
+	}
+	return samples, rows.Err()
+}
+
+// Events returns all persisted events in chronological order.
+func (st *DB) Events() ([]Event, error) {
+	rows, err := st.db.Query("SELECT ip, time, state, escalated FROM events ORDER BY time")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []Event
+	for rows.Next() {
+		var e Event
+		var ms int64
+		if err = rows.Scan(&e.IP, &ms, &e.State, &e.Escalated); err != nil {
+			return nil, err
+		}
+		e.Time = fromMillis(ms)
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
+
+// SaveTarget inserts or updates a target and its config.
+func (st *DB) SaveTarget(t Target) error {
+	_, err := st.db.Exec(`INSERT INTO targets (ip, requests, threshold, timeout, size, backup, maxhops, queries, protocol, numeric, probe, qname, qtype, mac, broadcast, iperf, pattern, interval, maxloss, label, tags)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?) ON CONFLICT(ip) DO UPDATE SET requests = excluded.requests,
+		threshold = excluded.threshold, timeout = excluded.timeout, size = excluded.size, backup = excluded.backup,
+		maxhops = excluded.maxhops, queries = excluded.queries, protocol = excluded.protocol, numeric = excluded.numeric,
+		probe = excluded.probe, qname = excluded.qname, qtype = excluded.qtype, mac = excluded.mac, broadcast = excluded.broadcast,
+		iperf = excluded.iperf, pattern = excluded.pattern, interval = excluded.interval, maxloss = excluded.maxloss,
+		label = excluded.label, tags = excluded.tags`,
+		t.IP, t.Requests, t.Threshold, t.Timeout, t.Size, t.Backup, t.MaxHops, t.Queries, t.Protocol, t.Numeric,
+		t.Probe, t.QName, t.QType, t.MAC, t.Broadcast, t.Iperf, t.Pattern, t.Interval, t.MaxLoss, t.Label, t.Tags)
+	return err
+}
+
+// DeleteTarget removes a target with its samples and events within a
+// single transaction so its alerts are not loaded back.
+func (st *DB) DeleteTarget(ip string) error {
+	tx, err := st.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	for _, table := range []string{"targets", "samples", "events"} {
+		if _, err = tx.Exec("DELETE FROM "+table+" WHERE ip = ?", ip); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// SaveEvent records a fired alert.
+func (st *DB) SaveEvent(e Event) error {
+	_, err := st.db.Exec("INSERT INTO events (ip, time, state, escalated) VALUES (?, ?, ?, ?)",
+		e.IP, millis(e.Time), e.State, e.Escalated)
+	return err
+}
+
+// InsertSamples writes a batch of samples within a single transaction.
+func (st *DB) InsertSamples(batch []Sample) error {
+	if len(batch) == 0 {
+		return nil
+	}
+
+	tx, err := st.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	for _, sp := range batch {
+		_, err = tx.Exec("INSERT INTO samples (ip, time, success, rtt) VALUES (?, ?, ?, ?)",
+			sp.IP, millis(sp.Time), sp.Success, sp.RTT)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// Close closes the database.
+func (st *DB) Close() error {
+	return st.db.Close()
+}
@@ -0,0 +1,56 @@
+package store
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// openTest opens a store in a temporary directory, skipping the
+// test without the sqlite driver.
+func openTest(t *testing.T) *DB {
+	t.Helper()
+	st, err := Open(filepath.Join(t.TempDir(), "pingo.db"), 0)
+	if err == ErrNoSQLite {
+		t.Skip(err)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { st.Close() })
+	return st
+}
+
+func TestDeleteTarget(t *testing.T) {
+	st := openTest(t)
+	now := time.Now()
+	for _, ip := range []string{"10.0.0.1", "10.0.0.2"} {
+		if err := st.SaveTarget(Target{IP: ip, Requests: 5, Tags: "core"}); err != nil {
+			t.Fatal(err)
+		}
+		if err := st.InsertSamples([]Sample{{IP: ip, Time: now, Success: true, RTT: 10}}); err != nil {
+			t.Fatal(err)
+		}
+		if err := st.SaveEvent(Event{IP: ip, Time: now, State: "down"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := st.DeleteTarget("10.0.0.1"); err != nil {
+		t.Fatal(err)
+	}
+
+	targets, err := st.Targets()
+	if err != nil || len(targets) != 1 || targets[0].IP != "10.0.0.2" || targets[0].Requests != 5 || targets[0].Tags != "core" {
+		t.Fatalf("targets %+v (%v)", targets, err)
+	}
+	events, err := st.Events()
+	if err != nil || len(events) != 1 || events[0].IP != "10.0.0.2" {
+		t.Fatalf("events %+v (%v)", events, err)
+	}
+	for ip, want := range map[string]int{"10.0.0.1": 0, "10.0.0.2": 1} {
+		if samples, err := st.Samples(ip, time.Time{}, now.Add(time.Second), 10); err != nil || len(samples) != want {
+			t.Fatalf("samples of %s %+v (%v)", ip, samples, err)
+		}
+	}
+}
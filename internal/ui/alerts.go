@@ -0,0 +1,326 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	STATEUNKNOWN = ""
+	STATEUP      = "up"
+	STATEDOWN    = "down"
+	// traceroute path to a target changed.
+	STATEPATH = "path changed"
+	// tls certificate of a target expires soon.
+	STATECERT = "certificate expiring"
+	// periodic summary of all targets.
+	STATEDIGEST = "digest"
+	// several targets of a subnet down together.
+	STATEOUTAGE = "group outage"
+)
+
+// alert represents a target state change with
+// a snapshot of its statistics at that moment.
+type alert struct {
+	ip    string
+	state string
+	time  time.Time
+	stats stat
+	// sustained downtime notification.
+	escalated bool
+	// description of a path change.
+	details string
+}
+
+// notifier defines any alert delivery channel.
+type notifier interface {
+	notify(a alert)
+}
+
+var (
+	// state changes to be delivered by notifiers, sized by initQueues.
+	alertsChan = make(chan alert, 100)
+)
+
+// String formats an alert into a single human-readable line.
+func (a alert) String() string {
+	if a.state == STATEPATH {
+		return fmt.Sprintf("[%s] %s path changed (%s)", a.time.Format("2006-01-02 15:04:05"), a.ip, a.details)
+	}
+	if a.state == STATECERT {
+		return fmt.Sprintf("[%s] %s certificate %s", a.time.Format("2006-01-02 15:04:05"), a.ip, a.details)
+	}
+	if a.state == STATEDIGEST {
+		return fmt.Sprintf("[%s] digest of the %s", a.time.Format("2006-01-02 15:04:05"), a.details)
+	}
+	if a.state == STATEOUTAGE {
+		return fmt.Sprintf("[%s] %s group outage (%s)", a.time.Format("2006-01-02 15:04:05"), a.ip, a.details)
+	}
+
+	state := a.state
+	if a.escalated {
+		state = "still down (escalated)"
+	}
+	return fmt.Sprintf("[%s] %s is %s (fails: %d - loss: %.1f%% - min/avg/max: %d/%d/%d ms)",
+		a.time.Format("2006-01-02 15:04:05"), a.ip, state, a.stats.fails,
+		a.stats.loss(), a.stats.min, a.stats.avg, a.stats.max)
+}
+
+// replies returns the number of successful responses.
+func (s *stat) replies() int {
+	return s.match + s.above + s.under
+}
+
+// loss returns the percentage of failed requests.
+func (s *stat) loss() float64 {
+	total := s.fails + s.replies()
+	if total == 0 {
+		return 0
+	}
+	return float64(s.fails) * 100 / float64(total)
+}
+
+// updateState tracks consecutive failures of a target and triggers
+// an alert when it goes down or when it recovers from a down state.
+func updateState(ip string, s *stat, failed bool) {
+	cfgs := getSettings()
+	if failed {
+		s.streak += 1
+		if s.state != STATEDOWN && s.streak >= cfgs.Alerts.DownAfter {
+			s.state = STATEDOWN
+			highlights.mark(ip, STATEDOWN)
+			sendAlert(ip, s)
+		}
+		return
+	}
+
+	s.streak = 0
+	if s.state == STATEDOWN {
+		s.state = STATEUP
+		highlights.mark(ip, STATEUP)
+		sendAlert(ip, s)
+		return
+	}
+	s.state = STATEUP
+}
+
+// sendAlert queues an alert without blocking the caller.
+func sendAlert(ip string, s *stat) {
+	// the daemon delivers the alerts of an attached ui.
+	if remote != nil {
+		return
+	}
+	a := alert{ip: ip, state: s.state, time: time.Now(), stats: *s}
+	select {
+	case alertsChan <- a:
+	default:
+		count(&counters.droppedAlerts)
+		alertsLog.Warn("Alerts queue full, dropped alert", "alert", a)
+	}
+}
+
+// sendPathAlert queues a traceroute path change alert of an ip.
+func sendPathAlert(ip string, changes []string) {
+	a := alert{ip: ip, state: STATEPATH, time: time.Now(), details: strings.Join(changes, " - ")}
+	select {
+	case alertsChan <- a:
+	default:
+		count(&counters.droppedAlerts)
+		alertsLog.Warn("Alerts queue full, dropped alert", "alert", a)
+	}
+}
+
+// sendCertAlert queues a certificate expiry alert of a target.
+func sendCertAlert(ip string, details string) {
+	a := alert{ip: ip, state: STATECERT, time: time.Now(), details: details}
+	select {
+	case alertsChan <- a:
+	default:
+		count(&counters.droppedAlerts)
+		alertsLog.Warn("Alerts queue full, dropped alert", "alert", a)
+	}
+}
+
+// buildNotifiers returns the alert channels enabled into settings by name.
+func buildNotifiers() map[string]notifier {
+	cfgs := getSettings()
+	notifiers := make(map[string]notifier)
+	if cfgs.SMTP.Enabled {
+		notifiers["smtp"] = newSMTPNotifier(cfgs.SMTP)
+	}
+
+	if cfgs.Exec.Enabled && cfgs.Exec.Command != "" {
+		notifiers["exec"] = newExecNotifier(cfgs.Exec)
+	}
+
+	if cfgs.Syslog.Enabled {
+		notifiers["syslog"] = newSyslogNotifier(cfgs.Syslog)
+	}
+
+	if cfgs.SNMP.Enabled {
+		notifiers["snmp"] = newSNMPNotifier(cfgs.SNMP)
+	}
+	return notifiers
+}
+
+// alertsManager decides which state changes are delivered. It drops
+// duplicates, holds repeated down alerts during the cooldown period,
+// suppresses flapping targets, collapses the targets of a subnet down
+// together into an outage and escalates sustained downtime.
+type alertsManager struct {
+	notifiers map[string]notifier
+	// latest state change and last delivered state per ip.
+	current  map[string]alert
+	notified map[string]string
+	// last time a down alert was delivered per ip.
+	lastDown map[string]time.Time
+	// recent state changes timestamps per ip.
+	changes   map[string][]time.Time
+	escalated map[string]bool
+	// ongoing outages per subnet.
+	outages map[string]*outage
+}
+
+// newAlertsManager creates an alerts manager.
+func newAlertsManager(notifiers map[string]notifier) *alertsManager {
+	return &alertsManager{
+		notifiers: notifiers,
+		current:   make(map[string]alert),
+		notified:  make(map[string]string),
+		lastDown:  make(map[string]time.Time),
+		changes:   make(map[string][]time.Time),
+		escalated: make(map[string]bool),
+		outages:   make(map[string]*outage),
+	}
+}
+
+// alertsDispatcher feeds the alerts manager with each state change,
+// periodically re-evaluates held alerts and escalations and delivers
+// the digest of the targets.
+func alertsDispatcher(notifiers map[string]notifier) {
+	am := newAlertsManager(notifiers)
+	ticker := time.NewTicker(10 * time.Second)
+	defer ticker.Stop()
+	var digest <-chan time.Time
+	// the digest period needs a restart to change.
+	if every := getSettings().Alerts.Digest.Every; every > 0 {
+		period := time.Duration(every) * time.Hour
+		digestTicker := time.NewTicker(period)
+		defer digestTicker.Stop()
+		digest = digestTicker.C
+	}
+	for {
+		select {
+		case a := <-alertsChan:
+			// path changes and certificates expiry are not
+			// target states, deliver them as is.
+			if a.state == STATEPATH || a.state == STATECERT {
+				alertsLog.Info("Target event", "target", a.ip, "event", a.state, "details", a.details)
+				am.deliver(a, getSettings().Alerts.Notify)
+				continue
+			}
+			alertsLog.Info("State changed", "target", a.ip, "state", a.state)
+			am.record(a)
+			am.evaluate(a.ip, time.Now())
+		case now := <-ticker.C:
+			for ip := range am.current {
+				am.evaluate(ip, now)
+			}
+		case now := <-digest:
+			digest := getSettings().Alerts.Digest
+			d := buildDigest(now, time.Duration(digest.Every)*time.Hour, digest.Worst)
+			alertsLog.Info("Targets digest", "details", d.details)
+			am.deliver(d, digest.Notify)
+		case <-exit:
+			return
+		}
+	}
+}
+
+// record saves a state change and its time for flap detection.
+func (am *alertsManager) record(a alert) {
+	am.current[a.ip] = a
+	am.changes[a.ip] = append(am.changes[a.ip], a.time)
+	if a.state != STATEDOWN {
+		am.escalated[a.ip] = false
+	}
+}
+
+// isFlapping tells if an ip changed state too often within the flap window.
+func (am *alertsManager) isFlapping(ip string, now time.Time) bool {
+	cfgs := getSettings()
+	if cfgs.Alerts.FlapCount <= 0 {
+		return false
+	}
+
+	window := time.Duration(cfgs.Alerts.FlapWindow) * time.Minute
+	var recent []time.Time
+	for _, t := range am.changes[ip] {
+		if now.Sub(t) <= window {
+			recent = append(recent, t)
+		}
+	}
+	am.changes[ip] = recent
+
+	return len(recent) >= cfgs.Alerts.FlapCount
+}
+
+// evaluate delivers the current state of an ip if it differs from the
+// last notified one and escalates a down state lasting for too long.
+func (am *alertsManager) evaluate(ip string, now time.Time) {
+	cfgs := getSettings()
+	a, ok := am.current[ip]
+	if !ok {
+		return
+	}
+
+	notified := am.notified[ip]
+	if notified == STATEUNKNOWN {
+		notified = STATEUP
+	}
+
+	if a.state != notified {
+		switch {
+		case am.isFlapping(ip, now):
+			alertsLog.Info("Alert held since the target is flapping", "target", ip)
+		case a.state == STATEDOWN && now.Sub(am.lastDown[ip]) < time.Duration(cfgs.Alerts.Cooldown)*time.Minute:
+			alertsLog.Info("Alert held during cooldown period", "target", ip)
+		case a.state == STATEDOWN && am.correlateDown(ip, a, now):
+		case a.state == STATEUP && am.correlateUp(ip, now):
+		default:
+			if a.state == STATEDOWN {
+				am.lastDown[ip] = now
+			}
+			am.notified[ip] = a.state
+			am.deliver(a, cfgs.Alerts.Notify)
+		}
+	}
+
+	esc := cfgs.Alerts.Escalation
+	if esc.After > 0 && a.state == STATEDOWN && am.notified[ip] == STATEDOWN && !am.escalated[ip] &&
+		am.outageOf(ip) == nil && now.Sub(a.time) >= time.Duration(esc.After)*time.Minute {
+		am.escalated[ip] = true
+		a.escalated = true
+		am.deliver(a, esc.Notify)
+	}
+}
+
+// deliver sends an alert to the given notifiers or to all if none is given.
+func (am *alertsManager) deliver(a alert, names []string) {
+	center.add(a)
+	store.saveEvent(a)
+	hub.publishAlert(a)
+	if len(names) == 0 {
+		for _, n := range am.notifiers {
+			n.notify(a)
+		}
+		return
+	}
+
+	for _, name := range names {
+		if n, ok := am.notifiers[name]; ok {
+			n.notify(a)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package ui
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jroimartin/gocui"
+)
+
+// annotation is a timestamped note of an action taken, dropped from
+// the ui while a target was focused, to correlate the measurements.
+type annotation struct {
+	time time.Time
+	ip   string
+	text string
+}
+
+// annotationStore keeps the notes of the session.
+type annotationStore struct {
+	lock  *sync.RWMutex
+	items []annotation
+}
+
+// global timeline notes.
+var annotations = &annotationStore{lock: &sync.RWMutex{}}
+
+// String formats a note as displayed into the outputs view.
+func (a annotation) String() string {
+	return fmt.Sprintf("[note %s] %s (on %s)", a.time.Format("2006-01-02 15:04:05"), a.text, a.ip)
+}
+
+// add records a note and shows it into the outputs and on the web
+// dashboard graphs.
+func (as *annotationStore) add(a annotation) {
+	as.lock.Lock()
+	as.items = append(as.items, a)
+	as.lock.Unlock()
+	bus.publish(EVOUTPUT, a.String())
+	hub.publishNote(a)
+}
+
+// list returns a copy of all notes in time order.
+func (as *annotationStore) list() []annotation {
+	as.lock.RLock()
+	defer as.lock.RUnlock()
+	items := make([]annotation, len(as.items))
+	copy(items, as.items)
+	return items
+}
+
+// annotationInputView displays a temporary input box to enter a note
+// about the focused ip.
+func annotationInputView(g *gocui.Gui, ipv *gocui.View) error {
+	_, cy := ipv.Cursor()
+	l, err := ipv.Line(cy)
+	if err != nil || len(strings.Fields(l)) < 2 {
+		return nil
+	}
+	ip := strings.Fields(l)[1]
+
+	maxX, maxY := g.Size()
+
+	const name = "annotation"
+
+	// construct the input box and position at the center of the screen.
+	if inputView, err := g.SetView(name, maxX/2-35, maxY/2, maxX/2+35, maxY/2+2); err != nil {
+		if err != gocui.ErrUnknownView {
+			log.Println("Failed to display input view: ", err)
+			return err
+		}
+
+		inputView.Title = fmt.Sprintf(" [%s] | Note (e.g. changed SFP) ", ip)
+		inputView.FgColor = gocui.ColorYellow
+		inputView.SelBgColor = gocui.ColorBlack
+		inputView.SelFgColor = gocui.ColorYellow
+		inputView.Editable = true
+
+		if _, err := g.SetCurrentView(name); err != nil {
+			log.Println(err)
+			return err
+		}
+		g.Cursor = true
+		inputView.Highlight = true
+		// bind Enter key to processInput function.
+		if err := g.SetKeybinding(name, gocui.KeyEnter, gocui.ModNone, processInput); err != nil {
+			log.Println(err)
+			return err
+		}
+
+		// bind Ctrl+Q and Escape keys to close the input box.
+		if err := g.SetKeybinding(name, gocui.KeyCtrlQ, gocui.ModNone, closeInputView); err != nil {
+			log.Println(err)
+			return err
+		}
+
+		if err := g.SetKeybinding(name, gocui.KeyEsc, gocui.ModNone, closeInputView); err != nil {
+			log.Println(err)
+			return err
+		}
+	}
+	return nil
+}
+
+// addAnnotation records a note entered from the ui.
+func addAnnotation(ip, text string) {
+	text = strings.Join(strings.Fields(text), " ")
+	if text == "" {
+		return
+	}
+	annotations.add(annotation{time: time.Now(), ip: ip, text: text})
+}
@@ -0,0 +1,402 @@
+package ui
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jroimartin/gocui"
+)
+
+// control socket client connection of the ui to a running daemon.
+// It is nil when the ui probes the targets by itself.
+var remote *attachClient
+
+// attachCommand is a request sent by an attached ui to the daemon.
+type attachCommand struct {
+	Cmd     string   `json:"cmd"`
+	Targets []string `json:"targets"`
+	// name of the template applied to the added targets.
+	Template string `json:"template,omitempty"`
+}
+
+// attachEvent is a live event received by an attached ui.
+type attachEvent struct {
+	Type   string          `json:"type"`
+	Time   time.Time       `json:"time"`
+	Target string          `json:"target"`
+	Data   json.RawMessage `json:"data"`
+}
+
+// defaultSocketPath returns the control socket path used by the
+// daemon and the attached ui when none is provided.
+func defaultSocketPath() string {
+	return filepath.Join(os.TempDir(), "pingo.sock")
+}
+
+// disableLocalOutputs turns off the notifiers, sinks, servers and
+// store of an attached ui since the daemon already provides them.
+func disableLocalOutputs(s *settings) {
+	s.SMTP.Enabled, s.Exec.Enabled, s.Syslog.Enabled, s.SNMP.Enabled = false, false, false, false
+	s.HTTP.Enabled, s.GRPC.Enabled = false, false
+	s.Influx.Enabled, s.Graphite.Enabled, s.Statsd.Enabled = false, false, false
+	s.Stream, s.SessionFile = "", ""
+	s.Store.Enabled = false
+	s.TLS.Checks = nil
+}
+
+// startControlServer listens on the unix socket where uis attach to the
+// daemon or to a shared session. It fails if another instance already
+// serves the same socket. Each attached ui receives the list of targets
+// then the live events and may add or delete targets unless the socket
+// is read-only. Closing the ui only detaches it. A read-only socket is
+// open to all local users so they can watch without changing anything.
+func startControlServer(path string, readOnly bool) (net.Listener, error) {
+	if conn, err := net.DialTimeout("unix", path, time.Second); err == nil {
+		conn.Close()
+		return nil, errors.New("another instance is listening on " + path)
+	}
+	// remove the socket left by a crashed daemon.
+	os.Remove(path)
+
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	// only the owner can control the daemon.
+	mode := os.FileMode(0600)
+	if readOnly {
+		mode = 0666
+	}
+	os.Chmod(path, mode)
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go serveAttached(conn, readOnly)
+		}
+	}()
+	return ln, nil
+}
+
+// serveAttached streams the live events to an attached ui
+// and applies its commands until it detaches.
+func serveAttached(conn net.Conn, readOnly bool) {
+	defer recoverPanic("serveAttached")
+	defer conn.Close()
+	logs.Info("UI attached", "subsystem", "control", "read_only", readOnly)
+
+	events := hub.subscribe()
+	defer hub.unsubscribe(events)
+
+	// tell the ui to lock its controls before the greeting.
+	if readOnly {
+		if err := writeEvent(conn, liveEvent{Type: "mode", Time: time.Now(), Data: "read-only"}); err != nil {
+			return
+		}
+	}
+	if err := writeEvent(conn, liveEvent{Type: "targets", Time: time.Now(), Data: dbs.getAllIPs()}); err != nil {
+		return
+	}
+
+	// the target currently watched by the session.
+	if ip := currentOnPingIP; ip != "" {
+		writeEvent(conn, liveEvent{Type: "focus", Time: time.Now(), Target: ip})
+	}
+
+	detached := make(chan struct{})
+	go func() {
+		defer close(detached)
+		dec := json.NewDecoder(conn)
+		for {
+			var c attachCommand
+			if err := dec.Decode(&c); err != nil {
+				return
+			}
+			if readOnly {
+				logs.Warn("Command refused on read-only socket", "subsystem", "control", "cmd", c.Cmd)
+				continue
+			}
+			switch c.Cmd {
+			case "add":
+				addTargets(c.Targets)
+				if t := templateOf(c.Template); t != nil {
+					for _, ip := range c.Targets {
+						dbs.applyTemplate(strings.TrimSpace(ip), t)
+					}
+				}
+			case "delete":
+				deleteTargets(c.Targets)
+			}
+		}
+	}()
+
+	for {
+		select {
+		case data := <-events:
+			conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+			if _, err := conn.Write(append(data, '\n')); err != nil {
+				return
+			}
+		case <-detached:
+			logs.Info("UI detached", "subsystem", "control")
+			return
+		case <-exit:
+			return
+		}
+	}
+}
+
+// writeEvent sends a single event as JSON line.
+func writeEvent(w io.Writer, e liveEvent) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
+
+// attachClient is the connection of the ui to the daemon. Ping outputs
+// lines are dispatched to the watchers of their target.
+type attachClient struct {
+	conn     net.Conn
+	lock     *sync.Mutex
+	watchers map[string]map[chan ProbeEvent]struct{}
+	closed   bool
+	// controls changing the targets are locked.
+	readOnly bool
+}
+
+// attachDaemon connects to the daemon control socket and
+// loads its targets in place of any local targets.
+func attachDaemon(path string, readOnly bool) (*attachClient, error) {
+	conn, err := net.DialTimeout("unix", path, 5*time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	rc := &attachClient{conn: conn, lock: &sync.Mutex{}, watchers: make(map[string]map[chan ProbeEvent]struct{}), readOnly: readOnly}
+	reader := bufio.NewReader(conn)
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err != nil {
+			conn.Close()
+			return nil, err
+		}
+
+		var hello attachEvent
+		if err = json.Unmarshal(line, &hello); err != nil {
+			conn.Close()
+			return nil, errors.New("unexpected daemon greeting")
+		}
+		if hello.Type == "mode" {
+			rc.readOnly = true
+			continue
+		}
+		if hello.Type != "targets" {
+			conn.Close()
+			return nil, errors.New("unexpected daemon greeting")
+		}
+		rc.syncTargets(hello.Data)
+		break
+	}
+	conn.SetReadDeadline(time.Time{})
+
+	go rc.receive(reader)
+	return rc, nil
+}
+
+// receive dispatches the daemon live events until the connection ends.
+func (rc *attachClient) receive(reader *bufio.Reader) {
+	defer recoverPanic("attachClient.receive")
+	defer rc.close()
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err != nil {
+			uiLog.Warn("Connection to the daemon lost", "err", err)
+			return
+		}
+
+		var e attachEvent
+		if err = json.Unmarshal(line, &e); err != nil {
+			continue
+		}
+
+		switch e.Type {
+		case "output":
+			var pe ProbeEvent
+			if json.Unmarshal(e.Data, &pe) == nil {
+				pe.Target = e.Target
+				rc.dispatch(pe)
+			}
+		case "state":
+			// alerts are delivered by the daemon, only keep them into the center.
+			var s liveState
+			if json.Unmarshal(e.Data, &s) == nil {
+				center.add(alert{ip: e.Target, state: s.State, time: e.Time, details: s.Details})
+			}
+		case "targets":
+			rc.syncTargets(e.Data)
+		case "focus":
+			// follow the target pinged by the shared session.
+			if rc.isReadOnly() && e.Target != currentOnPingIP {
+				go follow(e.Target)
+			}
+		}
+	}
+}
+
+// isReadOnly tells if the controls changing the targets are locked.
+func (rc *attachClient) isReadOnly() bool {
+	rc.lock.Lock()
+	defer rc.lock.Unlock()
+	return rc.readOnly
+}
+
+// follow displays the live outputs of the target watched by the session.
+func follow(ip string) {
+	bus.publish(EVTITLE, fmt.Sprintf(" Ping [%s] Outputs (Following) ", ip))
+	ipToPingChan <- ip
+	currentOnPingIP = ip
+	currentOutputsIP = ip
+	bus.publish(EVFOCUS, ip)
+}
+
+// writable wraps a key handler which changes the targets so it is
+// refused when the ui is attached to a session in read-only mode.
+func writable(handler func(*gocui.Gui, *gocui.View) error) func(*gocui.Gui, *gocui.View) error {
+	return func(g *gocui.Gui, v *gocui.View) error {
+		if remote != nil && remote.isReadOnly() {
+			bus.publish(EVOUTPUT, "Read-only view : this action is not allowed.")
+			return nil
+		}
+		return handler(g, v)
+	}
+}
+
+// syncTargets mirrors the daemon targets into the local database.
+func (rc *attachClient) syncTargets(data json.RawMessage) {
+	var ips []string
+	if err := json.Unmarshal(data, &ips); err != nil {
+		return
+	}
+
+	current := make(map[string]bool, len(ips))
+	for _, ip := range ips {
+		current[ip] = true
+		if !dbs.isExistsIP(ip) {
+			dbs.addNewIP(ip)
+		}
+	}
+	for _, ip := range dbs.getAllIPs() {
+		if !current[ip] && ip != currentOnPingIP {
+			dbs.deleteIP(ip)
+		}
+	}
+	refreshIPs()
+}
+
+// command asks the daemon to add or delete comma-separated targets.
+func (rc *attachClient) command(cmd, ips string) {
+	c := attachCommand{Cmd: cmd}
+	if i := strings.LastIndex(ips, TEMPLATESEP); i >= 0 {
+		ips, c.Template = ips[:i], strings.TrimSpace(ips[i+len(TEMPLATESEP):])
+	}
+	for _, ip := range strings.Split(ips, ",") {
+		if ip = strings.TrimSpace(ip); ip != "" {
+			c.Targets = append(c.Targets, ip)
+		}
+	}
+
+	data, err := json.Marshal(c)
+	if err != nil {
+		return
+	}
+	rc.conn.SetWriteDeadline(time.Now().Add(5 * time.Second))
+	if _, err = rc.conn.Write(append(data, '\n')); err != nil {
+		uiLog.Error("Failed to send command to the daemon", "cmd", cmd, "err", err)
+	}
+}
+
+// dispatch forwards a probe event to the watchers of its target.
+func (rc *attachClient) dispatch(e ProbeEvent) {
+	rc.lock.Lock()
+	defer rc.lock.Unlock()
+	for c := range rc.watchers[e.Target] {
+		select {
+		case c <- e:
+		default:
+		}
+	}
+}
+
+// watch displays the live ping outputs of an ip received from the
+// daemon until cancelled. The statistics are computed since watching.
+func (rc *attachClient) watch(ip string, ctx context.Context, handle func(e ProbeEvent)) {
+	lines := make(chan ProbeEvent, 100)
+	rc.lock.Lock()
+	if rc.closed {
+		rc.lock.Unlock()
+		bus.publish(EVOUTPUT, "Connection to the pingo daemon lost. Restart the ui to attach again.")
+		return
+	}
+	if rc.watchers[ip] == nil {
+		rc.watchers[ip] = make(map[chan ProbeEvent]struct{})
+	}
+	rc.watchers[ip][lines] = struct{}{}
+	rc.lock.Unlock()
+
+	defer func() {
+		rc.lock.Lock()
+		delete(rc.watchers[ip], lines)
+		rc.lock.Unlock()
+	}()
+
+	dbs.initStats(ip)
+	bus.publish(EVOUTPUT, "Attached to the pingo daemon. Waiting for the live outputs of "+ip+" ...")
+	for {
+		select {
+		case e, ok := <-lines:
+			if !ok {
+				bus.publish(EVOUTPUT, "Connection to the pingo daemon lost. Restart the ui to attach again.")
+				return
+			}
+			handle(e)
+		case <-ctx.Done():
+			return
+		case <-exit:
+			return
+		}
+	}
+}
+
+// close ends all watchers once the daemon connection is lost.
+func (rc *attachClient) close() {
+	rc.conn.Close()
+	rc.lock.Lock()
+	defer rc.lock.Unlock()
+	if rc.closed {
+		return
+	}
+	rc.closed = true
+	for _, watchers := range rc.watchers {
+		for c := range watchers {
+			close(c)
+		}
+	}
+	rc.watchers = make(map[string]map[chan ProbeEvent]struct{})
+}
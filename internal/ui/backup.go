@@ -0,0 +1,123 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// backupWriter tees the outputs lines of an ip into a per-ip file named
+// pingo_<ip>_<date>.log. A new file is started when the date changes or
+// when the current file reaches the maximum size (with _<n> suffix).
+type backupWriter struct {
+	ip    string
+	date  string
+	index int
+	size  int64
+	file  *os.File
+	// files written with their date, to read a run back.
+	parts []backupPart
+}
+
+// backupPart is a backup file holding the lines of a given date.
+type backupPart struct {
+	path string
+	date string
+}
+
+// newBackupWriter returns a writer for an ip if its backup flag is set.
+func newBackupWriter(ip string) *backupWriter {
+	cfg := dbs.getConfig(ip)
+	if cfg == nil || !cfg.backup {
+		return nil
+	}
+	return &backupWriter{ip: ip}
+}
+
+// filename builds the current backup file path.
+func (bw *backupWriter) filename() string {
+	cfgs := getSettings()
+	ip := strings.Replace(bw.ip, ":", "-", -1)
+	name := fmt.Sprintf("pingo_%s_%s.log", ip, bw.date)
+	if bw.index > 0 {
+		name = fmt.Sprintf("pingo_%s_%s_%d.log", ip, bw.date, bw.index)
+	}
+	return filepath.Join(cfgs.Backup.Dir, name)
+}
+
+// rotate closes the current file and opens the next one.
+func (bw *backupWriter) rotate(date string) error {
+	cfgs := getSettings()
+	bw.close()
+	if date != bw.date {
+		bw.date, bw.index = date, 0
+	} else {
+		bw.index++
+	}
+
+	if err := os.MkdirAll(cfgs.Backup.Dir, 0755); err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(bw.filename(), os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+
+	bw.file, bw.size = f, info.Size()
+	bw.parts = append(bw.parts, backupPart{path: bw.filename(), date: bw.date})
+	return nil
+}
+
+// write appends a timestamped line to the backup file.
+func (bw *backupWriter) write(line string) error {
+	cfgs := getSettings()
+	if bw == nil {
+		return nil
+	}
+
+	now := time.Now()
+	date := now.Format("20060102")
+	if bw.file == nil || date != bw.date || bw.size >= int64(cfgs.Backup.MaxSize)*1024*1024 {
+		if err := bw.rotate(date); err != nil {
+			return err
+		}
+	}
+
+	n, err := fmt.Fprintf(bw.file, "%s %s\n", now.Format("15:04:05.000"), line)
+	bw.size += int64(n)
+	return err
+}
+
+// close closes the current backup file.
+func (bw *backupWriter) close() {
+	if bw == nil || bw.file == nil {
+		return
+	}
+	bw.file.Close()
+	bw.file = nil
+}
+
+// files returns the backup files written so far.
+func (bw *backupWriter) files() []backupPart {
+	if bw == nil {
+		return nil
+	}
+	return bw.parts
+}
+
+// backupIndicator returns a title mark when the backup of an ip is active.
+func backupIndicator(ip string) string {
+	if cfg := dbs.getConfig(ip); cfg != nil && cfg.backup {
+		return "[REC] "
+	}
+	return ""
+}
@@ -0,0 +1,98 @@
+package ui
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/jroimartin/gocui"
+)
+
+const (
+	BANNER = "banner"
+
+	// time an error stays displayed into the banner.
+	BANNERDURATION = 8 * time.Second
+)
+
+// errorBanner is the latest failure displayed at the bottom of the
+// outputs view, so the user knows what failed without reading logs.
+type errorBanner struct {
+	lock *sync.Mutex
+	text string
+	// errors shown while the banner was displayed.
+	count int
+	until time.Time
+}
+
+// global error banner of the ui.
+var banner = &errorBanner{lock: &sync.Mutex{}}
+
+// showError displays a failure into the error banner. The caller still
+// logs the failure with its details.
+func showError(format string, args ...interface{}) {
+	banner.lock.Lock()
+	if time.Now().After(banner.until) {
+		banner.count = 0
+	}
+	banner.text = fmt.Sprintf(format, args...)
+	banner.count++
+	banner.until = time.Now().Add(BANNERDURATION)
+	banner.lock.Unlock()
+	refreshStatus()
+}
+
+// current returns the banner line or an empty string once expired.
+func (b *errorBanner) current() string {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if b.text == "" || time.Now().After(b.until) {
+		return ""
+	}
+	if b.count > 1 {
+		return fmt.Sprintf(" ERROR | %s (and %d more into the logs) ", b.text, b.count-1)
+	}
+	return fmt.Sprintf(" ERROR | %s ", b.text)
+}
+
+// expire clears the banner once its time passed. It tells whether the
+// ui must be redrawn to hide it.
+func (b *errorBanner) expire() bool {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if b.text == "" || time.Now().Before(b.until) {
+		return false
+	}
+	b.text, b.count = "", 0
+	return true
+}
+
+// layoutBanner draws the error banner over the last line of the outputs
+// view. Like the pinned table header, it is shrunk to nothing when
+// there is no error to show.
+func layoutBanner(g *gocui.Gui) error {
+	x0, _, x1, y1, err := g.ViewPosition(OUTPUTS)
+	if err != nil {
+		return nil
+	}
+	text := banner.current()
+	if text == "" {
+		x1 = x0 + 1
+	} else if x0+len(text)+1 < x1 {
+		x1 = x0 + len(text) + 1
+	}
+	bv, err := g.SetView(BANNER, x0, y1-2, x1, y1)
+	if err != nil && err != gocui.ErrUnknownView {
+		log.Println("Failed to create error banner view:", err)
+		return err
+	}
+	if err == gocui.ErrUnknownView {
+		bv.Frame = false
+		bv.FgColor = gocui.ColorWhite | gocui.AttrBold
+		bv.BgColor = gocui.ColorRed
+	}
+	bv.Clear()
+	fmt.Fprint(bv, text)
+	return nil
+}
@@ -0,0 +1,58 @@
+package ui
+
+import (
+	"fmt"
+	"math"
+	"sync"
+
+	"github.com/jeamon/pingo/internal/stats"
+)
+
+// color of the anomalies lines into the outputs view.
+const COLORANOMALY = "\x1b[33m"
+
+// baselineStore keeps the baseline of each target.
+type baselineStore struct {
+	lock  *sync.Mutex
+	items map[string]*stats.Baseline
+}
+
+// global latency baselines.
+var baselines = &baselineStore{lock: &sync.Mutex{}, items: make(map[string]*stats.Baseline)}
+
+// observe checks a reply time of an ip against its baseline then
+// learns it. An anomaly is reported with its description for the
+// outputs view. Nothing is detected until the warmup replies are
+// learned.
+func (bs *baselineStore) observe(ip string, rtt int) (string, bool) {
+	cfgs := getSettings()
+	if cfgs.Baseline.Sigma <= 0 {
+		return "", false
+	}
+	bs.lock.Lock()
+	defer bs.lock.Unlock()
+	b, ok := bs.items[ip]
+	if !ok {
+		b = &stats.Baseline{}
+		bs.items[ip] = b
+	}
+
+	x := float64(rtt)
+	message, anomaly := "", false
+	if b.Count >= cfgs.Baseline.Warmup {
+		sigmas := b.Sigmas(x)
+		if anomaly = math.Abs(sigmas) > cfgs.Baseline.Sigma; anomaly {
+			message = fmt.Sprintf("%s[anomaly] %s replied in %d ms : %+.1fσ from its baseline %.1f ± %.1f ms%s",
+				COLORANOMALY, ip, rtt, sigmas, b.Mean, b.Deviation(), COLORRESET)
+		}
+	}
+	b.Learn(x, cfgs.Baseline.Window)
+	return message, anomaly
+}
+
+// forget drops the baseline of a deleted ip.
+func (bs *baselineStore) forget(ip string) {
+	bs.lock.Lock()
+	delete(bs.items, ip)
+	bs.lock.Unlock()
+}
@@ -0,0 +1,58 @@
+//go:build darwin || freebsd || dragonfly || openbsd
+// +build darwin freebsd dragonfly openbsd
+
+package ui
+
+import (
+	"context"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// privilegesHint tells how to allow the native icmp engine.
+const privilegesHint = "run pingo as root"
+
+// buildTracerouteCommand constructs full traceroute command to run
+// with the focused ip options and the BSD traceroute flags shared by
+// macOS, FreeBSD (pfSense, OPNsense) and OpenBSD : TCP probes use -P
+// tcp instead of -T and the timeout config is the wait in seconds (-w)
+// of each probe. IPv6 targets are traced with traceroute6 which has
+// no protocol option.
+func buildTracerouteCommand(ip string, ctx context.Context) *exec.Cmd {
+	cfg := dbs.getConfig(ip)
+	name := "traceroute"
+	if strings.Contains(ip, ":") {
+		name = "traceroute6"
+	}
+	var args []string
+
+	if cfg.maxhops > 0 {
+		args = append(args, "-m", strconv.Itoa(cfg.maxhops))
+	}
+
+	if cfg.queries > 0 {
+		args = append(args, "-q", strconv.Itoa(cfg.queries))
+	}
+
+	if cfg.timeout > 0 {
+		args = append(args, "-w", strconv.Itoa(cfg.timeout))
+	}
+
+	if name == "traceroute" {
+		switch cfg.protocol {
+		case "icmp":
+			args = append(args, "-I")
+		case "tcp":
+			args = append(args, "-P", "tcp")
+		}
+	}
+
+	if cfg.numeric {
+		args = append(args, "-n")
+	}
+
+	args = append(args, ip)
+
+	return exec.CommandContext(ctx, name, args...)
+}
@@ -0,0 +1,211 @@
+package ui
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jeamon/pingo/internal/probe"
+	"github.com/jeamon/pingo/internal/stats"
+)
+
+// ui event kinds published on the bus.
+const (
+	// ip focused by the cursor or the scheduler.
+	EVFOCUS = iota
+	// ping or traceroute output line.
+	EVOUTPUT
+	// full content replacing the outputs view (mtr table).
+	EVTABLE
+	// custom title of the outputs view.
+	EVTITLE
+	// cleanup of the outputs view.
+	EVCLEAROUTPUTS
+	// probe result accounted into the statistics of its target.
+	EVSTATS
+	// cleanup of the statistics view.
+	EVCLEARSTATS
+	// full content replacing the statistics view (tools summaries).
+	EVSTATSTEXT
+)
+
+// default maximum number of queued output lines of a subscription.
+const BUSQUEUESIZE = 500
+
+// interval between two refreshes of the views with the queued events.
+const UIREFRESH = 100 * time.Millisecond
+
+type uiEvent struct {
+	kind int
+	text string
+	// probe output of the statistics events.
+	probe *ProbeEvent
+}
+
+// ProbeEvent is an output line of a probe with its parsed result. It
+// is carried from the prober loop to the views, the live clients and
+// the attached uis so none of them parses the raw line again.
+type ProbeEvent struct {
+	Target    string `json:"-"`
+	Threshold int    `json:"threshold,omitempty"`
+	Line      string `json:"line"`
+	// result of the request, nil for lines without
+	// result (header or summary).
+	Sample *stats.Sample `json:"sample,omitempty"`
+	// time to live and size in bytes of the reply, 0 when unknown.
+	TTL  int `json:"ttl,omitempty"`
+	Size int `json:"size,omitempty"`
+}
+
+// newProbeEvent builds the event of a probe output of an ip. seq
+// numbers the results of the run.
+func newProbeEvent(ip string, threshold int, ps probe.Sample, seq *int) ProbeEvent {
+	e := ProbeEvent{Target: ip, Threshold: threshold, Line: ps.Line, TTL: ps.TTL, Size: ps.Size}
+	if !ps.Informational {
+		*seq++
+		e.Sample = &stats.Sample{Time: ps.Time, RTT: ps.RTT, Seq: *seq, Success: ps.Success}
+	}
+	return e
+}
+
+// uiBus dispatches the ui events to the subscribed views. Publishing
+// never blocks so it is safe from the gocui main loop (key handlers)
+// and from the probing routines even when a view is behind.
+type uiBus struct {
+	lock *sync.RWMutex
+	subs map[int][]*subscription
+}
+
+// ui events dispatcher.
+var bus = &uiBus{lock: &sync.RWMutex{}, subs: make(map[int][]*subscription)}
+
+// subscription is the events queue of a subscriber. Once size output
+// lines are queued, the oldest line is dropped and accounted so the
+// subscriber can tell the user. Other events are never dropped and
+// consecutive focus, title, table or statistics events only keep the
+// latest.
+type subscription struct {
+	lock    *sync.Mutex
+	queue   []uiEvent
+	size    int
+	lines   int
+	dropped int
+}
+
+// subscribe registers a subscriber to the given kinds of events.
+func (b *uiBus) subscribe(size int, kinds ...int) *subscription {
+	s := &subscription{lock: &sync.Mutex{}, size: size}
+	b.lock.Lock()
+	for _, k := range kinds {
+		b.subs[k] = append(b.subs[k], s)
+	}
+	b.lock.Unlock()
+	return s
+}
+
+// unsubscribe stops queuing events to a subscriber.
+func (b *uiBus) unsubscribe(s *subscription) {
+	b.lock.Lock()
+	for k, subs := range b.subs {
+		for i, sub := range subs {
+			if sub == s {
+				b.subs[k] = append(subs[:i:i], subs[i+1:]...)
+				break
+			}
+		}
+	}
+	b.lock.Unlock()
+}
+
+// publish queues an event to each subscriber of its kind.
+func (b *uiBus) publish(kind int, text string) {
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+	for _, s := range b.subs[kind] {
+		s.push(uiEvent{kind: kind, text: text})
+	}
+}
+
+// publishProbe queues a probe event to each subscriber of its kind.
+func (b *uiBus) publishProbe(kind int, e ProbeEvent) {
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+	for _, s := range b.subs[kind] {
+		s.push(uiEvent{kind: kind, text: e.Target, probe: &e})
+	}
+}
+
+// depth returns the number of events queued by all subscribers.
+func (b *uiBus) depth() int {
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+	seen := make(map[*subscription]bool)
+	n := 0
+	for _, subs := range b.subs {
+		for _, s := range subs {
+			if seen[s] {
+				continue
+			}
+			seen[s] = true
+			s.lock.Lock()
+			n += len(s.queue)
+			s.lock.Unlock()
+		}
+	}
+	return n
+}
+
+// push queues an event until the subscriber drains it.
+func (s *subscription) push(e uiEvent) {
+	s.lock.Lock()
+	switch e.kind {
+	case EVFOCUS, EVTITLE, EVTABLE, EVSTATS, EVSTATSTEXT:
+		if n := len(s.queue); n > 0 && s.queue[n-1].kind == e.kind {
+			s.queue[n-1] = e
+			break
+		}
+		s.queue = append(s.queue, e)
+	case EVOUTPUT:
+		if s.lines >= s.size {
+			s.dropOldestLine()
+		}
+		s.queue = append(s.queue, e)
+		s.lines++
+	default:
+		s.queue = append(s.queue, e)
+	}
+	s.lock.Unlock()
+}
+
+// dropOldestLine removes the first queued output line.
+func (s *subscription) dropOldestLine() {
+	for i, e := range s.queue {
+		if e.kind == EVOUTPUT {
+			s.queue = append(s.queue[:i], s.queue[i+1:]...)
+			s.lines--
+			s.dropped++
+			count(&counters.droppedLines)
+			return
+		}
+	}
+}
+
+// drain returns the queued events in order. Dropped output lines
+// are reported by a notice line placed before the remaining ones.
+func (s *subscription) drain() []uiEvent {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	events := s.queue
+	s.queue, s.lines = nil, 0
+	if s.dropped > 0 {
+		notice := uiEvent{kind: EVOUTPUT, text: fmt.Sprintf("[pingo] %d lines dropped : the display is too slow.", s.dropped)}
+		s.dropped = 0
+		for i, e := range events {
+			if e.kind == EVOUTPUT {
+				events = append(events[:i], append([]uiEvent{notice}, events[i:]...)...)
+				break
+			}
+		}
+	}
+	return events
+}
@@ -0,0 +1,275 @@
+package ui
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jroimartin/gocui"
+)
+
+// certCheck is a tls certificate check of an ip port. The server
+// name (sni) is also used to verify the certificate if set.
+type certCheck struct {
+	ip   string
+	port int
+	sni  string
+}
+
+// certificate checks requested from the ui.
+var certCheckChan = make(chan certCheck, 1)
+
+// certReport holds the details of a server leaf certificate.
+type certReport struct {
+	subject  string
+	sans     []string
+	issuer   string
+	from     time.Time
+	until    time.Time
+	verified error
+}
+
+// days returns the number of full days before the certificate expiry.
+func (r *certReport) days(now time.Time) int {
+	return int(r.until.Sub(now).Hours() / 24)
+}
+
+// parseCertCheck reads a check defined as "ip[:port] [sni]".
+func parseCertCheck(input string) (certCheck, error) {
+	fields := strings.Fields(input)
+	if len(fields) == 0 {
+		return certCheck{}, errors.New("empty check")
+	}
+
+	c := certCheck{ip: fields[0], port: 443}
+	if host, port, err := net.SplitHostPort(fields[0]); err == nil {
+		c.ip = host
+		if c.port, err = strconv.Atoi(port); err != nil || c.port < 1 || c.port > 65535 {
+			return certCheck{}, errors.New("bad port " + port)
+		}
+	}
+	if !isValidIP(c.ip) {
+		return certCheck{}, errors.New("bad ip " + c.ip)
+	}
+	if len(fields) > 1 {
+		c.sni = fields[1]
+	}
+	return c, nil
+}
+
+// certCheckInputView displays a temporary input box to enter the
+// port and the server name to check on the focused ip.
+func certCheckInputView(g *gocui.Gui, ipv *gocui.View) error {
+	_, cy := ipv.Cursor()
+	l, err := ipv.Line(cy)
+	if err != nil || len(strings.Fields(l)) < 2 {
+		return nil
+	}
+	ip := strings.Fields(l)[1]
+
+	maxX, maxY := g.Size()
+
+	const name = "certCheck"
+
+	// construct the input box and position at the center of the screen.
+	if inputView, err := g.SetView(name, maxX/2-35, maxY/2, maxX/2+35, maxY/2+2); err != nil {
+		if err != gocui.ErrUnknownView {
+			log.Println("Failed to display input view: ", err)
+			return err
+		}
+
+		inputView.Title = fmt.Sprintf(" [%s] | TLS Port & Server Name (443 www.example.com) ", ip)
+		inputView.FgColor = gocui.ColorYellow
+		inputView.SelBgColor = gocui.ColorBlack
+		inputView.SelFgColor = gocui.ColorYellow
+		inputView.Editable = true
+
+		if _, err := g.SetCurrentView(name); err != nil {
+			log.Println(err)
+			return err
+		}
+		g.Cursor = true
+		inputView.Highlight = true
+		// bind Enter key to processInput function.
+		if err := g.SetKeybinding(name, gocui.KeyEnter, gocui.ModNone, processInput); err != nil {
+			log.Println(err)
+			return err
+		}
+
+		// bind Ctrl+Q and Escape keys to close the input box.
+		if err := g.SetKeybinding(name, gocui.KeyCtrlQ, gocui.ModNone, closeInputView); err != nil {
+			log.Println(err)
+			return err
+		}
+
+		if err := g.SetKeybinding(name, gocui.KeyEsc, gocui.ModNone, closeInputView); err != nil {
+			log.Println(err)
+			return err
+		}
+
+		inputView.Write([]byte("443"))
+		inputView.SetCursor(len(inputView.Buffer())-1, 0)
+	}
+	return nil
+}
+
+// addCertCheck parses the entered port and server name
+// then sends the check to the scheduler.
+func addCertCheck(ip, input string) {
+	fields := strings.Fields(input)
+	if len(fields) > 0 {
+		fields[0] = net.JoinHostPort(ip, fields[0])
+	}
+	c, err := parseCertCheck(strings.Join(fields, " "))
+	if err != nil {
+		bus.publish(EVOUTPUT, "Invalid certificate check : "+err.Error())
+		return
+	}
+
+	bus.publish(EVTITLE, fmt.Sprintf(" TLS Certificate [%s] Outputs ", ip))
+	certCheckChan <- c
+	// reset since no ping.
+	currentOnPingIP = ""
+	currentOutputsIP = ip
+}
+
+// executeCertCheck fetches a server certificate and
+// displays its details into the outputs view.
+func executeCertCheck(c certCheck, ctx context.Context) {
+	defer recoverPanic("executeCertCheck")
+	address := net.JoinHostPort(c.ip, strconv.Itoa(c.port))
+	sni := c.sni
+	if sni == "" {
+		sni = "none"
+	}
+	bus.publish(EVOUTPUT, fmt.Sprintf("Connecting to %s (server name %s) ...", address, sni))
+
+	r, err := fetchCert(ctx, c)
+	if ctx.Err() != nil {
+		return
+	}
+	if err != nil {
+		bus.publish(EVOUTPUT, "Failed to get the certificate : "+err.Error())
+		return
+	}
+
+	now := time.Now()
+	verified := "yes"
+	if r.verified != nil {
+		verified = "no (" + r.verified.Error() + ")"
+	}
+	for _, line := range []string{
+		"subject  : " + r.subject,
+		"names    : " + strings.Join(r.sans, ", "),
+		"issuer   : " + r.issuer,
+		"valid    : " + r.from.Format("2006-01-02 15:04") + " -> " + r.until.Format("2006-01-02 15:04"),
+		fmt.Sprintf("expiry   : in %d days", r.days(now)),
+		"verified : " + verified,
+	} {
+		bus.publish(EVOUTPUT, line)
+	}
+	checkCertExpiry(c, r, now)
+}
+
+// fetchCert connects to the server and returns its leaf certificate
+// details. The chain is verified separately so an invalid one is
+// still reported.
+func fetchCert(ctx context.Context, c certCheck) (*certReport, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	d := &tls.Dialer{Config: &tls.Config{ServerName: c.sni, InsecureSkipVerify: true}}
+	conn, err := d.DialContext(ctx, "tcp", net.JoinHostPort(c.ip, strconv.Itoa(c.port)))
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	certs := conn.(*tls.Conn).ConnectionState().PeerCertificates
+	if len(certs) == 0 {
+		return nil, errors.New("no certificate presented")
+	}
+	leaf := certs[0]
+
+	r := &certReport{subject: leaf.Subject.String(), sans: leaf.DNSNames, issuer: leaf.Issuer.String(),
+		from: leaf.NotBefore, until: leaf.NotAfter}
+	for _, ip := range leaf.IPAddresses {
+		r.sans = append(r.sans, ip.String())
+	}
+
+	opts := x509.VerifyOptions{DNSName: c.sni, Intermediates: x509.NewCertPool()}
+	if opts.DNSName == "" {
+		opts.DNSName = c.ip
+	}
+	for _, cert := range certs[1:] {
+		opts.Intermediates.AddCert(cert)
+	}
+	_, r.verified = leaf.Verify(opts)
+	return r, nil
+}
+
+// checkCertExpiry raises an alert when the certificate expires
+// within the configured number of days.
+func checkCertExpiry(c certCheck, r *certReport, now time.Time) {
+	cfgs := getSettings()
+	days := r.days(now)
+	if days > cfgs.TLS.WarnDays {
+		return
+	}
+
+	name := c.sni
+	if name == "" {
+		name = r.subject
+	}
+	details := fmt.Sprintf("port %d %s expires in %d days on %s", c.port, name, days, r.until.Format("2006-01-02"))
+	if days < 0 {
+		details = fmt.Sprintf("port %d %s expired on %s", c.port, name, r.until.Format("2006-01-02"))
+	}
+	sendCertAlert(c.ip, details)
+}
+
+// certsChecker periodically checks the certificates listed into
+// settings and alerts about the ones expiring soon.
+func certsChecker(checks []string) {
+	cfgs := getSettings()
+	var list []certCheck
+	for _, check := range checks {
+		c, err := parseCertCheck(check)
+		if err != nil {
+			alertsLog.Error("Invalid certificate check", "check", check, "err", err)
+			continue
+		}
+		list = append(list, c)
+	}
+	if len(list) == 0 {
+		return
+	}
+
+	ticker := time.NewTicker(time.Duration(cfgs.TLS.Every) * time.Hour)
+	defer ticker.Stop()
+	for {
+		for _, c := range list {
+			r, err := fetchCert(probes, c)
+			if err != nil {
+				if probes.Err() == nil {
+					alertsLog.Warn("Failed to check certificate", "target", c.ip, "port", c.port, "err", err)
+				}
+				continue
+			}
+			checkCertExpiry(c, r, time.Now())
+		}
+
+		select {
+		case <-ticker.C:
+		case <-exit:
+			return
+		}
+	}
+}
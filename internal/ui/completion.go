@@ -0,0 +1,102 @@
+package ui
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/jroimartin/gocui"
+)
+
+// maximum number of candidates listed under an input box.
+const COMPLETIONLIST = 6
+
+// completeInput returns the <Tab> handler of an input box. It completes
+// the last comma-separated entry with the longest prefix shared by its
+// candidates and lists them under the box when several are possible.
+func completeInput(candidates func(prefix string) []string) func(*gocui.Gui, *gocui.View) error {
+	return func(g *gocui.Gui, iv *gocui.View) error {
+		text := strings.TrimRight(iv.Buffer(), "\n")
+		head := text[:strings.LastIndex(text, ",")+1]
+		entry := text[len(head):]
+		word := strings.TrimLeft(entry, " ")
+
+		matches := candidates(word)
+		if len(matches) == 0 {
+			return showInputMessage(g, iv, "no match", gocui.ColorRed)
+		}
+		setInputText(iv, head+entry[:len(entry)-len(word)]+commonPrefix(matches))
+		if len(matches) == 1 {
+			deleteInputMessage(g)
+			return nil
+		}
+
+		list := matches
+		if len(list) > COMPLETIONLIST {
+			list = list[:COMPLETIONLIST]
+		}
+		message := strings.Join(list, "  ")
+		if more := len(matches) - len(list); more > 0 {
+			message += fmt.Sprintf("  (+%d more)", more)
+		}
+		return showInputMessage(g, iv, message, gocui.ColorCyan)
+	}
+}
+
+// completeIPs returns the IPs of the list starting with prefix.
+func completeIPs(prefix string) []string {
+	var matches []string
+	for _, ip := range dbs.getAllIPs() {
+		if strings.HasPrefix(ip, prefix) {
+			matches = append(matches, ip)
+		}
+	}
+	sort.Strings(matches)
+	return matches
+}
+
+// completePaths returns the files and folders starting with prefix,
+// as typed by the user. Folders end with a path separator so the next
+// <Tab> completes their content.
+func completePaths(prefix string) []string {
+	dir, base := filepath.Split(prefix)
+	read := dir
+	if read == "" {
+		read = "."
+	}
+
+	entries, err := ioutil.ReadDir(read)
+	if err != nil {
+		return nil
+	}
+	var matches []string
+	for _, e := range entries {
+		if !strings.HasPrefix(e.Name(), base) {
+			continue
+		}
+		// hidden files are only listed once their dot is typed.
+		if base == "" && strings.HasPrefix(e.Name(), ".") {
+			continue
+		}
+		if e.IsDir() {
+			matches = append(matches, dir+e.Name()+string(os.PathSeparator))
+		} else {
+			matches = append(matches, dir+e.Name())
+		}
+	}
+	return matches
+}
+
+// commonPrefix returns the longest prefix shared by all values.
+func commonPrefix(values []string) string {
+	prefix := values[0]
+	for _, v := range values[1:] {
+		for !strings.HasPrefix(v, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	return prefix
+}
@@ -0,0 +1,85 @@
+package ui
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// environment variables overriding the flags defaults and listing
+// the targets, for running pingo into a container.
+const (
+	ENVCONFIG   = "PINGO_CONFIG"
+	ENVLOGLEVEL = "PINGO_LOG_LEVEL"
+	ENVLOGFILE  = "PINGO_LOG_FILE"
+	ENVHTTP     = "PINGO_HTTP"
+	ENVTARGETS  = "PINGO_TARGETS"
+)
+
+// STDERRLOG is the logs file value writing the logs to stderr.
+const STDERRLOG = "-"
+
+// envOr returns the value of an environment variable or def if unset.
+func envOr(name, def string) string {
+	if v, ok := os.LookupEnv(name); ok {
+		return v
+	}
+	return def
+}
+
+// inContainer tells whether pingo runs into a docker, podman or
+// kubernetes container.
+func inContainer() bool {
+	if _, err := os.Stat("/.dockerenv"); err == nil {
+		return true
+	}
+	if _, err := os.Stat("/run/.containerenv"); err == nil {
+		return true
+	}
+	return os.Getenv("container") != "" || os.Getenv("KUBERNETES_SERVICE_HOST") != ""
+}
+
+// targetsFromEnv returns the entries of the PINGO_TARGETS variable,
+// separated by commas or new lines, like 8.8.8.8,1.1.1.1@dns.
+func targetsFromEnv() []string {
+	return strings.FieldsFunc(os.Getenv(ENVTARGETS), func(r rune) bool {
+		return r == ',' || r == '\n'
+	})
+}
+
+// healthStatus is the state of the process served by /healthz.
+type healthStatus struct {
+	Status     string `json:"status"`
+	Uptime     int64  `json:"uptime_seconds"`
+	Targets    int    `json:"targets"`
+	Routines   int    `json:"routines"`
+	Restarting int    `json:"restarting"`
+}
+
+// healthzHandler serves the liveness of pingo for the container
+// orchestrators : 503 while a supervised routine restarts after a
+// panic or once pingo is stopping, 200 otherwise.
+func healthzHandler(w http.ResponseWriter, r *http.Request) {
+	status := healthStatus{Status: "ok", Uptime: int64(time.Since(startTime) / time.Second), Targets: len(dbs.getAllIPs())}
+	for _, rs := range routines.list() {
+		status.Routines++
+		if rs.state == ROUTINERESTARTING {
+			status.Restarting++
+		}
+	}
+	code := http.StatusOK
+	select {
+	case <-exit:
+		status.Status, code = "stopping", http.StatusServiceUnavailable
+	default:
+		if status.Restarting > 0 {
+			status.Status, code = "degraded", http.StatusServiceUnavailable
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(status)
+}
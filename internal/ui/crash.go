@@ -0,0 +1,128 @@
+package ui
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jroimartin/gocui"
+)
+
+// session snapshot written on panic and offered for restore on next start.
+const CRASHSESSION = "crash-session.json"
+
+var (
+	// terminal ui to close on panic so the terminal is restored.
+	gui *gocui.Gui
+	// only the first panic is reported.
+	crashOnce sync.Once
+)
+
+// crashDir returns the folder of the diagnostic dumps, beside the logs.
+func crashDir() string {
+	return filepath.Dir(defaultLogFile())
+}
+
+// recoverPanic must be deferred first by each short-lived routine not
+// supervised. It reports a panic with a diagnostic dump and a session
+// snapshot then exits.
+func recoverPanic(where string) {
+	if r := recover(); r != nil {
+		crash(where, r, debug.Stack())
+	}
+}
+
+// crash restores the terminal, writes the diagnostic dump and exits.
+// Routines panicking meanwhile wait for the exit.
+func crash(where string, r interface{}, stack []byte) {
+	crashOnce.Do(func() {
+		if gui != nil {
+			gui.Close()
+		}
+		logs.Error("Program panic", "routine", where, "panic", fmt.Sprint(r))
+
+		fmt.Fprintf(os.Stderr, "pingo crashed into %s : %v\n", where, r)
+		if path, err := writeCrashDump(where, r, stack); err != nil {
+			fmt.Fprintln(os.Stderr, "failed to write the diagnostic dump:", err)
+		} else {
+			fmt.Fprintln(os.Stderr, "diagnostic dump written into", path)
+		}
+		os.Exit(2)
+	})
+	select {}
+}
+
+// writeCrashDump writes the panic details with the stacks of all routines
+// then snapshots the session. The snapshot is skipped after a few seconds
+// since the panicking routine may still hold a datastore lock.
+func writeCrashDump(where string, r interface{}, stack []byte) (string, error) {
+	dir := crashDir()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+
+	buf := make([]byte, 1<<20)
+	all := buf[:runtime.Stack(buf, true)]
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "time: %s\nroutine: %s\npanic: %v\ngo: %s %s/%s\n\n", time.Now().Format(time.RFC3339), where, r,
+		runtime.Version(), runtime.GOOS, runtime.GOARCH)
+	fmt.Fprintf(&b, "%s\nall routines:\n\n%s", stack, all)
+
+	path := filepath.Join(dir, fmt.Sprintf("crash_%s.txt", time.Now().Format("20060102_150405")))
+	if err := ioutil.WriteFile(path, []byte(b.String()), 0644); err != nil {
+		return "", err
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- exportSession(filepath.Join(dir, CRASHSESSION))
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			logs.Error("Failed to snapshot the session on panic", "err", err)
+		}
+	case <-time.After(3 * time.Second):
+		logs.Error("Timed out snapshotting the session on panic")
+	}
+	return path, nil
+}
+
+// offerRestore asks on the terminal to restore the targets and configs
+// of the session which crashed. The snapshot is then renamed so it is
+// only offered once.
+func offerRestore() {
+	path := filepath.Join(crashDir(), CRASHSESSION)
+	info, err := os.Stat(path)
+	if err != nil {
+		return
+	}
+
+	// ask only for an interactive start.
+	fi, err := os.Stdin.Stat()
+	if err != nil || fi.Mode()&os.ModeCharDevice == 0 {
+		return
+	}
+
+	fmt.Printf("The previous session crashed on %s. Restore its targets and configs ? [y/N] ", info.ModTime().Format("2006-01-02 15:04:05"))
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	if strings.EqualFold(strings.TrimSpace(answer), "y") {
+		if err = importSession(path); err != nil {
+			fmt.Fprintln(os.Stderr, "failed to restore the session:", err)
+			storeLog.Error("Failed to restore crashed session", "file", path, "err", err)
+		}
+	}
+
+	kept := filepath.Join(crashDir(), fmt.Sprintf("crash-session_%s.json", info.ModTime().Format("20060102_150405")))
+	if err = os.Rename(path, kept); err != nil {
+		os.Remove(path)
+	}
+}
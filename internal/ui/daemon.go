@@ -0,0 +1,147 @@
+package ui
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// runDaemon monitors all targets in background without any ui nor
+// output, only alerts, sinks and the web server deliver the results.
+// Each target is pinged continuously with its own configs and a bounded
+// ping is started again every interval seconds, with at most the
+// queues workers targets pinged at once. Uis can attach to the
+// daemon through the control socket or watch it through the read-only
+// shared socket. It returns on SIGTERM or interrupt
+// signal or on windows service stop request. SIGHUP (or the windows
+// service parameters change) reloads the settings file and the
+// targets files. Run as a systemd Type=notify service, it reports
+// its readiness and pings the watchdog if enabled.
+func runDaemon(config, pidfile, socket, share string) int {
+	if len(dbs.getAllIPs()) == 0 {
+		logs.Error("No targets to monitor")
+		return 2
+	}
+
+	if pidfile != "" {
+		if err := ioutil.WriteFile(pidfile, []byte(strconv.Itoa(os.Getpid())+"\n"), 0644); err != nil {
+			logs.Error("Failed to write pid file", "file", pidfile, "err", err)
+			return 1
+		}
+		defer os.Remove(pidfile)
+	}
+
+	ln, err := startControlServer(socket, false)
+	if err != nil {
+		logs.Error("Failed to listen on control socket", "socket", socket, "err", err)
+		return 1
+	}
+	defer ln.Close()
+
+	if share != "" {
+		sln, err := startControlServer(share, true)
+		if err != nil {
+			logs.Error("Failed to listen on shared socket", "socket", share, "err", err)
+			return 1
+		}
+		defer sln.Close()
+	}
+
+	stop, reload, stopped := serviceControl()
+	defer stopped()
+
+	startWorkers()
+
+	ctx, cancel := context.WithCancel(probes)
+	lock := &sync.Mutex{}
+	var pwg sync.WaitGroup
+	running := make(map[string]context.CancelFunc)
+
+	// monitor starts pinging the added targets and stops the deleted ones.
+	monitor := func() {
+		ips := dbs.getAllIPs()
+		current := make(map[string]bool, len(ips))
+		for _, ip := range ips {
+			current[ip] = true
+			if _, ok := running[ip]; ok {
+				continue
+			}
+			tctx, tcancel := context.WithCancel(ctx)
+			running[ip] = tcancel
+			ip := ip
+			supervise(&pwg, "ping "+ip, func() {
+				for {
+					if !workers.acquire(tctx) {
+						return
+					}
+					func() {
+						defer workers.release()
+						runPing(ip, tctx, func(e ProbeEvent) {
+							lock.Lock()
+							buildStats(e)
+							lock.Unlock()
+						})
+					}()
+
+					select {
+					case <-tctx.Done():
+						return
+					case <-time.After(time.Duration(getSettings().Interval) * time.Second):
+					}
+				}
+			})
+		}
+
+		for ip, tcancel := range running {
+			if !current[ip] {
+				tcancel()
+				delete(running, ip)
+			}
+		}
+		hub.publishTargets(ips)
+	}
+
+	// the watchdog is pinged from the daemon loop so a stuck loop
+	// gets pingo restarted.
+	var watchdog <-chan time.Time
+	if every := watchdogInterval(); every > 0 {
+		ticker := time.NewTicker(every)
+		defer ticker.Stop()
+		watchdog = ticker.C
+	}
+
+	monitor()
+	logs.Info("Daemon started", "targets", len(running), "pid", os.Getpid(), "socket", socket)
+	sdNotify(fmt.Sprintf("READY=1\nSTATUS=Monitoring %d targets", len(running)))
+
+loop:
+	for {
+		select {
+		case <-ipsChangedChan:
+			monitor()
+		case <-reload:
+			sdNotify("RELOADING=1")
+			logs.Info("Daemon reloading", "config", config)
+			reloadSettings(config)
+			dbs.loadInfosFromFiles(flag.Args())
+			monitor()
+			sdNotify(fmt.Sprintf("READY=1\nSTATUS=Monitoring %d targets", len(running)))
+		case <-watchdog:
+			sdNotify("WATCHDOG=1")
+		case <-stop:
+			break loop
+		}
+	}
+
+	logs.Info("Daemon stopping")
+	sdNotify("STOPPING=1")
+	cancel()
+	pwg.Wait()
+	close(exit)
+	return 0
+}
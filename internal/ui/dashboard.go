@@ -0,0 +1,124 @@
+package ui
+
+import (
+	"embed"
+	"encoding/json"
+	"io/fs"
+	"net/http"
+	"sort"
+	"time"
+)
+
+// web dashboard static files embedded into the binary.
+//
+//go:embed web
+var webFiles embed.FS
+
+// maximum number of recent events returned to the dashboard.
+const DASHBOARDEVENTS = 50
+
+// dashboardState is the initial snapshot loaded by the web dashboard
+// before it follows the live updates over the websocket.
+type dashboardState struct {
+	Time    time.Time         `json:"time"`
+	Targets []dashboardTarget `json:"targets"`
+	Events  []dashboardEvent  `json:"events"`
+	Notes   []dashboardNote   `json:"notes"`
+}
+
+type dashboardTarget struct {
+	Target  string       `json:"target"`
+	Subnet  string       `json:"subnet"`
+	State   string       `json:"state"`
+	Sent    int          `json:"sent"`
+	Loss    float64      `json:"loss"`
+	Min     int          `json:"min_ms"`
+	Avg     int          `json:"avg_ms"`
+	Max     int          `json:"max_ms"`
+	Last    int          `json:"last_ms"`
+	History []liveSample `json:"history"`
+	// position of each note into the history.
+	Marks []int `json:"marks"`
+	// per-minute aggregates of the samples older than the history.
+	Minutes []dashboardMinute `json:"minutes"`
+}
+
+type dashboardMinute struct {
+	Time time.Time `json:"time"`
+	Sent int       `json:"sent"`
+	Loss float64   `json:"loss"`
+	Avg  int       `json:"avg_ms"`
+	Max  int       `json:"max_ms"`
+}
+
+type dashboardEvent struct {
+	Time    time.Time `json:"time"`
+	Target  string    `json:"target"`
+	State   string    `json:"state"`
+	Details string    `json:"details,omitempty"`
+}
+
+type dashboardNote struct {
+	Time   time.Time `json:"time"`
+	Target string    `json:"target"`
+	Text   string    `json:"text"`
+}
+
+// dashboardHandler serves the embedded web dashboard files.
+func dashboardHandler() http.Handler {
+	sub, err := fs.Sub(webFiles, "web")
+	if err != nil {
+		// cannot happen since the directory is embedded.
+		panic(err)
+	}
+	return http.FileServer(http.FS(sub))
+}
+
+// stateHandler returns the targets statistics with their latency
+// history and the latest alerts as JSON.
+func stateHandler(w http.ResponseWriter, r *http.Request) {
+	state := dashboardState{Time: time.Now(), Targets: []dashboardTarget{}, Events: []dashboardEvent{}, Notes: []dashboardNote{}}
+	notes := annotations.list()
+	for _, ip := range dbs.getAllIPs() {
+		t := dashboardTarget{Target: ip, Subnet: subnetOf(ip), State: STATEUNKNOWN, History: []liveSample{}, Marks: []int{}, Minutes: []dashboardMinute{}}
+		if s := dbs.getStats(ip); s != nil {
+			t.State, t.Sent, t.Loss = s.state, s.fails+s.replies(), s.loss()
+			t.Min, t.Avg, t.Max, t.Last = s.min, s.avg, s.max, s.last
+		}
+		for _, a := range dbs.getMinutes(ip) {
+			t.Minutes = append(t.Minutes, dashboardMinute{Time: a.Time, Sent: a.Sent, Loss: a.Loss(), Avg: a.Avg(), Max: a.Max})
+		}
+		history := dbs.getHistory(ip)
+		for _, sp := range history {
+			t.History = append(t.History, liveSample{Success: sp.success, RTT: sp.rtt})
+		}
+		// a note is marked before the first sample taken after it.
+		for _, a := range notes {
+			if len(history) > 0 && a.time.After(history[0].time) {
+				t.Marks = append(t.Marks, sort.Search(len(history), func(i int) bool { return history[i].time.After(a.time) }))
+			}
+		}
+		state.Targets = append(state.Targets, t)
+	}
+
+	items := center.list()
+	if len(items) > DASHBOARDEVENTS {
+		items = items[len(items)-DASHBOARDEVENTS:]
+	}
+	for _, n := range items {
+		state.Events = append(state.Events, dashboardEvent{Time: n.alert.time, Target: n.alert.ip,
+			State: n.alert.state, Details: n.alert.details})
+	}
+
+	if len(notes) > DASHBOARDEVENTS {
+		notes = notes[len(notes)-DASHBOARDEVENTS:]
+	}
+	for _, a := range notes {
+		state.Notes = append(state.Notes, dashboardNote{Time: a.time, Target: a.ip, Text: a.text})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(state); err != nil {
+		logs.Error("Failed to send dashboard state", "subsystem", "http", "err", err)
+	}
+}
@@ -0,0 +1,53 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// probeDeadline returns the longest wait of a ping output line of an
+// ip. It exceeds the reply timeout of the target so a slow reply is
+// not counted twice. 0 disables the deadline.
+func probeDeadline(cfg *config) time.Duration {
+	cfgs := getSettings()
+	deadline := time.Duration(cfgs.Deadlines.Probe) * time.Second
+	if deadline <= 0 {
+		return 0
+	}
+	if timeout := replyTimeout(cfg); timeout >= deadline {
+		deadline = timeout + time.Second
+	}
+	return deadline
+}
+
+// pingDeadline returns the longest run of a bounded ping : twice the
+// time to send its requests once per interval (a second by default).
+// Unbounded pings run until stopped and have no deadline.
+func pingDeadline(cfg *config) time.Duration {
+	cfgs := getSettings()
+	if cfg.requests <= 0 || cfgs.Deadlines.Probe <= 0 {
+		return 0
+	}
+	interval := time.Second
+	if cfg.interval > 0 {
+		interval = time.Duration(cfg.interval) * time.Millisecond
+	}
+	return 2*time.Duration(cfg.requests)*interval + probeDeadline(cfg)
+}
+
+// withRunDeadline bounds a traceroute command run so a hung command
+// is killed even if the os options do not stop it.
+func withRunDeadline(ctx context.Context) (context.Context, context.CancelFunc) {
+	cfgs := getSettings()
+	if cfgs.Deadlines.Run <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, time.Duration(cfgs.Deadlines.Run)*time.Minute)
+}
+
+// deadlineMessage tells into the outputs view that a run was killed.
+func deadlineMessage(name, ip string) string {
+	cfgs := getSettings()
+	return fmt.Sprintf("The %s of %s was stopped after the run deadline of %d min.", name, ip, cfgs.Deadlines.Run)
+}
@@ -0,0 +1,153 @@
+package ui
+
+import (
+	"context"
+	"expvar"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// internal counters exposed by the debug server. They are
+// only updated with atomic operations.
+var counters struct {
+	// probe results dispatched to the sinks.
+	samples uint64
+	// results dropped by a full sink or dispatcher queue.
+	droppedSamples uint64
+	// results which waited for room into the full samples queue.
+	blockedSamples uint64
+	// alerts dropped by the full alerts queue.
+	droppedAlerts uint64
+	// output lines dropped by a slow ui view.
+	droppedLines uint64
+	// live events dropped by a slow websocket, grpc or attached client.
+	droppedEvents uint64
+	// trims of the oldest data by the memory guard.
+	memoryTrims uint64
+}
+
+// count increments a counter.
+func count(c *uint64) {
+	atomic.AddUint64(c, 1)
+}
+
+// samplesRate keeps the previous read of the samples counter.
+var samplesRate = struct {
+	lock  *sync.Mutex
+	count uint64
+	time  time.Time
+}{lock: &sync.Mutex{}, time: time.Now()}
+
+// runtimeStats returns the goroutines, queues depths and counters.
+// The samples rate is computed since the previous call.
+func runtimeStats() interface{} {
+	cfgs := getSettings()
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+
+	samples := atomic.LoadUint64(&counters.samples)
+	samplesRate.lock.Lock()
+	rate := 0.0
+	if elapsed := time.Since(samplesRate.time).Seconds(); elapsed > 0 {
+		rate = float64(samples-samplesRate.count) / elapsed
+	}
+	samplesRate.count, samplesRate.time = samples, time.Now()
+	samplesRate.lock.Unlock()
+
+	hub.lock.Lock()
+	wsClients := len(hub.clients)
+	hub.lock.Unlock()
+
+	busy, size, waiting := workers.usage()
+	samplesMem, minutesMem, outputsMem := memoryUsage()
+
+	return map[string]interface{}{
+		"goroutines":      runtime.NumGoroutine(),
+		"heap_alloc":      mem.HeapAlloc,
+		"gc_cycles":       mem.NumGC,
+		"targets":         len(dbs.getAllIPs()),
+		"samples_total":   samples,
+		"samples_per_sec": rate,
+		"queues": map[string]interface{}{
+			"samples": len(samplesChan),
+			"alerts":  len(alertsChan),
+			"ui":      bus.depth(),
+			"policy":  cfgs.Queues.Policy,
+		},
+		"workers": map[string]interface{}{
+			"busy":    busy,
+			"size":    size,
+			"waiting": waiting,
+		},
+		"live_clients": wsClients,
+		"routines":     routinesStats(),
+		"engine":       engineName(),
+		"dropped": map[string]uint64{
+			"samples": atomic.LoadUint64(&counters.droppedSamples),
+			"alerts":  atomic.LoadUint64(&counters.droppedAlerts),
+			"lines":   atomic.LoadUint64(&counters.droppedLines),
+			"events":  atomic.LoadUint64(&counters.droppedEvents),
+		},
+		"blocked_samples": atomic.LoadUint64(&counters.blockedSamples),
+		"memory": map[string]interface{}{
+			"samples": samplesMem,
+			"minutes": minutesMem,
+			"outputs": outputsMem,
+			"limit":   int64(cfgs.MemoryLimit) << 20,
+			"trims":   atomic.LoadUint64(&counters.memoryTrims),
+		},
+	}
+}
+
+// routinesStats returns the number of supervised routines by state
+// and their restarts.
+func routinesStats() map[string]int {
+	stats := map[string]int{ROUTINERUNNING: 0, ROUTINERESTARTING: 0, "restarts": 0}
+	for _, rs := range routines.list() {
+		stats[rs.state]++
+		stats["restarts"] += rs.restarts
+	}
+	return stats
+}
+
+func init() {
+	expvar.Publish("pingo", expvar.Func(runtimeStats))
+}
+
+// startDebugServer serves the pprof profiles under /debug/pprof/ and
+// the runtime statistics under /debug/vars then stops it on exit. It
+// must listen on a local address since profiles expose internals.
+func startDebugServer(address string) {
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	mux.Handle("/debug/vars", expvar.Handler())
+
+	server := &http.Server{
+		Addr:              address,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			logs.Error("Failed to run debug server", "subsystem", "debug", "err", err)
+		}
+	}()
+	logs.Info("Debug server listening", "subsystem", "debug", "address", address)
+
+	<-exit
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := server.Shutdown(ctx); err != nil {
+		logs.Error("Failed to shutdown debug server", "subsystem", "debug", "err", err)
+	}
+}
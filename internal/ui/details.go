@@ -0,0 +1,169 @@
+package ui
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/jroimartin/gocui"
+)
+
+const (
+	DETAILS = "details"
+
+	DWIDTH  = 90
+	DHEIGHT = 36
+
+	// latest state changes listed into the details popup.
+	DHISTORY = 6
+)
+
+// displayDetailsView shows into a popup everything known about the
+// focused ip : its configs, statistics, state changes and its latest
+// traceroute and mtr results.
+func displayDetailsView(g *gocui.Gui, ipv *gocui.View) error {
+	_, cy := ipv.Cursor()
+	l, err := ipv.Line(cy)
+	if err != nil || len(strings.Fields(l)) < 2 {
+		return nil
+	}
+	ip := strings.Fields(l)[1]
+
+	maxX, maxY := g.Size()
+	dv, err := g.SetView(DETAILS, (maxX-DWIDTH)/2, (maxY-DHEIGHT)/2, (maxX+DWIDTH)/2, (maxY+DHEIGHT)/2)
+	if err != nil && err != gocui.ErrUnknownView {
+		log.Println("Failed to create details view:", err)
+		return err
+	}
+	if err == gocui.ErrUnknownView {
+		dv.FgColor = gocui.ColorYellow
+		dv.Editable = false
+		dv.Wrap = true
+		for _, key := range []gocui.Key{gocui.KeyEsc, gocui.KeyCtrlQ} {
+			if err := g.SetKeybinding(DETAILS, key, gocui.ModNone, closeDetailsView); err != nil {
+				log.Println("Failed to bind keys to details view:", err)
+				return err
+			}
+		}
+	}
+	dv.Title = fmt.Sprintf(" Details of [%s] | Esc: close ", ip)
+	dv.Clear()
+	fmt.Fprint(dv, formatDetails(ip))
+	if _, err := g.SetCurrentView(DETAILS); err != nil {
+		log.Println("Failed to set focus on details view:", err)
+		return err
+	}
+	return nil
+}
+
+// closeDetailsView closes the target details popup.
+func closeDetailsView(g *gocui.Gui, dv *gocui.View) error {
+	g.DeleteKeybindings(dv.Name())
+	if err := g.DeleteView(dv.Name()); err != nil {
+		log.Println("Failed to delete details view:", err)
+		return err
+	}
+	return setCurrentDefaultView(g)
+}
+
+// formatDetails returns the sections of the details popup of an ip.
+func formatDetails(ip string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "address  : %s\n", ip)
+	b.WriteString(formatGeo(ip) + formatNIC(ip))
+
+	b.WriteString("\n[config]\n")
+	b.WriteString(dbs.formatIPConfig(ip) + "\n")
+	if cfg := dbs.getConfig(ip); cfg != nil && cfg.iperf > 0 {
+		fmt.Fprintf(&b, "iperf    : %d\n", cfg.iperf)
+	}
+
+	b.WriteString("\n[statistics]\n")
+	b.WriteString(formatDetailsStats(ip))
+
+	b.WriteString("\n[state history]\n")
+	var changes []string
+	for _, n := range center.list() {
+		if n.alert.ip == ip {
+			changes = append(changes, n.alert.String())
+		}
+	}
+	if len(changes) == 0 {
+		b.WriteString("no state change\n")
+	} else if len(changes) > DHISTORY {
+		fmt.Fprintf(&b, "... %d older changes into the notification center\n", len(changes)-DHISTORY)
+		changes = changes[len(changes)-DHISTORY:]
+	}
+	for _, c := range changes {
+		b.WriteString(c + "\n")
+	}
+
+	b.WriteString("\n[last traceroute]\n")
+	b.WriteString(formatDetailsTrace(ip))
+	return b.String()
+}
+
+// formatDetailsStats returns the state and the statistics of an ip
+// with the span of its samples history.
+func formatDetailsStats(ip string) string {
+	s := dbs.getStats(ip)
+	if s == nil || s.fails+s.replies() == 0 {
+		return "not pinged yet\n"
+	}
+	state := s.state
+	if state == "" {
+		state = "unknown"
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "state    : %s (%d consecutive failures)\n", state, s.streak)
+	fmt.Fprintf(&b, "last rtt : %d ms\n", s.last)
+	fmt.Fprintf(&b, "rtt      : min %d - avg %d - max %d ms\n", s.min, s.avg, s.max)
+	fmt.Fprintf(&b, "replies  : %d (match %d - above %d - under %d)\n", s.replies(), s.match, s.above, s.under)
+	fmt.Fprintf(&b, "fails    : %d (loss %.1f%%)\n", s.fails, s.loss())
+	if h := dbs.getHistory(ip); len(h) > 0 {
+		fmt.Fprintf(&b, "samples  : %d since %s\n", len(h), h[0].time.Format("2006-01-02 15:04:05"))
+	}
+	if m := dbs.getMinutes(ip); len(m) > 0 {
+		fmt.Fprintf(&b, "minutes  : %d aggregated since %s\n", len(m), m[0].Time.Format("2006-01-02 15:04"))
+	}
+	return b.String()
+}
+
+// formatDetailsTrace summarizes the latest traceroute and mtr results
+// of an ip : its path, the changes and the lossiest mtr hop.
+func formatDetailsTrace(ip string) string {
+	tr, m := traces.get(ip), traces.getMTR(ip)
+	if tr == nil && m == nil {
+		return "not traced yet\n"
+	}
+	var b strings.Builder
+	if tr != nil {
+		var path []string
+		for _, h := range tr.hops {
+			path = append(path, orStar(h.address))
+		}
+		fmt.Fprintf(&b, "run      : %s (%d hops)\n", tr.start.Format("2006-01-02 15:04:05"), len(tr.hops))
+		fmt.Fprintf(&b, "path     : %s\n", strings.Join(path, " > "))
+		if tr.previous != nil {
+			if len(tr.changes) == 0 {
+				b.WriteString("changes  : same path as the previous run\n")
+			} else {
+				fmt.Fprintf(&b, "changes  : %s\n", strings.Join(tr.changes, " - "))
+			}
+		}
+	}
+	if m != nil {
+		var worst *hopStat
+		for _, hs := range m.sortedHops() {
+			if hs.address != "" && (worst == nil || hs.loss() > worst.loss()) {
+				worst = hs
+			}
+		}
+		fmt.Fprintf(&b, "mtr      : %d rounds", m.rounds)
+		if worst != nil && worst.loss() > 0 {
+			fmt.Fprintf(&b, " - lossiest hop %d %s (%.1f%%)", worst.number, worst.address, worst.loss())
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
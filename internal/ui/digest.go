@@ -0,0 +1,95 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// default number of worst targets listed into a digest.
+const DIGESTWORST = 3
+
+// targetAvailability is the share of successful probes of a target
+// over the period of a digest.
+type targetAvailability struct {
+	ip     string
+	total  int
+	failed int
+}
+
+// loss returns the percentage of failed probes of the period.
+func (t targetAvailability) loss() float64 {
+	if t.total == 0 {
+		return 0
+	}
+	return float64(t.failed) * 100 / float64(t.total)
+}
+
+// buildDigest summarizes the availability of all targets, the worst
+// of them and the alerts fired over the period ending now.
+func buildDigest(now time.Time, period time.Duration, worst int) alert {
+	since := now.Add(-period)
+	var all targetAvailability
+	var targets []targetAvailability
+	down := 0
+	for _, ip := range dbs.getAllIPs() {
+		if s := dbs.getStats(ip); s != nil && s.state == STATEDOWN {
+			down++
+		}
+		t := targetAvailability{ip: ip}
+		// the samples dropped from the history are kept per minute.
+		for _, a := range dbs.getMinutes(ip) {
+			if a.Time.Before(since) || a.Time.After(now) {
+				continue
+			}
+			t.total += a.Sent
+			t.failed += a.Fails
+		}
+		for _, sp := range dbs.getHistory(ip) {
+			if sp.time.Before(since) || sp.time.After(now) {
+				continue
+			}
+			t.total++
+			if !sp.success {
+				t.failed++
+			}
+		}
+		all.total, all.failed = all.total+t.total, all.failed+t.failed
+		if t.failed > 0 {
+			targets = append(targets, t)
+		}
+	}
+
+	sort.SliceStable(targets, func(i, j int) bool {
+		return targets[i].loss() > targets[j].loss()
+	})
+	if len(targets) > worst {
+		targets = targets[:worst]
+	}
+
+	events := make(map[string]int)
+	for _, n := range center.list() {
+		if n.alert.state != STATEDIGEST && !n.alert.time.Before(since) && !n.alert.time.After(now) {
+			events[n.alert.state]++
+		}
+	}
+
+	parts := []string{fmt.Sprintf("%d targets (%d down)", len(dbs.getAllIPs()), down)}
+	if all.total == 0 {
+		parts = append(parts, "no probe")
+	} else {
+		parts = append(parts, fmt.Sprintf("availability %.2f%% of %d probes", 100-all.loss(), all.total))
+	}
+	if len(targets) > 0 {
+		var list []string
+		for _, t := range targets {
+			list = append(list, fmt.Sprintf("%s %.1f%% loss", t.ip, t.loss()))
+		}
+		parts = append(parts, "worst "+strings.Join(list, ", "))
+	}
+	parts = append(parts, fmt.Sprintf("events %d down, %d up, %d path changes, %d certificates",
+		events[STATEDOWN], events[STATEUP], events[STATEPATH], events[STATECERT]))
+
+	return alert{state: STATEDIGEST, time: now, details: fmt.Sprintf("last %dh : %s", int(period.Hours()), strings.Join(parts, " - "))}
+}
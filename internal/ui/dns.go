@@ -0,0 +1,250 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/jroimartin/gocui"
+)
+
+// dns records types which can be looked up.
+var dnsTypes = []string{"PTR", "A", "AAAA", "MX", "TXT"}
+
+// dnsQuery is a lookup requested from the ui. An ip is reverse
+// resolved then the forward types are queried for its names.
+type dnsQuery struct {
+	name  string
+	types []string
+}
+
+// dns lookups to run.
+var dnsQueryChan = make(chan dnsQuery, 1)
+
+// dnsLookupInputView displays a temporary input box to enter the
+// name or ip to resolve followed by the records types to query.
+func dnsLookupInputView(g *gocui.Gui, ipv *gocui.View) error {
+	// prefill with the focused ip.
+	target := ""
+	_, cy := ipv.Cursor()
+	if l, err := ipv.Line(cy); err == nil && len(strings.Fields(l)) > 1 {
+		target = strings.Fields(l)[1]
+	}
+
+	maxX, maxY := g.Size()
+
+	const name = "dnsLookup"
+
+	// construct the input box and position at the center of the screen.
+	if inputView, err := g.SetView(name, maxX/2-35, maxY/2, maxX/2+35, maxY/2+2); err != nil {
+		if err != gocui.ErrUnknownView {
+			log.Println("Failed to display input view: ", err)
+			return err
+		}
+
+		inputView.Title = " DNS Lookup (Name or IP then all or A,AAAA,PTR,MX,TXT) "
+		inputView.FgColor = gocui.ColorYellow
+		inputView.SelBgColor = gocui.ColorBlack
+		inputView.SelFgColor = gocui.ColorYellow
+		inputView.Editable = true
+
+		if _, err := g.SetCurrentView(name); err != nil {
+			log.Println(err)
+			return err
+		}
+		g.Cursor = true
+		inputView.Highlight = true
+		// bind Enter key to processInput function.
+		if err := g.SetKeybinding(name, gocui.KeyEnter, gocui.ModNone, processInput); err != nil {
+			log.Println(err)
+			return err
+		}
+
+		// bind Ctrl+Q and Escape keys to close the input box.
+		if err := g.SetKeybinding(name, gocui.KeyCtrlQ, gocui.ModNone, closeInputView); err != nil {
+			log.Println(err)
+			return err
+		}
+
+		if err := g.SetKeybinding(name, gocui.KeyEsc, gocui.ModNone, closeInputView); err != nil {
+			log.Println(err)
+			return err
+		}
+
+		inputView.Write([]byte(strings.TrimSpace(target + " all")))
+		inputView.SetCursor(len(inputView.Buffer())-1, 0)
+	}
+	return nil
+}
+
+// addDNSLookup parses the entered name and records types
+// and sends them to the scheduler.
+func addDNSLookup(input string) {
+	q, ok := parseDNSQuery(input)
+	if !ok {
+		return
+	}
+
+	bus.publish(EVTITLE, fmt.Sprintf(" DNS Lookup [%s] Outputs ", q.name))
+	dnsQueryChan <- q
+	// reset since no ping.
+	currentOnPingIP = ""
+	currentOutputsIP = ""
+}
+
+// parseDNSQuery reads a name followed by the records types to query,
+// all of them by default.
+func parseDNSQuery(input string) (dnsQuery, bool) {
+	fields := strings.Fields(strings.Replace(input, ",", " ", -1))
+	if len(fields) == 0 {
+		return dnsQuery{}, false
+	}
+
+	q := dnsQuery{name: strings.TrimSuffix(fields[0], ".")}
+	for _, t := range fields[1:] {
+		t = strings.ToUpper(t)
+		if t == "ALL" {
+			q.types = dnsTypes
+			break
+		}
+		for _, known := range dnsTypes {
+			if t == known {
+				q.types = append(q.types, t)
+			}
+		}
+	}
+	if len(q.types) == 0 {
+		q.types = dnsTypes
+	}
+	return q, true
+}
+
+// newResolver returns the resolver set into settings or the
+// system one. The resolver port defaults to 53.
+func newResolver(cfg dnsSettings) *net.Resolver {
+	if cfg.Resolver == "" {
+		return net.DefaultResolver
+	}
+
+	address := cfg.Resolver
+	if _, _, err := net.SplitHostPort(address); err != nil {
+		address = net.JoinHostPort(address, "53")
+	}
+	return &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, network, address)
+		},
+	}
+}
+
+// executeDNSLookup queries the records of a dns lookup and
+// displays each answer into the outputs view.
+func executeDNSLookup(q dnsQuery, ctx context.Context) {
+	cfgs := getSettings()
+	defer recoverPanic("executeDNSLookup")
+	resolver := newResolver(cfgs.DNS)
+	server := cfgs.DNS.Resolver
+	if server == "" {
+		server = "system resolver"
+	}
+	bus.publish(EVOUTPUT, fmt.Sprintf("Resolving %s (%s) with %s ...", q.name, strings.Join(q.types, ","), server))
+
+	start := time.Now()
+	names := []string{q.name}
+	if net.ParseIP(q.name) != nil {
+		names = nil
+		ptrs, err := dnsQueryRecords(ctx, resolver, "PTR", q.name)
+		for _, name := range ptrs {
+			names = append(names, strings.TrimSuffix(name, "."))
+		}
+		if wants(q.types, "PTR") {
+			dnsPublish("PTR", q.name, ptrs, err)
+		}
+	}
+
+	for _, name := range names {
+		for _, t := range q.types {
+			if t == "PTR" {
+				continue
+			}
+			if ctx.Err() != nil {
+				return
+			}
+			answers, err := dnsQueryRecords(ctx, resolver, t, name)
+			dnsPublish(t, name, answers, err)
+		}
+	}
+
+	if ctx.Err() == nil {
+		bus.publish(EVOUTPUT, fmt.Sprintf("Completed in %s.", time.Since(start).Round(time.Millisecond)))
+	}
+}
+
+// wants tells if the records type t is part of types.
+func wants(types []string, t string) bool {
+	for _, v := range types {
+		if v == t {
+			return true
+		}
+	}
+	return false
+}
+
+// dnsQueryRecords resolves a single records type of a name.
+func dnsQueryRecords(ctx context.Context, resolver *net.Resolver, t, name string) ([]string, error) {
+	cfgs := getSettings()
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(cfgs.DNS.Timeout)*time.Second)
+	defer cancel()
+
+	var answers []string
+	switch t {
+	case "PTR":
+		return resolver.LookupAddr(ctx, name)
+	case "A", "AAAA":
+		network := "ip4"
+		if t == "AAAA" {
+			network = "ip6"
+		}
+		ips, err := resolver.LookupIP(ctx, network, name)
+		if _, ok := err.(*net.AddrError); ok {
+			// the name only has addresses of the other family.
+			return nil, nil
+		}
+		for _, ip := range ips {
+			answers = append(answers, ip.String())
+		}
+		return answers, err
+	case "MX":
+		mxs, err := resolver.LookupMX(ctx, name)
+		for _, mx := range mxs {
+			answers = append(answers, fmt.Sprintf("%d %s", mx.Pref, mx.Host))
+		}
+		return answers, err
+	case "TXT":
+		return resolver.LookupTXT(ctx, name)
+	}
+	return nil, fmt.Errorf("unsupported records type %s", t)
+}
+
+// dnsPublish displays the answers or the failure of a query.
+func dnsPublish(t, name string, answers []string, err error) {
+	if err != nil {
+		if dnsErr, ok := err.(*net.DNSError); ok && dnsErr.IsNotFound {
+			bus.publish(EVOUTPUT, fmt.Sprintf("%-5s %s : no records", t, name))
+			return
+		}
+		bus.publish(EVOUTPUT, fmt.Sprintf("%-5s %s : %v", t, name, err))
+		return
+	}
+	if len(answers) == 0 {
+		bus.publish(EVOUTPUT, fmt.Sprintf("%-5s %s : no records", t, name))
+	}
+	for _, a := range answers {
+		bus.publish(EVOUTPUT, fmt.Sprintf("%-5s %s -> %s", t, name, a))
+	}
+}
@@ -0,0 +1,57 @@
+package ui
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/jeamon/pingo/internal/stats"
+)
+
+// downsample folds the samples dropped from the history of an ip into
+// its per-minute aggregates and drops the oldest aggregates beyond the
+// history_minutes limit. The history lock must be held.
+func (db *databases) downsample(ip string, dropped []sample) {
+	cfgs := getSettings()
+	samples := make([]stats.Sample, len(dropped))
+	for i, sp := range dropped {
+		samples[i] = stats.Sample{Time: sp.time, RTT: sp.rtt, Success: sp.success}
+	}
+	db.minutes[ip] = stats.Downsample(db.minutes[ip], samples, cfgs.HistoryMinutes)
+}
+
+// restoreMinutes appends the per-minute aggregates of an ip restored
+// from a session dump, older than its samples history.
+func (db *databases) restoreMinutes(ip string, minutes []stats.Aggregate) {
+	cfgs := getSettings()
+	db.hlock.Lock()
+	m := append(db.minutes[ip], minutes...)
+	if len(m) > cfgs.HistoryMinutes {
+		m = m[len(m)-cfgs.HistoryMinutes:]
+	}
+	db.minutes[ip] = m
+	db.hlock.Unlock()
+}
+
+// getMinutes returns a copy of the per-minute aggregates of the
+// samples of an ip older than its history.
+func (db *databases) getMinutes(ip string) []stats.Aggregate {
+	db.hlock.RLock()
+	m := make([]stats.Aggregate, len(db.minutes[ip]))
+	copy(m, db.minutes[ip])
+	db.hlock.RUnlock()
+	return m
+}
+
+// minutesRecords builds the per-minute aggregates rows of all targets
+// with headers.
+func minutesRecords() [][]string {
+	records := [][]string{{"target", "minute", "sent", "fails", "loss_pct", "min_ms", "avg_ms", "max_ms"}}
+	for _, ip := range dbs.getAllIPs() {
+		for _, a := range dbs.getMinutes(ip) {
+			records = append(records, []string{ip, a.Time.Format(time.RFC3339),
+				strconv.Itoa(a.Sent), strconv.Itoa(a.Fails), strconv.FormatFloat(a.Loss(), 'f', 2, 64),
+				strconv.Itoa(a.Min), strconv.Itoa(a.Avg()), strconv.Itoa(a.Max)})
+		}
+	}
+	return records
+}
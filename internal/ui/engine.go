@@ -0,0 +1,51 @@
+package ui
+
+import "github.com/jeamon/pingo/internal/probe"
+
+// engines of the ping probes.
+const (
+	// native when allowed, the system ping otherwise.
+	ENGINEAUTO = "auto"
+	// icmp echo requests sent by pingo itself.
+	ENGINENATIVE = "native"
+	// the system ping command.
+	ENGINESYSTEM = "system"
+)
+
+// kind of icmp sockets used by the native engine. It is
+// empty when the system ping command runs the probes.
+var icmpMode string
+
+// selectEngine picks the engine of the ping probes from the engine
+// setting and the icmp sockets the process is allowed to open. The
+// native engine falls back to the system ping without privileges.
+func selectEngine() {
+	cfgs := getSettings()
+	if cfgs.Engine != ENGINESYSTEM {
+		icmpMode = probe.ICMPMode()
+	}
+	if icmpMode == "" && cfgs.Engine == ENGINENATIVE {
+		probeLog.Warn("No icmp sockets allowed, falling back to the system ping", "hint", privilegesHint)
+		showError("The native engine needs icmp sockets : %s. Falling back to the system ping.", privilegesHint)
+	}
+	probeLog.Info("Ping engine selected", "engine", engineName())
+}
+
+// engineName describes the engine running the ping probes.
+func engineName() string {
+	if icmpMode == "" {
+		return "system ping"
+	}
+	return "native icmp (" + icmpMode + " sockets)"
+}
+
+// engineStatus is the short engine name shown into the status bar.
+func engineStatus() string {
+	switch icmpMode {
+	case probe.ICMPRAW:
+		return "raw icmp"
+	case probe.ICMPDGRAM:
+		return "udp icmp"
+	}
+	return "sys ping"
+}
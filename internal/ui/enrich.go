@@ -0,0 +1,173 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+// hopInfo holds the enrichment details of a hop address.
+type hopInfo struct {
+	ptr     string
+	asn     string
+	country string
+}
+
+// enricher resolves and caches the reverse name, the origin ASN (via
+// Team Cymru DNS service) and the country (via the geoip databases
+// when configured) of traceroute hops addresses.
+type enricher struct {
+	cfg   enrichSettings
+	cache map[string]*hopInfo
+	lock  *sync.RWMutex
+	// addresses being resolved.
+	pending map[string]chan struct{}
+}
+
+// hops details resolver. nil means enrichment is disabled.
+var enrich *enricher
+
+// newEnricher creates a hops resolver.
+func newEnricher(cfg enrichSettings) *enricher {
+	return &enricher{
+		cfg:     cfg,
+		cache:   make(map[string]*hopInfo),
+		lock:    &sync.RWMutex{},
+		pending: make(map[string]chan struct{}),
+	}
+}
+
+// info returns the cached details of an address. A missing address is
+// resolved in background so the details are available on next call.
+func (e *enricher) info(address string) hopInfo {
+	if e == nil || net.ParseIP(address) == nil {
+		return hopInfo{}
+	}
+
+	e.lock.RLock()
+	hi, ok := e.cache[address]
+	_, pending := e.pending[address]
+	e.lock.RUnlock()
+	if ok {
+		return *hi
+	}
+
+	if !pending {
+		go e.wait(context.Background(), address)
+	}
+	return hopInfo{}
+}
+
+// resolve blocks until all addresses are resolved or the context is done.
+func (e *enricher) resolve(ctx context.Context, addresses []string) {
+	if e == nil {
+		return
+	}
+
+	var rwg sync.WaitGroup
+	for _, address := range addresses {
+		if net.ParseIP(address) == nil {
+			continue
+		}
+		rwg.Add(1)
+		go func(address string) {
+			defer rwg.Done()
+			e.wait(ctx, address)
+		}(address)
+	}
+	rwg.Wait()
+}
+
+// wait resolves an address or waits for its ongoing resolution.
+func (e *enricher) wait(ctx context.Context, address string) {
+	e.lock.Lock()
+	if _, ok := e.cache[address]; ok {
+		e.lock.Unlock()
+		return
+	}
+	done, ok := e.pending[address]
+	if !ok {
+		done = make(chan struct{})
+		e.pending[address] = done
+	}
+	e.lock.Unlock()
+
+	if ok {
+		select {
+		case <-done:
+		case <-ctx.Done():
+		}
+		return
+	}
+
+	hi := e.lookup(address)
+
+	e.lock.Lock()
+	e.cache[address] = hi
+	delete(e.pending, address)
+	e.lock.Unlock()
+	close(done)
+}
+
+// lookup queries all the details of an address.
+func (e *enricher) lookup(address string) *hopInfo {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	hi := &hopInfo{}
+	if names, err := net.DefaultResolver.LookupAddr(ctx, address); err == nil && len(names) > 0 {
+		hi.ptr = strings.TrimSuffix(names[0], ".")
+	}
+
+	ip := net.ParseIP(address)
+	if ip.IsPrivate() || ip.IsLoopback() || ip.IsLinkLocalUnicast() {
+		return hi
+	}
+
+	var registryCountry string
+	hi.asn, registryCountry = e.lookupASN(ctx, ip)
+
+	hi.country = geo.locate(address).country
+
+	// fallback to the registry country of the prefix.
+	if hi.country == "" {
+		hi.country = registryCountry
+	}
+	return hi
+}
+
+// lookupASN queries the origin ASN and registry country of an address
+// from Team Cymru DNS service. The TXT answer looks like below.
+// "15169 | 8.8.8.0/24 | US | arin | 2014-03-14"
+func (e *enricher) lookupASN(ctx context.Context, ip net.IP) (string, string) {
+	var name string
+	if ip4 := ip.To4(); ip4 != nil {
+		name = fmt.Sprintf("%d.%d.%d.%d.origin.asn.cymru.com", ip4[3], ip4[2], ip4[1], ip4[0])
+	} else {
+		var nibbles []string
+		for i := len(ip) - 1; i >= 0; i-- {
+			nibbles = append(nibbles, fmt.Sprintf("%x.%x", ip[i]&0x0f, ip[i]>>4))
+		}
+		name = strings.Join(nibbles, ".") + ".origin6.asn.cymru.com"
+	}
+
+	txts, err := net.DefaultResolver.LookupTXT(ctx, name)
+	if err != nil || len(txts) == 0 {
+		probeLog.Debug("Failed to lookup hop asn", "address", ip.String(), "err", err)
+		return "", ""
+	}
+
+	fields := strings.Split(txts[0], "|")
+	if len(fields) < 3 {
+		return "", ""
+	}
+	// multiple origins are space separated, keep the first.
+	origins := strings.Fields(fields[0])
+	if len(origins) == 0 {
+		return "", ""
+	}
+	return "AS" + origins[0], strings.TrimSpace(fields[2])
+}
@@ -0,0 +1,67 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"time"
+)
+
+// execNotifier runs a user-defined command on each alert. The alert
+// details are passed to the command as environment variables.
+type execNotifier struct {
+	cfg execSettings
+}
+
+// newExecNotifier creates a command-based notifier.
+func newExecNotifier(cfg execSettings) *execNotifier {
+	return &execNotifier{cfg: cfg}
+}
+
+// notify spins up the command in background so a slow
+// script does not hold the delivery of other alerts.
+func (n *execNotifier) notify(a alert) {
+	go func() {
+		output, err := n.run(a)
+		if err != nil {
+			alertsLog.Error("Failed to run alert command", "notifier", "exec", "target", a.ip, "err", err, "output", string(output))
+		}
+	}()
+}
+
+// run executes the command with the alert environment variables.
+func (n *execNotifier) run(a alert) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(n.cfg.Timeout)*time.Second)
+	defer cancel()
+
+	// the configured command line is run by the shell on purpose so
+	// it can use pipes or redirections. The alert details are only
+	// passed as environment variables, never into the command line.
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", n.cfg.Command)
+	} else {
+		cmd = exec.CommandContext(ctx, LinuxShell, "-c", n.cfg.Command)
+	}
+
+	cmd.Env = append(os.Environ(), alertEnv(a)...)
+	return cmd.CombinedOutput()
+}
+
+// alertEnv builds the list of environment variables describing an alert.
+func alertEnv(a alert) []string {
+	return []string{
+		"PINGO_TARGET=" + a.ip,
+		"PINGO_STATE=" + a.state,
+		"PINGO_TIME=" + a.time.Format(time.RFC3339),
+		fmt.Sprintf("PINGO_LOSS=%.1f", a.stats.loss()),
+		fmt.Sprintf("PINGO_FAILS=%d", a.stats.fails),
+		fmt.Sprintf("PINGO_REPLIES=%d", a.stats.replies()),
+		fmt.Sprintf("PINGO_MIN=%d", a.stats.min),
+		fmt.Sprintf("PINGO_AVG=%d", a.stats.avg),
+		fmt.Sprintf("PINGO_MAX=%d", a.stats.max),
+		"PINGO_DETAILS=" + a.details,
+	}
+}
@@ -0,0 +1,229 @@
+package ui
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jroimartin/gocui"
+)
+
+// exportCSVInputView displays a temporary input box to enter
+// the filename prefix of the CSV files to export.
+func exportCSVInputView(g *gocui.Gui, cv *gocui.View) error {
+	maxX, maxY := g.Size()
+
+	const name = "exportCSV"
+
+	// construct the input box and position at the center of the screen.
+	if inputView, err := g.SetView(name, maxX/2-25, maxY/2, maxX/2+25, maxY/2+2); err != nil {
+		if err != gocui.ErrUnknownView {
+			log.Println("Failed to display input view: ", err)
+			return err
+		}
+
+		inputView.Title = " Export CSV & JSON (Enter Name Prefix) "
+		inputView.FgColor = gocui.ColorYellow
+		inputView.SelBgColor = gocui.ColorBlack
+		inputView.SelFgColor = gocui.ColorYellow
+		inputView.Editable = true
+
+		if _, err := g.SetCurrentView(name); err != nil {
+			log.Println(err)
+			return err
+		}
+		g.Cursor = true
+		inputView.Highlight = true
+		// bind Enter key to processInput function.
+		if err := g.SetKeybinding(name, gocui.KeyEnter, gocui.ModNone, processInput); err != nil {
+			log.Println(err)
+			return err
+		}
+
+		// bind Ctrl+Q and Escape keys to close the input box.
+		if err := g.SetKeybinding(name, gocui.KeyCtrlQ, gocui.ModNone, closeInputView); err != nil {
+			log.Println(err)
+			return err
+		}
+
+		if err := g.SetKeybinding(name, gocui.KeyEsc, gocui.ModNone, closeInputView); err != nil {
+			log.Println(err)
+			return err
+		}
+
+		inputView.Write([]byte("pingo-" + time.Now().Format("20060102-150405")))
+		inputView.SetCursor(len(inputView.Buffer())-1, 0)
+	}
+	return nil
+}
+
+// exportCSV writes the cumulative statistics of all targets into
+// <prefix>-stats.csv, their samples history into <prefix>-samples.csv,
+// the per-minute aggregates of the older samples into
+// <prefix>-minutes.csv and the timeline notes into
+// <prefix>-annotations.csv.
+// The full session state is also dumped into <prefix>-session.json file
+// and the targets as Nagios hosts into <prefix>-nagios.cfg file.
+func exportCSV(prefix string) {
+	if err := writeCSV(prefix+"-stats.csv", statsRecords()); err != nil {
+		storeLog.Error("Failed to export statistics", "err", err)
+		showError("Failed to export statistics : %v", err)
+	}
+
+	if err := writeCSV(prefix+"-samples.csv", samplesRecords()); err != nil {
+		storeLog.Error("Failed to export samples", "err", err)
+		showError("Failed to export samples : %v", err)
+	}
+
+	if err := writeCSV(prefix+"-minutes.csv", minutesRecords()); err != nil {
+		storeLog.Error("Failed to export per-minute aggregates", "err", err)
+		showError("Failed to export per-minute aggregates : %v", err)
+	}
+
+	if err := writeCSV(prefix+"-annotations.csv", annotationsRecords()); err != nil {
+		storeLog.Error("Failed to export annotations", "err", err)
+		showError("Failed to export annotations : %v", err)
+	}
+
+	if err := exportSession(prefix + "-session.json"); err != nil {
+		storeLog.Error("Failed to export session", "err", err)
+		showError("Failed to export session : %v", err)
+	}
+
+	if err := exportNagiosHosts(prefix + "-nagios.cfg"); err != nil {
+		storeLog.Error("Failed to export nagios hosts", "err", err)
+		showError("Failed to export nagios hosts : %v", err)
+	}
+}
+
+// statsRecords builds the statistics rows of all targets with headers.
+func statsRecords() [][]string {
+	records := [][]string{{"target", "state", "sent", "replies", "fails", "loss",
+		"min_ms", "avg_ms", "max_ms", "last_ms", "match", "above", "under", "threshold",
+		"country", "city", "asn", "org"}}
+
+	for _, ip := range dbs.getAllIPs() {
+		s, cfg := dbs.getStats(ip), dbs.getConfig(ip)
+		if s == nil || cfg == nil {
+			continue
+		}
+		gi := geo.locate(ip)
+		records = append(records, []string{ip, s.state,
+			strconv.Itoa(s.fails + s.replies()), strconv.Itoa(s.replies()), strconv.Itoa(s.fails),
+			strconv.FormatFloat(s.loss(), 'f', 2, 64),
+			strconv.Itoa(s.min), strconv.Itoa(s.avg), strconv.Itoa(s.max), strconv.Itoa(s.last),
+			strconv.Itoa(s.match), strconv.Itoa(s.above), strconv.Itoa(s.under), strconv.Itoa(cfg.threshold),
+			gi.country, gi.city, gi.asn, gi.org,
+		})
+	}
+	return records
+}
+
+// samplesRecords builds the samples history rows of all targets with headers.
+func samplesRecords() [][]string {
+	records := [][]string{{"target", "time", "success", "rtt_ms"}}
+	for _, ip := range dbs.getAllIPs() {
+		for _, sp := range dbs.getHistory(ip) {
+			records = append(records, []string{ip, sp.time.Format(time.RFC3339Nano),
+				strconv.FormatBool(sp.success), strconv.Itoa(sp.rtt)})
+		}
+	}
+	return records
+}
+
+// annotationsRecords builds the timeline notes rows with headers.
+func annotationsRecords() [][]string {
+	records := [][]string{{"time", "target", "note"}}
+	for _, a := range annotations.list() {
+		records = append(records, []string{a.time.Format(time.RFC3339Nano), a.ip, a.text})
+	}
+	return records
+}
+
+// writeCSV creates a file and writes all records into.
+func writeCSV(filename string, records [][]string) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w := csv.NewWriter(f)
+	if err = w.WriteAll(records); err != nil {
+		return err
+	}
+	return f.Sync()
+}
+
+// saveOutputsInputView displays a temporary input box to enter the
+// filename where to save the outputs view content of current target.
+func saveOutputsInputView(g *gocui.Gui, cv *gocui.View) error {
+	maxX, maxY := g.Size()
+
+	const name = "saveOutputs"
+
+	// construct the input box and position at the center of the screen.
+	if inputView, err := g.SetView(name, maxX/2-25, maxY/2, maxX/2+25, maxY/2+2); err != nil {
+		if err != gocui.ErrUnknownView {
+			log.Println("Failed to display input view: ", err)
+			return err
+		}
+
+		inputView.Title = " Save Outputs (Enter Filename) "
+		inputView.FgColor = gocui.ColorYellow
+		inputView.SelBgColor = gocui.ColorBlack
+		inputView.SelFgColor = gocui.ColorYellow
+		inputView.Editable = true
+
+		if _, err := g.SetCurrentView(name); err != nil {
+			log.Println(err)
+			return err
+		}
+		g.Cursor = true
+		inputView.Highlight = true
+		// bind Enter key to processInput function.
+		if err := g.SetKeybinding(name, gocui.KeyEnter, gocui.ModNone, processInput); err != nil {
+			log.Println(err)
+			return err
+		}
+
+		// bind Ctrl+Q and Escape keys to close the input box.
+		if err := g.SetKeybinding(name, gocui.KeyCtrlQ, gocui.ModNone, closeInputView); err != nil {
+			log.Println(err)
+			return err
+		}
+
+		if err := g.SetKeybinding(name, gocui.KeyEsc, gocui.ModNone, closeInputView); err != nil {
+			log.Println(err)
+			return err
+		}
+
+		target := "outputs"
+		if currentOutputsIP != "" {
+			target = strings.Replace(currentOutputsIP, ":", "-", -1)
+		}
+		inputView.Write([]byte(fmt.Sprintf("pingo_%s_%s.txt", target, time.Now().Format("20060102-150405"))))
+		inputView.SetCursor(len(inputView.Buffer())-1, 0)
+	}
+	return nil
+}
+
+// saveOutputs writes the full outputs view content into a file.
+func saveOutputs(g *gocui.Gui, filename string) {
+	ov, err := g.View(OUTPUTS)
+	if err != nil {
+		log.Println("Failed to get outputs view:", err)
+		return
+	}
+
+	content := ov.Title + "\n" + strings.TrimSpace(ov.Buffer()) + "\n"
+	if err := ioutil.WriteFile(filename, []byte(content), 0644); err != nil {
+		storeLog.Error("Failed to save outputs", "file", filename, "err", err)
+		showError("Failed to save outputs : %v", err)
+	}
+}
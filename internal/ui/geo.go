@@ -0,0 +1,113 @@
+package ui
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/oschwald/maxminddb-golang"
+)
+
+// geoInfo holds the location and network owner of an address.
+type geoInfo struct {
+	country string
+	city    string
+	asn     string
+	org     string
+}
+
+// geoLocator reads local MaxMind format databases : a country or city
+// one and optionally an ASN one. Lookups are offline and fast enough
+// to be made on each display so nothing is cached.
+type geoLocator struct {
+	location *maxminddb.Reader
+	asn      *maxminddb.Reader
+}
+
+// geoip databases. nil when none is configured.
+var geo *geoLocator
+
+// newGeoLocator opens the configured databases. It returns nil if
+// none of them could be opened.
+func newGeoLocator(cfg enrichSettings) *geoLocator {
+	l := &geoLocator{}
+	if cfg.GeoIP != "" {
+		db, err := maxminddb.Open(cfg.GeoIP)
+		if err != nil {
+			probeLog.Error("Failed to open geoip database", "file", cfg.GeoIP, "err", err)
+		} else {
+			l.location = db
+		}
+	}
+
+	if cfg.GeoIPASN != "" {
+		db, err := maxminddb.Open(cfg.GeoIPASN)
+		if err != nil {
+			probeLog.Error("Failed to open geoip asn database", "file", cfg.GeoIPASN, "err", err)
+		} else {
+			l.asn = db
+		}
+	}
+
+	if l.location == nil && l.asn == nil {
+		return nil
+	}
+	return l
+}
+
+// locate returns the known details of an address.
+func (l *geoLocator) locate(address string) geoInfo {
+	var gi geoInfo
+	ip := net.ParseIP(address)
+	if l == nil || ip == nil {
+		return gi
+	}
+
+	if l.location != nil {
+		var record struct {
+			Country struct {
+				ISOCode string `maxminddb:"iso_code"`
+			} `maxminddb:"country"`
+			City struct {
+				Names map[string]string `maxminddb:"names"`
+			} `maxminddb:"city"`
+		}
+		if err := l.location.Lookup(ip, &record); err != nil {
+			probeLog.Debug("Failed to lookup address location", "address", address, "err", err)
+		}
+		gi.country, gi.city = record.Country.ISOCode, record.City.Names["en"]
+	}
+
+	if l.asn != nil {
+		var record struct {
+			Number uint   `maxminddb:"autonomous_system_number"`
+			Org    string `maxminddb:"autonomous_system_organization"`
+		}
+		if err := l.asn.Lookup(ip, &record); err != nil {
+			probeLog.Debug("Failed to lookup address asn", "address", address, "err", err)
+		}
+		if record.Number > 0 {
+			gi.asn = fmt.Sprintf("AS%d", record.Number)
+		}
+		gi.org = record.Org
+	}
+	return gi
+}
+
+// location returns the city and country of an address as displayed.
+func (gi geoInfo) location() string {
+	return strings.Trim(gi.city+", "+gi.country, ", ")
+}
+
+// close releases the databases.
+func (l *geoLocator) close() {
+	if l == nil {
+		return
+	}
+	if l.location != nil {
+		l.location.Close()
+	}
+	if l.asn != nil {
+		l.asn.Close()
+	}
+}
@@ -0,0 +1,119 @@
+package ui
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"time"
+)
+
+// graphiteSink sends latency and loss metrics using Graphite plaintext
+// protocol over TCP or StatsD protocol over UDP.
+type graphiteSink struct {
+	cfg     metricsSinkSettings
+	network string
+	format  func(name string, value float64, kind string, t time.Time) string
+	conn    net.Conn
+	lines   chan string
+}
+
+// newGraphiteSink creates a Graphite plaintext emitter.
+func newGraphiteSink(cfg metricsSinkSettings) *graphiteSink {
+	return newMetricsEmitter(cfg, "tcp", func(name string, value float64, kind string, t time.Time) string {
+		return fmt.Sprintf("%s %g %d\n", name, value, t.Unix())
+	})
+}
+
+// newStatsdSink creates a StatsD emitter.
+func newStatsdSink(cfg metricsSinkSettings) *graphiteSink {
+	return newMetricsEmitter(cfg, "udp", func(name string, value float64, kind string, t time.Time) string {
+		return fmt.Sprintf("%s:%g|%s\n", name, value, kind)
+	})
+}
+
+// newMetricsEmitter creates a plaintext metrics emitter and starts its writer.
+func newMetricsEmitter(cfg metricsSinkSettings, network string, format func(string, float64, string, time.Time) string) *graphiteSink {
+	sk := &graphiteSink{cfg: cfg, network: network, format: format, lines: make(chan string, 1000)}
+	wg.Add(1)
+	go sk.run()
+	return sk
+}
+
+// metricName builds a dotted metric path. Dots and colons of the
+// target are replaced to keep one path element per target.
+func (sk *graphiteSink) metricName(ip, metric string) string {
+	target := strings.NewReplacer(".", "_", ":", "_").Replace(ip)
+	return fmt.Sprintf("%s.%s.%s", sk.cfg.Prefix, target, metric)
+}
+
+// sample emits the rtt of a reply or counts a failure.
+func (sk *graphiteSink) sample(sp sample) {
+	if sp.success {
+		sk.queue(sk.format(sk.metricName(sp.ip, "rtt_ms"), float64(sp.rtt), "ms", sp.time))
+		return
+	}
+	sk.queue(sk.format(sk.metricName(sp.ip, "failures"), 1, "c", sp.time))
+}
+
+// summary emits the loss and latency gauges of a target.
+func (sk *graphiteSink) summary(ip string, s stat, t time.Time) {
+	sk.queue(sk.format(sk.metricName(ip, "loss_percent"), s.loss(), "g", t))
+	sk.queue(sk.format(sk.metricName(ip, "rtt_avg_ms"), float64(s.avg), "g", t))
+	sk.queue(sk.format(sk.metricName(ip, "rtt_max_ms"), float64(s.max), "g", t))
+}
+
+// queue adds a line to be sent without blocking.
+func (sk *graphiteSink) queue(line string) {
+	select {
+	case sk.lines <- line:
+	default:
+		count(&counters.droppedSamples)
+		sinksLog.Warn("Metrics queue full, dropped line", "sink", sk.network, "line", strings.TrimSpace(line))
+	}
+}
+
+// run writes each queued line and closes the connection on exit.
+func (sk *graphiteSink) run() {
+	defer wg.Done()
+	defer recoverPanic("graphiteSink.run")
+	for {
+		select {
+		case line := <-sk.lines:
+			if err := sk.write(line); err != nil {
+				sinksLog.Error("Failed to send metrics", "sink", sk.network, "address", sk.cfg.Address, "err", err)
+			}
+		case <-sinksDrained:
+			for len(sk.lines) > 0 {
+				if err := sk.write(<-sk.lines); err != nil {
+					break
+				}
+			}
+			if sk.conn != nil {
+				sk.conn.Close()
+			}
+			return
+		}
+	}
+}
+
+// write sends a line and redials once on failure.
+func (sk *graphiteSink) write(line string) error {
+	var err error
+	for i := 0; i < 2; i++ {
+		if sk.conn == nil {
+			sk.conn, err = net.DialTimeout(sk.network, sk.cfg.Address, 5*time.Second)
+			if err != nil {
+				sk.conn = nil
+				continue
+			}
+		}
+
+		sk.conn.SetWriteDeadline(time.Now().Add(5 * time.Second))
+		if _, err = sk.conn.Write([]byte(line)); err == nil {
+			return nil
+		}
+		sk.conn.Close()
+		sk.conn = nil
+	}
+	return err
+}
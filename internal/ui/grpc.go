@@ -0,0 +1,372 @@
+package ui
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+)
+
+// gRPC status codes used by the control API.
+const (
+	GRPCOK              = 0
+	GRPCINVALIDARGUMENT = 3
+	GRPCUNIMPLEMENTED   = 12
+	GRPCUNAVAILABLE     = 14
+)
+
+// maximum size of a request message.
+const GRPCMAXMESSAGE = 4 << 20
+
+// samplesFeed fans out the probe results to the StreamSamples callers.
+// Slow callers miss samples instead of blocking the dispatcher.
+type samplesFeed struct {
+	lock    *sync.Mutex
+	clients map[chan sample]struct{}
+}
+
+// probe results broadcaster of the gRPC API.
+var feed = &samplesFeed{lock: &sync.Mutex{}, clients: make(map[chan sample]struct{})}
+
+// subscribe registers a new stream and returns its samples queue.
+func (f *samplesFeed) subscribe() chan sample {
+	c := make(chan sample, 256)
+	f.lock.Lock()
+	f.clients[c] = struct{}{}
+	f.lock.Unlock()
+	return c
+}
+
+// unsubscribe removes a stream.
+func (f *samplesFeed) unsubscribe(c chan sample) {
+	f.lock.Lock()
+	delete(f.clients, c)
+	f.lock.Unlock()
+}
+
+// sample queues a probe result to each stream. The feed is used as a results sink.
+func (f *samplesFeed) sample(sp sample) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	for c := range f.clients {
+		select {
+		case c <- sp:
+		default:
+			count(&counters.droppedEvents)
+		}
+	}
+}
+
+// summary is not streamed, callers can use ListTargets.
+func (f *samplesFeed) summary(ip string, s stat, t time.Time) {}
+
+// startGRPCServer runs the gRPC control API over plaintext HTTP/2
+// and stops it on exit. The service is defined into api/pingo.proto.
+func startGRPCServer(cfg grpcSettings) {
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/pingo.v1.Pingo/", grpcHandler)
+
+	server := &http.Server{
+		Addr:              cfg.Address,
+		Handler:           h2c.NewHandler(mux, &http2.Server{}),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			logs.Error("Failed to run grpc server", "subsystem", "grpc", "err", err)
+		}
+	}()
+
+	<-exit
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := server.Shutdown(ctx); err != nil {
+		logs.Error("Failed to shutdown grpc server", "subsystem", "grpc", "err", err)
+	}
+}
+
+// grpcHandler decodes the request message and dispatches the call.
+func grpcHandler(w http.ResponseWriter, r *http.Request) {
+	if r.ProtoMajor != 2 || !strings.HasPrefix(r.Header.Get("Content-Type"), "application/grpc") {
+		http.Error(w, "grpc request expected", http.StatusUnsupportedMediaType)
+		return
+	}
+	w.Header().Set("Content-Type", "application/grpc")
+
+	msg, err := readGRPCMessage(r.Body)
+	if err != nil {
+		grpcError(w, GRPCINVALIDARGUMENT, err.Error())
+		return
+	}
+	// all requests only carry a list of targets as first field.
+	targets, err := pbStrings(msg, 1)
+	if err != nil {
+		grpcError(w, GRPCINVALIDARGUMENT, err.Error())
+		return
+	}
+
+	switch path.Base(r.URL.Path) {
+	case "ListTargets":
+		grpcReply(w, encodeTargetsList())
+	case "AddTargets":
+		grpcReply(w, encodeStrings(1, addTargets(targets)))
+	case "DeleteTargets":
+		grpcReply(w, encodeStrings(1, deleteTargets(targets)))
+	case "StreamSamples":
+		streamSamples(w, r, targets)
+	default:
+		grpcError(w, GRPCUNIMPLEMENTED, "unknown method "+r.URL.Path)
+	}
+}
+
+// addTargets adds the new valid ips and returns them.
+func addTargets(ips []string) []string {
+	var added []string
+	for _, ip := range ips {
+		ip = strings.TrimSpace(ip)
+		if !isValidIP(ip) || dbs.isExistsIP(ip) {
+			continue
+		}
+		dbs.addNewIP(ip)
+		added = append(added, ip)
+	}
+	if len(added) > 0 {
+		refreshIPs()
+	}
+	return added
+}
+
+// deleteTargets removes the existing ips except the one being pinged.
+func deleteTargets(ips []string) []string {
+	var deleted []string
+	for _, ip := range ips {
+		ip = strings.TrimSpace(ip)
+		if ip == currentOnPingIP || !dbs.isExistsIP(ip) {
+			continue
+		}
+		dbs.deleteIP(ip)
+		deleted = append(deleted, ip)
+	}
+	if len(deleted) > 0 {
+		refreshIPs()
+	}
+	return deleted
+}
+
+// streamSamples sends each probe result of the given ips (all if
+// empty) until the caller cancels or the program exits.
+func streamSamples(w http.ResponseWriter, r *http.Request, ips []string) {
+	wanted := make(map[string]bool, len(ips))
+	for _, ip := range ips {
+		wanted[strings.TrimSpace(ip)] = true
+	}
+
+	samples := feed.subscribe()
+	defer feed.unsubscribe(samples)
+
+	w.Header().Set("Trailer", "Grpc-Status, Grpc-Message")
+	w.WriteHeader(http.StatusOK)
+	flusher, _ := w.(http.Flusher)
+	if flusher != nil {
+		flusher.Flush()
+	}
+
+	for {
+		select {
+		case sp := <-samples:
+			if len(wanted) > 0 && !wanted[sp.ip] {
+				continue
+			}
+			if err := writeGRPCMessage(w, encodeSample(sp)); err != nil {
+				return
+			}
+			if flusher != nil {
+				flusher.Flush()
+			}
+		case <-r.Context().Done():
+			return
+		case <-exit:
+			w.Header().Set("Grpc-Status", strconv.Itoa(GRPCUNAVAILABLE))
+			w.Header().Set("Grpc-Message", "server shutting down")
+			return
+		}
+	}
+}
+
+// readGRPCMessage reads a single length-prefixed uncompressed message.
+func readGRPCMessage(body io.Reader) ([]byte, error) {
+	var prefix [5]byte
+	if _, err := io.ReadFull(body, prefix[:]); err != nil {
+		return nil, errors.New("missing request message")
+	}
+	if prefix[0] != 0 {
+		return nil, errors.New("compressed messages are not supported")
+	}
+
+	size := binary.BigEndian.Uint32(prefix[1:])
+	if size > GRPCMAXMESSAGE {
+		return nil, errors.New("request message too large")
+	}
+
+	msg := make([]byte, size)
+	if _, err := io.ReadFull(body, msg); err != nil {
+		return nil, errors.New("truncated request message")
+	}
+	return msg, nil
+}
+
+// writeGRPCMessage writes a single length-prefixed message.
+func writeGRPCMessage(w io.Writer, msg []byte) error {
+	prefix := make([]byte, 5, 5+len(msg))
+	binary.BigEndian.PutUint32(prefix[1:], uint32(len(msg)))
+	_, err := w.Write(append(prefix, msg...))
+	return err
+}
+
+// grpcReply sends the response message of a unary call.
+func grpcReply(w http.ResponseWriter, msg []byte) {
+	w.Header().Set("Trailer", "Grpc-Status, Grpc-Message")
+	w.WriteHeader(http.StatusOK)
+	if err := writeGRPCMessage(w, msg); err != nil {
+		return
+	}
+	w.Header().Set("Grpc-Status", strconv.Itoa(GRPCOK))
+}
+
+// grpcError ends a call without response message (trailers-only).
+func grpcError(w http.ResponseWriter, code int, msg string) {
+	w.Header().Set("Grpc-Status", strconv.Itoa(code))
+	w.Header().Set("Grpc-Message", url.PathEscape(msg))
+	w.WriteHeader(http.StatusOK)
+}
+
+// encodeTargetsList builds the ListTargetsResponse message.
+func encodeTargetsList() []byte {
+	var msg []byte
+	for _, ip := range dbs.getAllIPs() {
+		t := pbAppendString(nil, 1, ip)
+		if s := dbs.getStats(ip); s != nil {
+			t = pbAppendString(t, 2, s.state)
+			t = pbAppendInt(t, 3, int64(s.fails+s.replies()))
+			t = pbAppendDouble(t, 4, s.loss())
+			t = pbAppendInt(t, 5, int64(s.min))
+			t = pbAppendInt(t, 6, int64(s.avg))
+			t = pbAppendInt(t, 7, int64(s.max))
+			t = pbAppendInt(t, 8, int64(s.last))
+		}
+		msg = pbAppendBytes(msg, 1, t)
+	}
+	return msg
+}
+
+// encodeSample builds the Sample message.
+func encodeSample(sp sample) []byte {
+	msg := pbAppendString(nil, 1, sp.ip)
+	msg = pbAppendInt(msg, 2, sp.time.UnixNano()/int64(time.Millisecond))
+	if sp.success {
+		msg = pbAppendVarint(pbAppendTag(msg, 3, 0), 1)
+	}
+	return pbAppendInt(msg, 4, int64(sp.rtt))
+}
+
+// encodeStrings builds a message made of a repeated string field.
+func encodeStrings(field int, values []string) []byte {
+	var msg []byte
+	for _, v := range values {
+		msg = pbAppendBytes(msg, field, []byte(v))
+	}
+	return msg
+}
+
+// protobuf wire format helpers. Fields with default values are omitted.
+
+func pbAppendVarint(b []byte, v uint64) []byte {
+	var buf [binary.MaxVarintLen64]byte
+	return append(b, buf[:binary.PutUvarint(buf[:], v)]...)
+}
+
+func pbAppendTag(b []byte, field, wire int) []byte {
+	return pbAppendVarint(b, uint64(field<<3|wire))
+}
+
+func pbAppendInt(b []byte, field int, v int64) []byte {
+	if v == 0 {
+		return b
+	}
+	return pbAppendVarint(pbAppendTag(b, field, 0), uint64(v))
+}
+
+func pbAppendDouble(b []byte, field int, v float64) []byte {
+	if v == 0 {
+		return b
+	}
+	var buf [8]byte
+	binary.LittleEndian.PutUint64(buf[:], math.Float64bits(v))
+	return append(pbAppendTag(b, field, 1), buf[:]...)
+}
+
+func pbAppendString(b []byte, field int, s string) []byte {
+	if s == "" {
+		return b
+	}
+	return pbAppendBytes(b, field, []byte(s))
+}
+
+func pbAppendBytes(b []byte, field int, data []byte) []byte {
+	b = pbAppendVarint(pbAppendTag(b, field, 2), uint64(len(data)))
+	return append(b, data...)
+}
+
+// pbStrings returns the values of a repeated string field and skips others.
+func pbStrings(msg []byte, field int) ([]string, error) {
+	var values []string
+	for len(msg) > 0 {
+		tag, n := binary.Uvarint(msg)
+		if n <= 0 {
+			return nil, errors.New("malformed message")
+		}
+		msg = msg[n:]
+
+		switch tag & 7 {
+		case 0:
+			if _, n = binary.Uvarint(msg); n <= 0 {
+				return nil, errors.New("malformed varint")
+			}
+			msg = msg[n:]
+		case 1, 5:
+			size := 8
+			if tag&7 == 5 {
+				size = 4
+			}
+			if len(msg) < size {
+				return nil, errors.New("malformed fixed field")
+			}
+			msg = msg[size:]
+		case 2:
+			size, n := binary.Uvarint(msg)
+			if n <= 0 || uint64(len(msg)-n) < size {
+				return nil, errors.New("malformed length-delimited field")
+			}
+			if int(tag>>3) == field {
+				values = append(values, string(msg[n:n+int(size)]))
+			}
+			msg = msg[n+int(size):]
+		default:
+			return nil, errors.New("unsupported wire type")
+		}
+	}
+	return values, nil
+}
@@ -0,0 +1,159 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sync"
+	"time"
+)
+
+// runHeadless pings all targets concurrently without the terminal UI,
+// each one with its own configs. Results are printed to stdout either
+// as a statistics table every interval or as JSON lines (ndjson). It
+// returns once all bounded pings complete or on interrupt signal. The
+// exit status is 1 when a target is down at the end, 0 otherwise.
+func runHeadless(output string, every time.Duration) int {
+	ips := dbs.getAllIPs()
+	if len(ips) == 0 {
+		fmt.Fprintln(os.Stderr, "no targets to probe : pass files of ip addresses as arguments or pipe them")
+		return 2
+	}
+
+	if every <= 0 {
+		every = 10 * time.Second
+	}
+
+	// alerts, sinks and web server keep working without ui.
+	if output == "ndjson" {
+		startWorkers(newNDJSONSink("-"))
+	} else {
+		startWorkers()
+	}
+
+	ctx, cancel := context.WithCancel(probes)
+	defer cancel()
+
+	// statistics are updated by each ping routine and read by the printer.
+	lock := &sync.Mutex{}
+	done := make(chan struct{})
+	var pwg sync.WaitGroup
+	for _, ip := range ips {
+		ip := ip
+		supervise(&pwg, "ping "+ip, func() {
+			if !workers.acquire(ctx) {
+				return
+			}
+			defer workers.release()
+			runPing(ip, ctx, func(e ProbeEvent) {
+				lock.Lock()
+				buildStats(e)
+				lock.Unlock()
+			})
+		})
+	}
+
+	go func() {
+		pwg.Wait()
+		close(done)
+	}()
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, shutdownSignals...)
+	defer signal.Stop(signals)
+
+	ticker := time.NewTicker(every)
+	defer ticker.Stop()
+
+loop:
+	for {
+		select {
+		case <-ticker.C:
+			if output != "ndjson" {
+				lock.Lock()
+				printSummary(os.Stdout, ips)
+				lock.Unlock()
+			}
+		case <-signals:
+			cancel()
+			break loop
+		case <-done:
+			break loop
+		}
+	}
+
+	close(exit)
+
+	lock.Lock()
+	defer lock.Unlock()
+	status := 0
+	for _, ip := range ips {
+		if s := dbs.getStats(ip); s != nil && s.state == STATEDOWN {
+			status = 1
+		}
+	}
+	if output != "ndjson" {
+		printSummary(os.Stdout, ips)
+	}
+	return status
+}
+
+// printSummary writes the current statistics of the targets as a table.
+func printSummary(w io.Writer, ips []string) {
+	fmt.Fprintf(w, "\n%s\n", time.Now().Format("2006-01-02 15:04:05"))
+	fmt.Fprintf(w, "%-39s %-5s %6s %6s %6s %6s %6s %6s  %s\n", "TARGET", "STATE", "SENT", "LOSS%", "MIN", "AVG", "MAX", "LAST", "TREND")
+	for _, ip := range ips {
+		s := dbs.getStats(ip)
+		if s == nil {
+			continue
+		}
+		state := s.state
+		if state == STATEUNKNOWN {
+			state = "-"
+		}
+		fmt.Fprintf(w, "%-39s %-5s %6d %6.1f %6d %6d %6d %6d  %s\n", ip, state,
+			s.fails+s.replies(), s.loss(), s.min, s.avg, s.max, s.last, sparkline(dbs.getHistory(ip), SPARKWIDTH))
+	}
+}
+
+// number of latest samples drawn into the summary trend column.
+const SPARKWIDTH = 30
+
+// bars of the sparklines from the lowest to the highest latency.
+var sparkBars = []rune("▁▂▃▄▅▆▇█")
+
+// sparkline draws the latency of the latest width samples with one bar
+// each, scaled between their lowest and highest reply times. A failed
+// probe is drawn with x.
+func sparkline(history []sample, width int) string {
+	if len(history) > width {
+		history = history[len(history)-width:]
+	}
+	low, high := -1, 0
+	for _, sp := range history {
+		if !sp.success {
+			continue
+		}
+		if low < 0 || sp.rtt < low {
+			low = sp.rtt
+		}
+		if sp.rtt > high {
+			high = sp.rtt
+		}
+	}
+	line := make([]rune, 0, len(history))
+	for _, sp := range history {
+		if !sp.success {
+			line = append(line, 'x')
+			continue
+		}
+		level := 0
+		if high > low {
+			level = (sp.rtt - low) * (len(sparkBars) - 1) / (high - low)
+		}
+		line = append(line, sparkBars[level])
+	}
+	return string(line)
+}
@@ -0,0 +1,101 @@
+package ui
+
+import (
+	"math"
+	"sort"
+	"sync"
+
+	"github.com/jroimartin/gocui"
+)
+
+// weights of the health score penalties, out of 100.
+const (
+	LOSSWEIGHT    = 60
+	LATENCYWEIGHT = 25
+	FLAPSWEIGHT   = 15
+
+	// highest score of a target currently down.
+	DOWNSCORE = 10
+)
+
+// health ranks the targets of the ips list.
+type health struct {
+	lock *sync.RWMutex
+	// list the worst targets first.
+	worstFirst bool
+}
+
+// global ranking of the ips list.
+var ranking = &health{lock: &sync.RWMutex{}}
+
+// flaps returns how many times the probes of an ip switched between
+// replies and failures over its samples history.
+func flaps(history []sample) int {
+	count := 0
+	for i := 1; i < len(history); i++ {
+		if history[i].success != history[i-1].success {
+			count++
+		}
+	}
+	return count
+}
+
+// healthScore returns the health of an ip from 0 (worst) to 100. The
+// loss up to its max loss, the average latency above the threshold and
+// the flaps of the probes lower it. A target never probed scores 100.
+func healthScore(ip string) int {
+	s, cfg := dbs.getStats(ip), dbs.getConfig(ip)
+	if s == nil || cfg == nil {
+		return 100
+	}
+
+	maxloss := 100.0
+	if cfg.maxloss > 0 {
+		maxloss = float64(cfg.maxloss)
+	}
+	penalty := math.Min(1, s.loss()/maxloss) * LOSSWEIGHT
+	if cfg.threshold > 0 && s.avg > cfg.threshold {
+		penalty += math.Min(1, float64(s.avg-cfg.threshold)/float64(cfg.threshold)) * LATENCYWEIGHT
+	}
+	// each flap costs a fifth of its weight.
+	penalty += math.Min(1, float64(flaps(dbs.getHistory(ip)))/5) * FLAPSWEIGHT
+
+	score := 100 - int(math.Round(penalty))
+	if s.state == STATEDOWN && score > DOWNSCORE {
+		score = DOWNSCORE
+	}
+	return score
+}
+
+// sorted tells whether the worst targets are listed first.
+func (h *health) sorted() bool {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+	return h.worstFirst
+}
+
+// sort orders the ips by their health score when the worst first order
+// is set. Equal scores keep the list order.
+func (h *health) sort(ips []string) []string {
+	if !h.sorted() {
+		return ips
+	}
+	scores := make(map[string]int, len(ips))
+	for _, ip := range ips {
+		scores[ip] = healthScore(ip)
+	}
+	sorted := append([]string(nil), ips...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return scores[sorted[i]] < scores[sorted[j]]
+	})
+	return sorted
+}
+
+// toggleWorstFirst switches the ips list between its order and the
+// worst first order.
+func toggleWorstFirst(g *gocui.Gui, ipv *gocui.View) error {
+	ranking.lock.Lock()
+	ranking.worstFirst = !ranking.worstFirst
+	ranking.lock.Unlock()
+	return updateIPsView(g)
+}
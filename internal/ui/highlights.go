@@ -0,0 +1,84 @@
+package ui
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jroimartin/gocui"
+)
+
+// colors of the highlighted ips into the list.
+const (
+	COLORDOWN      = "\x1b[31m"
+	COLORRECOVERED = "\x1b[35m"
+	COLORRESET     = "\x1b[0m"
+)
+
+// highlight marks into the ips list a target down or recently back up.
+type highlight struct {
+	state string
+	since time.Time
+}
+
+// highlightStore keeps the highlights of the targets until they are
+// acknowledged or faded after their recovery.
+type highlightStore struct {
+	lock  *sync.Mutex
+	items map[string]highlight
+}
+
+// global highlights of the ips list.
+var highlights = &highlightStore{lock: &sync.Mutex{}, items: make(map[string]highlight)}
+
+// mark highlights an ip going down or recovering. A recovered target
+// fades after the highlight_clear minutes of the alerts settings.
+func (hs *highlightStore) mark(ip, state string) {
+	cfgs := getSettings()
+	hs.lock.Lock()
+	hs.items[ip] = highlight{state: state, since: time.Now()}
+	hs.lock.Unlock()
+	if state == STATEUP && cfgs.Alerts.HighlightClear > 0 {
+		time.AfterFunc(time.Duration(cfgs.Alerts.HighlightClear)*time.Minute, refreshIPs)
+	}
+	refreshIPs()
+}
+
+// ack removes the highlight of an ip until its next state change.
+func (hs *highlightStore) ack(ip string) {
+	hs.lock.Lock()
+	delete(hs.items, ip)
+	hs.lock.Unlock()
+	refreshIPs()
+}
+
+// color returns the escape sequence coloring an ip into the list or
+// an empty string when it is not highlighted.
+func (hs *highlightStore) color(ip string) string {
+	cfgs := getSettings()
+	hs.lock.Lock()
+	defer hs.lock.Unlock()
+	h, ok := hs.items[ip]
+	if !ok {
+		return ""
+	}
+	if h.state == STATEDOWN {
+		return COLORDOWN
+	}
+	if clear := time.Duration(cfgs.Alerts.HighlightClear) * time.Minute; clear > 0 && time.Since(h.since) >= clear {
+		delete(hs.items, ip)
+		return ""
+	}
+	return COLORRECOVERED
+}
+
+// acknowledgeHighlight clears the highlight of the focused ip.
+func acknowledgeHighlight(g *gocui.Gui, ipv *gocui.View) error {
+	_, cy := ipv.Cursor()
+	l, err := ipv.Line(cy)
+	if err != nil || len(strings.Fields(l)) < 2 {
+		return nil
+	}
+	highlights.ack(strings.Fields(l)[1])
+	return nil
+}
@@ -0,0 +1,271 @@
+package ui
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jroimartin/gocui"
+)
+
+const (
+	RUNS = "runs"
+
+	RUNSWIDTH  = 120
+	RUNSHEIGHT = 20
+
+	// completed runs kept per target.
+	MAXRUNS = 50
+)
+
+// runSummary describes a completed ping or traceroute run of an ip.
+type runSummary struct {
+	ip      string
+	kind    string
+	start   time.Time
+	end     time.Time
+	stopped bool
+	// loss and latency profile or hops of the run.
+	details string
+	// files holding the outputs when the backup of the ip is set.
+	backups []backupPart
+}
+
+// runStore keeps the latest runs of each ip.
+type runStore struct {
+	lock *sync.RWMutex
+	runs map[string][]runSummary
+}
+
+// completed runs of all targets.
+var runs = &runStore{lock: &sync.RWMutex{}, runs: make(map[string][]runSummary)}
+
+// past runs to display again into the outputs view.
+var replayChan = make(chan runSummary, 1)
+
+// add records a run and drops the oldest ones of its ip.
+func (rs *runStore) add(r runSummary) {
+	rs.lock.Lock()
+	list := append(rs.runs[r.ip], r)
+	if len(list) > MAXRUNS {
+		list = list[len(list)-MAXRUNS:]
+	}
+	rs.runs[r.ip] = list
+	rs.lock.Unlock()
+}
+
+// list returns the runs of an ip from the latest.
+func (rs *runStore) list(ip string) []runSummary {
+	rs.lock.RLock()
+	defer rs.lock.RUnlock()
+	list := make([]runSummary, 0, len(rs.runs[ip]))
+	for i := len(rs.runs[ip]) - 1; i >= 0; i-- {
+		list = append(list, rs.runs[ip][i])
+	}
+	return list
+}
+
+// summary returns the loss and latency profile of a ping run.
+func (r *runReport) summary(stopped bool, backups []backupPart) runSummary {
+	sent, loss, min, avg, max := r.profile()
+	details := fmt.Sprintf("%d sent - %.1f%% loss - min/avg/max %d/%d/%d ms", sent, loss, min, avg, max)
+	// the quantile needs the samples only kept for bounded runs.
+	if r.bounded {
+		details = fmt.Sprintf("%d sent - %.1f%% loss - min/avg/max/p95 %d/%d/%d/%d ms", sent, loss, min, avg, max, r.results.Quantile(0.95))
+	}
+	return runSummary{ip: r.ip, kind: "ping", start: r.start, end: r.end, stopped: stopped, backups: backups, details: details}
+}
+
+// summary returns the hops of a traceroute run.
+func (tr *traceResult) summary(backups []backupPart) runSummary {
+	details := fmt.Sprintf("%d hops", len(tr.hops))
+	if n := len(tr.hops); n > 0 {
+		last := tr.hops[n-1]
+		details += fmt.Sprintf(" - last %s %.1f%% loss", orStar(last.address), last.loss())
+	}
+	if len(tr.changes) > 0 {
+		details += " - path changed"
+	}
+	return runSummary{ip: tr.ip, kind: "traceroute", start: tr.start, end: time.Now(), backups: backups, details: details}
+}
+
+// String formats a run into a line of the history view.
+func (r runSummary) String() string {
+	line := fmt.Sprintf("%s  %-7s %-10s  %s", r.start.Format("2006-01-02 15:04:05"), shortDuration(r.end.Sub(r.start)), r.kind, r.details)
+	if r.stopped {
+		line += " (stopped)"
+	}
+	if len(r.backups) > 0 {
+		line += " [saved]"
+	}
+	return line
+}
+
+// displayRunsView lists the completed runs of the focused ip to open
+// again the outputs of one of them.
+func displayRunsView(g *gocui.Gui, ipv *gocui.View) error {
+	_, cy := ipv.Cursor()
+	l, err := ipv.Line(cy)
+	if err != nil || len(strings.Fields(l)) < 2 {
+		return nil
+	}
+	ip := strings.Fields(l)[1]
+
+	maxX, maxY := g.Size()
+	rv, err := g.SetView(RUNS, (maxX-RUNSWIDTH)/2, (maxY-RUNSHEIGHT)/2, (maxX+RUNSWIDTH)/2, (maxY+RUNSHEIGHT)/2)
+	if err != nil && err != gocui.ErrUnknownView {
+		log.Println("Failed to create runs view:", err)
+		return err
+	}
+	if err == gocui.ErrUnknownView {
+		rv.FgColor = gocui.ColorYellow
+		rv.SelBgColor = gocui.ColorGreen
+		rv.SelFgColor = gocui.ColorBlack
+		rv.Highlight = true
+		rv.Editable = false
+		rv.Wrap = false
+
+		bindings := []struct {
+			key     interface{}
+			handler func(*gocui.Gui, *gocui.View) error
+		}{
+			{gocui.KeyEnter, openRun},
+			{gocui.KeyArrowUp, outMoveCursorUp},
+			{gocui.KeyArrowDown, outMoveCursorDown},
+			{gocui.KeyCtrlQ, closeRunsView},
+			{gocui.KeyEsc, closeRunsView},
+		}
+		for _, b := range bindings {
+			if err := g.SetKeybinding(RUNS, b.key, gocui.ModNone, b.handler); err != nil {
+				log.Println("Failed to bind keys to runs view:", err)
+				return err
+			}
+		}
+	}
+	rv.Title = fmt.Sprintf(" Runs of [%s] | Enter: open outputs - Esc: close ", ip)
+	rv.Clear()
+	rv.SetCursor(0, 0)
+	rv.SetOrigin(0, 0)
+	list := runs.list(ip)
+	if len(list) == 0 {
+		fmt.Fprintf(rv, "No completed ping or traceroute of %s yet.", ip)
+	}
+	for _, r := range list {
+		fmt.Fprintln(rv, r)
+	}
+	if _, err := g.SetCurrentView(RUNS); err != nil {
+		log.Println("Failed to set focus on runs view:", err)
+		return err
+	}
+	return nil
+}
+
+// closeRunsView closes the runs history popup.
+func closeRunsView(g *gocui.Gui, rv *gocui.View) error {
+	g.DeleteKeybindings(rv.Name())
+	if err := g.DeleteView(rv.Name()); err != nil {
+		log.Println("Failed to delete runs view:", err)
+		return err
+	}
+	return setCurrentDefaultView(g)
+}
+
+// openRun displays the saved outputs of the focused run.
+func openRun(g *gocui.Gui, rv *gocui.View) error {
+	title := rv.Title
+	ip := title[strings.Index(title, "[")+1 : strings.Index(title, "]")]
+	_, oy := rv.Origin()
+	_, cy := rv.Cursor()
+	list := runs.list(ip)
+	if oy+cy >= len(list) {
+		return nil
+	}
+	run := list[oy+cy]
+	if err := closeRunsView(g, rv); err != nil {
+		return err
+	}
+
+	bus.publish(EVTITLE, fmt.Sprintf(" History %s [%s] %s Outputs ", run.kind, ip, run.start.Format("2006-01-02 15:04:05")))
+	replayChan <- run
+	// reset since no ping.
+	currentOnPingIP = ""
+	currentOutputsIP = ip
+	return nil
+}
+
+// executeReplay displays again the outputs of a past run read from
+// the backup files : the ping lines with their time or the hops
+// table of a traceroute.
+func executeReplay(run runSummary, ctx context.Context) {
+	cfgs := getSettings()
+	defer recoverPanic("executeReplay")
+	if len(run.backups) == 0 {
+		bus.publish(EVOUTPUT, run.String())
+		bus.publish(EVOUTPUT, fmt.Sprintf("The outputs of this run were not saved : set the backup config of %s with <CTRL+E> to keep them.", run.ip))
+		return
+	}
+
+	lines, texts, err := readBackupRun(run)
+	if err != nil {
+		bus.publish(EVOUTPUT, "Failed to read the run outputs : "+err.Error())
+		return
+	}
+	if ctx.Err() != nil {
+		return
+	}
+
+	if run.kind == "traceroute" {
+		tr := newTraceResult(run.ip)
+		tr.start = run.start
+		for _, text := range texts {
+			tr.parse(text)
+		}
+		bus.publish(EVTABLE, run.String()+"\n"+tr.format())
+		return
+	}
+
+	// the outputs view keeps the same number of lines as a live run.
+	header := run.String() + "\n"
+	if len(lines) > cfgs.OutputLines {
+		header += fmt.Sprintf("... %d earlier lines into %s\n", len(lines)-cfgs.OutputLines, run.backups[0].path)
+		lines = lines[len(lines)-cfgs.OutputLines:]
+	}
+	bus.publish(EVTABLE, header+strings.Join(lines, "\n")+"\n")
+}
+
+// readBackupRun returns the backup lines written during a run and
+// their texts without the time.
+func readBackupRun(run runSummary) ([]string, []string, error) {
+	from := run.start.Truncate(time.Second)
+	var lines, texts []string
+	for _, part := range run.backups {
+		f, err := os.Open(part.path)
+		if err != nil {
+			return nil, nil, err
+		}
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			line := scanner.Text()
+			// 15:04:05.000 line
+			if len(line) < 13 {
+				continue
+			}
+			t, err := time.ParseInLocation("20060102 15:04:05.000", part.date+" "+line[:12], time.Local)
+			if err != nil || t.Before(from) || t.After(run.end) {
+				continue
+			}
+			lines, texts = append(lines, line), append(texts, line[13:])
+		}
+		err = scanner.Err()
+		f.Close()
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	return lines, texts, nil
+}
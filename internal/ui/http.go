@@ -0,0 +1,38 @@
+package ui
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// startHTTPServer runs the embedded web server and stops it on exit.
+func startHTTPServer(cfg httpSettings) {
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", metricsHandler)
+	mux.HandleFunc("/ws", wsHandler)
+	mux.HandleFunc("/api/state", stateHandler)
+	mux.HandleFunc("/healthz", healthzHandler)
+	mux.Handle("/", dashboardHandler())
+
+	server := &http.Server{
+		Addr:         cfg.Address,
+		Handler:      mux,
+		ReadTimeout:  10 * time.Second,
+		WriteTimeout: 30 * time.Second,
+	}
+
+	go func() {
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			logs.Error("Failed to run http server", "subsystem", "http", "err", err)
+		}
+	}()
+
+	<-exit
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := server.Shutdown(ctx); err != nil {
+		logs.Error("Failed to shutdown http server", "subsystem", "http", "err", err)
+	}
+}
@@ -0,0 +1,142 @@
+package ui
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// influxSink writes samples and summaries in InfluxDB line protocol
+// either to a file or to an InfluxDB server using v1 or v2 API.
+type influxSink struct {
+	cfg   influxSettings
+	lines chan string
+}
+
+// newInfluxSink creates a line protocol sink and starts its writer.
+func newInfluxSink(cfg influxSettings) *influxSink {
+	sk := &influxSink{cfg: cfg, lines: make(chan string, 1000)}
+	wg.Add(1)
+	go sk.run()
+	return sk
+}
+
+// sample converts a probe result into a line.
+func (sk *influxSink) sample(sp sample) {
+	if !isTargetSelected(sp.ip, sk.cfg.Targets) {
+		return
+	}
+	fields := "success=false"
+	if sp.success {
+		fields = fmt.Sprintf("success=true,rtt=%di", sp.rtt)
+	}
+	sk.queue(fmt.Sprintf("pingo_sample,target=%s %s %d", sp.ip, fields, sp.time.UnixNano()))
+}
+
+// summary converts the statistics of a target into a line.
+func (sk *influxSink) summary(ip string, s stat, t time.Time) {
+	if !isTargetSelected(ip, sk.cfg.Targets) {
+		return
+	}
+	sk.queue(fmt.Sprintf("pingo_summary,target=%s min=%di,avg=%di,max=%di,fails=%di,replies=%di,loss=%.2f,up=%t %d",
+		ip, s.min, s.avg, s.max, s.fails, s.replies(), s.loss(), s.state == STATEUP, t.UnixNano()))
+}
+
+// queue adds a line to the next batch without blocking.
+func (sk *influxSink) queue(line string) {
+	select {
+	case sk.lines <- line:
+	default:
+		count(&counters.droppedSamples)
+		sinksLog.Warn("Influx queue full, dropped line", "sink", "influx", "line", line)
+	}
+}
+
+// run flushes the collected lines every few seconds.
+func (sk *influxSink) run() {
+	defer wg.Done()
+	defer recoverPanic("influxSink.run")
+	var batch []string
+	ticker := time.NewTicker(5 * time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case line := <-sk.lines:
+			batch = append(batch, line)
+		case <-ticker.C:
+			if len(batch) == 0 {
+				continue
+			}
+			if err := sk.flush(batch); err != nil {
+				sinksLog.Error("Failed to write influx lines", "sink", "influx", "err", err)
+			}
+			batch = nil
+		case <-sinksDrained:
+			for len(sk.lines) > 0 {
+				batch = append(batch, <-sk.lines)
+			}
+			if len(batch) > 0 {
+				if err := sk.flush(batch); err != nil {
+					sinksLog.Error("Failed to write influx lines", "sink", "influx", "err", err)
+				}
+			}
+			return
+		}
+	}
+}
+
+// flush writes a batch of lines to the configured destination. The
+// timestamps are in nanoseconds, the default precision of the files
+// and of the write endpoints.
+func (sk *influxSink) flush(batch []string) error {
+	data := strings.Join(batch, "\n") + "\n"
+
+	if sk.cfg.File != "" {
+		f, err := os.OpenFile(sk.cfg.File, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = f.WriteString(data)
+		return err
+	}
+
+	params := url.Values{}
+	params.Set("precision", "ns")
+	endpoint := strings.TrimRight(sk.cfg.URL, "/")
+	if sk.cfg.Version == 2 {
+		params.Set("org", sk.cfg.Org)
+		params.Set("bucket", sk.cfg.Bucket)
+		endpoint = endpoint + "/api/v2/write?" + params.Encode()
+	} else {
+		params.Set("db", sk.cfg.Database)
+		endpoint = endpoint + "/write?" + params.Encode()
+	}
+
+	req, err := http.NewRequest(http.MethodPost, endpoint, bytes.NewBufferString(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	if sk.cfg.Version == 2 {
+		req.Header.Set("Authorization", "Token "+sk.cfg.Token)
+	} else if sk.cfg.Username != "" {
+		req.SetBasicAuth(sk.cfg.Username, sk.cfg.Password)
+	}
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return nil
+}
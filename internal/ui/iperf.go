@@ -0,0 +1,121 @@
+package ui
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/jroimartin/gocui"
+)
+
+// targets to measure the throughput to.
+var iperfChan = make(chan string, 1)
+
+// bitrate of an iperf3 summary line such as :
+// [SUM]   0.00-10.00  sec  1.10 GBytes   941 Mbits/sec    receiver
+var bitratePattern = regexp.MustCompile(`([\d.]+ [KMG]?bits/sec)`)
+
+// runIperf requests a throughput test toward the focused ip.
+func runIperf(g *gocui.Gui, ipv *gocui.View) error {
+	_, cy := ipv.Cursor()
+	l, err := ipv.Line(cy)
+	if err != nil || len(strings.Fields(l)) < 2 {
+		return nil
+	}
+	ip := strings.Fields(l)[1]
+
+	bus.publish(EVTITLE, fmt.Sprintf(" Throughput [%s] Outputs ", ip))
+	iperfChan <- ip
+	// reset since no ping.
+	currentOnPingIP = ""
+	currentOutputsIP = ip
+	return nil
+}
+
+// executeIperf runs an upload then a download iperf3 test toward the
+// server configured for an ip and summarizes them into the statistics.
+func executeIperf(ip string, ctx context.Context) {
+	cfgs := getSettings()
+	defer recoverPanic("executeIperf")
+	cfg := dbs.getConfig(ip)
+	if cfg == nil || cfg.iperf == 0 {
+		bus.publish(EVOUTPUT, fmt.Sprintf("No iperf3 server configured for %s. Set its iperf port with <CTRL+E>.", ip))
+		return
+	}
+
+	summary := fmt.Sprintf("iperf: %d\n", cfg.iperf)
+	var results []string
+	for _, reverse := range []bool{false, true} {
+		direction, label := "upload", "up"
+		if reverse {
+			direction, label = "download", "down"
+		}
+		bus.publish(EVOUTPUT, fmt.Sprintf("Testing %s throughput with %s:%d during %ds over %d stream(s) ...",
+			direction, ip, cfg.iperf, cfgs.Iperf.Duration, cfgs.Iperf.Streams))
+
+		bitrate, err := runIperfTest(ip, cfg.iperf, reverse, ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			bus.publish(EVOUTPUT, fmt.Sprintf("Failed to test %s throughput : %v", direction, err))
+			return
+		}
+		results = append(results, direction+" "+bitrate)
+		summary += fmt.Sprintf("%-5s: %s\n", label, strings.Replace(bitrate, "bits/sec", "b/s", 1))
+		bus.publish(EVSTATSTEXT, summary)
+	}
+	bus.publish(EVOUTPUT, "Completed : "+strings.Join(results, " - "))
+}
+
+// runIperfTest runs the iperf3 client, streams its outputs and returns
+// the bitrate received at the end of the test.
+func runIperfTest(ip string, port int, reverse bool, ctx context.Context) (string, error) {
+	cfgs := getSettings()
+	args := []string{"-c", ip, "-p", strconv.Itoa(port), "-t", strconv.Itoa(cfgs.Iperf.Duration),
+		"-P", strconv.Itoa(cfgs.Iperf.Streams), "-f", "m", "--forceflush"}
+	if reverse {
+		args = append(args, "-R")
+	}
+	cmd := exec.CommandContext(ctx, cfgs.Iperf.Path, args...)
+	outpipe, err := cmd.StdoutPipe()
+	if err != nil {
+		return "", err
+	}
+	cmd.Stderr = cmd.Stdout
+
+	release, err := startProcess(cmd)
+	if err != nil {
+		return "", err
+	}
+
+	var bitrate string
+	scanner := bufio.NewScanner(outpipe)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		bus.publish(EVOUTPUT, line)
+		// the sum of all streams is the last summary line.
+		if strings.HasSuffix(strings.TrimSpace(line), "receiver") {
+			if m := bitratePattern.FindString(line); m != "" {
+				bitrate = m
+			}
+		}
+	}
+	err = cmd.Wait()
+	release()
+	if err != nil {
+		return "", err
+	}
+	if bitrate == "" {
+		return "", errors.New("no summary found into iperf3 outputs")
+	}
+	return bitrate, nil
+}
@@ -0,0 +1,49 @@
+package ui
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// normalizeTags returns the space or comma separated tags of a list
+// lowercased, sorted and without duplicates, joined by commas.
+func normalizeTags(list string) string {
+	seen := make(map[string]bool)
+	var tags []string
+	for _, t := range strings.FieldsFunc(strings.ToLower(list), func(r rune) bool { return unicode.IsSpace(r) || r == ',' }) {
+		if !seen[t] {
+			seen[t] = true
+			tags = append(tags, t)
+		}
+	}
+	sort.Strings(tags)
+	return strings.Join(tags, ",")
+}
+
+// hasTag tells whether the target is tagged with a tag.
+func (cfg *config) hasTag(tag string) bool {
+	for _, t := range strings.Split(cfg.tags, ",") {
+		if t != "" && t == strings.ToLower(tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// labelDetails returns the label and the tags of an ip as searched
+// and listed by the search box, like "core switch | #core #lan".
+func labelDetails(ip string) string {
+	cfg := dbs.getConfig(ip)
+	if cfg == nil {
+		return ""
+	}
+	var parts []string
+	if cfg.label != "" {
+		parts = append(parts, cfg.label)
+	}
+	if cfg.tags != "" {
+		parts = append(parts, "#"+strings.Replace(cfg.tags, ",", " #", -1))
+	}
+	return strings.Join(parts, " | ")
+}
@@ -0,0 +1,57 @@
+//go:build !windows && !darwin && !freebsd && !dragonfly && !openbsd
+// +build !windows,!darwin,!freebsd,!dragonfly,!openbsd
+
+package ui
+
+import (
+	"context"
+	"os/exec"
+	"strconv"
+)
+
+// privilegesHint tells how to allow the native icmp engine.
+const privilegesHint = "run pingo as root, grant it the CAP_NET_RAW capability (setcap cap_net_raw+ep pingo) or allow the unprivileged icmp sockets of your group (sysctl net.ipv4.ping_group_range)"
+
+// buildTracerouteCommand constructs full traceroute command to run
+// with the focused ip options for linux and the other unix systems
+// sharing its flags. The timeout config is the wait in seconds (-w)
+// of each probe like on the other systems. ICMP probes (-I) may require root
+// privileges and TCP probes (-T) are not available on all systems.
+// Both are replaced by UDP probes on Termux without root.
+func buildTracerouteCommand(ip string, ctx context.Context) *exec.Cmd {
+	cfg := dbs.getConfig(ip)
+	var args []string
+
+	if cfg.maxhops > 0 {
+		args = append(args, "-m", strconv.Itoa(cfg.maxhops))
+	}
+
+	if cfg.queries > 0 {
+		args = append(args, "-q", strconv.Itoa(cfg.queries))
+	}
+
+	if cfg.timeout > 0 {
+		args = append(args, "-w", strconv.Itoa(cfg.timeout))
+	}
+
+	protocol := cfg.protocol
+	// udp probes only since icmp and tcp ones need raw sockets.
+	if rawSocketsDenied() {
+		protocol = "udp"
+	}
+
+	switch protocol {
+	case "icmp":
+		args = append(args, "-I")
+	case "tcp":
+		args = append(args, "-T")
+	}
+
+	if cfg.numeric {
+		args = append(args, "-n")
+	}
+
+	args = append(args, ip)
+
+	return exec.CommandContext(ctx, "traceroute", args...)
+}
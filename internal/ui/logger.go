@@ -0,0 +1,236 @@
+package ui
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// logging levels.
+const (
+	LEVELDEBUG = iota
+	LEVELINFO
+	LEVELWARN
+	LEVELERROR
+)
+
+var levelNames = []string{"debug", "info", "warn", "error"}
+
+// logCore is the shared output and level of a logger and its children.
+type logCore struct {
+	lock  *sync.Mutex
+	out   io.Writer
+	level int
+}
+
+// logger writes leveled entries in logfmt format (key=value pairs).
+// Each child created with With carries its own set of fields, this is
+// used to tag entries with the subsystem which produced them.
+type logger struct {
+	core   *logCore
+	fields []interface{}
+}
+
+var (
+	// application root logger. Writes nowhere until setupLogger.
+	logs = &logger{core: &logCore{lock: &sync.Mutex{}, out: io.Discard, level: LEVELINFO}}
+
+	// per-subsystem loggers.
+	alertsLog  = logs.With("subsystem", "alerts")
+	sinksLog   = logs.With("subsystem", "sinks")
+	storeLog   = logs.With("subsystem", "store")
+	probeLog   = logs.With("subsystem", "probe")
+	uiLog      = logs.With("subsystem", "ui")
+	settingLog = logs.With("subsystem", "settings")
+)
+
+// parseLevel converts a level name into its value.
+func parseLevel(name string) (int, error) {
+	for i, n := range levelNames {
+		if strings.EqualFold(n, strings.TrimSpace(name)) {
+			return i, nil
+		}
+	}
+	return LEVELINFO, fmt.Errorf("unknown log level %q", name)
+}
+
+// With returns a child logger which adds the given key-value pairs to each entry.
+func (l *logger) With(kv ...interface{}) *logger {
+	fields := make([]interface{}, 0, len(l.fields)+len(kv))
+	fields = append(fields, l.fields...)
+	fields = append(fields, kv...)
+	return &logger{core: l.core, fields: fields}
+}
+
+func (l *logger) Debug(msg string, kv ...interface{}) { l.write(LEVELDEBUG, msg, kv) }
+func (l *logger) Info(msg string, kv ...interface{})  { l.write(LEVELINFO, msg, kv) }
+func (l *logger) Warn(msg string, kv ...interface{})  { l.write(LEVELWARN, msg, kv) }
+func (l *logger) Error(msg string, kv ...interface{}) { l.write(LEVELERROR, msg, kv) }
+
+// write formats and outputs an entry if its level is enabled.
+func (l *logger) write(level int, msg string, kv []interface{}) {
+	if level < l.core.level {
+		return
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "time=%s level=%s", time.Now().Format("2006-01-02T15:04:05.000Z07:00"), levelNames[level])
+	pairs := append(append([]interface{}{}, l.fields...), kv...)
+	for i := 0; i+1 < len(pairs); i += 2 {
+		fmt.Fprintf(&b, " %v=%s", pairs[i], quoteValue(fmt.Sprint(pairs[i+1])))
+	}
+	fmt.Fprintf(&b, " msg=%s\n", quoteValue(msg))
+
+	l.core.lock.Lock()
+	l.core.out.Write([]byte(b.String()))
+	l.core.lock.Unlock()
+}
+
+// quoteValue quotes a value containing spaces or special characters.
+func quoteValue(v string) string {
+	if v == "" || strings.ContainsAny(v, " =\"\t\n") {
+		return fmt.Sprintf("%q", v)
+	}
+	return v
+}
+
+// stdLogWriter routes entries of the standard log package to a logger.
+type stdLogWriter struct {
+	l     *logger
+	level int
+}
+
+func (w stdLogWriter) Write(p []byte) (int, error) {
+	w.l.write(w.level, strings.TrimSpace(string(p)), nil)
+	return len(p), nil
+}
+
+// rotatingFile is a log file which is renamed with a timestamp suffix
+// once it reaches the maximum size or when the day changes. Only the
+// latest backups files are kept.
+type rotatingFile struct {
+	lock       *sync.Mutex
+	path       string
+	maxSize    int64
+	maxBackups int
+	file       *os.File
+	size       int64
+	day        string
+}
+
+// newRotatingFile opens (or creates) the log file and its folder.
+func newRotatingFile(path string, maxSizeMB, maxBackups int) (*rotatingFile, error) {
+	rf := &rotatingFile{lock: &sync.Mutex{}, path: path, maxSize: int64(maxSizeMB) * 1024 * 1024, maxBackups: maxBackups}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	return rf, rf.open()
+}
+
+// open opens the current log file in append mode.
+func (rf *rotatingFile) open() error {
+	f, err := os.OpenFile(rf.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	rf.file, rf.size = f, info.Size()
+	rf.day = info.ModTime().Format("20060102")
+	return nil
+}
+
+// Write appends data and rotates the file when needed.
+func (rf *rotatingFile) Write(p []byte) (int, error) {
+	rf.lock.Lock()
+	defer rf.lock.Unlock()
+
+	today := time.Now().Format("20060102")
+	if rf.size > 0 && (rf.size+int64(len(p)) > rf.maxSize || today != rf.day) {
+		if err := rf.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	rf.day = today
+
+	n, err := rf.file.Write(p)
+	rf.size += int64(n)
+	return n, err
+}
+
+// rotate renames the current file then removes the oldest backups.
+func (rf *rotatingFile) rotate() error {
+	rf.file.Close()
+	backup := rf.path + "." + time.Now().Format("20060102-150405.000")
+	if err := os.Rename(rf.path, backup); err != nil {
+		return err
+	}
+
+	if backups, err := filepath.Glob(rf.path + ".*"); err == nil && len(backups) > rf.maxBackups {
+		sort.Strings(backups)
+		for _, old := range backups[:len(backups)-rf.maxBackups] {
+			os.Remove(old)
+		}
+	}
+	return rf.open()
+}
+
+// Close closes the current log file.
+func (rf *rotatingFile) Close() error {
+	rf.lock.Lock()
+	defer rf.lock.Unlock()
+	return rf.file.Close()
+}
+
+// defaultLogFile returns the log file path into the user cache folder
+// so that no file is created into the current working directory.
+func defaultLogFile() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "pingo", "pingo.log")
+}
+
+// setupLogger configures the level and the rotating output of all loggers.
+func setupLogger(level, path string, maxSizeMB, maxBackups int) (io.Closer, error) {
+	lvl, err := parseLevel(level)
+	if err != nil {
+		return nil, err
+	}
+
+	if path == STDERRLOG {
+		logs.core.lock.Lock()
+		logs.core.out, logs.core.level = os.Stderr, lvl
+		logs.core.lock.Unlock()
+		return ioutil.NopCloser(os.Stderr), nil
+	}
+
+	if path == "" {
+		path = defaultLogFile()
+	}
+
+	if maxSizeMB <= 0 {
+		maxSizeMB = 10
+	}
+
+	rf, err := newRotatingFile(path, maxSizeMB, maxBackups)
+	if err != nil {
+		return nil, err
+	}
+
+	logs.core.lock.Lock()
+	logs.core.out, logs.core.level = rf, lvl
+	logs.core.lock.Unlock()
+
+	return rf, nil
+}
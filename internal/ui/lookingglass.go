@@ -0,0 +1,295 @@
+package ui
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jroimartin/gocui"
+)
+
+const (
+	LOOKINGGLASS = "lookingGlass"
+
+	LWIDTH  = 100
+	LHEIGHT = 26
+)
+
+// html tags removed from the text looking-glasses outputs.
+var htmlTagPattern = regexp.MustCompile(`<[^>]*>`)
+
+// ripeLookingGlass is the subset of a RIPEstat looking-glass response
+// used : the routes seen by the peers of each RIS route collector.
+type ripeLookingGlass struct {
+	Data struct {
+		RRCs []struct {
+			Peers []struct {
+				Prefix string `json:"prefix"`
+				ASPath string `json:"as_path"`
+			} `json:"peers"`
+		} `json:"rrcs"`
+	} `json:"data"`
+}
+
+// bgpRoute summarizes the paths of a prefix seen by the peers.
+type bgpRoute struct {
+	prefix string
+	peers  int
+	paths  map[string]int
+}
+
+// bgpRoutes are the routes of an address from the most specific.
+type bgpRoutes []*bgpRoute
+
+// displayLookingGlassView queries in background the configured
+// looking-glasses for the routes of the focused ip or traceroute
+// hop and shows them into a popup.
+func displayLookingGlassView(g *gocui.Gui, cv *gocui.View) error {
+	cfgs := getSettings()
+	_, cy := cv.Cursor()
+	l, err := cv.Line(cy)
+	if err != nil {
+		return nil
+	}
+
+	var ip string
+	if cv.Name() == IPLIST {
+		if fields := strings.Fields(l); len(fields) > 1 {
+			ip = fields[1]
+		}
+	} else {
+		ip, _ = parseHopAddress(l)
+	}
+	if !isValidIP(ip) {
+		return nil
+	}
+
+	maxX, maxY := g.Size()
+	lv, err := g.SetView(LOOKINGGLASS, (maxX-LWIDTH)/2, (maxY-LHEIGHT)/2, (maxX+LWIDTH)/2, (maxY+LHEIGHT)/2)
+	if err != nil && err != gocui.ErrUnknownView {
+		log.Println("Failed to create looking-glass view:", err)
+		return err
+	}
+	if err == gocui.ErrUnknownView {
+		lv.FgColor = gocui.ColorYellow
+		lv.Editable = false
+		for _, key := range []gocui.Key{gocui.KeyEsc, gocui.KeyCtrlQ} {
+			if err := g.SetKeybinding(LOOKINGGLASS, key, gocui.ModNone, closeLookingGlassView); err != nil {
+				log.Println("Failed to bind keys to looking-glass view:", err)
+				return err
+			}
+		}
+	}
+	lv.Title = fmt.Sprintf(" BGP routes of [%s] | Esc: close ", ip)
+	lv.Clear()
+	if len(cfgs.LookingGlass) == 0 {
+		fmt.Fprint(lv, "No looking-glass configured. Add some into the looking_glass settings.")
+	} else {
+		fmt.Fprintf(lv, "Querying %d looking-glass(es) for %s ...", len(cfgs.LookingGlass), ip)
+	}
+	if _, err := g.SetCurrentView(LOOKINGGLASS); err != nil {
+		log.Println("Failed to set focus on looking-glass view:", err)
+		return err
+	}
+	if len(cfgs.LookingGlass) == 0 {
+		return nil
+	}
+
+	go func() {
+		defer recoverPanic("queryLookingGlasses")
+		results := make([][]string, len(cfgs.LookingGlass))
+		for i, lg := range cfgs.LookingGlass {
+			lines, err := queryLookingGlass(lg, ip)
+			if err != nil {
+				lines = []string{"Failed to query : " + err.Error()}
+			}
+			results[i] = lines
+		}
+		g.Update(func(g *gocui.Gui) error {
+			lv, verr := g.View(LOOKINGGLASS)
+			// closed or showing another address meanwhile.
+			if verr != nil || !strings.Contains(lv.Title, "["+ip+"]") {
+				return nil
+			}
+			lv.Clear()
+			_, height := lv.Size()
+			fmt.Fprint(lv, formatLookingGlasses(cfgs.LookingGlass, results, height))
+			return nil
+		})
+	}()
+	return nil
+}
+
+// closeLookingGlassView closes the routes popup.
+func closeLookingGlassView(g *gocui.Gui, lv *gocui.View) error {
+	g.DeleteKeybindings(lv.Name())
+	if err := g.DeleteView(lv.Name()); err != nil {
+		log.Println("Failed to delete looking-glass view:", err)
+		return err
+	}
+	return setCurrentDefaultView(g)
+}
+
+// queryLookingGlass fetches the routes of an ip from a looking-glass
+// and returns them as lines to display.
+func queryLookingGlass(lg lookingGlassSettings, ip string) ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	url := strings.ReplaceAll(lg.URL, "{ip}", ip)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "pingo")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("looking-glass replied %s", resp.Status)
+	}
+
+	if lg.Format == "ripestat" {
+		var data ripeLookingGlass
+		if err = json.NewDecoder(resp.Body).Decode(&data); err != nil {
+			return nil, err
+		}
+		return data.routes().lines(), nil
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	text := string(body)
+	if strings.Contains(resp.Header.Get("Content-Type"), "html") {
+		text = htmlTagPattern.ReplaceAllString(text, "")
+	}
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.TrimRight(line, " \t\r"); strings.TrimSpace(line) != "" {
+			lines = append(lines, line)
+		}
+	}
+	if len(lines) == 0 {
+		return []string{"No outputs."}, nil
+	}
+	return lines, nil
+}
+
+// routes groups the paths seen by all peers by prefix, the most
+// specific prefixes first since they are the ones forwarded on.
+func (r *ripeLookingGlass) routes() bgpRoutes {
+	byPrefix := make(map[string]*bgpRoute)
+	var routes bgpRoutes
+	for _, rrc := range r.Data.RRCs {
+		for _, p := range rrc.Peers {
+			route, ok := byPrefix[p.Prefix]
+			if !ok {
+				route = &bgpRoute{prefix: p.Prefix, paths: make(map[string]int)}
+				byPrefix[p.Prefix] = route
+				routes = append(routes, route)
+			}
+			route.peers++
+			route.paths[p.ASPath]++
+		}
+	}
+	sort.SliceStable(routes, func(i, j int) bool {
+		return prefixLength(routes[i].prefix) > prefixLength(routes[j].prefix)
+	})
+	return routes
+}
+
+// prefixLength returns the mask length of a cidr prefix.
+func prefixLength(prefix string) int {
+	var n int
+	if i := strings.LastIndex(prefix, "/"); i >= 0 {
+		fmt.Sscanf(prefix[i+1:], "%d", &n)
+	}
+	return n
+}
+
+// pathOrigin returns the last AS of a path.
+func pathOrigin(path string) string {
+	asns := strings.Fields(path)
+	if len(asns) == 0 {
+		return "n/a"
+	}
+	return "AS" + strings.Trim(asns[len(asns)-1], "{}")
+}
+
+// lines formats the routes with their AS paths, the most seen first.
+func (routes bgpRoutes) lines() []string {
+	if len(routes) == 0 {
+		return []string{"No route seen : the address is not announced on the internet."}
+	}
+	var lines []string
+	for _, route := range routes {
+		paths := make([]string, 0, len(route.paths))
+		origins := make(map[string]bool)
+		var originsList []string
+		for path := range route.paths {
+			paths = append(paths, path)
+			if o := pathOrigin(path); !origins[o] {
+				origins[o] = true
+				originsList = append(originsList, o)
+			}
+		}
+		sort.Strings(originsList)
+		sort.Slice(paths, func(i, j int) bool {
+			if route.paths[paths[i]] != route.paths[paths[j]] {
+				return route.paths[paths[i]] > route.paths[paths[j]]
+			}
+			return paths[i] < paths[j]
+		})
+
+		lines = append(lines, fmt.Sprintf("prefix  : %s  origin %s  seen by %d peers", route.prefix, strings.Join(originsList, ", "), route.peers))
+		for _, path := range paths {
+			lines = append(lines, fmt.Sprintf("  %5d  %s", route.paths[path], path))
+		}
+	}
+	return lines
+}
+
+// formatLookingGlasses returns the results of each looking-glass
+// sharing the lines of a popup of height lines.
+func formatLookingGlasses(lgs []lookingGlassSettings, results [][]string, height int) string {
+	var b strings.Builder
+	// the name and the separating line of each section.
+	rows := height/len(lgs) - 2
+	if rows < 1 {
+		rows = 1
+	}
+	for i, lg := range lgs {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		name := lg.Name
+		if name == "" {
+			name = lg.URL
+		}
+		fmt.Fprintf(&b, "[%s]\n", name)
+		lines := results[i]
+		for n, line := range lines {
+			if n == rows-1 && len(lines) > rows {
+				fmt.Fprintf(&b, "  ... %d more lines\n", len(lines)-n)
+				break
+			}
+			if len(line) > LWIDTH-2 {
+				line = line[:LWIDTH-3] + "~"
+			}
+			b.WriteString(line + "\n")
+		}
+	}
+	return b.String()
+}
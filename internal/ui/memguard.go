@@ -0,0 +1,114 @@
+package ui
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/jeamon/pingo/internal/stats"
+)
+
+const (
+	// approximate sizes of a sample and of a per-minute aggregate
+	// kept into the history, with their share of the slices.
+	SAMPLEBYTES    = 64
+	AGGREGATEBYTES = 72
+
+	// interval between two checks of the memory used.
+	MEMORYCHECK = 10 * time.Second
+)
+
+var (
+	// size of the lines kept by the outputs view.
+	outputBytes int64
+	// set to ask the outputs view to drop its oldest lines.
+	trimOutputs int32
+)
+
+// historyBytes returns the approximate memory used by the samples
+// history and by its per-minute aggregates.
+func (db *databases) historyBytes() (int64, int64) {
+	db.hlock.RLock()
+	defer db.hlock.RUnlock()
+	var samples, minutes int64
+	for _, h := range db.history {
+		samples += int64(cap(h)) * SAMPLEBYTES
+	}
+	for _, m := range db.minutes {
+		minutes += int64(cap(m)) * AGGREGATEBYTES
+	}
+	return samples, minutes
+}
+
+// trimHistory downsamples the oldest half of the samples history of
+// each target into per-minute aggregates, or drops it when these are
+// disabled.
+func (db *databases) trimHistory() {
+	cfgs := getSettings()
+	db.hlock.Lock()
+	defer db.hlock.Unlock()
+	for ip, h := range db.history {
+		half := len(h) / 2
+		if cfgs.HistoryMinutes > 0 {
+			db.downsample(ip, h[:half])
+		}
+		// copied so the dropped samples are released.
+		db.history[ip] = append([]sample(nil), h[half:]...)
+	}
+}
+
+// trimMinutes drops the oldest half of the per-minute aggregates of
+// each target.
+func (db *databases) trimMinutes() {
+	db.hlock.Lock()
+	defer db.hlock.Unlock()
+	for ip, m := range db.minutes {
+		db.minutes[ip] = append([]stats.Aggregate(nil), m[len(m)/2:]...)
+	}
+}
+
+// memoryUsage returns the approximate memory used by the samples
+// history, its per-minute aggregates and the output lines, which are
+// held by both the outputs ring and the view.
+func memoryUsage() (int64, int64, int64) {
+	samples, minutes := dbs.historyBytes()
+	return samples, minutes, 2 * atomic.LoadInt64(&outputBytes)
+}
+
+// memoryGuard trims the biggest of the samples history, its per-minute
+// aggregates and the output lines while the memory they use exceeds
+// the memory_limit setting, so long sessions monitoring many targets
+// do not run out of memory.
+func memoryGuard() {
+	ticker := time.NewTicker(MEMORYCHECK)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-exit:
+			return
+		case <-ticker.C:
+		}
+
+		cfgs := getSettings()
+		samples, minutes, outputs := memoryUsage()
+		used, limit := samples+minutes+outputs, int64(cfgs.MemoryLimit)<<20
+		if used <= limit {
+			continue
+		}
+
+		var trimmed string
+		switch {
+		case outputs >= samples && outputs >= minutes:
+			atomic.StoreInt32(&trimOutputs, 1)
+			trimmed = "output lines"
+		case samples >= minutes:
+			dbs.trimHistory()
+			trimmed = "samples history"
+		default:
+			dbs.trimMinutes()
+			trimmed = "per-minute aggregates"
+		}
+		count(&counters.memoryTrims)
+		logs.Warn("Memory limit exceeded, oldest data trimmed", "used_mb", used>>20, "limit_mb", cfgs.MemoryLimit, "trimmed", trimmed)
+		showError("Memory limit of %d MB exceeded (%.1f MB used) : the oldest half of the %s was trimmed", cfgs.MemoryLimit, float64(used)/(1<<20), trimmed)
+	}
+}
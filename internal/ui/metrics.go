@@ -0,0 +1,55 @@
+package ui
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// metricsHandler exposes per-target statistics in the Prometheus text format.
+func metricsHandler(w http.ResponseWriter, r *http.Request) {
+	var b strings.Builder
+	ips := dbs.getAllIPs()
+	snapshots := make(map[string]stat, len(ips))
+	for _, ip := range ips {
+		if s := dbs.getStats(ip); s != nil {
+			snapshots[ip] = *s
+		}
+	}
+
+	metrics := []struct {
+		name  string
+		kind  string
+		help  string
+		value func(s stat) float64
+	}{
+		{"pingo_rtt_seconds", "gauge", "Round-trip time of the last reply in seconds.", func(s stat) float64 { return float64(s.last) / 1000 }},
+		{"pingo_rtt_min_seconds", "gauge", "Minimum round-trip time in seconds.", func(s stat) float64 { return float64(s.min) / 1000 }},
+		{"pingo_rtt_avg_seconds", "gauge", "Average round-trip time in seconds.", func(s stat) float64 { return float64(s.avg) / 1000 }},
+		{"pingo_rtt_max_seconds", "gauge", "Maximum round-trip time in seconds.", func(s stat) float64 { return float64(s.max) / 1000 }},
+		{"pingo_loss_ratio", "gauge", "Ratio of failed requests.", func(s stat) float64 { return s.loss() / 100 }},
+		{"pingo_up", "gauge", "Target state (1 for up and 0 for down or unknown).", func(s stat) float64 {
+			if s.state == STATEUP {
+				return 1
+			}
+			return 0
+		}},
+		{"pingo_sent_total", "counter", "Number of requests sent.", func(s stat) float64 { return float64(s.fails + s.replies()) }},
+		{"pingo_received_total", "counter", "Number of replies received.", func(s stat) float64 { return float64(s.replies()) }},
+		{"pingo_threshold_above_total", "counter", "Number of replies above the threshold.", func(s stat) float64 { return float64(s.above) }},
+	}
+
+	for _, m := range metrics {
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s %s\n", m.name, m.help, m.name, m.kind)
+		for _, ip := range ips {
+			s, ok := snapshots[ip]
+			if !ok {
+				continue
+			}
+			fmt.Fprintf(&b, "%s{target=%q} %g\n", m.name, ip, m.value(s))
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	fmt.Fprint(w, b.String())
+}
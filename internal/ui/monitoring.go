@@ -0,0 +1,287 @@
+package ui
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	// nagios or icinga 1 host definitions and their directives.
+	nagiosHostBlock = regexp.MustCompile(`(?s)define\s+host\s*\{(.*?)\}`)
+	// icinga 2 host objects up to their closing brace line.
+	icingaHostBlock = regexp.MustCompile(`(?s)object\s+Host\s+"([^"]*)"\s*\{(.*?)\n\s*\}`)
+	icingaAttribute = regexp.MustCompile(`(?m)^\s*([\w.]+)\s*=\s*"?([^"\n]*?)"?\s*$`)
+)
+
+// monitoredHost is a host imported from a monitoring system with its
+// warning thresholds, 0 when not set.
+type monitoredHost struct {
+	name    string
+	address string
+	// round trip average in ms and packet loss in %.
+	rta  int
+	loss int
+}
+
+// zabbixExport is the subset of a Zabbix hosts export (JSON format)
+// used : the interfaces addresses and the ICMP templates macros.
+type zabbixExport struct {
+	Export struct {
+		Hosts []struct {
+			Host       string `json:"host"`
+			Interfaces []struct {
+				IP string `json:"ip"`
+			} `json:"interfaces"`
+			Macros []struct {
+				Macro string `json:"macro"`
+				Value string `json:"value"`
+			} `json:"macros"`
+		} `json:"hosts"`
+	} `json:"zabbix_export"`
+}
+
+// parseMonitoringHosts reads the hosts of a Nagios or Icinga config, a
+// Zabbix JSON export or a CSV hosts list. It tells whether the content
+// is one of these formats rather than a plain list of ip addresses.
+func parseMonitoringHosts(filename string, content []byte) ([]monitoredHost, bool, error) {
+	text := string(content)
+	switch {
+	case bytes.Contains(content, []byte(`"zabbix_export"`)):
+		hosts, err := parseZabbixHosts(content)
+		return hosts, true, err
+	case nagiosHostBlock.MatchString(text):
+		return parseNagiosHosts(text), true, nil
+	case icingaHostBlock.MatchString(text):
+		return parseIcingaHosts(text), true, nil
+	case strings.EqualFold(filepath.Ext(filename), ".csv"):
+		return parseCSVHosts(content)
+	}
+	return nil, false, nil
+}
+
+// parseCheckPing reads the warning thresholds of a check_ping command
+// like check_ping!100.0,20%!500.0,60%.
+func parseCheckPing(command string) (int, int) {
+	args := strings.Split(command, "!")
+	if len(args) < 2 || !strings.Contains(args[0], "check_ping") {
+		return 0, 0
+	}
+	warning := strings.SplitN(args[1], ",", 2)
+	rta, _ := strconv.ParseFloat(strings.TrimSpace(warning[0]), 64)
+	var loss float64
+	if len(warning) == 2 {
+		loss, _ = strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(warning[1]), "%"), 64)
+	}
+	return int(math.Round(rta)), int(math.Round(loss))
+}
+
+// parseNagiosHosts reads the address and the check_ping thresholds of
+// the host definitions of a Nagios or Icinga 1 config.
+func parseNagiosHosts(text string) []monitoredHost {
+	var hosts []monitoredHost
+	for _, block := range nagiosHostBlock.FindAllStringSubmatch(text, -1) {
+		var h monitoredHost
+		for _, line := range strings.Split(block[1], "\n") {
+			if i := strings.IndexAny(line, ";#"); i >= 0 {
+				line = line[:i]
+			}
+			fields := strings.Fields(line)
+			if len(fields) < 2 {
+				continue
+			}
+			switch fields[0] {
+			case "host_name":
+				h.name = fields[1]
+			case "address":
+				h.address = fields[1]
+			case "check_command":
+				h.rta, h.loss = parseCheckPing(fields[1])
+			}
+		}
+		if h.address != "" {
+			hosts = append(hosts, h)
+		}
+	}
+	return hosts
+}
+
+// parseIcingaHosts reads the address and the ping command thresholds
+// (vars.ping_wrta and vars.ping_wpl) of the host objects of an
+// Icinga 2 config.
+func parseIcingaHosts(text string) []monitoredHost {
+	var hosts []monitoredHost
+	for _, block := range icingaHostBlock.FindAllStringSubmatch(text, -1) {
+		h := monitoredHost{name: block[1]}
+		for _, attr := range icingaAttribute.FindAllStringSubmatch(block[2], -1) {
+			value := strings.TrimSpace(attr[2])
+			switch attr[1] {
+			case "address":
+				h.address = value
+			case "address6":
+				if h.address == "" {
+					h.address = value
+				}
+			case "vars.ping_wrta", "vars.ping4_wrta", "vars.ping6_wrta":
+				rta, _ := strconv.ParseFloat(value, 64)
+				h.rta = int(math.Round(rta))
+			case "vars.ping_wpl", "vars.ping4_wpl", "vars.ping6_wpl":
+				loss, _ := strconv.ParseFloat(value, 64)
+				h.loss = int(math.Round(loss))
+			}
+		}
+		if h.address != "" {
+			hosts = append(hosts, h)
+		}
+	}
+	return hosts
+}
+
+// parseZabbixHosts reads the interfaces addresses of the hosts of a
+// Zabbix export with the warning macros of the ICMP ping template :
+// {$ICMP_RESPONSE_TIME_WARN} in seconds and {$ICMP_LOSS_WARN} in %.
+func parseZabbixHosts(content []byte) ([]monitoredHost, error) {
+	var export zabbixExport
+	if err := json.Unmarshal(content, &export); err != nil {
+		return nil, err
+	}
+	var hosts []monitoredHost
+	for _, zh := range export.Export.Hosts {
+		var rta, loss int
+		for _, m := range zh.Macros {
+			value, _ := strconv.ParseFloat(strings.TrimSpace(m.Value), 64)
+			switch m.Macro {
+			case "{$ICMP_RESPONSE_TIME_WARN}":
+				rta = int(math.Round(value * 1000))
+			case "{$ICMP_LOSS_WARN}":
+				loss = int(math.Round(value))
+			}
+		}
+		for _, i := range zh.Interfaces {
+			if i.IP != "" {
+				hosts = append(hosts, monitoredHost{name: zh.Host, address: i.IP, rta: rta, loss: loss})
+			}
+		}
+	}
+	return hosts, nil
+}
+
+// parseCSVHosts reads a CSV hosts list whose header names the address
+// column (ip, address or interface) and optionally the warning round
+// trip average in ms (rta or threshold) and packet loss in % (pl or
+// loss), or the Zabbix macros columns.
+func parseCSVHosts(content []byte) ([]monitoredHost, bool, error) {
+	records, err := csv.NewReader(bytes.NewReader(content)).ReadAll()
+	if err != nil || len(records) == 0 {
+		return nil, false, nil
+	}
+	name, address, rta, loss := -1, -1, -1, -1
+	// zabbix macros are in seconds.
+	rtaScale := 1.0
+	for i, column := range records[0] {
+		switch strings.ToLower(strings.TrimSpace(column)) {
+		case "host", "host_name", "name":
+			name = i
+		case "ip", "address", "interface":
+			address = i
+		case "rta", "threshold":
+			rta = i
+		case "{$icmp_response_time_warn}":
+			rta, rtaScale = i, 1000
+		case "pl", "loss", "{$icmp_loss_warn}":
+			loss = i
+		}
+	}
+	// not a hosts list but a plain list of addresses.
+	if address < 0 {
+		return nil, false, nil
+	}
+
+	var hosts []monitoredHost
+	for _, r := range records[1:] {
+		field := func(i int) string {
+			if i < 0 || i >= len(r) {
+				return ""
+			}
+			return strings.TrimSpace(r[i])
+		}
+		h := monitoredHost{name: field(name), address: field(address)}
+		if v, err := strconv.ParseFloat(field(rta), 64); err == nil {
+			h.rta = int(math.Round(v * rtaScale))
+		}
+		if v, err := strconv.ParseFloat(strings.TrimSuffix(field(loss), "%"), 64); err == nil {
+			h.loss = int(math.Round(v))
+		}
+		if h.address != "" {
+			hosts = append(hosts, h)
+		}
+	}
+	return hosts, true, nil
+}
+
+// importHosts adds the hosts of a monitoring system with their warning
+// round trip average as threshold, packet loss as max loss and host
+// name as label. Hosts defined by name instead of ip address are
+// skipped.
+func (db *databases) importHosts(filename string, hosts []monitoredHost) {
+	var skipped []string
+	for _, h := range hosts {
+		if !isValidIP(h.address) {
+			skipped = append(skipped, h.address)
+			continue
+		}
+		db.addNewIP(h.address)
+		cfg := db.getConfig(h.address)
+		if cfg == nil {
+			continue
+		}
+		if h.rta > 0 {
+			cfg.threshold = h.rta
+		}
+		if h.loss > 0 && h.loss <= 100 {
+			cfg.maxloss = h.loss
+		}
+		if h.name != "" && h.name != h.address {
+			cfg.label = h.name
+		}
+		db.updateConfig(h.address, cfg)
+		store.saveTarget(h.address, cfg)
+	}
+	uiLog.Info("Imported monitoring hosts", "file", filename, "hosts", len(hosts)-len(skipped), "skipped", len(skipped))
+	if len(skipped) > 0 {
+		showError("%d hosts of %s skipped since not ip addresses : %s", len(skipped), filename, strings.Join(skipped, ", "))
+	}
+}
+
+// exportNagiosHosts writes the targets as Nagios host definitions
+// checked with check_ping at their threshold and max loss, so the
+// monitoring can mirror them.
+func exportNagiosHosts(filename string) error {
+	var b strings.Builder
+	for _, ip := range dbs.getAllIPs() {
+		cfg := dbs.getConfig(ip)
+		if cfg == nil {
+			continue
+		}
+		command := "check-host-alive"
+		if cfg.threshold > 0 || cfg.maxloss > 0 {
+			rta, loss := cfg.threshold, cfg.maxloss
+			if rta <= 0 {
+				rta = 5000
+			}
+			if loss <= 0 {
+				loss = 100
+			}
+			command = fmt.Sprintf("check_ping!%d.0,%d%%!%d.0,100%%", rta, loss, 2*rta)
+		}
+		fmt.Fprintf(&b, "define host {\n    use            generic-host\n    host_name      %s\n    address        %s\n    check_command  %s\n}\n\n", ip, ip, command)
+	}
+	return ioutil.WriteFile(filename, []byte(b.String()), 0644)
+}
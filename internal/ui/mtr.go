@@ -0,0 +1,206 @@
+package ui
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+)
+
+// hopStat holds the cumulative statistics of a hop in MTR mode.
+type hopStat struct {
+	number  int
+	address string
+	host    string
+	sent    int
+	lost    int
+	last    float64
+	best    float64
+	worst   float64
+	sum     float64
+}
+
+// mtrSession keeps the per-hop statistics of repeated traceroutes.
+type mtrSession struct {
+	ip     string
+	rounds int
+	hops   map[int]*hopStat
+}
+
+// newMTRSession creates an empty MTR session for an ip.
+func newMTRSession(ip string) *mtrSession {
+	return &mtrSession{ip: ip, hops: make(map[int]*hopStat)}
+}
+
+// snapshot returns a copy of the session statistics.
+func (m *mtrSession) snapshot() *mtrSession {
+	c := &mtrSession{ip: m.ip, rounds: m.rounds, hops: make(map[int]*hopStat, len(m.hops))}
+	for n, hs := range m.hops {
+		hsc := *hs
+		c.hops[n] = &hsc
+	}
+	return c
+}
+
+// sortedHops returns the hops statistics ordered by hop number.
+func (m *mtrSession) sortedHops() []*hopStat {
+	numbers := make([]int, 0, len(m.hops))
+	for n := range m.hops {
+		numbers = append(numbers, n)
+	}
+	sort.Ints(numbers)
+
+	hops := make([]*hopStat, 0, len(numbers))
+	for _, n := range numbers {
+		hops = append(hops, m.hops[n])
+	}
+	return hops
+}
+
+// add updates the statistics of a hop with a parsed traceroute line.
+func (m *mtrSession) add(h hop) {
+	hs, ok := m.hops[h.number]
+	if !ok {
+		hs = &hopStat{number: h.number}
+		m.hops[h.number] = hs
+	}
+
+	if h.address != "" {
+		hs.address, hs.host = h.address, h.host
+	}
+
+	hs.sent += h.probes()
+	hs.lost += h.lost
+	// pathping average time stands for all replies of the hop.
+	if h.sent > 0 && len(h.rtts) > 0 {
+		rtt := h.rtts[0]
+		if hs.sent-hs.lost == h.sent-h.lost || rtt < hs.best {
+			hs.best = rtt
+		}
+		if rtt > hs.worst {
+			hs.worst = rtt
+		}
+		hs.last = rtt
+		hs.sum += rtt * float64(h.sent-h.lost)
+		return
+	}
+
+	for _, rtt := range h.rtts {
+		if hs.sent-hs.lost == 1 || rtt < hs.best {
+			hs.best = rtt
+		}
+		if rtt > hs.worst {
+			hs.worst = rtt
+		}
+		hs.last = rtt
+		hs.sum += rtt
+	}
+}
+
+// loss returns the percentage of lost probes of a hop.
+func (hs *hopStat) loss() float64 {
+	if hs.sent == 0 {
+		return 0
+	}
+	return float64(hs.lost) * 100 / float64(hs.sent)
+}
+
+// avg returns the average rtt of a hop.
+func (hs *hopStat) avg() float64 {
+	if hs.sent-hs.lost == 0 {
+		return 0
+	}
+	return hs.sum / float64(hs.sent-hs.lost)
+}
+
+// format renders the hops statistics as a table.
+func (m *mtrSession) format() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "MTR to %s - round %d - %s\n\n", m.ip, m.rounds, time.Now().Format("15:04:05"))
+	fmt.Fprintf(&b, "%4s  %-40s ", "Hop", "Address")
+	if enrich != nil {
+		fmt.Fprintf(&b, "%-9s %-2s ", "ASN", "CC")
+	}
+	fmt.Fprintf(&b, "%6s %5s %7s %7s %7s %7s\n", "Loss%", "Snt", "Last", "Avg", "Best", "Worst")
+	for _, hs := range m.sortedHops() {
+		info := enrich.info(hs.address)
+		host := hs.host
+		if (host == "" || host == hs.address) && info.ptr != "" {
+			host = info.ptr
+		}
+		address := hs.address
+		if address == "" {
+			address = "???"
+		} else if host != "" && host != hs.address {
+			address = fmt.Sprintf("%s (%s)", host, hs.address)
+		}
+		if len(address) > 40 {
+			address = address[:40]
+		}
+		fmt.Fprintf(&b, "%4d  %-40s ", hs.number, address)
+		if enrich != nil {
+			fmt.Fprintf(&b, "%-9s %-2s ", info.asn, info.country)
+		}
+		fmt.Fprintf(&b, "%5.1f%% %5d %7.1f %7.1f %7.1f %7.1f\n",
+			hs.loss(), hs.sent, hs.last, hs.avg(), hs.best, hs.worst)
+	}
+	return b.String()
+}
+
+// executeMTR repeatedly traces an ip and displays the live hops table.
+func executeMTR(ip string, ctx context.Context) {
+	defer recoverPanic("executeMTR")
+	m := newMTRSession(ip)
+	for {
+		// each round is killed after the run deadline.
+		cmd, err := startCommand(ctx, "mtr round", ip, func(ctx context.Context) *exec.Cmd {
+			return buildTracerouteCommand(ip, ctx)
+		})
+		if err != nil {
+			return
+		}
+
+		reader := bufio.NewReader(cmd.output)
+		for {
+			data, err := reader.ReadString('\n')
+			if h, ok := parsePathpingLine(data); ok {
+				m.add(h)
+			} else if h, ok := parseHopLine(data); ok {
+				m.add(h)
+			}
+			if err != nil {
+				break
+			}
+		}
+		cmd.Wait()
+		cmd.release()
+		deadline := cmd.ctx.Err() != nil
+		cmd.cancel()
+
+		if ctx.Err() != nil {
+			return
+		}
+
+		// the hops of a round stopped by its deadline are kept but
+		// the round is not counted.
+		if !deadline {
+			m.rounds++
+		}
+		traces.saveMTR(m)
+		table := m.format()
+		if deadline {
+			table += "\n" + deadlineMessage("mtr round", ip) + "\n"
+		}
+		bus.publish(EVTABLE, table)
+
+		// pause between two rounds.
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(time.Second):
+		}
+	}
+}
@@ -0,0 +1,297 @@
+package ui
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jroimartin/gocui"
+)
+
+// ips to trace concurrently.
+var ipsToMultiTraceChan = make(chan []string, 1)
+
+// sharedHop aggregates a hop address seen on the paths of several targets.
+type sharedHop struct {
+	address string
+	host    string
+	targets []string
+	// hops numbers where the address was seen.
+	numbers map[int]bool
+	sent    int
+	lost    int
+}
+
+// multiTraceInputView displays a temporary input box to enter the
+// comma-separated list of IPs to trace concurrently.
+func multiTraceInputView(g *gocui.Gui, cv *gocui.View) error {
+	maxX, maxY := g.Size()
+
+	const name = "multiTrace"
+
+	// construct the input box and position at the center of the screen.
+	if inputView, err := g.SetView(name, maxX/2-35, maxY/2, maxX/2+35, maxY/2+2); err != nil {
+		if err != gocui.ErrUnknownView {
+			log.Println("Failed to display input view: ", err)
+			return err
+		}
+
+		inputView.Title = " Parallel Traceroute (Enter IPs Comma Separated or all) "
+		inputView.FgColor = gocui.ColorYellow
+		inputView.SelBgColor = gocui.ColorBlack
+		inputView.SelFgColor = gocui.ColorYellow
+		inputView.Editable = true
+
+		if _, err := g.SetCurrentView(name); err != nil {
+			log.Println(err)
+			return err
+		}
+		g.Cursor = true
+		inputView.Highlight = true
+		// bind Enter key to processInput function.
+		if err := g.SetKeybinding(name, gocui.KeyEnter, gocui.ModNone, processInput); err != nil {
+			log.Println(err)
+			return err
+		}
+
+		// bind Ctrl+Q and Escape keys to close the input box.
+		if err := g.SetKeybinding(name, gocui.KeyCtrlQ, gocui.ModNone, closeInputView); err != nil {
+			log.Println(err)
+			return err
+		}
+
+		if err := g.SetKeybinding(name, gocui.KeyEsc, gocui.ModNone, closeInputView); err != nil {
+			log.Println(err)
+			return err
+		}
+
+		// bind Tab key to complete the known ip addresses.
+		if err := g.SetKeybinding(name, gocui.KeyTab, gocui.ModNone, completeInput(completeIPs)); err != nil {
+			log.Println(err)
+			return err
+		}
+
+		inputView.Write([]byte("all"))
+		inputView.SetCursor(len(inputView.Buffer())-1, 0)
+	}
+	return nil
+}
+
+// addMultiTrace parses the entered list of IPs and
+// sends them to the scheduler for a parallel trace.
+func addMultiTrace(input string) {
+	var ips []string
+	if strings.EqualFold(strings.TrimSpace(input), "all") {
+		ips = dbs.getAllIPs()
+	} else {
+		for _, ip := range strings.Split(input, ",") {
+			if ip = strings.TrimSpace(ip); isValidIP(ip) {
+				ips = append(ips, ip)
+			}
+		}
+	}
+
+	if len(ips) == 0 {
+		return
+	}
+
+	bus.publish(EVTITLE, fmt.Sprintf(" Parallel Traceroute [%d targets] Outputs ", len(ips)))
+	ipsToMultiTraceChan <- ips
+	// reset since no ping.
+	currentOnPingIP = ""
+	currentOutputsIP = ""
+}
+
+// traceOnce runs a traceroute until its end and returns the parsed result.
+func traceOnce(ip string, ctx context.Context) (*traceResult, error) {
+	cmd := buildTracerouteCommand(ip, ctx)
+	cmd.Stderr = cmd.Stdout
+	outpipe, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+
+	release, err := startProcess(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	tr := newTraceResult(ip)
+	reader := bufio.NewReader(decodeOutput(outpipe))
+	for {
+		data, err := reader.ReadString('\n')
+		tr.parse(data)
+		if err != nil {
+			break
+		}
+	}
+	cmd.Wait()
+	release()
+
+	return tr, ctx.Err()
+}
+
+// executeMultiTrace traces several ips with at most cfgs.Parallel
+// concurrent traceroutes then displays the combined hops view.
+func executeMultiTrace(ips []string, ctx context.Context) {
+	cfgs := getSettings()
+	defer recoverPanic("executeMultiTrace")
+	results := make([]*traceResult, len(ips))
+	slots := make(chan struct{}, cfgs.Parallel)
+	progress := make(chan struct{}, len(ips))
+	var twg sync.WaitGroup
+
+	for i, ip := range ips {
+		twg.Add(1)
+		go func(i int, ip string) {
+			defer recoverPanic("traceOnce")
+			defer twg.Done()
+			select {
+			case slots <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			defer func() {
+				<-slots
+				progress <- struct{}{}
+			}()
+
+			tr, err := traceOnce(ip, ctx)
+			if err != nil {
+				if ctx.Err() == nil {
+					probeLog.Error("Failed to run traceroute", "target", ip, "err", err)
+				}
+				return
+			}
+			results[i] = tr
+		}(i, ip)
+	}
+
+	go func() {
+		twg.Wait()
+		close(progress)
+	}()
+
+	done := 0
+	start := time.Now()
+	bus.publish(EVTABLE, fmt.Sprintf("Tracing %d targets (%d in parallel) ...", len(ips), cfgs.Parallel))
+	for range progress {
+		done++
+		if ctx.Err() == nil {
+			bus.publish(EVTABLE, fmt.Sprintf("Tracing %d targets (%d in parallel) : %d done in %s ...",
+				len(ips), cfgs.Parallel, done, time.Since(start).Round(time.Second)))
+		}
+	}
+
+	if ctx.Err() != nil {
+		return
+	}
+
+	var completed []*traceResult
+	var addresses []string
+	for _, tr := range results {
+		if tr == nil {
+			continue
+		}
+		completed = append(completed, tr)
+		addresses = append(addresses, tr.addresses()...)
+		tr.compare(traces.save(tr))
+		if len(tr.changes) > 0 && cfgs.Alerts.PathChange {
+			sendPathAlert(tr.ip, tr.changes)
+		}
+	}
+	enrich.resolve(ctx, addresses)
+
+	if ctx.Err() == nil {
+		bus.publish(EVTABLE, formatMultiTrace(completed))
+	}
+}
+
+// formatMultiTrace renders the hops shared by several paths, with their
+// cumulative loss, followed by the path of each target. A transit hop
+// shared by all failing paths is the likely faulty segment.
+func formatMultiTrace(results []*traceResult) string {
+	shared := make(map[string]*sharedHop)
+	for _, tr := range results {
+		for _, h := range tr.hops {
+			if h.address == "" {
+				continue
+			}
+			sh, ok := shared[h.address]
+			if !ok {
+				sh = &sharedHop{address: h.address, host: h.host, numbers: make(map[int]bool)}
+				shared[h.address] = sh
+			}
+			if len(sh.targets) == 0 || sh.targets[len(sh.targets)-1] != tr.ip {
+				sh.targets = append(sh.targets, tr.ip)
+			}
+			sh.numbers[h.number] = true
+			sh.sent += h.probes()
+			sh.lost += h.lost
+		}
+	}
+
+	var common []*sharedHop
+	for _, sh := range shared {
+		if len(sh.targets) > 1 {
+			common = append(common, sh)
+		}
+	}
+	// most shared hops first then the lossy ones.
+	sort.Slice(common, func(i, j int) bool {
+		if len(common[i].targets) != len(common[j].targets) {
+			return len(common[i].targets) > len(common[j].targets)
+		}
+		return common[i].loss() > common[j].loss()
+	})
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Parallel traceroute of %d targets - %s\n\n", len(results), time.Now().Format("15:04:05"))
+	fmt.Fprintf(&b, "Hops shared by several paths :\n\n")
+	fmt.Fprintf(&b, "%-40s %-9s %-2s %5s %6s  %-7s %s\n", "Address", "ASN", "CC", "Paths", "Loss%", "Hops#", "Targets")
+	for _, sh := range common {
+		info := enrich.info(sh.address)
+		var ns []int
+		for n := range sh.numbers {
+			ns = append(ns, n)
+		}
+		sort.Ints(ns)
+		var numbers []string
+		for _, n := range ns {
+			numbers = append(numbers, fmt.Sprint(n))
+		}
+		fmt.Fprintf(&b, "%-40s %-9s %-2s %5d %5.1f%%  %-7s %s\n", hopLabel(sh.address, sh.host), info.asn, info.country,
+			len(sh.targets), sh.loss(), strings.Join(numbers, ","), strings.Join(sh.targets, ", "))
+	}
+	if len(common) == 0 {
+		b.WriteString("none\n")
+	}
+
+	b.WriteString("\nPaths :\n\n")
+	for _, tr := range results {
+		var path []string
+		lossy := 0
+		for _, h := range tr.hops {
+			path = append(path, orStar(h.address))
+			if h.lost > 0 {
+				lossy++
+			}
+		}
+		fmt.Fprintf(&b, "%-15s %2d hops (%d lossy) : %s\n", tr.ip, len(tr.hops), lossy, strings.Join(path, " > "))
+	}
+
+	return b.String()
+}
+
+// loss returns the percentage of lost probes over all paths.
+func (sh *sharedHop) loss() float64 {
+	if sh.sent == 0 {
+		return 0
+	}
+	return float64(sh.lost) * 100 / float64(sh.sent)
+}
@@ -0,0 +1,100 @@
+package ui
+
+import (
+	"encoding/json"
+	"os"
+	"syscall"
+	"time"
+)
+
+// ndjsonRecord is the JSON line written for each probe result.
+type ndjsonRecord struct {
+	Time    time.Time `json:"time"`
+	Target  string    `json:"target"`
+	Success bool      `json:"success"`
+	RTT     int       `json:"rtt_ms"`
+}
+
+// ndjsonSink appends every probe result as a JSON line to a file or
+// a named pipe or to the standard output when the path is "-". Non-blocking mode is used so that a pipe without
+// reader never blocks the program, the results are dropped instead.
+type ndjsonSink struct {
+	path    string
+	file    *os.File
+	records chan ndjsonRecord
+	failing bool
+}
+
+// newNDJSONSink creates a JSON lines sink and starts its writer.
+func newNDJSONSink(path string) *ndjsonSink {
+	sk := &ndjsonSink{path: path, records: make(chan ndjsonRecord, 1000)}
+	wg.Add(1)
+	go sk.run()
+	return sk
+}
+
+// sample queues a probe result.
+func (sk *ndjsonSink) sample(sp sample) {
+	select {
+	case sk.records <- ndjsonRecord{Time: sp.time, Target: sp.ip, Success: sp.success, RTT: sp.rtt}:
+	default:
+		count(&counters.droppedSamples)
+	}
+}
+
+// summary is not streamed, consumers compute it from the samples.
+func (sk *ndjsonSink) summary(ip string, s stat, t time.Time) {}
+
+// run writes each queued record and closes the file on exit.
+func (sk *ndjsonSink) run() {
+	defer wg.Done()
+	defer recoverPanic("ndjsonSink.run")
+	for {
+		select {
+		case r := <-sk.records:
+			err := sk.write(r)
+			if err != nil && !sk.failing {
+				sinksLog.Error("Failed to stream results", "sink", "ndjson", "path", sk.path, "err", err)
+			}
+			sk.failing = err != nil
+		case <-sinksDrained:
+			for len(sk.records) > 0 {
+				sk.write(<-sk.records)
+			}
+			if sk.file != nil && sk.file != os.Stdout {
+				sk.file.Close()
+			}
+			return
+		}
+	}
+}
+
+// write encodes a record and (re)opens the destination when needed.
+func (sk *ndjsonSink) write(r ndjsonRecord) error {
+	var err error
+	if sk.file == nil && sk.path == "-" {
+		sk.file = os.Stdout
+	}
+
+	if sk.file == nil {
+		sk.file, err = os.OpenFile(sk.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND|syscall.O_NONBLOCK, 0644)
+		if err != nil {
+			sk.file = nil
+			return err
+		}
+	}
+
+	data, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+
+	if _, err = sk.file.Write(append(data, '\n')); err != nil {
+		// reader of the pipe may be gone so reopen next time.
+		if sk.file != os.Stdout {
+			sk.file.Close()
+		}
+		sk.file = nil
+	}
+	return err
+}
@@ -0,0 +1,148 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/jroimartin/gocui"
+)
+
+// targets to resolve into the neighbors table.
+var neighborChan = make(chan string, 1)
+
+// link layer addresses as printed by the systems tools :
+// aa:bb:cc:dd:ee:ff, aa-bb-cc-dd-ee-ff or 0:1b:2c:d:ee:f.
+var macPattern = regexp.MustCompile(`\b[0-9a-fA-F]{1,2}([:-])[0-9a-fA-F]{1,2}(?:[:-][0-9a-fA-F]{1,2}){4}\b`)
+
+// lookupNeighbor requests the link layer address of the focused ip.
+func lookupNeighbor(g *gocui.Gui, ipv *gocui.View) error {
+	_, cy := ipv.Cursor()
+	l, err := ipv.Line(cy)
+	if err != nil || len(strings.Fields(l)) < 2 {
+		return nil
+	}
+	ip := strings.Fields(l)[1]
+
+	bus.publish(EVTITLE, fmt.Sprintf(" Neighbor [%s] Outputs ", ip))
+	neighborChan <- ip
+	// reset since no ping.
+	currentOnPingIP = ""
+	currentOutputsIP = ip
+	return nil
+}
+
+// executeNeighborLookup resolves an on-link target into the system
+// neighbors (ARP or NDP) table and displays its MAC address so the
+// layer 2 reachability can be confirmed even when ICMP is filtered.
+func executeNeighborLookup(ip string, ctx context.Context) {
+	defer recoverPanic("executeNeighborLookup")
+	iface, network := onLinkInterface(net.ParseIP(ip))
+	if iface == "" {
+		bus.publish(EVOUTPUT, fmt.Sprintf("%s is not on a local subnet : only the hosts of the local links have a neighbor entry.", ip))
+		return
+	}
+	bus.publish(EVOUTPUT, fmt.Sprintf("Resolving %s on %s (%s) ...", ip, iface, network))
+
+	// any packet makes the system resolve the address.
+	target := ip
+	if strings.HasPrefix(strings.ToLower(ip), "fe80:") {
+		target = ip + "%" + iface
+	}
+	if conn, err := net.DialTimeout("udp", net.JoinHostPort(target, "9"), time.Second); err == nil {
+		conn.Write([]byte{0})
+		conn.Close()
+	}
+
+	var mac, state string
+	for i := 0; i < 6; i++ {
+		select {
+		case <-time.After(500 * time.Millisecond):
+		case <-ctx.Done():
+			return
+		}
+		var err error
+		mac, state, err = readNeighbor(ip, ctx)
+		if err != nil {
+			bus.publish(EVOUTPUT, "Failed to read the neighbors table : "+err.Error())
+			return
+		}
+		if mac != "" || state == "FAILED" {
+			break
+		}
+	}
+
+	if mac == "" {
+		bus.publish(EVOUTPUT, fmt.Sprintf("No MAC address resolved for %s : the host does not answer ARP/NDP on %s (down or not on this link).", ip, iface))
+		return
+	}
+	hw, err := net.ParseMAC(mac)
+	if err != nil {
+		bus.publish(EVOUTPUT, "Invalid MAC address into the neighbors table : "+mac)
+		return
+	}
+
+	bus.publish(EVOUTPUT, "mac      : "+formatMAC(hw))
+	bus.publish(EVOUTPUT, "oui      : "+formatOUI(hw))
+	if state != "" {
+		bus.publish(EVOUTPUT, "state    : "+state)
+	}
+	bus.publish(EVOUTPUT, "interface: "+iface)
+	bus.publish(EVOUTPUT, "The host is reachable at layer 2.")
+}
+
+// onLinkInterface returns the name and the subnet of the local
+// interface whose network contains an ip.
+func onLinkInterface(ip net.IP) (string, string) {
+	if ip == nil || ip.IsLoopback() {
+		return "", ""
+	}
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		return "", ""
+	}
+	for _, iface := range ifaces {
+		if iface.Flags&net.FlagUp == 0 || iface.Flags&net.FlagLoopback != 0 {
+			continue
+		}
+		addrs, err := iface.Addrs()
+		if err != nil {
+			continue
+		}
+		for _, addr := range addrs {
+			if n, ok := addr.(*net.IPNet); ok && n.Contains(ip) {
+				return iface.Name, n.String()
+			}
+		}
+	}
+	return "", ""
+}
+
+// findMAC returns the first MAC address of a neighbors table line
+// with its bytes padded to two digits.
+func findMAC(line string) string {
+	m := macPattern.FindStringSubmatch(line)
+	if m == nil {
+		return ""
+	}
+	parts := strings.Split(m[0], m[1])
+	for i, p := range parts {
+		if len(p) == 1 {
+			parts[i] = "0" + p
+		}
+	}
+	return strings.Join(parts, ":")
+}
+
+// formatOUI returns the organizationally unique identifier of a MAC
+// address and whether it is assigned by the vendor.
+func formatOUI(mac net.HardwareAddr) string {
+	oui := strings.ToUpper(mac[:3].String())
+	if mac[0]&0x02 != 0 {
+		return oui + " (locally administered : random or virtual)"
+	}
+	return oui + " (universally administered)"
+}
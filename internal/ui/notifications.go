@@ -0,0 +1,278 @@
+package ui
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/jroimartin/gocui"
+)
+
+const (
+	NOTIFICATIONS = "notifications"
+
+	NWIDTH  = 100
+	NHEIGHT = 20
+)
+
+// notification is a fired alert kept into the notification center.
+type notification struct {
+	alert alert
+	acked bool
+}
+
+// notificationCenter stores all fired alerts so they are
+// not lost once they scrolled out of the outputs view.
+type notificationCenter struct {
+	items []*notification
+	lock  *sync.RWMutex
+}
+
+var (
+	// global notification center.
+	center = &notificationCenter{lock: &sync.RWMutex{}}
+
+	// request a refresh of the status bar.
+	statusChan = make(chan struct{}, 1)
+)
+
+// add records a new alert as unread.
+func (nc *notificationCenter) add(a alert) {
+	nc.lock.Lock()
+	nc.items = append(nc.items, &notification{alert: a})
+	nc.lock.Unlock()
+	refreshStatus()
+}
+
+// list returns a copy of all notifications.
+func (nc *notificationCenter) list() []notification {
+	nc.lock.RLock()
+	items := make([]notification, 0, len(nc.items))
+	for _, n := range nc.items {
+		items = append(items, *n)
+	}
+	nc.lock.RUnlock()
+	return items
+}
+
+// unread returns the number of not acknowledged alerts.
+func (nc *notificationCenter) unread() int {
+	count := 0
+	nc.lock.RLock()
+	for _, n := range nc.items {
+		if !n.acked {
+			count++
+		}
+	}
+	nc.lock.RUnlock()
+	return count
+}
+
+// acknowledge marks the alert at the given position as read.
+func (nc *notificationCenter) acknowledge(pos int) {
+	nc.lock.Lock()
+	if pos >= 0 && pos < len(nc.items) {
+		nc.items[pos].acked = true
+	}
+	nc.lock.Unlock()
+	refreshStatus()
+}
+
+// acknowledgeAll marks all alerts as read.
+func (nc *notificationCenter) acknowledgeAll() {
+	nc.lock.Lock()
+	for _, n := range nc.items {
+		n.acked = true
+	}
+	nc.lock.Unlock()
+	refreshStatus()
+}
+
+// clear removes the alert at the given position.
+func (nc *notificationCenter) clear(pos int) {
+	nc.lock.Lock()
+	if pos >= 0 && pos < len(nc.items) {
+		nc.items = append(nc.items[:pos], nc.items[pos+1:]...)
+	}
+	nc.lock.Unlock()
+	refreshStatus()
+}
+
+// clearAll removes all alerts.
+func (nc *notificationCenter) clearAll() {
+	nc.lock.Lock()
+	nc.items = nil
+	nc.lock.Unlock()
+	refreshStatus()
+}
+
+// format returns one line per alert, unread ones are marked with a star.
+func (nc *notificationCenter) format() string {
+	var out string
+	nc.lock.RLock()
+	for _, n := range nc.items {
+		mark := "*"
+		if n.acked {
+			mark = " "
+		}
+		out = out + fmt.Sprintf("[%s] %s\n", mark, n.alert)
+	}
+	nc.lock.RUnlock()
+	return out
+}
+
+// refreshStatus requests a status bar update without blocking.
+func refreshStatus() {
+	select {
+	case statusChan <- struct{}{}:
+	default:
+	}
+}
+
+// formatStatus builds the content of the status bar. While a ping
+// runs, it shows its clock prefixed by the unread alerts count.
+// Otherwise the unread alerts count or the ping engine is shown.
+func formatStatus() string {
+	count := center.unread()
+	if running := clock.format(); running != "" {
+		if count > 0 {
+			return fmt.Sprintf(" %d! %s ", count, running)
+		}
+		return " " + running + " "
+	}
+	if count > 0 {
+		return fmt.Sprintf(" F1 Help | %d Alerts ", count)
+	}
+	return " F1 Help | " + engineStatus() + " "
+}
+
+// updateInfosView displays the status bar content on each refresh request.
+func updateInfosView(g *gocui.Gui, infosView *gocui.View) {
+	// the ping clock ticks each second.
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			// the redraw also hides an expired error banner.
+			if clock.format() != "" || banner.expire() {
+				refreshStatus()
+			}
+		case <-statusChan:
+			g.Update(func(g *gocui.Gui) error {
+				infosView.Clear()
+				fmt.Fprint(infosView, formatStatus())
+				return nil
+			})
+		case <-exit:
+			return
+		}
+	}
+}
+
+// displayNotificationsView displays the notification center at the center of the screen.
+func displayNotificationsView(g *gocui.Gui, cv *gocui.View) error {
+	maxX, maxY := g.Size()
+
+	if nv, err := g.SetView(NOTIFICATIONS, (maxX-NWIDTH)/2, (maxY-NHEIGHT)/2, (maxX+NWIDTH)/2, (maxY+NHEIGHT)/2); err != nil {
+		if err != gocui.ErrUnknownView {
+			log.Println("Failed to create notifications view:", err)
+			return err
+		}
+
+		nv.Title = " Alerts | Enter: ack - Ctrl+A: ack all - Delete: clear - Ctrl+R: clear all "
+		nv.FgColor = gocui.ColorYellow
+		nv.SelBgColor = gocui.ColorGreen
+		nv.SelFgColor = gocui.ColorBlack
+		nv.Highlight = true
+		nv.Editable = false
+		nv.Wrap = false
+
+		if _, err := g.SetCurrentView(NOTIFICATIONS); err != nil {
+			log.Println("Failed to set focus on notifications view:", err)
+			return err
+		}
+
+		bindings := []struct {
+			key     interface{}
+			handler func(*gocui.Gui, *gocui.View) error
+		}{
+			{gocui.KeyEnter, acknowledgeNotification},
+			{gocui.KeyCtrlA, acknowledgeAllNotifications},
+			{gocui.KeyDelete, clearNotification},
+			{gocui.KeyCtrlR, clearAllNotifications},
+			{gocui.KeyArrowUp, outMoveCursorUp},
+			{gocui.KeyArrowDown, outMoveCursorDown},
+			{gocui.KeyCtrlQ, closeNotificationsView},
+			{gocui.KeyEsc, closeNotificationsView},
+		}
+
+		for _, b := range bindings {
+			if err := g.SetKeybinding(NOTIFICATIONS, b.key, gocui.ModNone, b.handler); err != nil {
+				log.Println("Failed to bind keys to notifications view:", err)
+				return err
+			}
+		}
+
+		fmt.Fprint(nv, center.format())
+	}
+	return nil
+}
+
+// notificationPosition returns the index of the focused alert.
+func notificationPosition(nv *gocui.View) int {
+	_, oy := nv.Origin()
+	_, cy := nv.Cursor()
+	return oy + cy
+}
+
+// redrawNotificationsView reloads the notifications view content.
+func redrawNotificationsView(nv *gocui.View) {
+	nv.Clear()
+	fmt.Fprint(nv, center.format())
+	if notificationPosition(nv) >= len(nv.BufferLines())-1 {
+		nv.SetCursor(0, 0)
+		nv.SetOrigin(0, 0)
+	}
+}
+
+// acknowledgeNotification marks the focused alert as read.
+func acknowledgeNotification(g *gocui.Gui, nv *gocui.View) error {
+	center.acknowledge(notificationPosition(nv))
+	redrawNotificationsView(nv)
+	return nil
+}
+
+// acknowledgeAllNotifications marks all alerts as read.
+func acknowledgeAllNotifications(g *gocui.Gui, nv *gocui.View) error {
+	center.acknowledgeAll()
+	redrawNotificationsView(nv)
+	return nil
+}
+
+// clearNotification removes the focused alert.
+func clearNotification(g *gocui.Gui, nv *gocui.View) error {
+	center.clear(notificationPosition(nv))
+	redrawNotificationsView(nv)
+	return nil
+}
+
+// clearAllNotifications removes all alerts.
+func clearAllNotifications(g *gocui.Gui, nv *gocui.View) error {
+	center.clearAll()
+	redrawNotificationsView(nv)
+	return nil
+}
+
+// closeNotificationsView closes the notification center.
+func closeNotificationsView(g *gocui.Gui, nv *gocui.View) error {
+	nv.Clear()
+	g.DeleteKeybindings(nv.Name())
+	if err := g.DeleteView(nv.Name()); err != nil {
+		log.Println("Failed to delete notifications view:", err)
+		return err
+	}
+
+	return setCurrentDefaultView(g)
+}
@@ -0,0 +1,156 @@
+package ui
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// days after which the downloaded registry is refreshed.
+const OUIMAXAGE = 90
+
+// ouiRegistry maps the MAC addresses prefixes (24, 28 and 36 bits
+// assignments) to their organization names. It is loaded on first
+// use from a local file or from a copy of the IEEE registry cached
+// into the user cache folder.
+type ouiRegistry struct {
+	once    sync.Once
+	vendors map[string]string
+}
+
+// MAC addresses vendors registry.
+var ouis = &ouiRegistry{}
+
+// vendor returns the organization which assigned a MAC address.
+func (r *ouiRegistry) vendor(mac net.HardwareAddr) string {
+	if len(mac) < 6 || mac[0]&0x02 != 0 {
+		// locally administered addresses have no vendor.
+		return ""
+	}
+	r.once.Do(r.load)
+	prefix := strings.ToUpper(hex.EncodeToString(mac))
+	// the longest assignments first.
+	for _, n := range []int{9, 7, 6} {
+		if v, ok := r.vendors[prefix[:n]]; ok {
+			return v
+		}
+	}
+	return ""
+}
+
+// load reads the configured registry. Its lookups are disabled
+// if it cannot be loaded.
+func (r *ouiRegistry) load() {
+	cfgs := getSettings()
+	source := cfgs.Enrich.OUI
+	if source == "" {
+		return
+	}
+	path := source
+	if strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://") {
+		path = filepath.Join(filepath.Dir(defaultLogFile()), "oui.csv")
+		if info, err := os.Stat(path); err != nil || time.Since(info.ModTime()) > OUIMAXAGE*24*time.Hour {
+			if err := downloadOUI(source, path); err != nil {
+				// a stale copy is better than none.
+				probeLog.Error("Failed to download the oui registry", "url", source, "err", err)
+			}
+		}
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		probeLog.Error("Failed to open the oui registry", "file", path, "err", err)
+		return
+	}
+	defer f.Close()
+	if r.vendors, err = parseOUI(f); err != nil {
+		probeLog.Error("Failed to parse the oui registry", "file", path, "err", err)
+	}
+}
+
+// downloadOUI saves the registry into a file. It is written into
+// a temporary file first so a failed download keeps the old copy.
+func downloadOUI(url, path string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("User-Agent", "pingo")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("server replied %s", resp.Status)
+	}
+
+	if err = os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(path), "oui-*.csv")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err = io.Copy(tmp, resp.Body); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// parseOUI reads the IEEE registry CSV format :
+// Registry,Assignment,Organization Name,Organization Address
+func parseOUI(r io.Reader) (map[string]string, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	vendors := make(map[string]string)
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(record) < 3 {
+			continue
+		}
+		prefix := strings.ToUpper(strings.TrimSpace(record[1]))
+		if n := len(prefix); n != 6 && n != 7 && n != 9 {
+			// the header line.
+			continue
+		}
+		vendors[prefix] = strings.TrimSpace(record[2])
+	}
+	if len(vendors) == 0 {
+		return nil, fmt.Errorf("no assignments found")
+	}
+	return vendors, nil
+}
+
+// formatMAC returns a MAC address followed by its vendor if known.
+func formatMAC(mac net.HardwareAddr) string {
+	if v := ouis.vendor(mac); v != "" {
+		return mac.String() + " (" + v + ")"
+	}
+	return mac.String()
+}
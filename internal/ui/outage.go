@@ -0,0 +1,103 @@
+package ui
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"time"
+)
+
+// outage is an ongoing failure of several targets of a same subnet,
+// alerted once instead of once per target.
+type outage struct {
+	subnet  string
+	start   time.Time
+	members map[string]bool
+}
+
+// subnetOf returns the subnet of an ip with the prefix lengths of the
+// settings, like 10.1.2.0/24.
+func subnetOf(ip string) string {
+	cfgs := getSettings()
+	addr := net.ParseIP(ip)
+	if addr == nil {
+		return ""
+	}
+	mask := net.CIDRMask(cfgs.Subnet.Prefix6, 128)
+	if v4 := addr.To4(); v4 != nil {
+		addr, mask = v4, net.CIDRMask(cfgs.Subnet.Prefix, 32)
+	}
+	return (&net.IPNet{IP: addr.Mask(mask), Mask: mask}).String()
+}
+
+// outageOf returns the ongoing outage an ip is part of, if any.
+func (am *alertsManager) outageOf(ip string) *outage {
+	if o, ok := am.outages[subnetOf(ip)]; ok && o.members[ip] {
+		return o
+	}
+	return nil
+}
+
+// correlateDown tells whether the down alert of an ip must not be
+// delivered alone. It is held during the correlation window, then
+// joins or starts the outage of its subnet when enough of its targets
+// are down.
+func (am *alertsManager) correlateDown(ip string, a alert, now time.Time) bool {
+	cfgs := getSettings()
+	settings := cfgs.Alerts.Outage
+	if settings.Min <= 1 {
+		return false
+	}
+	subnet := subnetOf(ip)
+	if o, ok := am.outages[subnet]; ok {
+		o.members[ip] = true
+		am.notified[ip] = STATEDOWN
+		alertsLog.Info("Target joined the outage of its subnet", "target", ip, "subnet", subnet)
+		return true
+	}
+	if now.Sub(a.time) < time.Duration(settings.Window)*time.Second {
+		alertsLog.Info("Alert held to correlate with its subnet", "target", ip, "subnet", subnet)
+		return true
+	}
+
+	var down []string
+	for other, c := range am.current {
+		if c.state == STATEDOWN && am.notified[other] != STATEDOWN && subnetOf(other) == subnet {
+			down = append(down, other)
+		}
+	}
+	if len(down) < settings.Min {
+		return false
+	}
+
+	sort.Strings(down)
+	o := &outage{subnet: subnet, start: now, members: make(map[string]bool)}
+	for _, member := range down {
+		o.members[member] = true
+		am.notified[member] = STATEDOWN
+		am.lastDown[member] = now
+	}
+	am.outages[subnet] = o
+	am.deliver(alert{ip: subnet, state: STATEOUTAGE, time: now,
+		details: fmt.Sprintf("%d targets down : %s", len(down), strings.Join(down, ", "))}, cfgs.Alerts.Notify)
+	return true
+}
+
+// correlateUp tells whether the recovery of an ip is part of an outage.
+// It is not delivered alone and the outage ends with its last target.
+func (am *alertsManager) correlateUp(ip string, now time.Time) bool {
+	cfgs := getSettings()
+	o := am.outageOf(ip)
+	if o == nil {
+		return false
+	}
+	delete(o.members, ip)
+	am.notified[ip] = STATEUP
+	if len(o.members) == 0 {
+		delete(am.outages, o.subnet)
+		am.deliver(alert{ip: o.subnet, state: STATEOUTAGE, time: now,
+			details: fmt.Sprintf("over after %s", now.Sub(o.start).Round(time.Second))}, cfgs.Alerts.Notify)
+	}
+	return true
+}
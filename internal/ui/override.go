@@ -0,0 +1,265 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/jroimartin/gocui"
+)
+
+// fields a temporary override can change, with their label into the
+// configs edit box.
+var overrideFields = map[string]string{
+	"requests":  "requests",
+	"interval":  "interval",
+	"timeout":   "timeout",
+	"size":      "pkts size",
+	"pattern":   "pattern",
+	"threshold": "threshold",
+	"maxloss":   "max loss",
+	"maxhops":   "max hops",
+	"queries":   "queries",
+	"protocol":  "protocol",
+	"backup":    "backup",
+	"numeric":   "numeric",
+}
+
+// override is a temporary change of the configs of an ip, reverted
+// once its duration elapsed.
+type override struct {
+	// the override as entered, like "interval=0.2s for 30m".
+	text     string
+	original config
+	until    time.Time
+	timer    *time.Timer
+}
+
+// overridesStore keeps the ongoing overrides of each ip.
+type overridesStore struct {
+	lock  *sync.Mutex
+	items map[string]*override
+	// message shown once the restarted ping of an ip cleared the
+	// outputs view.
+	notices map[string]string
+}
+
+// global temporary overrides.
+var overrides = &overridesStore{lock: &sync.Mutex{}, items: make(map[string]*override), notices: make(map[string]string)}
+
+// parseOverride reads an override like "interval=0.2s size=1000 for 30m"
+// into the edit box lines it changes and its duration. A duration
+// without unit is in minutes.
+func parseOverride(text string) (map[string]string, time.Duration, error) {
+	text = strings.ToLower(strings.TrimSpace(text))
+	i := strings.LastIndex(text, " for ")
+	if i < 0 {
+		return nil, 0, errors.New("missing duration (like interval=0.2s for 30m)")
+	}
+
+	value := strings.TrimSpace(text[i+len(" for "):])
+	d, err := time.ParseDuration(value)
+	if m, merr := strconv.Atoi(value); merr == nil {
+		d, err = time.Duration(m)*time.Minute, nil
+	}
+	if err != nil || d <= 0 {
+		return nil, 0, fmt.Errorf("invalid duration %s (like 30m or 2h)", value)
+	}
+
+	changes := make(map[string]string)
+	for _, term := range strings.FieldsFunc(text[:i], func(r rune) bool { return unicode.IsSpace(r) || r == ',' }) {
+		fv := strings.SplitN(term, "=", 2)
+		if len(fv) != 2 {
+			return nil, 0, fmt.Errorf("invalid term %s (expect field=value)", term)
+		}
+		label, ok := overrideFields[fv[0]]
+		if !ok {
+			return nil, 0, fmt.Errorf("unknown field %s (use requests, interval, timeout, size, pattern, threshold, maxloss, maxhops, queries, protocol, backup or numeric)", fv[0])
+		}
+		valid := *parseIPConfig(label + ": " + fv[1]) != (config{})
+		if label == "backup" || label == "numeric" {
+			valid = fv[1] == "true" || fv[1] == "false"
+		}
+		if !valid {
+			return nil, 0, fmt.Errorf("invalid value %s for %s", fv[1], fv[0])
+		}
+		changes[label] = fv[1]
+	}
+	if len(changes) == 0 {
+		return nil, 0, errors.New("nothing to override (like interval=0.2s for 30m)")
+	}
+	return changes, d, nil
+}
+
+// apply changes the configs of an ip for a while. The configs before
+// the first of successive overrides are the ones restored. Overrides
+// are not persisted.
+func (ovs *overridesStore) apply(ip, text string) error {
+	changes, d, err := parseOverride(text)
+	if err != nil {
+		return err
+	}
+	current := dbs.getConfig(ip)
+	if current == nil {
+		return fmt.Errorf("unknown target %s", ip)
+	}
+
+	ovs.lock.Lock()
+	o, ok := ovs.items[ip]
+	if ok {
+		o.timer.Stop()
+	} else {
+		o = &override{original: *current}
+		ovs.items[ip] = o
+	}
+	o.text, o.until = strings.TrimSpace(text), time.Now().Add(d)
+	o.timer = time.AfterFunc(d, func() { ovs.revert(ip) })
+	ovs.lock.Unlock()
+
+	lines := strings.Split(dbs.formatEditIPConfig(ip), "\n")
+	for i, line := range lines {
+		label := strings.TrimSpace(strings.SplitN(line, ":", 2)[0])
+		if value, ok := changes[label]; ok {
+			lines[i] = label + ": " + value
+		}
+	}
+	cfg := parseIPConfig(strings.Join(lines, "\n"))
+	cfg.start = current.start
+	dbs.updateConfig(ip, cfg)
+
+	ovs.restartPing(ip, fmt.Sprintf("[override] %s : %s until %s then back to its configs.", ip, o.text, o.until.Format("15:04:05")))
+	return nil
+}
+
+// revert restores the configs of an ip before its override.
+func (ovs *overridesStore) revert(ip string) {
+	ovs.lock.Lock()
+	o, ok := ovs.items[ip]
+	delete(ovs.items, ip)
+	ovs.lock.Unlock()
+	if !ok {
+		return
+	}
+	o.timer.Stop()
+
+	current := dbs.getConfig(ip)
+	if current == nil {
+		return
+	}
+	cfg := o.original
+	cfg.start = current.start
+	dbs.updateConfig(ip, &cfg)
+
+	ovs.restartPing(ip, fmt.Sprintf("[override] %s : configs restored after %s.", ip, o.text))
+}
+
+// cancel drops the override of an ip without restoring its configs,
+// when they are edited or the ip deleted.
+func (ovs *overridesStore) cancel(ip string) {
+	ovs.lock.Lock()
+	if o, ok := ovs.items[ip]; ok {
+		o.timer.Stop()
+		delete(ovs.items, ip)
+	}
+	ovs.lock.Unlock()
+}
+
+// current returns the ongoing override of an ip if any.
+func (ovs *overridesStore) current(ip string) (string, time.Time, bool) {
+	ovs.lock.Lock()
+	defer ovs.lock.Unlock()
+	if o, ok := ovs.items[ip]; ok {
+		return o.text, o.until, true
+	}
+	return "", time.Time{}, false
+}
+
+// restartPing starts again the ping of an ip being pinged so it runs
+// with its new configs. The message is shown into the outputs view
+// once cleared by the new ping.
+func (ovs *overridesStore) restartPing(ip, message string) {
+	if currentOnPingIP != ip {
+		bus.publish(EVOUTPUT, message)
+		return
+	}
+	ovs.lock.Lock()
+	ovs.notices[ip] = message
+	ovs.lock.Unlock()
+	bus.publish(EVTITLE, fmt.Sprintf(" %sPing [%s] Outputs ", backupIndicator(ip), ip))
+	ipToPingChan <- ip
+	bus.publish(EVFOCUS, ip)
+}
+
+// notice returns once the message to show when the ping of an ip starts.
+func (ovs *overridesStore) notice(ip string) string {
+	ovs.lock.Lock()
+	defer ovs.lock.Unlock()
+	message := ovs.notices[ip]
+	delete(ovs.notices, ip)
+	return message
+}
+
+// overrideInputView displays a temporary input box to override the
+// configs of the focused ip for a while. An empty override restores
+// them at once.
+func overrideInputView(g *gocui.Gui, ipv *gocui.View) error {
+	_, cy := ipv.Cursor()
+	l, err := ipv.Line(cy)
+	if err != nil || len(strings.Fields(l)) < 2 {
+		return nil
+	}
+	ip := strings.Fields(l)[1]
+
+	maxX, maxY := g.Size()
+
+	const name = "override"
+
+	// construct the input box and position at the center of the screen.
+	if inputView, err := g.SetView(name, maxX/2-35, maxY/2, maxX/2+35, maxY/2+2); err != nil {
+		if err != gocui.ErrUnknownView {
+			log.Println("Failed to display input view: ", err)
+			return err
+		}
+
+		inputView.Title = fmt.Sprintf(" [%s] | Override (e.g. interval=0.2s for 30m) ", ip)
+		text, until, active := overrides.current(ip)
+		if active {
+			inputView.Title = fmt.Sprintf(" [%s] | Override Until %s - Empty To Restore ", ip, until.Format("15:04:05"))
+		}
+		inputView.FgColor = gocui.ColorYellow
+		inputView.SelBgColor = gocui.ColorBlack
+		inputView.SelFgColor = gocui.ColorYellow
+		inputView.Editable = true
+
+		if _, err := g.SetCurrentView(name); err != nil {
+			log.Println(err)
+			return err
+		}
+		g.Cursor = true
+		inputView.Highlight = true
+		// bind Enter key to processInput function.
+		if err := g.SetKeybinding(name, gocui.KeyEnter, gocui.ModNone, processInput); err != nil {
+			log.Println(err)
+			return err
+		}
+
+		// bind Ctrl+Q and Escape keys to close the input box.
+		if err := g.SetKeybinding(name, gocui.KeyCtrlQ, gocui.ModNone, closeInputView); err != nil {
+			log.Println(err)
+			return err
+		}
+
+		if err := g.SetKeybinding(name, gocui.KeyEsc, gocui.ModNone, closeInputView); err != nil {
+			log.Println(err)
+			return err
+		}
+
+		setInputText(inputView, text)
+	}
+	return nil
+}
@@ -0,0 +1,3284 @@
+// Package ui is the pingo program : its terminal ui, the daemon and
+// the headless modes with their alerts, sinks and services. It runs
+// the probes of the probe package and persists its state through
+// the store package.
+package ui
+
+// Pingo is a small & light go-based tool for IP reachability administration tasks with rich user interface.
+
+// Version  : 1.0.0
+// Author   : Jerome AMON
+// Created  : 19 November 2021
+
+import (
+	"bufio"
+	"context"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net"
+	"os"
+	"os/exec"
+	"os/signal"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jroimartin/gocui"
+
+	"github.com/jeamon/pingo/internal/probe"
+	"github.com/jeamon/pingo/internal/stats"
+)
+
+const (
+	IPLIST  = "ips"
+	STATS   = "stats"
+	INFOS   = "infos"
+	CONFIG  = "config"
+	OUTPUTS = "outputs"
+	HELP    = "help"
+
+	IPSPOSITION     = "ipsPosition"
+	OUTPUTSPOSITION = "outputsPosition"
+	CONFIGPOSITION  = "configPosition"
+	STATSPOSITION   = "statsPosition"
+
+	// message displayed under an input box.
+	INPUTMESSAGE = "inputMessage"
+
+	IPSWIDTH = 22
+	HWIDTH   = 46
+	HHEIGHT  = 49
+)
+
+const helpDetails = `
+-------------+------------------------------
+    CTRL + A | add ips [@ config template]
+-------------+------------------------------
+    CTRL + D | delete focused ip address
+-------------+------------------------------
+    CTRL + E | edit focused ip's configs
+-------------+------------------------------
+    CTRL + F | fuzzy search a target
+-------------+------------------------------
+    CTRL + L | load & add ip from files
+-------------+------------------------------
+    CTRL + N | view & manage fired alerts
+-------------+------------------------------
+    CTRL + Q | close help or stop action 
+-------------+------------------------------
+    CTRL + P | start pinging focused ip
+-------------+------------------------------
+    CTRL + R | clear outputs view content
+-------------+------------------------------
+    CTRL + W | wrap or scroll long outputs
+-------------+------------------------------
+    CTRL + S | save outputs content to file
+-------------+------------------------------
+    CTRL + T | traceroute the focused ip
+-------------+------------------------------
+    CTRL + X | export session (csv & json)
+-------------+------------------------------
+    F1 & Esc | display or close help view
+-------------+------------------------------
+    F12      | debug view (routines status)
+-------------+------------------------------
+    <Enter>  | start pinging focused ip
+-------------+------------------------------
+    <Enter>  | on a hop : add & ping its ip
+-------------+------------------------------
+    P or T   | Ping or Trace focused ip
+-------------+------------------------------
+    M        | continuous trace (mtr mode)
+-------------+------------------------------
+    G        | parallel trace of many ips
+-------------+------------------------------
+    D        | dns lookup of ip or any name
+-------------+------------------------------
+    W        | owner of focused ip or hop
+-------------+------------------------------
+    B        | bgp routes of focused ip/hop
+-------------+------------------------------
+    R        | run a playbook on focused ip
+-------------+------------------------------
+    S        | tcp ports scan of focused ip
+-------------+------------------------------
+    C        | tls certificate of focused ip
+-------------+------------------------------
+    N        | snmp poll of focused device
+-------------+------------------------------
+    O        | wake-on-lan of focused ip
+-------------+------------------------------
+    A        | arp/ndp entry of focused ip
+-------------+------------------------------
+    I        | iperf3 throughput of focused ip
+-------------+------------------------------
+    i        | all details of the focused ip
+-------------+------------------------------
+    H        | past runs of the focused ip
+-------------+------------------------------
+    J        | annotate the timeline
+-------------+------------------------------
+    K        | acknowledge ip highlight
+-------------+------------------------------
+    E        | override ip configs a while
+-------------+------------------------------
+    F        | filter ips (e.g. loss>1)
+-------------+------------------------------
+    L        | list worst ips first or not
+-------------+------------------------------
+    V        | view subnets & filter on one
+-------------+------------------------------
+    U        | compare focused ip by vantage
+-------------+------------------------------
+    X        | export trace & mtr reports
+-------------+------------------------------
+    1 to 9   | sort hops table by a column
+-------------+------------------------------
+    Tab Key  | move focus between views
+-------------+------------------------------
+    Tab Key  | complete file or ip in boxes
+-------------+------------------------------
+    ↑ and ↓  | recall recent values in boxes
+-------------+------------------------------
+    ↕ and ↔  | navigate into the IP list
+-------------+------------------------------
+    ↑ and ↓  | scroll configs & stats views
+-------------+------------------------------
+    CTRL + C | close the full program
+-------------+------------------------------
+
+::::::: Crafted with ♥ by Jerome Amon ::::::
+`
+
+type config struct {
+	start    string
+	requests int
+	// milliseconds between two requests. 0 keeps the ping default.
+	interval  int
+	threshold int
+	// loss percentage costing the full loss weight of the health
+	// score. 0 means 100%.
+	maxloss int
+	timeout int
+	size    int
+	// hexadecimal bytes filling the ping payload.
+	pattern string
+	backup  bool
+	// traceroute options.
+	maxhops  int
+	queries  int
+	protocol string
+	numeric  bool
+	// probe type : icmp (ping) or dns (query to a dns server).
+	probe string
+	qname string
+	qtype string
+	// wake-on-lan mac address and broadcast address.
+	mac       string
+	broadcast string
+	// port of the iperf3 server of the ip. 0 means none.
+	iperf int
+	// name of the target and its comma separated tags, like "core,lan".
+	label string
+	tags  string
+}
+
+type stat struct {
+	min   int
+	avg   int
+	max   int
+	fails int
+	match int
+	above int
+	under int
+	// latest reply time.
+	last int
+	// replies far from the latency baseline.
+	anomalies int
+	// consecutive failures and current state.
+	streak int
+	state  string
+}
+
+var (
+	// global datastore.
+	dbs *databases
+
+	// request a refresh of the ips list.
+	ipsChangedChan = make(chan struct{}, 1)
+
+	// IP to ping and to trace.
+	ipToPingChan  = make(chan string, 1)
+	ipToTraceChan = make(chan string, 1)
+	ipToMTRChan   = make(chan string, 1)
+	// keep ongoing pinging IP, useful to
+	// avoid its deletion on CTRL+D.
+	currentOnPingIP string
+	// ip of the outputs view content.
+	currentOutputsIP string
+
+	// stop ongoing processing (ping or trace).
+	stopProcessingChan = make(chan struct{})
+
+	// control all goroutines.
+	exit = make(chan struct{})
+	wg   sync.WaitGroup
+
+	// parent context of all probes, cancelled on shutdown.
+	probes, stopProbes = context.WithCancel(context.Background())
+	// the samples dispatcher runs and will release the sinks.
+	workersStarted bool
+
+	LinuxShell = "/bin/sh"
+)
+
+// struct of a datastore.
+type databases struct {
+	ips     map[string]struct{}
+	configs map[string]*config
+	stats   map[string]*stat
+	history map[string][]sample
+	// per-minute aggregates of the samples dropped from the history.
+	minutes map[string][]stats.Aggregate
+	ipslock *sync.RWMutex
+	cfglock *sync.RWMutex
+	slock   *sync.RWMutex
+	hlock   *sync.RWMutex
+}
+
+// newDatabases creates new databases.
+func newDatabases() *databases {
+	return &databases{
+		ips:     map[string]struct{}{},
+		configs: make(map[string]*config),
+		stats:   make(map[string]*stat),
+		history: make(map[string][]sample),
+		minutes: make(map[string][]stats.Aggregate),
+		ipslock: &sync.RWMutex{},
+		cfglock: &sync.RWMutex{},
+		slock:   &sync.RWMutex{},
+		hlock:   &sync.RWMutex{},
+	}
+}
+
+// isExists checks if given ip exists.
+func (db *databases) isExistsIP(ip string) bool {
+
+	// check if ip is present.
+	db.ipslock.RLock()
+	if _, ok := db.ips[ip]; ok {
+		db.ipslock.RUnlock()
+		return true
+	}
+	db.ipslock.RUnlock()
+
+	return false
+}
+
+// addOneMoreIPs take a string of comma-separated IPs and
+// initialize their configs & stats then add them. The configs
+// of the template are applied to them if any. It returns the
+// invalid entries which were ignored.
+func (db *databases) addOneMoreIPs(ips string, t *templateSettings) []string {
+	var invalid []string
+	for _, ip := range strings.Split(ips, ",") {
+		if ip = strings.TrimSpace(ip); ip != "" && !isValidIP(ip) {
+			invalid = append(invalid, ip)
+			continue
+		}
+		db.addNewIP(ip)
+		if t != nil {
+			db.applyTemplate(ip, t)
+		}
+	}
+	return invalid
+}
+
+// addNewIP inserts a new ip with its initial configs & stats.
+func (db *databases) addNewIP(ip string) {
+	ip = strings.TrimSpace(ip)
+	if !isValidIP(ip) || db.isExistsIP(ip) {
+		return
+	}
+
+	db.addIP(ip)
+	db.addConfig(ip)
+	db.initStats(ip)
+	store.saveTarget(ip, db.getConfig(ip))
+}
+
+// addIP inserts a new ip with empty struct as value.
+func (db *databases) addIP(ip string) {
+	db.ipslock.Lock()
+	db.ips[ip] = struct{}{}
+	db.ipslock.Unlock()
+}
+
+// addConfig inserts a new ip with 0 values as initial configs.
+func (db *databases) addConfig(ip string) {
+	db.cfglock.Lock()
+	db.configs[ip] = &config{start: "n/a"}
+	db.cfglock.Unlock()
+}
+
+// dnsQName returns the name queried by the dns probe.
+func (cfg *config) dnsQName() string {
+	if cfg.qname == "" {
+		return "."
+	}
+	return cfg.qname
+}
+
+// dnsQType returns the records type queried by the dns probe.
+func (cfg *config) dnsQType() string {
+	if cfg.qtype == "" {
+		return "NS"
+	}
+	return cfg.qtype
+}
+
+// updateConfig replace the existing configs values of an ip by new ones.
+func (db *databases) updateConfig(ip string, cfg *config) {
+	db.cfglock.Lock()
+	db.configs[ip] = cfg
+	db.cfglock.Unlock()
+}
+
+// initStats initialize an ip with 0 values as initial stats.
+func (db *databases) initStats(ip string) {
+	db.slock.Lock()
+	s := &stat{}
+	// keep the alerting state across runs of a target.
+	if old, ok := db.stats[ip]; ok {
+		s.state, s.streak = old.state, old.streak
+	}
+	db.stats[ip] = s
+	db.slock.Unlock()
+}
+
+// addSample appends a probe result to an ip history and downsamples
+// the oldest samples once the history size limit is reached.
+func (db *databases) addSample(sp sample) {
+	cfgs := getSettings()
+	db.hlock.Lock()
+	h := append(db.history[sp.ip], sp)
+	if len(h) > cfgs.History {
+		if cfgs.HistoryMinutes > 0 {
+			db.downsample(sp.ip, h[:len(h)-cfgs.History])
+		}
+		h = h[len(h)-cfgs.History:]
+	}
+	db.history[sp.ip] = h
+	db.hlock.Unlock()
+}
+
+// getHistory returns a copy of the samples history of an ip.
+func (db *databases) getHistory(ip string) []sample {
+	db.hlock.RLock()
+	h := make([]sample, len(db.history[ip]))
+	copy(h, db.history[ip])
+	db.hlock.RUnlock()
+	return h
+}
+
+// getJob retrieves a given job data based on its id from jobs store.
+func (db *databases) getConfig(ip string) *config {
+	db.cfglock.RLock()
+	defer db.cfglock.RUnlock()
+	cfg, ok := db.configs[ip]
+	if !ok {
+		return nil
+	}
+	// callers get a snapshot, changes go through updateConfig.
+	c := *cfg
+	return &c
+}
+
+// markStarted records the current time as start of an ip probing.
+func (db *databases) markStarted(ip string) {
+	db.cfglock.Lock()
+	if cfg, ok := db.configs[ip]; ok {
+		c := *cfg
+		c.start = getCurrentTime()
+		db.configs[ip] = &c
+	}
+	db.cfglock.Unlock()
+}
+
+// getStats returns a snapshot of an ip statistics or nil if the
+// ip does not exist. It is safe to read while probes update them.
+func (db *databases) getStats(ip string) *stat {
+	db.slock.RLock()
+	defer db.slock.RUnlock()
+	s, ok := db.stats[ip]
+	if !ok {
+		return nil
+	}
+	c := *s
+	return &c
+}
+
+// updateStats applies a change to an ip statistics under lock. It
+// returns false if the ip was deleted meanwhile.
+func (db *databases) updateStats(ip string, change func(s *stat)) bool {
+	db.slock.Lock()
+	defer db.slock.Unlock()
+	s, ok := db.stats[ip]
+	if !ok {
+		return false
+	}
+	change(s)
+	return true
+}
+
+// getAllIPs returns a sorted (by length) list of current IPs.
+func (db *databases) getAllIPs() []string {
+	dbs.ipslock.RLock()
+	ips := make([]string, 0, len(dbs.ips))
+	for ip, _ := range dbs.ips {
+		ips = append(ips, ip)
+	}
+	dbs.ipslock.RUnlock()
+	sort.Strings(ips)
+	sort.SliceStable(ips, func(i, j int) bool {
+		return len(ips[i]) < len(ips[j])
+	})
+
+	return ips
+}
+
+// deleteOneMoreIPs take a string of comma-separated IPs
+// and remove them completely from the database.
+func (db *databases) deleteOneMoreIPs(ips string) {
+	ipList := strings.Split(ips, ",")
+	if len(ipList) == 0 {
+		return
+	}
+
+	for _, ip := range ipList {
+		if ip == currentOnPingIP {
+			continue
+		}
+		db.deleteIP(ip)
+	}
+}
+
+// deleteIP remove completely an ip from datastore.
+func (db *databases) deleteIP(ip string) {
+	ip = strings.TrimSpace(ip)
+	if !isValidIP(ip) || !db.isExistsIP(ip) {
+		return
+	}
+
+	// remove from ips.
+	db.ipslock.Lock()
+	delete(db.ips, ip)
+	db.ipslock.Unlock()
+
+	// remove from configs.
+	db.cfglock.Lock()
+	delete(db.configs, ip)
+	db.cfglock.Unlock()
+
+	// remove from stats.
+	db.slock.Lock()
+	delete(db.stats, ip)
+	db.slock.Unlock()
+
+	// remove from history.
+	db.hlock.Lock()
+	delete(db.history, ip)
+	delete(db.minutes, ip)
+	db.hlock.Unlock()
+
+	baselines.forget(ip)
+	overrides.cancel(ip)
+	store.deleteTarget(ip)
+}
+
+// isValidPattern tells whether a ping payload pattern is made of 1
+// to 16 hexadecimal bytes as accepted by the ping -p option.
+func isValidPattern(pattern string) bool {
+	if len(pattern) == 0 || len(pattern) > 32 || len(pattern)%2 != 0 {
+		return false
+	}
+	_, err := hex.DecodeString(pattern)
+	return err == nil
+}
+
+// parseInterval reads the milliseconds between two requests given as
+// a number of milliseconds or as a duration like 0.5s.
+func parseInterval(value string) (int, bool) {
+	if ms, err := strconv.Atoi(value); err == nil {
+		return ms, ms > 0
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d < time.Millisecond {
+		return 0, false
+	}
+	return int(d / time.Millisecond), true
+}
+
+// isValidIP returns true if ip is valid.
+func isValidIP(ip string) bool {
+	return net.ParseIP(ip) != nil
+}
+
+// formatIPConfig formats a given IP configuration.
+func (db *databases) formatIPConfig(ip string) string {
+	cfg := db.getConfig(ip)
+	if cfg == nil {
+		return ""
+	}
+	probe := "icmp"
+	if cfg.probe == "dns" {
+		probe = fmt.Sprintf("dns %s %s", cfg.dnsQName(), cfg.dnsQType())
+	}
+	pattern := cfg.pattern
+	if pattern == "" {
+		pattern = "none"
+	}
+	maxloss := "none"
+	if cfg.maxloss > 0 {
+		maxloss = fmt.Sprintf("%d%%", cfg.maxloss)
+	}
+	interval := "default"
+	if cfg.interval > 0 {
+		interval = fmt.Sprintf("%d ms", cfg.interval)
+	}
+	return fmt.Sprintf("backup   : %v\ntimeout  : %d\nstarted  : %s\nrequests : %d\ninterval : %s\npkts size: %d\npattern  : %s\nthreshold: %d\nmax loss : %s\nmax hops : %d\nqueries  : %d\nprotocol : %s\nnumeric  : %v\nprobe    : %s\nwol      : %v",
+		cfg.backup, cfg.timeout, cfg.start, cfg.requests, interval, cfg.size, pattern, cfg.threshold, maxloss, cfg.maxhops, cfg.queries, cfg.protocol, cfg.numeric, probe, cfg.mac != "")
+}
+
+// formatIPStats formats a given IP statistics.
+func (db *databases) formatIPStats(ip string) string {
+	s := db.getStats(ip)
+	if s == nil {
+		return ""
+	}
+	return fmt.Sprintf("min  : %d\navg  : %d\nmax  : %d\nfails: %d\nmatch: %d\nabove: %d\nunder: %d\nanoms: %d\nscore: %d\n",
+		s.min, s.avg, s.max, s.fails, s.match, s.above, s.under, s.anomalies, healthScore(ip))
+}
+
+// loadInitialInfos is called at startup and loads any data piped
+// and from all files passed as arguments then fill the databases
+// of IP infos with only valid IP addresses.
+func (db *databases) loadInitialInfos() {
+
+	// retrieve standard input info.
+	fi, err := os.Stdin.Stat()
+	if err == nil && (fi.Mode()&os.ModeCharDevice) == 0 {
+		var entries []string
+		// there is data from pipe input, so grab the
+		// full content and build a list of entries.
+		content, _ := ioutil.ReadAll(os.Stdin)
+		entries = strings.Split(string(content), "\n")
+		db.addEntries(entries)
+	}
+
+	// parse any files content.
+	db.loadInfosFromFiles(flag.Args())
+
+	// targets of the environment, like into a container.
+	db.addEntries(targetsFromEnv())
+}
+
+// loadInfosFromFiles loads data from all files passed as
+// input on <CTRL+L> press and fill the databases of IP infos
+// with only valid IP addresses.
+func (db *databases) loadInfosFromFiles(filenames []string) {
+
+	if len(filenames) == 0 {
+		return
+	}
+
+	// for each valid file path, grab its full
+	// content and build a list of entries.
+	var lines []string
+	var entries []string
+	for _, file := range filenames {
+		content, err := ioutil.ReadFile(file)
+		if err != nil {
+			uiLog.Error("Failed to load ips file", "file", file, "err", err)
+			showError("Failed to load %s : %v", file, err)
+			continue
+		}
+		// hosts exported from a monitoring system.
+		hosts, ok, err := parseMonitoringHosts(file, content)
+		if err != nil {
+			uiLog.Error("Failed to import monitoring hosts", "file", file, "err", err)
+			showError("Failed to import %s : %v", file, err)
+			continue
+		}
+		if ok {
+			db.importHosts(file, hosts)
+			continue
+		}
+		// construct the list based on "\n" as sep.
+		// then add lines content to entries list.
+		lines = strings.Split(string(content), "\n")
+		entries = append(entries, lines...)
+	}
+
+	if len(entries) == 0 {
+		// no data input.
+		return
+	}
+
+	db.addEntries(entries)
+}
+
+// addEntries adds the valid IP addresses of the lines of a list,
+// each followed by the template to apply to it if any.
+func (db *databases) addEntries(entries []string) {
+	for _, e := range entries {
+		ip, t, err := splitTemplate(e)
+		if err != nil {
+			uiLog.Error("Failed to apply template", "entry", strings.TrimSpace(e), "err", err)
+			showError("Failed to add %s : %v", strings.TrimSpace(ip), err)
+			continue
+		}
+		if isValidIP(strings.TrimSpace(ip)) {
+			db.addNewIP(strings.TrimSpace(ip))
+			if t != nil {
+				db.applyTemplate(strings.TrimSpace(ip), t)
+			}
+		}
+	}
+}
+
+// Main parses the command line flags then runs the program : the
+// terminal ui, the daemon or the headless probing.
+func Main() {
+	defer recoverPanic("main")
+
+	configFile := flag.String("config", envOr(ENVCONFIG, "pingo.json"), "path of the JSON settings file")
+	logLevel := flag.String("log-level", envOr(ENVLOGLEVEL, "info"), "logging level (debug, info, warn or error)")
+	logFile := flag.String("log-file", envOr(ENVLOGFILE, ""), "path of the logs file or - for stderr (default into the user cache folder, stderr into a container without ui)")
+	logMaxSize := flag.Int("log-max-size", 10, "maximum size in MB of the logs file before rotation")
+	logMaxBackups := flag.Int("log-max-backups", 5, "number of rotated logs files to keep")
+	noTUI := flag.Bool("no-tui", false, "probe the targets without the terminal ui and print results to stdout")
+	output := flag.String("output", "summary", "results format without ui : summary or ndjson")
+	every := flag.Int("every", 10, "seconds between two summaries without ui")
+	daemon := flag.Bool("daemon", false, "monitor the targets in background with alerts and sinks only")
+	pidfile := flag.String("pidfile", "", "file to write the process id into in daemon mode")
+	socket := flag.String("socket", defaultSocketPath(), "control socket of the daemon where the ui attaches")
+	attach := flag.Bool("attach", false, "attach the ui to the running daemon or shared session of -socket")
+	readOnly := flag.Bool("read-only", false, "lock the controls changing the targets of the attached ui")
+	share := flag.String("share", "", "read-only socket where other uis can attach to watch this session or daemon")
+	pprofAddr := flag.String("pprof", "", "local address serving pprof profiles and runtime statistics (disabled if empty)")
+	httpAddr := flag.String("http", envOr(ENVHTTP, ""), "address of the web server with the health endpoint, overriding the settings (disabled if empty)")
+	flag.Parse()
+
+	// without terminal, like into a container, the targets are probed headless.
+	if !*daemon && !*attach && !*noTUI && !hasTerminal() {
+		*noTUI = true
+	}
+	// logs are collected from stderr into a container.
+	if *logFile == "" && (*daemon || *noTUI) && inContainer() {
+		*logFile = STDERRLOG
+	}
+
+	runtime.GOMAXPROCS(runtime.NumCPU())
+
+	// on windows only change terminal title.
+	if runtime.GOOS == "windows" {
+		exec.Command("cmd", "/c", "title [ PinGo By Jerome Amon ]").Run()
+	}
+
+	lf, err := setupLogger(*logLevel, *logFile, *logMaxSize, *logMaxBackups)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to setup logs:", err)
+		os.Exit(1)
+	}
+	defer lf.Close()
+	// route standard logger entries as errors of the ui subsystem.
+	log.SetFlags(0)
+	log.SetOutput(stdLogWriter{l: uiLog, level: LEVELERROR})
+
+	// for linux-based platform lets find the current shell binary path
+	// if environnement shell is set and not empty we use it as default.
+	if runtime.GOOS != "windows" {
+		if len(os.Getenv("SHELL")) > 0 {
+			LinuxShell = os.Getenv("SHELL")
+		}
+	}
+
+	// load global settings from file if any.
+	cfgs := loadSettings(*configFile)
+	setupTermux(cfgs)
+	if *httpAddr != "" {
+		cfgs.HTTP.Enabled, cfgs.HTTP.Address = true, *httpAddr
+	}
+	if *attach {
+		disableLocalOutputs(cfgs)
+	}
+	setSettings(cfgs)
+	initQueues()
+	selectEngine()
+
+	// init databases and loads any persisted and passed infos.
+	dbs = newDatabases()
+	if cfgs.Store.Enabled {
+		if store, err = openStore(cfgs.Store); err != nil {
+			storeLog.Error("Failed to open persistent store", "err", err)
+			showError("Failed to open the persistent store : %v", err)
+			store = nil
+		} else if err = store.load(dbs); err != nil {
+			storeLog.Error("Failed to load persistent store", "err", err)
+			showError("Failed to load the persistent store : %v", err)
+		}
+	}
+	dbs.loadInitialInfos()
+	if !*daemon && !*noTUI && !*attach {
+		offerRestore()
+	}
+
+	geo = newGeoLocator(cfgs.Enrich)
+	defer geo.close()
+
+	if cfgs.Enrich.Enabled {
+		enrich = newEnricher(cfgs.Enrich)
+	}
+
+	if *attach {
+		if remote, err = attachDaemon(*socket, *readOnly); err != nil {
+			fmt.Fprintln(os.Stderr, "failed to attach to the daemon:", err)
+			os.Exit(1)
+		}
+	}
+
+	if *pprofAddr != "" {
+		supervise(&wg, "debug server", func() { startDebugServer(*pprofAddr) })
+	}
+
+	if *daemon {
+		status := runDaemon(*configFile, *pidfile, *socket, *share)
+		shutdown()
+		os.Exit(status)
+	}
+
+	if *noTUI {
+		status := runHeadless(*output, time.Duration(*every)*time.Second)
+		shutdown()
+		os.Exit(status)
+	}
+
+	g, err := gocui.NewGui(gocui.OutputNormal)
+	if err != nil {
+		log.Println("Failed to initialize the gui:", err)
+		return
+	}
+	defer g.Close()
+	// keybindings handlers run into the main loop.
+	gui = g
+	defer recoverPanic("ui")
+
+	g.Highlight = true
+	g.SelFgColor = gocui.ColorRed
+	g.BgColor = gocui.ColorBlack
+	g.FgColor = gocui.ColorWhite
+	g.InputEsc = true
+	// g.Mouse = true
+	g.Cursor = false
+
+	g.SetManagerFunc(layout)
+
+	err = g.SetKeybinding("", gocui.KeyCtrlC, gocui.ModNone, quit)
+	if err != nil {
+		log.Println("Could not set key [CtrlC] binding to main view:", err)
+		return
+	}
+
+	if err = setupViews(g); err != nil {
+		return
+	}
+	configView, _ := g.View(CONFIG)
+	outputsView, _ := g.View(OUTPUTS)
+	statsView, _ := g.View(STATS)
+	infosView, _ := g.View(INFOS)
+
+	// display current ips.
+	g.Update(updateIPsView)
+
+	supervise(&wg, "scheduler", scheduler)
+
+	configSub := bus.subscribe(cfgs.Queues.UI, EVFOCUS)
+	supervise(&wg, "config view", func() { updateConfigView(g, configView, configSub) })
+
+	outputsSub := bus.subscribe(cfgs.Queues.UI, EVOUTPUT, EVTABLE, EVTITLE, EVCLEAROUTPUTS)
+	supervise(&wg, "outputs view", func() { updateOutputsView(g, outputsView, outputsSub) })
+
+	statsSub := bus.subscribe(cfgs.Queues.UI, EVSTATS, EVCLEARSTATS, EVSTATSTEXT)
+	supervise(&wg, "stats view", func() { updateStatsView(g, statsView, statsSub) })
+
+	supervise(&wg, "infos view", func() { updateInfosView(g, infosView) })
+
+	supervise(&wg, "ips watcher", func() { watchIPsChanges(g) })
+
+	supervise(&wg, "signals watcher", func() { watchSignals(g) })
+
+	startWorkers()
+
+	// let other uis watch this session.
+	if *share != "" && remote == nil {
+		ln, err := startControlServer(*share, true)
+		if err != nil {
+			log.Println("Failed to share the session:", err)
+		} else {
+			defer ln.Close()
+		}
+	}
+
+	if err := g.MainLoop(); err != nil && err != gocui.ErrQuit {
+		close(exit)
+		log.Println("Exited from the main loop:", err)
+	}
+
+	shutdown()
+}
+
+// startWorkers starts the alerting, the results sinks (with any
+// extra ones), the embedded web server and the memory guard
+// background routines.
+func startWorkers(extra ...sink) {
+	cfgs := getSettings()
+	workersStarted = true
+
+	notifiers := buildNotifiers()
+	supervise(&wg, "alerts dispatcher", func() { alertsDispatcher(notifiers) })
+
+	sinks := append(buildSinks(), extra...)
+	supervise(&wg, "samples dispatcher", func() { samplesDispatcher(sinks, cfgs.Interval) })
+
+	if cfgs.HTTP.Enabled {
+		supervise(&wg, "http server", func() { startHTTPServer(cfgs.HTTP) })
+	}
+
+	if cfgs.GRPC.Enabled {
+		supervise(&wg, "grpc server", func() { startGRPCServer(cfgs.GRPC) })
+	}
+
+	if len(cfgs.TLS.Checks) > 0 {
+		supervise(&wg, "certs checker", func() { certsChecker(cfgs.TLS.Checks) })
+	}
+
+	if cfgs.MemoryLimit > 0 {
+		supervise(&wg, "memory guard", memoryGuard)
+	}
+}
+
+// shutdown stops all probes and kills their processes, waits for
+// all routines to stop once exit channel is closed so the sinks
+// flush their pending results then dumps the session state if
+// requested.
+func shutdown() {
+	cfgs := getSettings()
+	stopProbes()
+	procs.killAll()
+	// the store may be opened without any results to dispatch.
+	if !workersStarted {
+		close(sinksDrained)
+	}
+	wg.Wait()
+
+	if cfgs.SessionFile != "" {
+		if err := exportSession(cfgs.SessionFile); err != nil {
+			storeLog.Error("Failed to export session on exit", "err", err)
+		}
+	}
+}
+
+// updateIPsView loads and displays all ips.
+// Formats each IP - 15 witdh and left align.
+func updateIPsView(g *gocui.Gui) error {
+	v, err := g.View(IPLIST)
+	if err != nil {
+		log.Println("Failed to update list of ips:", err)
+		return err
+	}
+
+	v.Clear()
+
+	ips := dbs.getAllIPs()
+	shown := ranking.sort(filter.apply(ips))
+	title := "IP Addresses"
+	if ranking.sorted() {
+		title = "Worst First"
+	}
+	if filter.active() {
+		v.Title = fmt.Sprintf(" %s %d/%d ", title, len(shown), len(ips))
+	} else {
+		v.Title = fmt.Sprintf(" %s ", title)
+	}
+	for i, ip := range shown {
+		if color := highlights.color(ip); color != "" {
+			fmt.Fprintf(v, "[%02d] %s%-15s%s\n", i, color, ip, COLORRESET)
+			continue
+		}
+		fmt.Fprintf(v, "[%02d] %-15s\n", i, ip)
+	}
+	// mirror the list to the uis watching this session.
+	hub.publishTargets(ips)
+
+	return nil
+}
+
+// refreshIPs requests a redisplay of the ips list once
+// targets were added or deleted from outside the ui.
+func refreshIPs() {
+	select {
+	case ipsChangedChan <- struct{}{}:
+	default:
+	}
+}
+
+// watchSignals quits the ui as <CTRL+Q> would do when pingo
+// is asked to stop by a signal (terminal closed, kill).
+func watchSignals(g *gocui.Gui) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, shutdownSignals...)
+	defer signal.Stop(signals)
+	select {
+	case sig := <-signals:
+		uiLog.Info("Stopping on signal", "signal", sig.String())
+		g.Update(func(g *gocui.Gui) error {
+			select {
+			case <-exit:
+				return nil
+			default:
+				return quit(g, nil)
+			}
+		})
+	case <-exit:
+	}
+}
+
+// watchIPsChanges redisplays the ips list on each refresh request.
+func watchIPsChanges(g *gocui.Gui) {
+	// a filtered or ranked list follows the statistics changes.
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if filter.active() || ranking.sorted() {
+				g.Update(updateIPsView)
+			}
+		case <-ipsChangedChan:
+			g.Update(updateIPsView)
+		case <-exit:
+			return
+		}
+	}
+}
+
+// updateConfigView displays focused IP configs.
+func updateConfigView(g *gocui.Gui, configView *gocui.View, sub *subscription) {
+	ticker := time.NewTicker(UIREFRESH)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-exit:
+			return
+		case <-ticker.C:
+		}
+
+		events := sub.drain()
+		if len(events) == 0 {
+			continue
+		}
+		// only the latest focused ip matters.
+		ip := events[len(events)-1].text
+		g.Update(func(g *gocui.Gui) error {
+			configView.Clear()
+			fmt.Fprint(configView, dbs.formatIPConfig(ip))
+			return nil
+		})
+	}
+}
+
+// updateOutputsView displays each ping execution output.
+// It cleans the outputs view when requested. Only the latest
+// lines are kept and the view is rewritten from them once
+// a tenth of its lines were truncated or the memory guard
+// trimmed them. Events received meanwhile are applied at
+// once on each refresh. Repeated lines are collapsed into
+// the last one when configured.
+func updateOutputsView(g *gocui.Gui, outputsView *gocui.View, sub *subscription) {
+	cfgs := getSettings()
+	ring := newLineRing(cfgs.OutputLines)
+	step := cfgs.OutputLines/10 + 1
+	redrawn := 0
+	// last line compared to the next ones and its repeats.
+	var lastKey string
+	var repeats int
+	// the last line changed so the view must be rewritten.
+	var redraw bool
+	ticker := time.NewTicker(UIREFRESH)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-exit:
+			return
+		case <-ticker.C:
+		}
+
+		// the memory guard asks to drop the oldest half of the lines.
+		if atomic.CompareAndSwapInt32(&trimOutputs, 1, 0) && ring.count > 0 {
+			ring.trim(ring.count / 2)
+			redrawn, redraw = ring.truncated, true
+		}
+
+		events := sub.drain()
+		if len(events) == 0 && !redraw {
+			continue
+		}
+
+		var ops []func()
+		// consecutive lines are written at once.
+		var lines strings.Builder
+		flush := func() {
+			if redraw {
+				redraw = false
+				lines.Reset()
+				content := ring.content()
+				ops = append(ops, func() {
+					// the table scrolled out of the ring.
+					outputsTable = ""
+					outputsView.Clear()
+					fmt.Fprint(outputsView, content)
+				})
+				return
+			}
+			if lines.Len() == 0 {
+				return
+			}
+			text := lines.String()
+			lines.Reset()
+			ops = append(ops, func() { fmt.Fprint(outputsView, text) })
+		}
+
+		for _, e := range events {
+			e := e
+			switch e.kind {
+			case EVOUTPUT:
+				if getSettings().CollapseRepeats {
+					key := repeatKey(e.text)
+					if ring.count > 0 && key == lastKey {
+						repeats++
+						ring.replaceLast(fmt.Sprintf("%s (x%d)", e.text, repeats))
+						redraw = true
+						continue
+					}
+					lastKey, repeats = key, 1
+				}
+				ring.add(e.text)
+				if ring.truncated-redrawn < step {
+					// a pending rewrite includes the line.
+					if !redraw {
+						lines.WriteString("\n" + e.text)
+					}
+					continue
+				}
+				// the ring already holds the pending lines.
+				redraw = false
+				lines.Reset()
+				redrawn = ring.truncated
+				content := ring.content()
+				ops = append(ops, func() {
+					// the table scrolled out of the ring.
+					outputsTable = ""
+					outputsView.Clear()
+					fmt.Fprint(outputsView, content)
+				})
+			case EVTABLE:
+				ring.reset()
+				redrawn = 0
+				lines.Reset()
+				lastKey, redraw = "", false
+				ops = append(ops, func() {
+					outputsTable = e.text
+					outputsView.Clear()
+					fmt.Fprint(outputsView, sortTable(e.text))
+				})
+			case EVCLEAROUTPUTS:
+				ring.reset()
+				redrawn = 0
+				lines.Reset()
+				lastKey, redraw = "", false
+				ops = append(ops, func() {
+					outputsTable = ""
+					tableSort.column, tableSort.reverse = 0, false
+					outputsView.Clear()
+					outputsView.SetCursor(0, 0)
+					outputsView.SetOrigin(0, 0)
+				})
+			case EVTITLE:
+				flush()
+				ops = append(ops, func() { outputsView.Title = e.text })
+			}
+		}
+		flush()
+		atomic.StoreInt64(&outputBytes, int64(ring.bytes))
+
+		g.Update(func(g *gocui.Gui) error {
+			for _, op := range ops {
+				op()
+			}
+			return nil
+		})
+	}
+}
+
+// updateStatsView displays ongoing Ping statistics. All entries
+// received meanwhile are accounted on each refresh.
+func updateStatsView(g *gocui.Gui, statsView *gocui.View, sub *subscription) {
+	ticker := time.NewTicker(UIREFRESH)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-exit:
+			return
+		case <-ticker.C:
+		}
+
+		events := sub.drain()
+		if len(events) == 0 {
+			continue
+		}
+		g.Update(func(g *gocui.Gui) error {
+			var shown string
+			for _, e := range events {
+				if e.kind == EVCLEARSTATS {
+					shown = ""
+					statsView.Clear()
+					continue
+				}
+				if e.kind == EVSTATSTEXT {
+					shown = ""
+					statsView.Clear()
+					fmt.Fprint(statsView, e.text)
+					continue
+				}
+				shown = e.probe.Target
+			}
+			if shown != "" {
+				statsView.Clear()
+				fmt.Fprint(statsView, dbs.formatIPStats(shown))
+			}
+			return nil
+		})
+	}
+}
+
+// buildStats updates the Ping statistics of the target of a probe
+// event with its result. It returns false when the event carries no
+// result or the target was deleted meanwhile.
+func buildStats(e ProbeEvent) bool {
+	if e.Sample == nil {
+		return false
+	}
+	ip, threshold, sp := e.Target, e.Threshold, *e.Sample
+	rt := sp.RTT
+	if !sp.Success {
+		// failure response.
+		if !dbs.updateStats(ip, func(stats *stat) {
+			stats.fails += 1
+			updateState(ip, stats, true)
+		}) {
+			// target deleted meanwhile.
+			return false
+		}
+		publishSample(ip, rt, false)
+		return true
+	}
+
+	anomaly, isAnomaly := baselines.observe(ip, rt)
+	// reply response.
+	if !dbs.updateStats(ip, func(stats *stat) {
+		stats.last = rt
+		if isAnomaly {
+			stats.anomalies += 1
+		}
+		updateState(ip, stats, false)
+
+		modif := false
+		if stats.min == 0 && stats.max == 0 {
+			// matches the first output data.
+			stats.min, stats.max = rt, rt
+			modif = true
+		} else {
+			// this for following outputs.
+			if rt < stats.min {
+				stats.min = rt
+				modif = true
+			} else if stats.max < rt {
+				stats.max = rt
+				modif = true
+			}
+		}
+		// compute average only if there was a change.
+		if modif {
+			stats.avg = (stats.min + stats.max) / 2
+		}
+
+		if rt == threshold {
+			stats.match += 1
+		} else if rt > threshold {
+			stats.above += 1
+		} else if rt < threshold {
+			stats.under += 1
+		}
+	}) {
+		return false
+	}
+	publishSample(ip, rt, true)
+	if isAnomaly {
+		bus.publish(EVOUTPUT, anomaly)
+	}
+
+	return true
+}
+
+// setupViews creates the main views of the ui with their keybindings
+// and moves the focus on the ips list.
+func setupViews(g *gocui.Gui) error {
+	maxX, maxY := g.Size()
+
+	// IPs list view.
+	ipsView, err := g.SetView(IPLIST, 0, 0, IPSWIDTH, maxY-19)
+	if err != nil && err != gocui.ErrUnknownView {
+		log.Println("Failed to create ips list view:", err)
+		return err
+	}
+	ipsView.Title = " IP Addresses "
+	if remote != nil {
+		ipsView.Title = " IPs [Attached] "
+		if remote.isReadOnly() {
+			ipsView.Title = " IPs [Read-Only] "
+		}
+	}
+	ipsView.FgColor = gocui.ColorYellow
+	ipsView.SelBgColor = gocui.ColorGreen
+	ipsView.SelFgColor = gocui.ColorBlack
+	ipsView.Highlight = true
+
+	// Outputs view.
+	outputsView, err := g.SetView(OUTPUTS, IPSWIDTH+1, 0, maxX-1, maxY-1)
+	if err != nil && err != gocui.ErrUnknownView {
+		log.Println("Failed to create outputs view:", err)
+		return err
+	}
+	outputsView.Title = " Ping Outputs "
+	outputsView.FgColor = gocui.ColorYellow
+	outputsView.SelBgColor = gocui.ColorGreen
+	outputsView.SelFgColor = gocui.ColorBlack
+	outputsView.Autoscroll = true
+	outputsView.Wrap = false
+	outputsView.Highlight = true
+
+	// Current Ping Configs view.
+	configView, err := g.SetView(CONFIG, 0, maxY-18, IPSWIDTH, maxY-11)
+	if err != nil && err != gocui.ErrUnknownView {
+		log.Println("Failed to create config view:", err)
+		return err
+	}
+	configView.Title = " Configs "
+	configView.FgColor = gocui.ColorYellow
+	configView.SelBgColor = gocui.ColorGreen
+	configView.SelFgColor = gocui.ColorBlack
+	configView.Highlight = false
+
+	// Current Ping Statistics view.
+	statsView, err := g.SetView(STATS, 0, maxY-10, IPSWIDTH, maxY-2)
+	if err != nil && err != gocui.ErrUnknownView {
+		log.Println("Failed to create stats view:", err)
+		return err
+	}
+	statsView.Title = " Stats "
+	statsView.FgColor = gocui.ColorYellow
+	statsView.SelBgColor = gocui.ColorGreen
+	statsView.SelFgColor = gocui.ColorBlack
+	statsView.Highlight = false
+	statsView.Editable = false
+
+	// Infos view.
+	infosView, err := g.SetView(INFOS, 0, maxY-2, IPSWIDTH, maxY)
+	if err != nil && err != gocui.ErrUnknownView {
+		log.Println("Failed to create infos view:", err)
+		return err
+	}
+	infosView.FgColor = gocui.ColorRed
+	infosView.Highlight = false
+	infosView.Editable = false
+	infosView.Frame = false
+	fmt.Fprint(infosView, formatStatus())
+
+	// Apply keybindings to ui.
+	if err = keybindings(g); err != nil {
+		log.Println("Failed to setup keybindings:", err)
+		return err
+	}
+
+	// move the focus on the jobs list box.
+	if _, err = g.SetCurrentView(IPLIST); err != nil {
+		log.Println("Failed to set focus on ips view:", err)
+		return err
+	}
+	// set the cursor & origin to highlight first IP.
+	ipsView.SetCursor(0, 0)
+	ipsView.SetOrigin(0, 0)
+
+	return nil
+}
+
+func layout(g *gocui.Gui) error {
+	maxX, maxY := g.Size()
+
+	// IPs list view.
+	_, err := g.SetView(IPLIST, 0, 0, IPSWIDTH, maxY-19)
+	if err != nil && err != gocui.ErrUnknownView {
+		log.Println("Failed to create ips list view:", err)
+		return err
+	}
+
+	// Outputs view.
+	_, err = g.SetView(OUTPUTS, IPSWIDTH+1, 0, maxX-1, maxY-1)
+	if err != nil && err != gocui.ErrUnknownView {
+		log.Println("Failed to create outputs view:", err)
+		return err
+	}
+
+	// Current Ping Configs view.
+	_, err = g.SetView(CONFIG, 0, maxY-18, IPSWIDTH, maxY-11)
+	if err != nil && err != gocui.ErrUnknownView {
+		log.Println("Failed to create config view:", err)
+		return err
+	}
+
+	// Current Ping Statistics view.
+	_, err = g.SetView(STATS, 0, maxY-10, IPSWIDTH, maxY-2)
+	if err != nil && err != gocui.ErrUnknownView {
+		log.Println("Failed to create stats view:", err)
+		return err
+	}
+
+	// Infos view.
+	_, err = g.SetView(INFOS, 0, maxY-2, IPSWIDTH, maxY)
+	if err != nil && err != gocui.ErrUnknownView {
+		log.Println("Failed to create infos view:", err)
+		return err
+	}
+
+	// Scroll positions drawn over the bottom borders.
+	if err = layoutPosition(g, IPLIST, IPSPOSITION, ""); err != nil {
+		return err
+	}
+
+	if err = layoutPosition(g, OUTPUTS, OUTPUTSPOSITION, "line "); err != nil {
+		return err
+	}
+
+	if err = layoutPosition(g, CONFIG, CONFIGPOSITION, ""); err != nil {
+		return err
+	}
+
+	if err = layoutPosition(g, STATS, STATSPOSITION, ""); err != nil {
+		return err
+	}
+
+	// Header row of the scrolled hops tables.
+	if err = layoutTableHeader(g); err != nil {
+		return err
+	}
+
+	// Latest failure shown over the outputs.
+	return layoutBanner(g)
+}
+
+// layoutPosition displays the position of the cursor line into the
+// lines of a view over its bottom border, once they exceed its height.
+func layoutPosition(g *gocui.Gui, name, position, label string) error {
+	v, err := g.View(name)
+	if err != nil {
+		return nil
+	}
+	text := formatPosition(v, label)
+	_, _, x1, y1, err := g.ViewPosition(name)
+	if err != nil {
+		return nil
+	}
+	pv, err := g.SetView(position, x1-len(text)-2, y1-1, x1-1, y1+1)
+	if err != nil && err != gocui.ErrUnknownView {
+		log.Println("Failed to create position view:", err)
+		return err
+	}
+	if err == gocui.ErrUnknownView {
+		pv.Frame = false
+		pv.FgColor = gocui.ColorYellow
+	}
+	pv.Clear()
+	fmt.Fprint(pv, text)
+	return nil
+}
+
+// formatPosition returns " line 1520/8043 (18%) " for the cursor line
+// of a view or nothing when all its lines fit into it.
+func formatPosition(v *gocui.View, label string) string {
+	_, height := v.Size()
+	lines := v.BufferLines()
+	total := len(lines)
+	for total > 0 && strings.TrimSpace(lines[total-1]) == "" {
+		total--
+	}
+	if total <= height {
+		return ""
+	}
+	_, oy := v.Origin()
+	if v.Autoscroll && !v.Wrap {
+		// the view scrolls to its end on next draw.
+		oy = total - height
+	}
+	_, cy := v.Cursor()
+	line := oy + cy + 1
+	if !v.Highlight {
+		// the views without cursor tell their last shown line.
+		line = oy + height
+	}
+	if line > total {
+		line = total
+	}
+	return fmt.Sprintf(" %s%d/%d (%d%%) ", label, line, total, line*100/total)
+}
+
+func quit(g *gocui.Gui, v *gocui.View) error {
+	close(exit)
+	return gocui.ErrQuit
+}
+
+// keybindings binds multiple keys to views.
+func keybindings(g *gocui.Gui) error {
+
+	if err := g.SetKeybinding("", gocui.KeyCtrlC, gocui.ModNone, quit); err != nil {
+		return err
+	}
+
+	if err := g.SetKeybinding("", gocui.KeyTab, gocui.ModNone, nextView); err != nil {
+		return err
+	}
+
+	// use F1 to display help message when the focus is on OUPUTS or IPLIST view.
+	if err := g.SetKeybinding(IPLIST, gocui.KeyF1, gocui.ModNone, displayHelpView); err != nil {
+		return err
+	}
+
+	if err := g.SetKeybinding(OUTPUTS, gocui.KeyF1, gocui.ModNone, displayHelpView); err != nil {
+		return err
+	}
+
+	// use F12 to display the runtime statistics and the supervised routines.
+	if err := g.SetKeybinding(IPLIST, gocui.KeyF12, gocui.ModNone, displayDebugView); err != nil {
+		return err
+	}
+
+	if err := g.SetKeybinding(OUTPUTS, gocui.KeyF12, gocui.ModNone, displayDebugView); err != nil {
+		return err
+	}
+
+	// Ctrl+A to create & add one or more new ip addresses (comma-separated input).
+	if err := g.SetKeybinding(IPLIST, gocui.KeyCtrlA, gocui.ModNone, writable(addIPInputView)); err != nil {
+		return err
+	}
+
+	if err := g.SetKeybinding(OUTPUTS, gocui.KeyCtrlA, gocui.ModNone, writable(addIPInputView)); err != nil {
+		return err
+	}
+
+	// Ctrl+D to delete one or more existing ip addresses (comma-separated input).
+	if err := g.SetKeybinding(IPLIST, gocui.KeyCtrlD, gocui.ModNone, writable(deleteIPInputView)); err != nil {
+		return err
+	}
+
+	if err := g.SetKeybinding(OUTPUTS, gocui.KeyCtrlD, gocui.ModNone, writable(deleteIPInputView)); err != nil {
+		return err
+	}
+
+	// Ctrl+F to find and move cursor on existing ip address.
+	if err := g.SetKeybinding(IPLIST, gocui.KeyCtrlF, gocui.ModNone, searchIPInputView); err != nil {
+		return err
+	}
+
+	if err := g.SetKeybinding(OUTPUTS, gocui.KeyCtrlF, gocui.ModNone, searchIPInputView); err != nil {
+		return err
+	}
+
+	// Ctrl+L to load new IP infos from a set of files entered into an input box.
+	if err := g.SetKeybinding(IPLIST, gocui.KeyCtrlL, gocui.ModNone, writable(loadIPsInputView)); err != nil {
+		return err
+	}
+
+	if err := g.SetKeybinding(OUTPUTS, gocui.KeyCtrlL, gocui.ModNone, writable(loadIPsInputView)); err != nil {
+		return err
+	}
+
+	// Ctrl+N to display the notification center of fired alerts.
+	if err := g.SetKeybinding(IPLIST, gocui.KeyCtrlN, gocui.ModNone, displayNotificationsView); err != nil {
+		return err
+	}
+
+	if err := g.SetKeybinding(OUTPUTS, gocui.KeyCtrlN, gocui.ModNone, displayNotificationsView); err != nil {
+		return err
+	}
+
+	// Ctrl+X to export statistics and samples history into CSV files.
+	if err := g.SetKeybinding(IPLIST, gocui.KeyCtrlX, gocui.ModNone, exportCSVInputView); err != nil {
+		return err
+	}
+
+	if err := g.SetKeybinding(OUTPUTS, gocui.KeyCtrlX, gocui.ModNone, exportCSVInputView); err != nil {
+		return err
+	}
+
+	// Ctrl+S to save the outputs view content into a file.
+	if err := g.SetKeybinding(OUTPUTS, gocui.KeyCtrlS, gocui.ModNone, saveOutputsInputView); err != nil {
+		return err
+	}
+
+	if err := g.SetKeybinding(IPLIST, gocui.KeyCtrlS, gocui.ModNone, saveOutputsInputView); err != nil {
+		return err
+	}
+
+	// Ctrl+R to clear the outputs view content.
+	if err := g.SetKeybinding(OUTPUTS, gocui.KeyCtrlR, gocui.ModNone, clearOutputsView); err != nil {
+		return err
+	}
+
+	// Press <Enter> key or <P> or <Ctrl+P> to add current focused IP to Ping scheduler.
+	if err := g.SetKeybinding(IPLIST, gocui.KeyEnter, gocui.ModNone, addPing); err != nil {
+		return err
+	}
+
+	if err := g.SetKeybinding(IPLIST, gocui.KeyCtrlP, gocui.ModNone, addPing); err != nil {
+		return err
+	}
+
+	if err := g.SetKeybinding(IPLIST, 'P', gocui.ModNone, addPing); err != nil {
+		return err
+	}
+
+	// Press <T> key or <Ctrl+T> to add current focused IP to Traceroute scheduler.
+	if err := g.SetKeybinding(IPLIST, 'T', gocui.ModNone, addTraceroute); err != nil {
+		return err
+	}
+
+	if err := g.SetKeybinding(IPLIST, gocui.KeyCtrlT, gocui.ModNone, addTraceroute); err != nil {
+		return err
+	}
+
+	// Press <M> key to start a continuous traceroute (mtr mode) on current focused IP.
+	if err := g.SetKeybinding(IPLIST, 'M', gocui.ModNone, addMTR); err != nil {
+		return err
+	}
+
+	// Press <G> key to trace a group of IPs in parallel.
+	if err := g.SetKeybinding(IPLIST, 'G', gocui.ModNone, multiTraceInputView); err != nil {
+		return err
+	}
+
+	// Press <D> key to run a dns lookup of the focused IP or any name.
+	if err := g.SetKeybinding(IPLIST, 'D', gocui.ModNone, dnsLookupInputView); err != nil {
+		return err
+	}
+
+	// Press <S> key to scan the tcp ports of the focused IP.
+	if err := g.SetKeybinding(IPLIST, 'S', gocui.ModNone, portScanInputView); err != nil {
+		return err
+	}
+
+	// Press <C> key to check the tls certificate of the focused IP.
+	if err := g.SetKeybinding(IPLIST, 'C', gocui.ModNone, certCheckInputView); err != nil {
+		return err
+	}
+
+	// Press <A> key to resolve the MAC address of the focused IP.
+	if err := g.SetKeybinding(IPLIST, 'A', gocui.ModNone, lookupNeighbor); err != nil {
+		return err
+	}
+
+	// Press <I> key to measure the throughput to the focused IP.
+	if err := g.SetKeybinding(IPLIST, 'I', gocui.ModNone, runIperf); err != nil {
+		return err
+	}
+
+	// Press <i> key to display all details of the focused IP.
+	if err := g.SetKeybinding(IPLIST, 'i', gocui.ModNone, displayDetailsView); err != nil {
+		return err
+	}
+
+	// Press <H> key to browse the completed runs of the focused IP.
+	if err := g.SetKeybinding(IPLIST, 'H', gocui.ModNone, displayRunsView); err != nil {
+		return err
+	}
+
+	// Press <J> key to annotate the timeline with an action taken.
+	if err := g.SetKeybinding(IPLIST, 'J', gocui.ModNone, annotationInputView); err != nil {
+		return err
+	}
+
+	// Press <F> key to filter the IPs list by their statistics.
+	if err := g.SetKeybinding(IPLIST, 'F', gocui.ModNone, filterInputView); err != nil {
+		return err
+	}
+
+	// Press <L> key to list the worst targets first or back in order.
+	if err := g.SetKeybinding(IPLIST, 'L', gocui.ModNone, toggleWorstFirst); err != nil {
+		return err
+	}
+
+	// Press <V> key to display the subnets of the targets.
+	if err := g.SetKeybinding(IPLIST, 'V', gocui.ModNone, displaySubnetsView); err != nil {
+		return err
+	}
+
+	// Press <U> key to compare the focused IP from the vantages.
+	if err := g.SetKeybinding(IPLIST, 'U', gocui.ModNone, displayVantagesView); err != nil {
+		return err
+	}
+
+	// Press <E> key to override the configs of the focused IP for a while.
+	if err := g.SetKeybinding(IPLIST, 'E', gocui.ModNone, overrideInputView); err != nil {
+		return err
+	}
+
+	// Press <K> key to acknowledge the highlight of the focused IP.
+	if err := g.SetKeybinding(IPLIST, 'K', gocui.ModNone, acknowledgeHighlight); err != nil {
+		return err
+	}
+
+	// Press <O> key to wake up the focused IP with its MAC address.
+	if err := g.SetKeybinding(IPLIST, 'O', gocui.ModNone, wakeTarget); err != nil {
+		return err
+	}
+
+	// Press <N> key to poll the snmp agent of the focused IP.
+	if err := g.SetKeybinding(IPLIST, 'N', gocui.ModNone, displaySNMPView); err != nil {
+		return err
+	}
+
+	// Press <W> key to display the owner of the focused IP or traceroute hop.
+	if err := g.SetKeybinding(IPLIST, 'W', gocui.ModNone, displayRDAPView); err != nil {
+		return err
+	}
+
+	if err := g.SetKeybinding(OUTPUTS, 'W', gocui.ModNone, displayRDAPView); err != nil {
+		return err
+	}
+
+	// Press <R> key to run a troubleshooting playbook on the focused IP.
+	if err := g.SetKeybinding(IPLIST, 'R', gocui.ModNone, playbookInputView); err != nil {
+		return err
+	}
+
+	// Press <B> key to display the BGP routes of the focused IP or traceroute hop.
+	if err := g.SetKeybinding(IPLIST, 'B', gocui.ModNone, displayLookingGlassView); err != nil {
+		return err
+	}
+
+	if err := g.SetKeybinding(OUTPUTS, 'B', gocui.ModNone, displayLookingGlassView); err != nil {
+		return err
+	}
+
+	// Press <X> key to export the traceroute and MTR results of the outputs view ip.
+	if err := g.SetKeybinding(IPLIST, 'X', gocui.ModNone, exportTraceInputView); err != nil {
+		return err
+	}
+
+	if err := g.SetKeybinding(OUTPUTS, 'X', gocui.ModNone, exportTraceInputView); err != nil {
+		return err
+	}
+
+	// Press <Enter> key on a hop of the traceroute table to ping that hop.
+	if err := g.SetKeybinding(OUTPUTS, gocui.KeyEnter, gocui.ModNone, writable(drillDownHop)); err != nil {
+		return err
+	}
+
+	// arrow keys binding to navigate over the list of items.
+	if err := g.SetKeybinding(IPLIST, gocui.KeyArrowUp, gocui.ModNone, ipsMoveCursorUp); err != nil {
+		return err
+	}
+
+	if err := g.SetKeybinding(IPLIST, gocui.KeyArrowDown, gocui.ModNone, ipsMoveCursorDown); err != nil {
+		return err
+	}
+
+	if err := g.SetKeybinding(OUTPUTS, gocui.KeyArrowUp, gocui.ModNone, outMoveCursorUp); err != nil {
+		return err
+	}
+
+	if err := g.SetKeybinding(OUTPUTS, gocui.KeyArrowDown, gocui.ModNone, outMoveCursorDown); err != nil {
+		return err
+	}
+
+	// the fixed height configs and stats views scroll their fields.
+	for _, name := range []string{CONFIG, STATS} {
+		if err := g.SetKeybinding(name, gocui.KeyArrowUp, gocui.ModNone, scrollView(-1)); err != nil {
+			return err
+		}
+
+		if err := g.SetKeybinding(name, gocui.KeyArrowDown, gocui.ModNone, scrollView(1)); err != nil {
+			return err
+		}
+	}
+
+	if err := g.SetKeybinding(OUTPUTS, gocui.KeyArrowLeft, gocui.ModNone, outScrollLeft); err != nil {
+		return err
+	}
+
+	if err := g.SetKeybinding(OUTPUTS, gocui.KeyArrowRight, gocui.ModNone, outScrollRight); err != nil {
+		return err
+	}
+
+	// toggle between wrapping and horizontal scrolling of the long outputs lines.
+	// 1 to 9 sort the hops tables by a column and 0 restores the order.
+	for column := 0; column <= 9; column++ {
+		if err := g.SetKeybinding(OUTPUTS, rune('0'+column), gocui.ModNone, sortOutputsTable(column)); err != nil {
+			return err
+		}
+	}
+
+	if err := g.SetKeybinding(IPLIST, gocui.KeyCtrlW, gocui.ModNone, toggleOutputsWrap); err != nil {
+		return err
+	}
+
+	if err := g.SetKeybinding(OUTPUTS, gocui.KeyCtrlW, gocui.ModNone, toggleOutputsWrap); err != nil {
+		return err
+	}
+
+	// stop current ongoing action (if any) - which could be Ping or Traceroute.
+	if err := g.SetKeybinding(IPLIST, gocui.KeyCtrlQ, gocui.ModNone, stopCurrentProcessing); err != nil {
+		return err
+	}
+
+	if err := g.SetKeybinding(OUTPUTS, gocui.KeyCtrlQ, gocui.ModNone, stopCurrentProcessing); err != nil {
+		return err
+	}
+
+	if err := g.SetKeybinding(CONFIG, gocui.KeyCtrlQ, gocui.ModNone, stopCurrentProcessing); err != nil {
+		return err
+	}
+
+	if err := g.SetKeybinding(STATS, gocui.KeyCtrlQ, gocui.ModNone, stopCurrentProcessing); err != nil {
+		return err
+	}
+
+	// Ctrl+E to edit focused IP configuration details.
+	if err := g.SetKeybinding(IPLIST, gocui.KeyCtrlE, gocui.ModNone, writable(editIPConfigView)); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// stopCurrentProcessing triggered on CTRL+Q send stop flag to channel.
+func stopCurrentProcessing(g *gocui.Gui, v *gocui.View) error {
+	stopProcessingChan <- struct{}{}
+	currentOnPingIP = ""
+	return nil
+}
+
+// displayHelpView displays help details but trying to center it.
+func displayHelpView(g *gocui.Gui, cv *gocui.View) error {
+
+	maxX, maxY := g.Size()
+
+	// construct the input box and position at the center of the screen.
+	if helpView, err := g.SetView(HELP, (maxX-HWIDTH)/2, (maxY-HHEIGHT)/2, maxX/2+HWIDTH, (maxY+HHEIGHT)/2); err != nil {
+		if err != gocui.ErrUnknownView {
+			log.Println("Failed to create help view:", err)
+			return err
+		}
+
+		helpView.FgColor = gocui.ColorGreen
+		helpView.SelBgColor = gocui.ColorBlack
+		helpView.SelFgColor = gocui.ColorYellow
+		helpView.Editable = false
+		helpView.Autoscroll = true
+		helpView.Wrap = true
+		helpView.Frame = false
+
+		if _, err := g.SetCurrentView(HELP); err != nil {
+			log.Println("Failed to set focus on help view:", err)
+			return err
+		}
+		g.Cursor = false
+
+		// bind Ctrl+Q and Escape and F1 keys to close the input box.
+		if err := g.SetKeybinding(HELP, gocui.KeyCtrlQ, gocui.ModNone, closeHelpView); err != nil {
+			log.Println("Failed to bind keys (CtrlQ) to help view:", err)
+			return err
+		}
+
+		if err := g.SetKeybinding(HELP, gocui.KeyF1, gocui.ModNone, closeHelpView); err != nil {
+			log.Println("Failed to bind keys (F1) to help view:", err)
+			return err
+		}
+
+		if err := g.SetKeybinding(HELP, gocui.KeyEsc, gocui.ModNone, closeHelpView); err != nil {
+			log.Println("Failed to bind keys (Esc) to help view:", err)
+			return err
+		}
+
+		fmt.Fprint(helpView, helpDetails)
+
+	}
+	return nil
+}
+
+// closeHelpView closes help view then move the focus on IP list view.
+func closeHelpView(g *gocui.Gui, hv *gocui.View) error {
+
+	hv.Clear()
+	g.Cursor = false
+	g.DeleteKeybindings(hv.Name())
+	if err := g.DeleteView(hv.Name()); err != nil {
+		log.Println("Failed to delete help view:", err)
+		return err
+	}
+
+	return setCurrentDefaultView(g)
+}
+
+// clearOutputsView clears outputs view content.
+func clearOutputsView(g *gocui.Gui, v *gocui.View) error {
+	v.Clear()
+	return nil
+}
+
+// addIPInputView displays a temporary input box to enter
+// a comma-separated list of IP addresses.
+func addIPInputView(g *gocui.Gui, cv *gocui.View) error {
+	maxX, maxY := g.Size()
+
+	const name = "addIP"
+
+	// construct the input box and position at the center of the screen.
+	if inputView, err := g.SetView(name, maxX/2-30, maxY/2, maxX/2+30, maxY/2+2); err != nil {
+		if err != gocui.ErrUnknownView {
+			log.Println("Failed to display input view: ", err)
+			return err
+		}
+
+		inputView.Title = " Enter IP Addresses (Separated By Comma) [@ Template] "
+		inputView.FgColor = gocui.ColorYellow
+		inputView.SelBgColor = gocui.ColorBlack
+		inputView.SelFgColor = gocui.ColorYellow
+		inputView.Editable = true
+
+		if _, err := g.SetCurrentView(name); err != nil {
+			log.Println(err)
+			return err
+		}
+		g.Cursor = true
+		inputView.Highlight = true
+		// bind Enter key to processInput function.
+		if err := g.SetKeybinding(name, gocui.KeyEnter, gocui.ModNone, processInput); err != nil {
+			log.Println(err)
+			return err
+		}
+
+		// bind Ctrl+Q and Escape keys to close the input box.
+		if err := g.SetKeybinding(name, gocui.KeyCtrlQ, gocui.ModNone, closeInputView); err != nil {
+			log.Println(err)
+			return err
+		}
+
+		if err := g.SetKeybinding(name, gocui.KeyEsc, gocui.ModNone, closeInputView); err != nil {
+			log.Println(err)
+			return err
+		}
+
+		// bind Up and Down keys to recall the recent values.
+		if err := g.SetKeybinding(name, gocui.KeyArrowUp, gocui.ModNone, recallInput(true)); err != nil {
+			log.Println(err)
+			return err
+		}
+
+		if err := g.SetKeybinding(name, gocui.KeyArrowDown, gocui.ModNone, recallInput(false)); err != nil {
+			log.Println(err)
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteIPInputView displays a temporary input box to delete an IP.
+func deleteIPInputView(g *gocui.Gui, cv *gocui.View) error {
+	maxX, maxY := g.Size()
+
+	const name = "deleteIP"
+
+	// construct the input box and position at the center of the screen.
+	if inputView, err := g.SetView(name, maxX/2-12, maxY/2, maxX/2+12, maxY/2+2); err != nil {
+		if err != gocui.ErrUnknownView {
+			log.Println("Failed to display input view: ", err)
+			return err
+		}
+
+		inputView.Title = " Delete IP Addresses "
+		inputView.FgColor = gocui.ColorYellow
+		inputView.SelBgColor = gocui.ColorBlack
+		inputView.SelFgColor = gocui.ColorYellow
+		inputView.Editable = true
+
+		if _, err := g.SetCurrentView(name); err != nil {
+			log.Println(err)
+			return err
+		}
+		g.Cursor = true
+		inputView.Highlight = true
+		// bind Enter key to processInput function.
+		if err := g.SetKeybinding(name, gocui.KeyEnter, gocui.ModNone, processInput); err != nil {
+			log.Println(err)
+			return err
+		}
+
+		// bind Ctrl+Q and Escape keys to close the input box.
+		if err := g.SetKeybinding(name, gocui.KeyCtrlQ, gocui.ModNone, closeInputView); err != nil {
+			log.Println(err)
+			return err
+		}
+
+		if err := g.SetKeybinding(name, gocui.KeyEsc, gocui.ModNone, closeInputView); err != nil {
+			log.Println(err)
+			return err
+		}
+
+		// bind Tab key to complete the known ip addresses.
+		if err := g.SetKeybinding(name, gocui.KeyTab, gocui.ModNone, completeInput(completeIPs)); err != nil {
+			log.Println(err)
+			return err
+		}
+	}
+	return nil
+}
+
+// searchIPInputView displays a temporary input box to find an IP.
+func searchIPInputView(g *gocui.Gui, cv *gocui.View) error {
+	maxX, maxY := g.Size()
+
+	const name = "searchIP"
+
+	// construct the input box and position at the center of the screen.
+	if inputView, err := g.SetView(name, maxX/2-30, maxY/2, maxX/2+30, maxY/2+2); err != nil {
+		if err != gocui.ErrUnknownView {
+			log.Println("Failed to display input view: ", err)
+			return err
+		}
+
+		inputView.Title = " Search IP Address, Name or Location "
+		inputView.FgColor = gocui.ColorYellow
+		inputView.SelBgColor = gocui.ColorBlack
+		inputView.SelFgColor = gocui.ColorYellow
+		inputView.Editable = true
+		// filter the listed targets on each key.
+		inputView.Editor = searchEditor(g)
+
+		if _, err := g.SetCurrentView(name); err != nil {
+			log.Println(err)
+			return err
+		}
+		g.Cursor = true
+		inputView.Highlight = true
+		// bind Enter key to processInput function.
+		if err := g.SetKeybinding(name, gocui.KeyEnter, gocui.ModNone, searchAndFocusIP); err != nil {
+			log.Println(err)
+			return err
+		}
+
+		// bind Ctrl+Q and Escape keys to close the input box.
+		if err := g.SetKeybinding(name, gocui.KeyCtrlQ, gocui.ModNone, closeInputView); err != nil {
+			log.Println(err)
+			return err
+		}
+
+		if err := g.SetKeybinding(name, gocui.KeyEsc, gocui.ModNone, closeInputView); err != nil {
+			log.Println(err)
+			return err
+		}
+
+		// bind Up and Down keys to select a listed target.
+		if err := g.SetKeybinding(name, gocui.KeyArrowUp, gocui.ModNone, moveSearchSelection(-1)); err != nil {
+			log.Println(err)
+			return err
+		}
+
+		if err := g.SetKeybinding(name, gocui.KeyArrowDown, gocui.ModNone, moveSearchSelection(1)); err != nil {
+			log.Println(err)
+			return err
+		}
+
+		// list the recently searched targets.
+		if err := updateSearchResults(g, inputView); err != nil {
+			log.Println("Failed to display search results:", err)
+		}
+
+		// bind Tab key to complete the known ip addresses.
+		if err := g.SetKeybinding(name, gocui.KeyTab, gocui.ModNone, completeInput(completeIPs)); err != nil {
+			log.Println(err)
+			return err
+		}
+	}
+	return nil
+}
+
+// loadIPsInputView displays a temporary input box to enter
+// a comma-separated list of files to load IP addresses from.
+func loadIPsInputView(g *gocui.Gui, cv *gocui.View) error {
+	maxX, maxY := g.Size()
+
+	const name = "addFiles"
+
+	// construct the input box and position at the center of the screen.
+	if inputView, err := g.SetView(name, maxX/2-25, maxY/2, maxX/2+25, maxY/2+2); err != nil {
+		if err != gocui.ErrUnknownView {
+			log.Println("Failed to display input view: ", err)
+			return err
+		}
+
+		inputView.Title = " Enter Filenames (Separated By Comma) "
+		inputView.FgColor = gocui.ColorYellow
+		inputView.SelBgColor = gocui.ColorBlack
+		inputView.SelFgColor = gocui.ColorYellow
+		inputView.Editable = true
+
+		if _, err := g.SetCurrentView(name); err != nil {
+			log.Println(err)
+			return err
+		}
+		g.Cursor = true
+		inputView.Highlight = true
+		// bind Enter key to processInput function.
+		if err := g.SetKeybinding(name, gocui.KeyEnter, gocui.ModNone, processInput); err != nil {
+			log.Println(err)
+			return err
+		}
+
+		// bind Ctrl+Q and Escape keys to close the input box.
+		if err := g.SetKeybinding(name, gocui.KeyCtrlQ, gocui.ModNone, closeInputView); err != nil {
+			log.Println(err)
+			return err
+		}
+
+		if err := g.SetKeybinding(name, gocui.KeyEsc, gocui.ModNone, closeInputView); err != nil {
+			log.Println(err)
+			return err
+		}
+
+		// bind Up and Down keys to recall the recent values.
+		if err := g.SetKeybinding(name, gocui.KeyArrowUp, gocui.ModNone, recallInput(true)); err != nil {
+			log.Println(err)
+			return err
+		}
+
+		if err := g.SetKeybinding(name, gocui.KeyArrowDown, gocui.ModNone, recallInput(false)); err != nil {
+			log.Println(err)
+			return err
+		}
+
+		// bind Tab key to complete the file and folder names.
+		if err := g.SetKeybinding(name, gocui.KeyTab, gocui.ModNone, completeInput(completePaths)); err != nil {
+			log.Println(err)
+			return err
+		}
+	}
+	return nil
+}
+
+// formatEditIPConfig formats a given IP configuration for editing.
+func (db *databases) formatEditIPConfig(ip string) string {
+	cfg := db.getConfig(ip)
+	probe := cfg.probe
+	if probe == "" {
+		probe = "icmp"
+	}
+	return fmt.Sprintf("backup   : %v\ntimeout  : %d\nrequests : %d\ninterval : %d\npkts size: %d\npattern  : %s\nthreshold: %d\nmax loss : %d\nmax hops : %d\nqueries  : %d\nprotocol : %s\nnumeric  : %v\nprobe    : %s\nqname    : %s\nqtype    : %s\nmac      : %s\nbroadcast: %s\niperf    : %d\nlabel    : %s\ntags     : %s",
+		cfg.backup, cfg.timeout, cfg.requests, cfg.interval, cfg.size, cfg.pattern, cfg.threshold, cfg.maxloss, cfg.maxhops, cfg.queries, cfg.protocol, cfg.numeric, probe, cfg.dnsQName(), cfg.dnsQType(), cfg.mac, cfg.wolBroadcast(), cfg.iperf, cfg.label, cfg.tags)
+}
+
+// editIPConfigView displays a temporary input box to enter
+// configuration of the current focused IP address.
+func editIPConfigView(g *gocui.Gui, ipv *gocui.View) error {
+
+	_, cy := ipv.Cursor()
+	l, err := ipv.Line(cy)
+	if err != nil {
+		log.Println("Failed to read current focused ip value:", err)
+		return nil
+	}
+	if len(l) == 0 {
+		return nil
+	}
+
+	ip := strings.Fields(strings.TrimSpace(l))[1]
+	cfg := dbs.formatEditIPConfig(ip)
+
+	maxX, maxY := g.Size()
+	const name = "editIPConfig"
+
+	// construct the input box from the center of the screen, moved
+	// up on short screens so its fields stay above the bottom.
+	y1 := maxY/2 + 21
+	if y1 > maxY-1 {
+		y1 = maxY - 1
+	}
+	y0 := y1 - 21
+	if y0 < 0 {
+		y0 = 0
+	}
+	if inputView, err := g.SetView(name, maxX/2-23, y0, maxX/2+23, y1); err != nil {
+		if err != gocui.ErrUnknownView {
+			log.Println("Failed to display input view: ", err)
+			return err
+		}
+
+		inputView.Title = fmt.Sprintf(" [%s] | Edit & Press Enter ", ip)
+		inputView.FgColor = gocui.ColorYellow
+		inputView.SelBgColor = gocui.ColorBlack
+		inputView.SelFgColor = gocui.ColorRed
+		inputView.Editable = true
+
+		if _, err := g.SetCurrentView(name); err != nil {
+			log.Println(err)
+			return err
+		}
+		g.Cursor = true
+		inputView.Highlight = true
+		// bind Enter key to processInput function.
+		if err := g.SetKeybinding(name, gocui.KeyEnter, gocui.ModNone, processInput); err != nil {
+			log.Println(err)
+			return err
+		}
+
+		// bind Ctrl+Q and Escape keys to close the input box.
+		if err := g.SetKeybinding(name, gocui.KeyCtrlQ, gocui.ModNone, closeInputView); err != nil {
+			log.Println(err)
+			return err
+		}
+
+		if err := g.SetKeybinding(name, gocui.KeyEsc, gocui.ModNone, closeInputView); err != nil {
+			log.Println(err)
+			return err
+		}
+
+		fmt.Fprint(inputView, strings.TrimSpace(cfg))
+	}
+	return nil
+}
+
+// processInput takes the buffer content and process it based on input
+// view name. It adds/deletes one or more of IP to/from the database.
+func processInput(g *gocui.Gui, iv *gocui.View) error {
+
+	// read buffer from the beginning.
+	iv.Rewind()
+
+	// ips list view.
+	ov, _ := g.View(IPLIST)
+
+	switch iv.Name() {
+
+	case "addIP":
+
+		if strings.TrimSpace(iv.Buffer()) != "" {
+			ips, t, err := splitTemplate(iv.Buffer())
+			if err != nil {
+				return showInputError(g, iv, strings.TrimSpace(iv.Buffer()), err.Error())
+			}
+			invalid := dbs.addOneMoreIPs(ips, t)
+			if remote != nil {
+				remote.command("add", iv.Buffer())
+			}
+			if len(invalid) > 0 {
+				// keep the invalid entries to correct them.
+				text := strings.Join(invalid, ", ")
+				if t != nil {
+					text += " " + TEMPLATESEP + " " + t.Name
+				}
+				g.Update(updateIPsView)
+				return showInputError(g, iv, text,
+					fmt.Sprintf("%d invalid entries ignored : %s", len(invalid), strings.Join(invalid, ", ")))
+			}
+			recents.add(iv.Name(), iv.Buffer())
+		} else {
+			// no data entered, so go back.
+			addIPInputView(g, ov)
+			return nil
+		}
+
+	case "deleteIP":
+
+		if strings.TrimSpace(iv.Buffer()) != "" {
+			dbs.deleteOneMoreIPs(iv.Buffer())
+			if remote != nil {
+				remote.command("delete", iv.Buffer())
+			}
+		} else {
+			deleteIPInputView(g, ov)
+			return nil
+		}
+
+	case "addFiles":
+
+		if strings.TrimSpace(iv.Buffer()) != "" {
+			filenames := strings.Split(strings.TrimSpace(iv.Buffer()), ",")
+			// remove any space around each filename.
+			for i := 0; i < len(filenames); i++ {
+				filenames[i] = strings.TrimSpace(filenames[i])
+			}
+			dbs.loadInfosFromFiles(filenames)
+			recents.add(iv.Name(), iv.Buffer())
+		} else {
+			loadIPsInputView(g, ov)
+			return nil
+		}
+
+	case "exportCSV":
+
+		if strings.TrimSpace(iv.Buffer()) != "" {
+			exportCSV(strings.TrimSpace(iv.Buffer()))
+		} else {
+			exportCSVInputView(g, ov)
+			return nil
+		}
+
+	case "saveOutputs":
+
+		if strings.TrimSpace(iv.Buffer()) != "" {
+			saveOutputs(g, strings.TrimSpace(iv.Buffer()))
+		} else {
+			saveOutputsInputView(g, ov)
+			return nil
+		}
+
+	case "exportTrace":
+
+		if strings.TrimSpace(iv.Buffer()) != "" {
+			exportTrace(currentOutputsIP, strings.TrimSpace(iv.Buffer()))
+		} else {
+			exportTraceInputView(g, ov)
+			return nil
+		}
+
+	case "multiTrace":
+
+		if strings.TrimSpace(iv.Buffer()) != "" {
+			addMultiTrace(iv.Buffer())
+		} else {
+			multiTraceInputView(g, ov)
+			return nil
+		}
+
+	case "dnsLookup":
+
+		if strings.TrimSpace(iv.Buffer()) != "" {
+			addDNSLookup(iv.Buffer())
+		} else {
+			dnsLookupInputView(g, ov)
+			return nil
+		}
+
+	case "portScan":
+
+		if strings.TrimSpace(iv.Buffer()) != "" {
+			// retreive the IP address concerned.
+			ip := strings.TrimSpace(strings.Split(iv.Title, "|")[0])
+			ip = strings.TrimLeft(ip, "[")
+			ip = strings.TrimRight(ip, "]")
+			addPortScan(ip, iv.Buffer())
+		}
+
+	case "certCheck":
+
+		if strings.TrimSpace(iv.Buffer()) != "" {
+			// retreive the IP address concerned.
+			ip := strings.TrimSpace(strings.Split(iv.Title, "|")[0])
+			ip = strings.TrimLeft(ip, "[")
+			ip = strings.TrimRight(ip, "]")
+			addCertCheck(ip, iv.Buffer())
+		}
+
+	case "playbook":
+
+		if strings.TrimSpace(iv.Buffer()) != "" {
+			// retreive the IP address concerned.
+			ip := strings.TrimSpace(strings.Split(iv.Title, "|")[0])
+			ip = strings.TrimLeft(ip, "[")
+			ip = strings.TrimRight(ip, "]")
+			addPlaybook(ip, iv.Buffer())
+		}
+
+	case "annotation":
+
+		if strings.TrimSpace(iv.Buffer()) != "" {
+			// retreive the IP address concerned.
+			ip := strings.TrimSpace(strings.Split(iv.Title, "|")[0])
+			ip = strings.TrimLeft(ip, "[")
+			ip = strings.TrimRight(ip, "]")
+			addAnnotation(ip, iv.Buffer())
+		}
+
+	case "filter":
+
+		// an empty query shows back all targets.
+		if err := filter.set(iv.Buffer()); err != nil {
+			return showInputError(g, iv, strings.TrimSpace(iv.Buffer()), err.Error())
+		}
+		recents.add(iv.Name(), iv.Buffer())
+
+	case "override":
+
+		// retreive the IP address concerned.
+		ip := strings.TrimSpace(strings.Split(iv.Title, "|")[0])
+		ip = strings.Trim(ip, "[]")
+		if strings.TrimSpace(iv.Buffer()) == "" {
+			overrides.revert(ip)
+		} else if err := overrides.apply(ip, iv.Buffer()); err != nil {
+			return showInputError(g, iv, strings.TrimSpace(iv.Buffer()), err.Error())
+		} else {
+			bus.publish(EVFOCUS, ip)
+		}
+
+	case "editIPConfig":
+
+		if strings.TrimSpace(iv.Buffer()) != "" {
+			// retreive the IP address concerned.
+			ip := strings.TrimSpace(strings.Split(iv.Title, "|")[0])
+			ip = strings.TrimLeft(ip, "[")
+			ip = strings.TrimRight(ip, "]")
+			editIPConfig(ip, strings.TrimSpace(iv.Buffer()))
+		}
+	}
+
+	if err := deleteInputView(g, iv); err != nil {
+		log.Println("Failed to delete input box view: ", err)
+		return err
+	}
+
+	// set back the focus on ips list view.
+	if _, err := g.SetCurrentView(IPLIST); err != nil {
+		log.Println("Failed to set back focus on ips list view: ", err)
+	}
+
+	// updateIPsView(g)
+	g.Update(updateIPsView)
+
+	return nil
+}
+
+// editIPConfig takes input data and update a given IP configs.
+// It ends any temporary override of the configs.
+func editIPConfig(ip, configs string) {
+	cfg := parseIPConfig(configs)
+	// update if only cfg changed.
+	if *cfg != (config{}) {
+		overrides.cancel(ip)
+		cfg.start = "n/a"
+		dbs.updateConfig(ip, cfg)
+		store.saveTarget(ip, cfg)
+	}
+}
+
+// parseIPConfig reads the "field: value" lines of the configs edit
+// box. Invalid values are ignored.
+func parseIPConfig(configs string) *config {
+	cfg := &config{}
+	lines := strings.Split(configs, "\n")
+	for _, line := range lines {
+		// mac addresses contain colons.
+		fv := strings.SplitN(line, ":", 2)
+		if len(fv) != 2 {
+			continue
+		}
+
+		switch strings.TrimSpace(fv[0]) {
+
+		case "requests":
+			if req, err := strconv.Atoi(strings.TrimSpace(fv[1])); err == nil && req > 0 {
+				cfg.requests = req
+			}
+
+		case "interval":
+			if i, ok := parseInterval(strings.TrimSpace(fv[1])); ok {
+				cfg.interval = i
+			}
+
+		case "threshold":
+			if thres, err := strconv.Atoi(strings.TrimSpace(fv[1])); err == nil && thres > 0 {
+				cfg.threshold = thres
+			}
+
+		case "timeout":
+			if t, err := strconv.Atoi(strings.TrimSpace(fv[1])); err == nil && t > 0 {
+				cfg.timeout = t
+			}
+
+		case "pkts size":
+			if s, err := strconv.Atoi(strings.TrimSpace(fv[1])); err == nil && s > 0 {
+				cfg.size = s
+			}
+
+		case "pattern":
+			if p := strings.ToLower(strings.TrimSpace(fv[1])); isValidPattern(p) {
+				cfg.pattern = p
+			}
+
+		case "backup":
+			if strings.ToLower(strings.TrimSpace(fv[1])) == "true" {
+				cfg.backup = true
+			}
+
+			if strings.ToLower(strings.TrimSpace(fv[1])) == "false" {
+				cfg.backup = false
+			}
+
+		case "max loss":
+			if l, err := strconv.Atoi(strings.TrimSuffix(strings.TrimSpace(fv[1]), "%")); err == nil && l > 0 && l <= 100 {
+				cfg.maxloss = l
+			}
+
+		case "max hops":
+			if h, err := strconv.Atoi(strings.TrimSpace(fv[1])); err == nil && h > 0 && h <= 255 {
+				cfg.maxhops = h
+			}
+
+		case "queries":
+			if q, err := strconv.Atoi(strings.TrimSpace(fv[1])); err == nil && q > 0 && q <= 10 {
+				cfg.queries = q
+			}
+
+		case "protocol":
+			switch p := strings.ToLower(strings.TrimSpace(fv[1])); p {
+			case "icmp", "udp", "tcp":
+				cfg.protocol = p
+			case "pathping":
+				if runtime.GOOS == "windows" {
+					cfg.protocol = p
+				}
+			}
+
+		case "numeric":
+			cfg.numeric = strings.ToLower(strings.TrimSpace(fv[1])) == "true"
+
+		case "probe":
+			if p := strings.ToLower(strings.TrimSpace(fv[1])); p == "dns" {
+				cfg.probe = p
+			}
+
+		case "qname":
+			if n := strings.TrimSpace(fv[1]); n != "" && n != "." {
+				cfg.qname = n
+			}
+
+		case "qtype":
+			t := strings.ToUpper(strings.TrimSpace(fv[1]))
+			for _, known := range probe.DNSTypes {
+				if t == known {
+					cfg.qtype = t
+				}
+			}
+
+		case "mac":
+			if mac, err := net.ParseMAC(strings.TrimSpace(fv[1])); err == nil {
+				cfg.mac = mac.String()
+			}
+
+		case "broadcast":
+			if b := net.ParseIP(strings.TrimSpace(fv[1])); b != nil && b.To4() != nil && b.String() != "255.255.255.255" {
+				cfg.broadcast = b.String()
+			}
+
+		case "iperf":
+			if port, err := strconv.Atoi(strings.TrimSpace(fv[1])); err == nil && port > 0 && port <= 65535 {
+				cfg.iperf = port
+			}
+
+		case "label":
+			cfg.label = strings.TrimSpace(fv[1])
+
+		case "tags":
+			cfg.tags = normalizeTags(fv[1])
+		}
+	}
+	return cfg
+}
+
+// searchAndFocusIP moves the cursor on the selected listed target.
+func searchAndFocusIP(g *gocui.Gui, iv *gocui.View) error {
+	// read buffer from the beginning.
+	iv.Rewind()
+
+	// ips list view.
+	ov, _ := g.View(IPLIST)
+
+	input := strings.TrimSpace(iv.Buffer())
+	ip := selectedSearchResult(g)
+	if ip == "" {
+		if input == "" {
+			return nil
+		}
+		deleteSearchResults(g)
+		return showInputError(g, iv, input, fmt.Sprintf("no target matches %s", input))
+	}
+
+	// get all current lines of ips list view.
+	pos := -1
+	lines := ov.BufferLines()
+	for i, line := range lines {
+		if fields := strings.Fields(line); len(fields) > 1 && fields[1] == ip {
+			pos = i
+		}
+	}
+	if pos == -1 {
+		return showInputError(g, iv, input, fmt.Sprintf("%s is not into the list", ip))
+	}
+	recents.add(iv.Name(), ip)
+
+	if err := deleteInputView(g, iv); err != nil {
+		return err
+	}
+
+	// set back the focus on ips list view.
+	if _, err := g.SetCurrentView(IPLIST); err != nil {
+		log.Println("Failed to set back focus on ips list view: ", err)
+	}
+	ov.SetCursor(0, pos)
+
+	return nil
+}
+
+// showInputError displays a message under an input box and puts back
+// the text to correct into the box.
+func showInputError(g *gocui.Gui, iv *gocui.View, text, message string) error {
+	setInputText(iv, text)
+	return showInputMessage(g, iv, message, gocui.ColorRed)
+}
+
+// setInputText replaces the text of an input box and moves the cursor
+// at its end, scrolling the box if the text exceeds its width.
+func setInputText(iv *gocui.View, text string) {
+	iv.Clear()
+	fmt.Fprint(iv, text)
+	width, _ := iv.Size()
+	if width < 1 {
+		return
+	}
+	ox := 0
+	if len(text) >= width {
+		ox = len(text) - width + 1
+	}
+	iv.SetOrigin(ox, 0)
+	iv.SetCursor(len(text)-ox, 0)
+}
+
+// showInputMessage displays a message with the given color under an
+// input box.
+func showInputMessage(g *gocui.Gui, iv *gocui.View, message string, color gocui.Attribute) error {
+	x0, _, x1, y1, err := g.ViewPosition(iv.Name())
+	if err != nil {
+		return nil
+	}
+	if x0+len(message)+1 > x1 {
+		x1 = x0 + len(message) + 1
+	}
+	mv, err := g.SetView(INPUTMESSAGE, x0, y1, x1, y1+2)
+	if err != nil && err != gocui.ErrUnknownView {
+		log.Println("Failed to display input message view:", err)
+		return err
+	}
+	mv.Frame = false
+	mv.FgColor = color | gocui.AttrBold
+	mv.Clear()
+	fmt.Fprint(mv, message)
+	return nil
+}
+
+// deleteInputMessage removes the message of an input box if any.
+func deleteInputMessage(g *gocui.Gui) {
+	if err := g.DeleteView(INPUTMESSAGE); err != nil && err != gocui.ErrUnknownView {
+		log.Println("Failed to delete input message view:", err)
+	}
+}
+
+// deleteInputView deletes a temporary input view.
+func deleteInputView(g *gocui.Gui, iv *gocui.View) error {
+	// clear and delete input view.
+	iv.Clear()
+	g.Cursor = false
+	deleteInputMessage(g)
+	deleteSearchResults(g)
+	g.DeleteKeybindings(iv.Name())
+	if err := g.DeleteView(iv.Name()); err != nil {
+		log.Println("Failed to delete input view: ", err)
+		return err
+	}
+	return nil
+}
+
+// nextView moves the focus to another view.
+func nextView(g *gocui.Gui, v *gocui.View) error {
+
+	cv := g.CurrentView()
+
+	if cv == nil {
+		if _, err := g.SetCurrentView(IPLIST); err != nil {
+			log.Printf("Failed to set focus on default (%v) view: %v", IPLIST, err)
+			return err
+		}
+		return nil
+	}
+
+	switch cv.Name() {
+
+	case IPLIST:
+		// move the focus on Outputs view.
+		if _, err := g.SetCurrentView(OUTPUTS); err != nil {
+			log.Println("Failed to set focus on outputs view:", err)
+			return err
+		}
+
+	case OUTPUTS:
+		// move the focus on Configs view.
+		if _, err := g.SetCurrentView(CONFIG); err != nil {
+			log.Println("Failed to set focus on configs view:", err)
+			return err
+		}
+
+	case CONFIG:
+		// move the focus on Stats view.
+		if _, err := g.SetCurrentView(STATS); err != nil {
+			log.Println("Failed to set focus on stats view:", err)
+			return err
+		}
+
+	case STATS:
+		// move the focus on IPs view.
+		if _, err := g.SetCurrentView(IPLIST); err != nil {
+			log.Println("Failed to set focus on ips view:", err)
+			return err
+		}
+	}
+
+	return nil
+}
+
+// closeInputView close temporary input view and abort change.
+func closeInputView(g *gocui.Gui, iv *gocui.View) error {
+	// clear the temporary input view.
+	iv.Clear()
+	// no input, so disbale cursor.
+	g.Cursor = false
+	deleteInputMessage(g)
+	deleteSearchResults(g)
+
+	// must delete keybindings before the view, or fatal error.
+	g.DeleteKeybindings(iv.Name())
+	if err := g.DeleteView(iv.Name()); err != nil {
+		log.Println("Failed to delete input view:", err)
+		return err
+	}
+
+	return setCurrentDefaultView(g)
+}
+
+// setCurrentDefaultView moves the focus on default view.
+func setCurrentDefaultView(g *gocui.Gui) error {
+	// move back the focus on the jobs list box.
+	if _, err := g.SetCurrentView(IPLIST); err != nil {
+		log.Println("Failed to set focus on default view:", err)
+		return err
+	}
+	return nil
+}
+
+// ipsLineBelow returns true if there is an IP at position y+1.
+func ipsLineBelow(v *gocui.View) bool {
+	_, cy := v.Cursor()
+	if l, _ := v.Line(cy + 1); l != "" {
+		bus.publish(EVFOCUS, strings.Fields(strings.TrimSpace(l))[1])
+		return true
+	}
+	return false
+}
+
+// ipsMoveCursorDown moves cursor to (currentY + 1) position if there is data there.
+func ipsMoveCursorDown(g *gocui.Gui, v *gocui.View) error {
+	if v != nil && ipsLineBelow(v) {
+		// there is data to next line.
+		v.MoveCursor(0, 1, false)
+	}
+
+	return nil
+}
+
+// ipsLineAbove returns true if there is an IP at position y-1.
+func ipsLineAbove(v *gocui.View) bool {
+	_, cy := v.Cursor()
+	if l, _ := v.Line(cy - 1); l != "" {
+		bus.publish(EVFOCUS, strings.Fields(strings.TrimSpace(l))[1])
+		return true
+	}
+	return false
+}
+
+// ipsMoveCursorUp moves cursor to (currentY - 1) position if there is data there.
+func ipsMoveCursorUp(g *gocui.Gui, v *gocui.View) error {
+	if v != nil && ipsLineAbove(v) {
+		// there is data upper.
+		v.MoveCursor(0, -1, false)
+	}
+
+	return nil
+}
+
+// lineBelow returns true if there is a non-empty string in cursor position y+1.
+func lineBelow(v *gocui.View) bool {
+	_, cy := v.Cursor()
+	if l, _ := v.Line(cy + 1); l != "" {
+		return true
+	}
+	return false
+}
+
+// outMoveCursorDown moves cursor to (currentY + 1) position if there is data there.
+func outMoveCursorDown(g *gocui.Gui, v *gocui.View) error {
+	if v != nil && lineBelow(v) {
+		// there is data to next line.
+		v.MoveCursor(0, 1, false)
+	}
+
+	return nil
+}
+
+// lineAbove returns true if there is a non-empty string in cursor position y-1.
+func lineAbove(v *gocui.View) bool {
+	_, cy := v.Cursor()
+	if l, _ := v.Line(cy - 1); l != "" {
+		return true
+	}
+	return false
+}
+
+// outMoveCursorUp moves cursor to (currentY - 1) position if there is data there.
+func outMoveCursorUp(g *gocui.Gui, v *gocui.View) error {
+	if v != nil && lineAbove(v) {
+		// there is data upper.
+		v.MoveCursor(0, -1, false)
+	}
+
+	return nil
+}
+
+// scrollView returns a handler moving the content of a view without
+// cursor by dy lines, keeping its last line at the bottom at most.
+func scrollView(dy int) func(g *gocui.Gui, v *gocui.View) error {
+	return func(g *gocui.Gui, v *gocui.View) error {
+		if v == nil {
+			return nil
+		}
+		_, height := v.Size()
+		lines := v.BufferLines()
+		total := len(lines)
+		for total > 0 && strings.TrimSpace(lines[total-1]) == "" {
+			total--
+		}
+		ox, oy := v.Origin()
+		oy += dy
+		if oy > total-height {
+			oy = total - height
+		}
+		if oy < 0 {
+			oy = 0
+		}
+		return v.SetOrigin(ox, oy)
+	}
+}
+
+// columns scrolled by each left or right arrow key press.
+const HSCROLLSTEP = 8
+
+// toggleOutputsWrap switches the outputs view between wrapping the
+// long lines and displaying them unwrapped with horizontal scrolling.
+func toggleOutputsWrap(g *gocui.Gui, v *gocui.View) error {
+	ov, err := g.View(OUTPUTS)
+	if err != nil {
+		return nil
+	}
+	ov.Wrap = !ov.Wrap
+	// an empty write makes the view split its lines again.
+	ov.Write(nil)
+	_, oy := ov.Origin()
+	return ov.SetOrigin(0, oy)
+}
+
+// outScrollRight shifts the unwrapped outputs view to the right
+// while some lines are still cut.
+func outScrollRight(g *gocui.Gui, v *gocui.View) error {
+	if v == nil || v.Wrap {
+		return nil
+	}
+	ox, oy := v.Origin()
+	width, _ := v.Size()
+	longest := 0
+	for _, l := range v.BufferLines() {
+		if n := len([]rune(l)); n > longest {
+			longest = n
+		}
+	}
+	if ox+width >= longest {
+		return nil
+	}
+	return v.SetOrigin(ox+HSCROLLSTEP, oy)
+}
+
+// outScrollLeft shifts the unwrapped outputs view back to the left.
+func outScrollLeft(g *gocui.Gui, v *gocui.View) error {
+	if v == nil || v.Wrap {
+		return nil
+	}
+	ox, oy := v.Origin()
+	if ox == 0 {
+		return nil
+	}
+	ox -= HSCROLLSTEP
+	if ox < 0 {
+		ox = 0
+	}
+	return v.SetOrigin(ox, oy)
+}
+
+// addPing is triggered when Enter or CTRL+P or <P> key is pressed
+// inside IPLIST view. It extracts the exact IP address and add it
+// to the channel <ipToPingChan> for ping scheduler.
+func addPing(g *gocui.Gui, ipv *gocui.View) error {
+	_, cy := ipv.Cursor()
+	l, err := ipv.Line(cy)
+	if err != nil {
+		log.Println("Failed to read current focused ip value:", err)
+		return nil
+	}
+	if len(l) == 0 {
+		return nil
+	}
+	ip := strings.Fields(strings.TrimSpace(l))[1]
+	bus.publish(EVTITLE, fmt.Sprintf(" %sPing [%s] Outputs ", backupIndicator(ip), ip))
+	ipToPingChan <- ip
+	currentOnPingIP = ip
+	currentOutputsIP = ip
+	bus.publish(EVFOCUS, ip)
+	return nil
+}
+
+// addTraceroute is triggered when CTRL+T or <T> key is pressed inside
+// IPLIST view. It extracts the exact IP address and add it to the
+// channel <ipToTraceChan> for traceroute scheduler.
+func addTraceroute(g *gocui.Gui, ipv *gocui.View) error {
+	_, cy := ipv.Cursor()
+	l, err := ipv.Line(cy)
+	if err != nil {
+		log.Println("Failed to read current focused ip value:", err)
+		return nil
+	}
+	if len(l) == 0 {
+		return nil
+	}
+	ip := strings.Fields(strings.TrimSpace(l))[1]
+	bus.publish(EVTITLE, fmt.Sprintf(" %sTraceroute [%s] Outputs ", backupIndicator(ip), ip))
+	ipToTraceChan <- ip
+	// reset since no ping.
+	currentOnPingIP = ""
+	currentOutputsIP = ip
+	return nil
+}
+
+// addMTR is triggered when <M> key is pressed inside IPLIST view. It
+// extracts the exact IP address and add it to the channel <ipToMTRChan>
+// for continuous traceroute scheduler.
+func addMTR(g *gocui.Gui, ipv *gocui.View) error {
+	_, cy := ipv.Cursor()
+	l, err := ipv.Line(cy)
+	if err != nil {
+		log.Println("Failed to read current focused ip value:", err)
+		return nil
+	}
+	if len(l) == 0 {
+		return nil
+	}
+	ip := strings.Fields(strings.TrimSpace(l))[1]
+	bus.publish(EVTITLE, fmt.Sprintf(" MTR [%s] Outputs ", ip))
+	ipToMTRChan <- ip
+	// reset since no ping.
+	currentOnPingIP = ""
+	currentOutputsIP = ip
+	return nil
+}
+
+// drillDownHop is triggered when Enter key is pressed inside OUTPUTS view
+// while it displays a traceroute or MTR hops table. It adds the focused
+// hop address to the list of IPs (if not yet) and starts pinging it.
+func drillDownHop(g *gocui.Gui, ov *gocui.View) error {
+	if !strings.Contains(ov.Title, "Traceroute [") && !strings.Contains(ov.Title, "MTR [") {
+		return nil
+	}
+
+	_, cy := ov.Cursor()
+	l, err := ov.Line(cy)
+	if err != nil {
+		log.Println("Failed to read current focused hop line:", err)
+		return nil
+	}
+
+	ip, ok := parseHopAddress(l)
+	if !ok {
+		return nil
+	}
+
+	dbs.addNewIP(ip)
+	g.Update(func(g *gocui.Gui) error {
+		updateIPsView(g)
+		// move the ips list cursor on the hop address.
+		ipv, err := g.View(IPLIST)
+		if err != nil {
+			return nil
+		}
+		for i, line := range ipv.BufferLines() {
+			if fields := strings.Fields(line); len(fields) == 2 && fields[1] == ip {
+				ipv.SetCursor(0, i)
+			}
+		}
+		return nil
+	})
+
+	bus.publish(EVTITLE, fmt.Sprintf(" %sPing [%s] Outputs ", backupIndicator(ip), ip))
+	ipToPingChan <- ip
+	currentOnPingIP = ip
+	currentOutputsIP = ip
+	bus.publish(EVFOCUS, ip)
+	return nil
+}
+
+// scheduler watches the ping and traceroute jobs channels and spin up
+// a separate ping or traceroute executor. It can clear the outputs view
+// or just cancel any ongoing processing.
+func scheduler() {
+	var ctx context.Context
+	var cancel context.CancelFunc
+	_, cancel = context.WithCancel(probes)
+	// a restarted scheduler does not leave the current job running.
+	defer func() { cancel() }()
+	for {
+		select {
+		case ip := <-ipToPingChan:
+			cancel()
+			bus.publish(EVCLEAROUTPUTS, "")
+			bus.publish(EVCLEARSTATS, "")
+			ctx, cancel = context.WithCancel(probes)
+			pctx := ctx
+			supervise(nil, "ping "+ip, func() { executePing(ip, pctx) })
+		case ip := <-ipToTraceChan:
+			cancel()
+			bus.publish(EVCLEAROUTPUTS, "")
+			bus.publish(EVCLEARSTATS, "")
+			ctx, cancel = context.WithCancel(probes)
+			pctx := ctx
+			supervise(nil, "traceroute "+ip, func() { executeTraceroute(ip, pctx) })
+		case ip := <-ipToMTRChan:
+			cancel()
+			bus.publish(EVCLEAROUTPUTS, "")
+			bus.publish(EVCLEARSTATS, "")
+			ctx, cancel = context.WithCancel(probes)
+			pctx := ctx
+			supervise(nil, "mtr "+ip, func() { executeMTR(ip, pctx) })
+		case ips := <-ipsToMultiTraceChan:
+			cancel()
+			bus.publish(EVCLEAROUTPUTS, "")
+			bus.publish(EVCLEARSTATS, "")
+			ctx, cancel = context.WithCancel(probes)
+			pctx := ctx
+			supervise(nil, "multitrace "+strings.Join(ips, ","), func() { executeMultiTrace(ips, pctx) })
+		case q := <-dnsQueryChan:
+			cancel()
+			bus.publish(EVCLEAROUTPUTS, "")
+			bus.publish(EVCLEARSTATS, "")
+			ctx, cancel = context.WithCancel(probes)
+			pctx := ctx
+			supervise(nil, "dns "+q.name, func() { executeDNSLookup(q, pctx) })
+		case s := <-portScanChan:
+			cancel()
+			bus.publish(EVCLEAROUTPUTS, "")
+			bus.publish(EVCLEARSTATS, "")
+			ctx, cancel = context.WithCancel(probes)
+			pctx := ctx
+			supervise(nil, "portscan "+s.ip, func() { executePortScan(s, pctx) })
+		case c := <-certCheckChan:
+			cancel()
+			bus.publish(EVCLEAROUTPUTS, "")
+			bus.publish(EVCLEARSTATS, "")
+			ctx, cancel = context.WithCancel(probes)
+			pctx := ctx
+			supervise(nil, "cert "+c.ip, func() { executeCertCheck(c, pctx) })
+		case ip := <-wakeChan:
+			cancel()
+			bus.publish(EVCLEAROUTPUTS, "")
+			bus.publish(EVCLEARSTATS, "")
+			ctx, cancel = context.WithCancel(probes)
+			pctx := ctx
+			supervise(nil, "wake "+ip, func() { executeWake(ip, pctx) })
+		case ip := <-neighborChan:
+			cancel()
+			bus.publish(EVCLEAROUTPUTS, "")
+			bus.publish(EVCLEARSTATS, "")
+			ctx, cancel = context.WithCancel(probes)
+			pctx := ctx
+			supervise(nil, "neighbor "+ip, func() { executeNeighborLookup(ip, pctx) })
+		case ip := <-iperfChan:
+			cancel()
+			bus.publish(EVCLEAROUTPUTS, "")
+			bus.publish(EVCLEARSTATS, "")
+			ctx, cancel = context.WithCancel(probes)
+			pctx := ctx
+			supervise(nil, "iperf "+ip, func() { executeIperf(ip, pctx) })
+		case run := <-playbookChan:
+			cancel()
+			bus.publish(EVCLEAROUTPUTS, "")
+			bus.publish(EVCLEARSTATS, "")
+			ctx, cancel = context.WithCancel(probes)
+			pctx := ctx
+			supervise(nil, "playbook "+run.ip, func() { executePlaybook(run, pctx) })
+		case run := <-replayChan:
+			cancel()
+			bus.publish(EVCLEAROUTPUTS, "")
+			bus.publish(EVCLEARSTATS, "")
+			ctx, cancel = context.WithCancel(probes)
+			pctx := ctx
+			supervise(nil, "replay "+run.ip, func() { executeReplay(run, pctx) })
+		case <-stopProcessingChan:
+			cancel()
+		case <-exit:
+			cancel()
+			return
+		}
+
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+// getCurrentTime returns the current datetime in custom format.
+func getCurrentTime() string {
+	t := time.Now()
+	return fmt.Sprintf("%02d:%02d:%02d", t.Hour(), t.Minute(), t.Second())
+}
+
+// executePing runs the full ping command and streams its outputs
+// to the outputs and statistics views.
+func executePing(ip string, ctx context.Context) {
+	// bounded pings show their progress.
+	progress := newJobProgress(fmt.Sprintf(" %sPing [%s] Outputs ", backupIndicator(ip), ip), dbs.getConfig(ip).requests, "")
+	results := 0
+	run := clock.begin()
+	defer clock.end(run)
+	handle := func(e ProbeEvent) {
+		if buildStats(e) {
+			bus.publishProbe(EVSTATS, e)
+		}
+		bus.publish(EVOUTPUT, e.Line)
+		if e.Sample != nil {
+			results++
+			progress.set(results)
+			if e.Sample.Success {
+				clock.replied(run)
+			}
+		}
+	}
+	hub.publishFocus(ip)
+	if notice := overrides.notice(ip); notice != "" {
+		bus.publish(EVOUTPUT, notice)
+	}
+
+	// the daemon pings the targets of an attached ui.
+	if remote != nil {
+		remote.watch(ip, ctx, handle)
+		return
+	}
+	runPing(ip, ctx, handle)
+	if ctx.Err() == nil {
+		progress.complete()
+	}
+}
+
+// newProber builds the engine which pings an ip with its options.
+// It queries a dns server based on the ip probe config, otherwise
+// sends the icmp requests itself if allowed or runs the system ping
+// command. It can be swapped for another Prober implementation such
+// as a fake.
+var newProber = func(ip string) probe.Prober {
+	if cfg := dbs.getConfig(ip); cfg != nil && cfg.probe == "dns" {
+		return buildDNSProber(ip)
+	}
+	if icmpMode != "" {
+		return buildICMPProber(ip)
+	}
+	cfg := dbs.getConfig(ip)
+	return &probe.ExecProber{Target: ip, Cmd: func(ctx context.Context) *exec.Cmd {
+		_, cmd := buildPingCommand(ip, ctx)
+		prepareCommand(cmd)
+		return cmd
+	}, Track: procs.track, ProbeTimeout: probeDeadline(cfg), RunTimeout: pingDeadline(cfg), CodePage: getSettings().CodePage}
+}
+
+// runPing runs the full ping command and calls handle with the event
+// of each output line. It returns once the ping ends or is cancelled.
+func runPing(ip string, ctx context.Context, handle func(e ProbeEvent)) {
+	cfgs := getSettings()
+	var prober probe.Prober
+	if err := startWithRetry(ctx, "ping", ip, func() error {
+		prober = newProber(ip)
+		return prober.Start(ctx)
+	}); err != nil {
+		return
+	}
+	defer prober.Stop()
+
+	// reset this IP stats.
+	dbs.initStats(ip)
+
+	cfg := dbs.getConfig(ip)
+	threshold := cfg.threshold
+	bw := newBackupWriter(ip)
+	defer bw.close()
+	report := newRunReport(ip, threshold, cfg.requests)
+
+	seq := 0
+	for ps := range prober.Results() {
+		e := newProbeEvent(ip, threshold, ps, &seq)
+		if e.Sample != nil {
+			report.add(*e.Sample)
+		}
+		hub.publishOutput(e)
+		handle(e)
+		if err := bw.write(ps.Line); err != nil {
+			probeLog.Error("Failed to backup ping output", "target", ip, "err", err)
+			showError("Failed to backup the ping of %s : %v", ip, err)
+		}
+	}
+
+	report.end = time.Now()
+	if sent, _, _, _, _ := report.profile(); sent > 0 {
+		runs.add(report.summary(ctx.Err() != nil, bw.files()))
+	}
+
+	// bounded ping completed without being stopped.
+	if cfgs.Reports.Enabled && ctx.Err() == nil && report.bounded {
+		if err := report.write(cfgs.Reports.Dir); err != nil {
+			probeLog.Error("Failed to write ping report", "target", ip, "err", err)
+			showError("Failed to write the ping report of %s : %v", ip, err)
+		}
+	}
+}
+
+// executeTraceroute runs the traceroute command.
+func executeTraceroute(ip string, ctx context.Context) {
+	cfgs := getSettings()
+	defer recoverPanic("executeTraceroute")
+	maxhops := dbs.getConfig(ip).maxhops
+	if maxhops <= 0 {
+		maxhops = DEFAULTMAXHOPS
+	}
+	progress := newJobProgress(fmt.Sprintf(" %sTraceroute [%s] Outputs ", backupIndicator(ip), ip), maxhops, "hop")
+
+	// async start, the command is killed after the run deadline.
+	cmd, err := startCommand(ctx, "traceroute", ip, func(ctx context.Context) *exec.Cmd {
+		return buildTracerouteCommand(ip, ctx)
+	})
+	if err != nil {
+		return
+	}
+	defer cmd.cancel()
+
+	done := make(chan error)
+	go func() {
+		err := cmd.Wait()
+		cmd.release()
+		done <- err
+	}()
+
+	// read each line from the pipe content including
+	// the newline char and stream it to data channel.
+	parsed := make(chan struct{})
+	go func() {
+		defer close(parsed)
+		defer recoverPanic("executeTraceroute")
+		var data string
+		var err error
+		bw := newBackupWriter(ip)
+		defer bw.close()
+		tr := newTraceResult(ip)
+		reader := bufio.NewReader(cmd.output)
+		for {
+			data, err = reader.ReadString('\n')
+			if err != nil {
+				// keep only completed runs to compare paths.
+				if ctx.Err() != nil {
+					return
+				}
+				if cmd.ctx.Err() != nil {
+					bus.publish(EVOUTPUT, deadlineMessage("traceroute", ip))
+					return
+				}
+				// refresh the table once hops details are resolved.
+				if enrich != nil {
+					var previous []string
+					if p := traces.get(ip); p != nil {
+						previous = p.addresses()
+					}
+					enrich.resolve(ctx, append(tr.addresses(), previous...))
+				}
+				tr.compare(traces.save(tr))
+				runs.add(tr.summary(bw.files()))
+				if ctx.Err() == nil {
+					bus.publish(EVTABLE, tr.format())
+				}
+				if len(tr.changes) > 0 && cfgs.Alerts.PathChange {
+					sendPathAlert(ip, tr.changes)
+				}
+				progress.complete()
+				return
+			}
+			tr.parse(data)
+			bus.publish(EVTABLE, tr.format())
+			if n := len(tr.hops); n > 0 {
+				progress.set(tr.hops[n-1].number)
+			}
+			if err = bw.write(strings.TrimSpace(data)); err != nil {
+				probeLog.Error("Failed to backup traceroute output", "target", ip, "err", err)
+				showError("Failed to backup the traceroute of %s : %v", ip, err)
+			}
+		}
+	}()
+
+	select {
+	case <-ctx.Done():
+		return
+	case <-done:
+		// wait for the final table of the run.
+		<-parsed
+		return
+	}
+}
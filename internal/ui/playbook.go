@@ -0,0 +1,310 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jroimartin/gocui"
+)
+
+// playbookRun is a playbook requested from the ui.
+type playbookRun struct {
+	ip   string
+	name string
+	// nil when no playbook has this name.
+	playbook *playbookSettings
+}
+
+// playbooks to run.
+var playbookChan = make(chan playbookRun, 1)
+
+// playbookStep is a parsed action of a playbook.
+type playbookStep struct {
+	text string
+	run  func(ip string, ctx context.Context)
+}
+
+// playbookInputView runs the playbook on the focused ip or displays a
+// temporary input box to choose it when several are configured.
+func playbookInputView(g *gocui.Gui, ipv *gocui.View) error {
+	cfgs := getSettings()
+	_, cy := ipv.Cursor()
+	l, err := ipv.Line(cy)
+	if err != nil || len(strings.Fields(l)) < 2 {
+		return nil
+	}
+	ip := strings.Fields(l)[1]
+
+	if len(cfgs.Playbooks) < 2 {
+		name := ""
+		if len(cfgs.Playbooks) == 1 {
+			name = cfgs.Playbooks[0].Name
+		}
+		addPlaybook(ip, name)
+		return nil
+	}
+
+	var names []string
+	for _, p := range cfgs.Playbooks {
+		names = append(names, p.Name)
+	}
+
+	maxX, maxY := g.Size()
+
+	const name = "playbook"
+
+	// construct the input box and position at the center of the screen.
+	if inputView, err := g.SetView(name, maxX/2-35, maxY/2, maxX/2+35, maxY/2+2); err != nil {
+		if err != gocui.ErrUnknownView {
+			log.Println("Failed to display input view: ", err)
+			return err
+		}
+
+		inputView.Title = fmt.Sprintf(" [%s] | Playbook (%s) ", ip, strings.Join(names, ", "))
+		inputView.FgColor = gocui.ColorYellow
+		inputView.SelBgColor = gocui.ColorBlack
+		inputView.SelFgColor = gocui.ColorYellow
+		inputView.Editable = true
+
+		if _, err := g.SetCurrentView(name); err != nil {
+			log.Println(err)
+			return err
+		}
+		g.Cursor = true
+		inputView.Highlight = true
+		// bind Enter key to processInput function.
+		if err := g.SetKeybinding(name, gocui.KeyEnter, gocui.ModNone, processInput); err != nil {
+			log.Println(err)
+			return err
+		}
+
+		// bind Ctrl+Q and Escape keys to close the input box.
+		if err := g.SetKeybinding(name, gocui.KeyCtrlQ, gocui.ModNone, closeInputView); err != nil {
+			log.Println(err)
+			return err
+		}
+
+		if err := g.SetKeybinding(name, gocui.KeyEsc, gocui.ModNone, closeInputView); err != nil {
+			log.Println(err)
+			return err
+		}
+
+		inputView.Write([]byte(names[0]))
+		inputView.SetCursor(len(inputView.Buffer())-1, 0)
+	}
+	return nil
+}
+
+// addPlaybook sends the playbook named name to the scheduler.
+func addPlaybook(ip, name string) {
+	cfgs := getSettings()
+	name = strings.TrimSpace(name)
+	run := playbookRun{ip: ip, name: name}
+	for i, p := range cfgs.Playbooks {
+		if strings.EqualFold(p.Name, name) {
+			run.playbook = &cfgs.Playbooks[i]
+		}
+	}
+
+	bus.publish(EVTITLE, fmt.Sprintf(" Playbook %s [%s] Outputs ", name, ip))
+	playbookChan <- run
+	// reset since no ping.
+	currentOnPingIP = ""
+	currentOutputsIP = ip
+}
+
+// parsePlaybook checks all the steps of a playbook before any runs.
+func parsePlaybook(p playbookSettings) ([]playbookStep, error) {
+	cfgs := getSettings()
+	if len(p.Steps) == 0 {
+		return nil, errors.New("no steps")
+	}
+	var steps []playbookStep
+	for _, text := range p.Steps {
+		fields := strings.Fields(text)
+		if len(fields) == 0 {
+			continue
+		}
+		args := strings.Join(fields[1:], " ")
+		step := playbookStep{text: strings.Join(fields, " ")}
+		switch strings.ToLower(fields[0]) {
+		case "ping":
+			count := 10
+			if args != "" {
+				n, err := strconv.Atoi(args)
+				if err != nil || n <= 0 {
+					return nil, errors.New("bad ping count " + args)
+				}
+				count = n
+			}
+			step.run = func(ip string, ctx context.Context) { runPlaybookPing(ip, count, ctx) }
+		case "trace", "traceroute":
+			step.run = executeTraceroute
+		case "dns":
+			step.run = func(ip string, ctx context.Context) {
+				q, _ := parseDNSQuery(ip + " " + args)
+				executeDNSLookup(q, ctx)
+			}
+		case "scan":
+			list := args
+			if list == "" {
+				list = cfgs.Scan.Ports
+			}
+			ports, err := parsePorts(list)
+			if err != nil {
+				return nil, err
+			}
+			step.run = func(ip string, ctx context.Context) { executePortScan(portScan{ip: ip, ports: ports}, ctx) }
+		case "cert":
+			port, sni := "443", ""
+			if len(fields) > 1 {
+				port = fields[1]
+			}
+			if len(fields) > 2 {
+				sni = fields[2]
+			}
+			// validated with a placeholder address.
+			if _, err := parseCertCheck(net.JoinHostPort("127.0.0.1", port)); err != nil {
+				return nil, err
+			}
+			step.run = func(ip string, ctx context.Context) {
+				c, _ := parseCertCheck(net.JoinHostPort(ip, port) + " " + sni)
+				executeCertCheck(c, ctx)
+			}
+		case "arp", "neighbor":
+			step.run = executeNeighborLookup
+		default:
+			return nil, errors.New("unknown action " + fields[0])
+		}
+		steps = append(steps, step)
+	}
+	return steps, nil
+}
+
+// executePlaybook runs in order the steps of a playbook against an
+// ip then saves their combined outputs into the reports directory.
+func executePlaybook(run playbookRun, ctx context.Context) {
+	cfgs := getSettings()
+	defer recoverPanic("executePlaybook")
+	if len(cfgs.Playbooks) == 0 {
+		bus.publish(EVOUTPUT, "No playbook configured. Add some into the playbooks settings.")
+		return
+	}
+	if run.playbook == nil {
+		bus.publish(EVOUTPUT, fmt.Sprintf("Unknown playbook %q.", run.name))
+		return
+	}
+	steps, err := parsePlaybook(*run.playbook)
+	if err != nil {
+		bus.publish(EVOUTPUT, fmt.Sprintf("Invalid playbook %q : %v", run.name, err))
+		return
+	}
+
+	// collect the outputs of each step.
+	capture := bus.subscribe(cfgs.OutputLines, EVOUTPUT, EVTABLE)
+	defer bus.unsubscribe(capture)
+
+	start := time.Now()
+	progress := newJobProgress(fmt.Sprintf(" Playbook %s [%s] Outputs ", run.name, run.ip), len(steps), "step")
+	var lines []string
+	var restored string
+	for i, step := range steps {
+		if i > 0 {
+			bus.publish(EVOUTPUT, "")
+		}
+		// the steps tools may have changed the title.
+		progress.set(i + 1)
+		bus.publish(EVOUTPUT, fmt.Sprintf("===== [%d/%d] %s %s =====", i+1, len(steps), step.text, run.ip))
+		step.run(run.ip, ctx)
+		if ctx.Err() != nil {
+			return
+		}
+
+		var table string
+		for _, e := range capture.drain() {
+			switch {
+			case e.kind == EVOUTPUT:
+				lines = append(lines, e.text)
+			case e.text != restored:
+				table = e.text
+			}
+		}
+		if table != "" {
+			lines = append(lines, strings.Split(strings.TrimRight(table, "\n"), "\n")...)
+			// the table replaced the outputs of the previous steps.
+			restored = strings.Join(lines, "\n") + "\n"
+			bus.publish(EVTABLE, restored)
+		}
+	}
+
+	progress.complete()
+	path, err := writePlaybookReport(run, start, lines)
+	if err != nil {
+		bus.publish(EVOUTPUT, "Failed to save the playbook report : "+err.Error())
+		return
+	}
+	bus.publish(EVOUTPUT, fmt.Sprintf("Completed in %s : report saved into %s", time.Since(start).Round(time.Second), path))
+}
+
+// runPlaybookPing pings an ip until count results are received and
+// feeds the statistics view. The ping is bounded here since the
+// probes have no count of their own when the requests config is 0.
+func runPlaybookPing(ip string, count int, ctx context.Context) {
+	// a reply per second and some slack for the failures.
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(count+5)*time.Second)
+	defer cancel()
+
+	prober := newProber(ip)
+	if err := prober.Start(ctx); err != nil {
+		bus.publish(EVOUTPUT, "Failed to start ping : "+err.Error())
+		return
+	}
+	defer prober.Stop()
+
+	dbs.initStats(ip)
+	threshold := dbs.getConfig(ip).threshold
+	results := 0
+	for ps := range prober.Results() {
+		e := newProbeEvent(ip, threshold, ps, &results)
+		if buildStats(e) {
+			bus.publishProbe(EVSTATS, e)
+		}
+		bus.publish(EVOUTPUT, e.Line)
+		if e.Sample != nil {
+			if results == count {
+				cancel()
+			}
+		}
+	}
+}
+
+// writePlaybookReport saves the outputs of a playbook run.
+func writePlaybookReport(run playbookRun, start time.Time, lines []string) (string, error) {
+	cfgs := getSettings()
+	dir := cfgs.Reports.Dir
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "playbook  : %s\n", run.playbook.Name)
+	fmt.Fprintf(&b, "target    : %s\n", run.ip)
+	fmt.Fprintf(&b, "started   : %s\n", start.Format("2006-01-02 15:04:05"))
+	fmt.Fprintf(&b, "duration  : %s\n", time.Since(start).Round(time.Second))
+	fmt.Fprintf(&b, "steps     : %s\n\n", strings.Join(run.playbook.Steps, " | "))
+	b.WriteString(strings.Join(lines, "\n") + "\n")
+
+	name := fmt.Sprintf("playbook_%s_%s_%s.txt", strings.Replace(run.playbook.Name, " ", "-", -1),
+		strings.Replace(run.ip, ":", "-", -1), start.Format("20060102-150405"))
+	path := filepath.Join(dir, name)
+	return path, ioutil.WriteFile(path, []byte(b.String()), 0644)
+}
@@ -0,0 +1,218 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jroimartin/gocui"
+)
+
+// most common tcp ports (nmap top 100) scanned by default.
+var topPorts = []int{7, 9, 13, 21, 22, 23, 25, 26, 37, 53, 79, 80, 81, 88, 106, 110, 111, 113, 119, 135,
+	139, 143, 144, 179, 199, 389, 427, 443, 444, 445, 465, 513, 514, 515, 543, 544, 548, 554, 587, 631,
+	646, 873, 990, 993, 995, 1025, 1026, 1027, 1028, 1029, 1110, 1433, 1720, 1723, 1755, 1900, 2000, 2001, 2049, 2121,
+	2717, 3000, 3128, 3306, 3389, 3986, 4899, 5000, 5009, 5051, 5060, 5101, 5190, 5357, 5432, 5631, 5666, 5800, 5900, 6000,
+	6001, 6646, 7070, 8000, 8008, 8009, 8080, 8081, 8443, 8888, 9100, 9999, 10000, 32768, 49152, 49153, 49154, 49155, 49156, 49157}
+
+// usual services names of the well-known ports.
+var portServices = map[int]string{
+	21: "ftp", 22: "ssh", 23: "telnet", 25: "smtp", 53: "domain", 80: "http", 88: "kerberos", 110: "pop3",
+	111: "rpcbind", 135: "msrpc", 139: "netbios-ssn", 143: "imap", 179: "bgp", 389: "ldap", 443: "https",
+	445: "microsoft-ds", 465: "smtps", 514: "shell", 515: "printer", 548: "afp", 554: "rtsp", 587: "submission",
+	631: "ipp", 873: "rsync", 993: "imaps", 995: "pop3s", 1433: "ms-sql", 1723: "pptp", 2049: "nfs",
+	3306: "mysql", 3389: "ms-wbt-server", 5060: "sip", 5432: "postgresql", 5900: "vnc", 6000: "x11",
+	8080: "http-proxy", 8443: "https-alt", 9100: "jetdirect",
+}
+
+// portScan is a scan requested from the ui.
+type portScan struct {
+	ip    string
+	ports []int
+}
+
+// port scans to run.
+var portScanChan = make(chan portScan, 1)
+
+// portScanInputView displays a temporary input box to enter the
+// ports to scan on the focused ip.
+func portScanInputView(g *gocui.Gui, ipv *gocui.View) error {
+	cfgs := getSettings()
+	_, cy := ipv.Cursor()
+	l, err := ipv.Line(cy)
+	if err != nil || len(strings.Fields(l)) < 2 {
+		return nil
+	}
+	ip := strings.Fields(l)[1]
+
+	maxX, maxY := g.Size()
+
+	const name = "portScan"
+
+	// construct the input box and position at the center of the screen.
+	if inputView, err := g.SetView(name, maxX/2-35, maxY/2, maxX/2+35, maxY/2+2); err != nil {
+		if err != gocui.ErrUnknownView {
+			log.Println("Failed to display input view: ", err)
+			return err
+		}
+
+		inputView.Title = fmt.Sprintf(" [%s] | Ports to Scan (top100 or 22,80,8000-8100) ", ip)
+		inputView.FgColor = gocui.ColorYellow
+		inputView.SelBgColor = gocui.ColorBlack
+		inputView.SelFgColor = gocui.ColorYellow
+		inputView.Editable = true
+
+		if _, err := g.SetCurrentView(name); err != nil {
+			log.Println(err)
+			return err
+		}
+		g.Cursor = true
+		inputView.Highlight = true
+		// bind Enter key to processInput function.
+		if err := g.SetKeybinding(name, gocui.KeyEnter, gocui.ModNone, processInput); err != nil {
+			log.Println(err)
+			return err
+		}
+
+		// bind Ctrl+Q and Escape keys to close the input box.
+		if err := g.SetKeybinding(name, gocui.KeyCtrlQ, gocui.ModNone, closeInputView); err != nil {
+			log.Println(err)
+			return err
+		}
+
+		if err := g.SetKeybinding(name, gocui.KeyEsc, gocui.ModNone, closeInputView); err != nil {
+			log.Println(err)
+			return err
+		}
+
+		inputView.Write([]byte(cfgs.Scan.Ports))
+		inputView.SetCursor(len(inputView.Buffer())-1, 0)
+	}
+	return nil
+}
+
+// addPortScan parses the entered ports and sends the scan to the scheduler.
+func addPortScan(ip, input string) {
+	ports, err := parsePorts(input)
+	if err != nil {
+		bus.publish(EVOUTPUT, "Invalid ports list : "+err.Error())
+		return
+	}
+
+	bus.publish(EVTITLE, fmt.Sprintf(" Port Scan [%s] Outputs ", ip))
+	portScanChan <- portScan{ip: ip, ports: ports}
+	// reset since no ping.
+	currentOnPingIP = ""
+	currentOutputsIP = ip
+}
+
+// parsePorts expands a comma-separated list of ports and
+// ranges. The top100 keyword stands for the common ports.
+func parsePorts(input string) ([]int, error) {
+	seen := make(map[int]bool)
+	var ports []int
+	add := func(p int) {
+		if !seen[p] {
+			seen[p] = true
+			ports = append(ports, p)
+		}
+	}
+
+	for _, item := range strings.Split(input, ",") {
+		item = strings.TrimSpace(item)
+		switch {
+		case item == "":
+			continue
+		case strings.EqualFold(item, "top100"):
+			for _, p := range topPorts {
+				add(p)
+			}
+		case strings.Contains(item, "-"):
+			bounds := strings.SplitN(item, "-", 2)
+			from, err1 := strconv.Atoi(strings.TrimSpace(bounds[0]))
+			to, err2 := strconv.Atoi(strings.TrimSpace(bounds[1]))
+			if err1 != nil || err2 != nil || from < 1 || to > 65535 || from > to {
+				return nil, errors.New("bad range " + item)
+			}
+			for p := from; p <= to; p++ {
+				add(p)
+			}
+		default:
+			p, err := strconv.Atoi(item)
+			if err != nil || p < 1 || p > 65535 {
+				return nil, errors.New("bad port " + item)
+			}
+			add(p)
+		}
+	}
+
+	if len(ports) == 0 {
+		return nil, errors.New("no ports")
+	}
+	return ports, nil
+}
+
+// executePortScan connects to each port with at most cfgs.Scan.Parallel
+// concurrent attempts and lists the open ones into the outputs view.
+func executePortScan(s portScan, ctx context.Context) {
+	cfgs := getSettings()
+	defer recoverPanic("executePortScan")
+	timeout := time.Duration(cfgs.Scan.Timeout) * time.Millisecond
+	bus.publish(EVOUTPUT, fmt.Sprintf("Scanning %d tcp ports of %s (%d in parallel, %s timeout) ...",
+		len(s.ports), s.ip, cfgs.Scan.Parallel, timeout))
+
+	start := time.Now()
+	slots := make(chan struct{}, cfgs.Scan.Parallel)
+	var lock sync.Mutex
+	var open []int
+	var swg sync.WaitGroup
+
+	for _, port := range s.ports {
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+			swg.Wait()
+			return
+		}
+
+		swg.Add(1)
+		go func(port int) {
+			defer swg.Done()
+			defer func() { <-slots }()
+			d := net.Dialer{Timeout: timeout}
+			conn, err := d.DialContext(ctx, "tcp", net.JoinHostPort(s.ip, strconv.Itoa(port)))
+			if err != nil {
+				return
+			}
+			conn.Close()
+			bus.publish(EVOUTPUT, formatOpenPort(port))
+			lock.Lock()
+			open = append(open, port)
+			lock.Unlock()
+		}(port)
+	}
+	swg.Wait()
+
+	if ctx.Err() != nil {
+		return
+	}
+	sort.Ints(open)
+	var list []string
+	for _, p := range open {
+		list = append(list, strconv.Itoa(p))
+	}
+	bus.publish(EVOUTPUT, fmt.Sprintf("Completed in %s : %d open ports out of %d scanned. %s",
+		time.Since(start).Round(time.Millisecond), len(open), len(s.ports), strings.Join(list, ",")))
+}
+
+// formatOpenPort returns the line displayed for an open port.
+func formatOpenPort(port int) string {
+	return strings.TrimSpace(fmt.Sprintf("%-10s open  %s", strconv.Itoa(port)+"/tcp", portServices[port]))
+}
@@ -0,0 +1,136 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+
+	"github.com/jeamon/pingo/internal/probe"
+)
+
+// first and longest waits between two launches of a command which
+// failed to start.
+const (
+	RETRYMINDELAY = time.Second
+	RETRYMAXDELAY = time.Minute
+)
+
+// processes keeps the running external commands (ping, traceroute)
+// so they are all killed on exit, with their own children, even if
+// their probe was not stopped.
+type processes struct {
+	lock    *sync.Mutex
+	running map[*os.Process]struct{}
+}
+
+// running external commands.
+var procs = &processes{lock: &sync.Mutex{}, running: make(map[*os.Process]struct{})}
+
+// startProcess starts a command into its own process group (job object
+// on windows) and tracks it. The returned function must be called once
+// the command was waited.
+func startProcess(cmd *exec.Cmd) (func(), error) {
+	prepareCommand(cmd)
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	return procs.track(cmd), nil
+}
+
+// track registers a started command and returns the function
+// which removes it once exited.
+func (ps *processes) track(cmd *exec.Cmd) func() {
+	p := cmd.Process
+	attachProcess(p)
+	ps.lock.Lock()
+	ps.running[p] = struct{}{}
+	ps.lock.Unlock()
+	return func() {
+		ps.lock.Lock()
+		delete(ps.running, p)
+		ps.lock.Unlock()
+	}
+}
+
+// killAll kills all running commands and their children.
+func (ps *processes) killAll() {
+	ps.lock.Lock()
+	defer ps.lock.Unlock()
+	for p := range ps.running {
+		killProcessTree(p)
+	}
+}
+
+// startWithRetry calls start until it succeeds or ctx is done. Each
+// failure (program missing, permission denied) is shown into the
+// outputs view then retried after a delay doubled up to RETRYMAXDELAY.
+// It returns the last error once ctx is done.
+func startWithRetry(ctx context.Context, name, ip string, start func() error) error {
+	delay := RETRYMINDELAY
+	for {
+		err := start()
+		if err == nil {
+			return nil
+		}
+		probeLog.Error("Failed to start "+name, "target", ip, "err", err, "retry", delay)
+		showError("Failed to start the %s of %s : %v", name, ip, err)
+		bus.publish(EVOUTPUT, fmt.Sprintf("Failed to start the %s of %s : %v. Retrying in %s.", name, ip, err, delay))
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(delay):
+		}
+		if delay *= 2; delay > RETRYMAXDELAY {
+			delay = RETRYMAXDELAY
+		}
+	}
+}
+
+// decodeOutput converts into UTF-8 the outputs of the system commands
+// printed with the code page of the settings, so localized messages
+// and non-ascii names render correctly.
+func decodeOutput(r io.Reader) io.Reader {
+	cfgs := getSettings()
+	return probe.Decoder(r, cfgs.CodePage)
+}
+
+// command is a started external command bounded by the run deadline.
+type command struct {
+	*exec.Cmd
+	// done once stopped or after the run deadline.
+	ctx    context.Context
+	cancel context.CancelFunc
+	// combined outputs decoded into UTF-8.
+	output io.Reader
+	// must be called once the command was waited.
+	release func()
+}
+
+// startCommand starts the command made by build with the run deadline
+// and retries while it fails to start.
+func startCommand(ctx context.Context, name, ip string, build func(context.Context) *exec.Cmd) (*command, error) {
+	c := &command{}
+	err := startWithRetry(ctx, name, ip, func() error {
+		var err error
+		c.ctx, c.cancel = withRunDeadline(ctx)
+		c.Cmd = build(c.ctx)
+		c.Stderr = c.Stdout
+		var outpipe io.Reader
+		if outpipe, err = c.StdoutPipe(); err == nil {
+			c.output = decodeOutput(outpipe)
+			c.release, err = startProcess(c.Cmd)
+		}
+		if err != nil {
+			c.cancel()
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
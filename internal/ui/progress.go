@@ -0,0 +1,139 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// default maximum hops of the traceroute and tracert commands.
+const DEFAULTMAXHOPS = 30
+
+// jobProgress tracks the completion of a bounded job to display it
+// into the outputs view title, so a hung job can be told apart from
+// a job still working.
+type jobProgress struct {
+	// title of the outputs view without progress.
+	title string
+	total int
+	done  int
+	start time.Time
+	// the job reports its steps (traceroute hops) and has no ETA.
+	unit string
+}
+
+// newJobProgress starts tracking a job of total steps.
+func newJobProgress(title string, total int, unit string) *jobProgress {
+	return &jobProgress{title: strings.TrimRight(title, " "), total: total, start: time.Now(), unit: unit}
+}
+
+// set records the steps done and refreshes the title.
+func (p *jobProgress) set(done int) {
+	if p.total <= 0 || done == p.done {
+		return
+	}
+	p.done = done
+	bus.publish(EVTITLE, p.format(false))
+}
+
+// complete marks the job as ended without being stopped.
+func (p *jobProgress) complete() {
+	if p.total <= 0 {
+		return
+	}
+	bus.publish(EVTITLE, p.format(true))
+}
+
+// format returns the title followed by the progress :
+// " Ping [ip] Outputs | 23/100 (23%) ETA 1m17s " or
+// " Traceroute [ip] Outputs | hop 7/30 ".
+func (p *jobProgress) format(completed bool) string {
+	var progress string
+	if p.unit != "" {
+		progress = fmt.Sprintf("%s %d/%d", p.unit, p.done, p.total)
+	} else {
+		progress = fmt.Sprintf("%d/%d (%d%%)", p.done, p.total, p.done*100/p.total)
+	}
+	switch {
+	case completed:
+		progress += " done"
+	case p.unit == "" && p.done > 0 && p.done < p.total:
+		elapsed := time.Since(p.start)
+		eta := elapsed / time.Duration(p.done) * time.Duration(p.total-p.done)
+		progress += " ETA " + eta.Round(time.Second).String()
+	}
+	return fmt.Sprintf("%s | %s ", p.title, progress)
+}
+
+// probeClock follows the ping displayed into the outputs view to tell
+// how long it is running and since when its target did not reply.
+type probeClock struct {
+	lock *sync.Mutex
+	// identifies the active ping so a stopped one cannot end the next.
+	run   int
+	start time.Time
+	reply time.Time
+}
+
+// active ping clock shown into the status bar.
+var clock = &probeClock{lock: &sync.Mutex{}}
+
+// begin starts timing a new ping and returns its identifier.
+func (c *probeClock) begin() int {
+	c.lock.Lock()
+	c.run++
+	c.start, c.reply = time.Now(), time.Time{}
+	run := c.run
+	c.lock.Unlock()
+	refreshStatus()
+	return run
+}
+
+// replied records a successful reply of the ping run.
+func (c *probeClock) replied(run int) {
+	c.lock.Lock()
+	if c.run == run {
+		c.reply = time.Now()
+	}
+	c.lock.Unlock()
+}
+
+// end stops timing the ping run unless another one started meanwhile.
+func (c *probeClock) end(run int) {
+	c.lock.Lock()
+	if c.run == run {
+		c.start = time.Time{}
+	}
+	c.lock.Unlock()
+	refreshStatus()
+}
+
+// format returns the elapsed time of the active ping and the age of
+// its last reply : "5m12s | reply 3s". It is empty without ping.
+func (c *probeClock) format() string {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.start.IsZero() {
+		return ""
+	}
+	if c.reply.IsZero() {
+		return shortDuration(time.Since(c.start)) + " | no reply"
+	}
+	return shortDuration(time.Since(c.start)) + " | reply " + shortDuration(time.Since(c.reply))
+}
+
+// shortDuration formats a duration with its two largest units.
+func shortDuration(d time.Duration) string {
+	s := int(d.Seconds())
+	switch {
+	case s < 60:
+		return fmt.Sprintf("%ds", s)
+	case s < 3600:
+		return fmt.Sprintf("%dm%02ds", s/60, s%60)
+	case s < 86400:
+		return fmt.Sprintf("%dh%02dm", s/3600, s%3600/60)
+	default:
+		return fmt.Sprintf("%dd%02dh", s/86400, s%86400/3600)
+	}
+}
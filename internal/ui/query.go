@@ -0,0 +1,252 @@
+package ui
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/jroimartin/gocui"
+)
+
+// term of a filter query : a field, a comparison and a value.
+var queryTerm = regexp.MustCompile(`^([a-z]+)(>=|<=|!=|=|>|<)(.+)$`)
+
+// fields of the statistics a filter query can test, with the unit of
+// their values.
+var queryFields = map[string]string{
+	"ip":        "",
+	"subnet":    "",
+	"state":     "",
+	"tag":       "",
+	"loss":      "%",
+	"min":       "ms",
+	"avg":       "ms",
+	"max":       "ms",
+	"last":      "ms",
+	"fails":     "",
+	"sent":      "",
+	"score":     "",
+	"anomalies": "",
+}
+
+// condition is a term of a filter query.
+type condition struct {
+	field string
+	op    string
+	text  string
+	value float64
+}
+
+// ipsFilter holds the query filtering the targets of the ips list.
+type ipsFilter struct {
+	lock       *sync.RWMutex
+	query      string
+	conditions []condition
+}
+
+// global filter of the ips list.
+var filter = &ipsFilter{lock: &sync.RWMutex{}}
+
+// parseQuery reads the space or comma separated terms of a query like
+// "loss>1 avg>100ms state=down". All terms must match.
+func parseQuery(query string) ([]condition, error) {
+	var conditions []condition
+	for _, term := range strings.FieldsFunc(strings.ToLower(query), func(r rune) bool { return unicode.IsSpace(r) || r == ',' }) {
+		m := queryTerm.FindStringSubmatch(term)
+		if m == nil {
+			return nil, fmt.Errorf("invalid term %s (expect field, operator and value)", term)
+		}
+		c := condition{field: m[1], op: m[2], text: m[3]}
+		unit, ok := queryFields[c.field]
+		if !ok {
+			return nil, fmt.Errorf("unknown field %s (use ip, subnet, state, tag, loss, min, avg, max, last, fails, sent, anomalies or score)", c.field)
+		}
+
+		switch c.field {
+		case "ip", "subnet", "state", "tag":
+			if c.op != "=" && c.op != "!=" {
+				return nil, fmt.Errorf("invalid operator %s for %s (use = or !=)", c.op, c.field)
+			}
+			conditions = append(conditions, c)
+			continue
+		case "min", "avg", "max", "last":
+			if d, err := time.ParseDuration(c.text); err == nil {
+				c.value = float64(d) / float64(time.Millisecond)
+				conditions = append(conditions, c)
+				continue
+			}
+		}
+
+		v, err := strconv.ParseFloat(strings.TrimSuffix(c.text, unit), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value %s for %s", c.text, c.field)
+		}
+		c.value = v
+		conditions = append(conditions, c)
+	}
+	return conditions, nil
+}
+
+// matches tells whether the statistics of an ip satisfy the condition.
+func (c condition) matches(ip string, s *stat) bool {
+	var value float64
+	switch c.field {
+	case "ip":
+		return strings.HasPrefix(ip, c.text) == (c.op == "=")
+	case "subnet":
+		return (subnetOf(ip) == c.text) == (c.op == "=")
+	case "state":
+		return (s.state == c.text) == (c.op == "=")
+	case "tag":
+		cfg := dbs.getConfig(ip)
+		return (cfg != nil && cfg.hasTag(c.text)) == (c.op == "=")
+	case "loss":
+		value = s.loss()
+	case "min":
+		value = float64(s.min)
+	case "avg":
+		value = float64(s.avg)
+	case "max":
+		value = float64(s.max)
+	case "last":
+		value = float64(s.last)
+	case "fails":
+		value = float64(s.fails)
+	case "sent":
+		value = float64(s.fails + s.replies())
+	case "anomalies":
+		value = float64(s.anomalies)
+	case "score":
+		value = float64(healthScore(ip))
+	}
+
+	switch c.op {
+	case ">":
+		return value > c.value
+	case ">=":
+		return value >= c.value
+	case "<":
+		return value < c.value
+	case "<=":
+		return value <= c.value
+	case "!=":
+		return value != c.value
+	}
+	return value == c.value
+}
+
+// set replaces the filter query. An empty query shows all targets.
+func (f *ipsFilter) set(query string) error {
+	conditions, err := parseQuery(query)
+	if err != nil {
+		return err
+	}
+	f.lock.Lock()
+	f.query, f.conditions = strings.TrimSpace(query), conditions
+	f.lock.Unlock()
+	refreshIPs()
+	return nil
+}
+
+// current returns the filter query.
+func (f *ipsFilter) current() string {
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+	return f.query
+}
+
+// active tells whether the ips list is filtered.
+func (f *ipsFilter) active() bool {
+	return f.current() != ""
+}
+
+// apply returns the ips matching all conditions of the query.
+func (f *ipsFilter) apply(ips []string) []string {
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+	if f.query == "" {
+		return ips
+	}
+	var selected []string
+	for _, ip := range ips {
+		s := dbs.getStats(ip)
+		if s == nil {
+			continue
+		}
+		ok := true
+		for _, c := range f.conditions {
+			if !c.matches(ip, s) {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			selected = append(selected, ip)
+		}
+	}
+	return selected
+}
+
+// filterInputView displays a temporary input box to enter the query
+// filtering the ips list.
+func filterInputView(g *gocui.Gui, cv *gocui.View) error {
+	maxX, maxY := g.Size()
+
+	const name = "filter"
+
+	// construct the input box and position at the center of the screen.
+	if inputView, err := g.SetView(name, maxX/2-35, maxY/2, maxX/2+35, maxY/2+2); err != nil {
+		if err != gocui.ErrUnknownView {
+			log.Println("Failed to display input view: ", err)
+			return err
+		}
+
+		inputView.Title = " Filter (e.g. loss>1 avg>100ms state=down) - Empty For All "
+		inputView.FgColor = gocui.ColorYellow
+		inputView.SelBgColor = gocui.ColorBlack
+		inputView.SelFgColor = gocui.ColorYellow
+		inputView.Editable = true
+
+		if _, err := g.SetCurrentView(name); err != nil {
+			log.Println(err)
+			return err
+		}
+		g.Cursor = true
+		inputView.Highlight = true
+		// bind Enter key to processInput function.
+		if err := g.SetKeybinding(name, gocui.KeyEnter, gocui.ModNone, processInput); err != nil {
+			log.Println(err)
+			return err
+		}
+
+		// bind Ctrl+Q and Escape keys to close the input box.
+		if err := g.SetKeybinding(name, gocui.KeyCtrlQ, gocui.ModNone, closeInputView); err != nil {
+			log.Println(err)
+			return err
+		}
+
+		if err := g.SetKeybinding(name, gocui.KeyEsc, gocui.ModNone, closeInputView); err != nil {
+			log.Println(err)
+			return err
+		}
+
+		// bind Up and Down keys to recall the recent values.
+		if err := g.SetKeybinding(name, gocui.KeyArrowUp, gocui.ModNone, recallInput(true)); err != nil {
+			log.Println(err)
+			return err
+		}
+
+		if err := g.SetKeybinding(name, gocui.KeyArrowDown, gocui.ModNone, recallInput(false)); err != nil {
+			log.Println(err)
+			return err
+		}
+
+		setInputText(inputView, filter.current())
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+package ui
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// policies of the samples queue once full.
+const (
+	// the probes wait for the sinks.
+	POLICYBLOCK = "block"
+	// the oldest queued sample is dropped for the new one.
+	POLICYDROPOLDEST = "drop-oldest"
+	// the new sample is dropped.
+	POLICYDROPNEWEST = "drop-newest"
+)
+
+// workerPool bounds the number of targets probed at once. A
+// pool without slots lets all targets be probed at once.
+type workerPool struct {
+	slots chan struct{}
+	// probes waiting for a free slot.
+	waiting int64
+}
+
+// pool of the probe workers without ui.
+var workers = newWorkerPool(0)
+
+// newWorkerPool builds a pool of size workers, unbounded if 0.
+func newWorkerPool(size int) *workerPool {
+	wp := &workerPool{}
+	if size > 0 {
+		wp.slots = make(chan struct{}, size)
+	}
+	return wp
+}
+
+// acquire waits for a free slot. It returns false once ctx is done.
+func (wp *workerPool) acquire(ctx context.Context) bool {
+	if wp.slots == nil {
+		return ctx.Err() == nil
+	}
+	atomic.AddInt64(&wp.waiting, 1)
+	defer atomic.AddInt64(&wp.waiting, -1)
+	select {
+	case wp.slots <- struct{}{}:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// release frees the slot of a probe.
+func (wp *workerPool) release() {
+	if wp.slots != nil {
+		<-wp.slots
+	}
+}
+
+// usage returns the busy and total slots (0 if unbounded)
+// and the number of probes waiting for a slot.
+func (wp *workerPool) usage() (int, int, int64) {
+	return len(wp.slots), cap(wp.slots), atomic.LoadInt64(&wp.waiting)
+}
+
+// initQueues sizes the probe workers pool and the samples and alerts
+// queues from the settings. It must be called once they are loaded
+// and before any probe starts.
+func initQueues() {
+	cfgs := getSettings()
+	workers = newWorkerPool(cfgs.Queues.Workers)
+	samplesChan = make(chan sample, cfgs.Queues.Samples)
+	alertsChan = make(chan alert, cfgs.Queues.Alerts)
+}
+
+// queueSample hands a sample to the sinks dispatcher following the
+// policy of the samples queue once full.
+func queueSample(sp sample) {
+	cfgs := getSettings()
+	switch cfgs.Queues.Policy {
+	case POLICYBLOCK:
+		select {
+		case samplesChan <- sp:
+			count(&counters.samples)
+			return
+		default:
+		}
+		count(&counters.blockedSamples)
+		select {
+		case samplesChan <- sp:
+			count(&counters.samples)
+		case <-exit:
+			// the dispatcher may have drained the queue already.
+			count(&counters.droppedSamples)
+		}
+
+	case POLICYDROPOLDEST:
+		for {
+			select {
+			case samplesChan <- sp:
+				count(&counters.samples)
+				return
+			default:
+			}
+			select {
+			case old := <-samplesChan:
+				count(&counters.droppedSamples)
+				sinksLog.Warn("Samples queue full, dropped oldest sample", "target", old.ip)
+			default:
+			}
+		}
+
+	default:
+		select {
+		case samplesChan <- sp:
+			count(&counters.samples)
+		default:
+			count(&counters.droppedSamples)
+			sinksLog.Warn("Samples queue full, dropped sample", "target", sp.ip)
+		}
+	}
+}
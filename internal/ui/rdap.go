@@ -0,0 +1,284 @@
+package ui
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jroimartin/gocui"
+)
+
+const (
+	RDAP = "rdap"
+
+	RWIDTH  = 80
+	RHEIGHT = 14
+)
+
+// rdapInfo holds the ownership details of an address.
+type rdapInfo struct {
+	prefix  string
+	rangeOf string
+	network string
+	org     string
+	country string
+	asn     string
+	abuse   string
+	source  string
+}
+
+// rdapNetwork is the subset of an RDAP ip network response used.
+type rdapNetwork struct {
+	Handle       string       `json:"handle"`
+	Name         string       `json:"name"`
+	Country      string       `json:"country"`
+	StartAddress string       `json:"startAddress"`
+	EndAddress   string       `json:"endAddress"`
+	Cidrs        []rdapCidr   `json:"cidr0_cidrs"`
+	Entities     []rdapEntity `json:"entities"`
+}
+
+type rdapCidr struct {
+	V4Prefix string `json:"v4prefix"`
+	V6Prefix string `json:"v6prefix"`
+	Length   int    `json:"length"`
+}
+
+type rdapEntity struct {
+	Roles    []string        `json:"roles"`
+	VCard    json.RawMessage `json:"vcardArray"`
+	Entities []rdapEntity    `json:"entities"`
+}
+
+// rdapCache keeps the lookups results since ownership rarely changes.
+var rdapCache = struct {
+	lock  *sync.Mutex
+	infos map[string]*rdapInfo
+}{lock: &sync.Mutex{}, infos: make(map[string]*rdapInfo)}
+
+// displayRDAPView fetches in background the ownership details of the
+// focused ip or traceroute hop and shows them into a popup.
+func displayRDAPView(g *gocui.Gui, cv *gocui.View) error {
+	_, cy := cv.Cursor()
+	l, err := cv.Line(cy)
+	if err != nil {
+		return nil
+	}
+
+	var ip string
+	if cv.Name() == IPLIST {
+		if fields := strings.Fields(l); len(fields) > 1 {
+			ip = fields[1]
+		}
+	} else {
+		ip, _ = parseHopAddress(l)
+	}
+	if !isValidIP(ip) {
+		return nil
+	}
+
+	maxX, maxY := g.Size()
+	rv, err := g.SetView(RDAP, (maxX-RWIDTH)/2, (maxY-RHEIGHT)/2, (maxX+RWIDTH)/2, (maxY+RHEIGHT)/2)
+	if err != nil && err != gocui.ErrUnknownView {
+		log.Println("Failed to create rdap view:", err)
+		return err
+	}
+	if err == gocui.ErrUnknownView {
+		rv.FgColor = gocui.ColorYellow
+		rv.Editable = false
+		rv.Wrap = true
+		for _, key := range []gocui.Key{gocui.KeyEsc, gocui.KeyCtrlQ} {
+			if err := g.SetKeybinding(RDAP, key, gocui.ModNone, closeRDAPView); err != nil {
+				log.Println("Failed to bind keys to rdap view:", err)
+				return err
+			}
+		}
+	}
+	rv.Title = fmt.Sprintf(" Owner of [%s] | Esc: close ", ip)
+	rv.Clear()
+	fmt.Fprint(rv, formatGeo(ip))
+	fmt.Fprintf(rv, "Looking up %s ...", ip)
+	if _, err := g.SetCurrentView(RDAP); err != nil {
+		log.Println("Failed to set focus on rdap view:", err)
+		return err
+	}
+
+	go func() {
+		defer recoverPanic("lookupRDAP")
+		info, err := lookupRDAP(ip)
+		nic := formatNIC(ip)
+		g.Update(func(g *gocui.Gui) error {
+			rv, verr := g.View(RDAP)
+			// closed or showing another address meanwhile.
+			if verr != nil || !strings.Contains(rv.Title, "["+ip+"]") {
+				return nil
+			}
+			rv.Clear()
+			fmt.Fprint(rv, formatGeo(ip)+nic)
+			if err != nil {
+				fmt.Fprintf(rv, "Failed to lookup %s : %v", ip, err)
+				return nil
+			}
+			fmt.Fprint(rv, info.format(ip))
+			return nil
+		})
+	}()
+	return nil
+}
+
+// closeRDAPView closes the ownership details popup.
+func closeRDAPView(g *gocui.Gui, rv *gocui.View) error {
+	g.DeleteKeybindings(rv.Name())
+	if err := g.DeleteView(rv.Name()); err != nil {
+		log.Println("Failed to delete rdap view:", err)
+		return err
+	}
+	return setCurrentDefaultView(g)
+}
+
+// lookupRDAP queries the RDAP service then the origin ASN of an ip.
+func lookupRDAP(ip string) (*rdapInfo, error) {
+	cfgs := getSettings()
+	rdapCache.lock.Lock()
+	info, ok := rdapCache.infos[ip]
+	rdapCache.lock.Unlock()
+	if ok {
+		return info, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	url := strings.TrimRight(cfgs.Enrich.RDAP, "/") + "/ip/" + ip
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/rdap+json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("rdap service replied %s", resp.Status)
+	}
+
+	var n rdapNetwork
+	if err = json.NewDecoder(resp.Body).Decode(&n); err != nil {
+		return nil, err
+	}
+
+	info = &rdapInfo{network: n.Name, country: n.Country, source: resp.Request.URL.String()}
+	if n.Handle != "" {
+		info.network = strings.TrimSpace(n.Name + " (" + n.Handle + ")")
+	}
+	if n.StartAddress != "" {
+		info.rangeOf = n.StartAddress + " - " + n.EndAddress
+	}
+	var prefixes []string
+	for _, c := range n.Cidrs {
+		prefixes = append(prefixes, fmt.Sprintf("%s%s/%d", c.V4Prefix, c.V6Prefix, c.Length))
+	}
+	info.prefix = strings.Join(prefixes, ", ")
+	info.org, info.abuse = rdapContacts(n.Entities)
+
+	if addr := net.ParseIP(ip); !addr.IsPrivate() && !addr.IsLoopback() {
+		info.asn, _ = enrich.lookupASN(ctx, addr)
+	}
+
+	rdapCache.lock.Lock()
+	rdapCache.infos[ip] = info
+	rdapCache.lock.Unlock()
+	return info, nil
+}
+
+// rdapContacts returns the registrant name and the abuse email
+// found into the entities and their nested entities.
+func rdapContacts(entities []rdapEntity) (string, string) {
+	var org, abuse string
+	for _, e := range entities {
+		for _, role := range e.Roles {
+			switch role {
+			case "registrant":
+				if org == "" {
+					org = vcardField(e.VCard, "fn")
+				}
+			case "abuse":
+				if abuse == "" {
+					abuse = vcardField(e.VCard, "email")
+				}
+			}
+		}
+		o, a := rdapContacts(e.Entities)
+		if org == "" {
+			org = o
+		}
+		if abuse == "" {
+			abuse = a
+		}
+	}
+	return org, abuse
+}
+
+// vcardField returns the first text value of a jCard property.
+// ["vcard", [["fn", {}, "text", "Google LLC"], ...]]
+func vcardField(data json.RawMessage, name string) string {
+	var card []interface{}
+	if json.Unmarshal(data, &card) != nil || len(card) < 2 {
+		return ""
+	}
+	props, _ := card[1].([]interface{})
+	for _, p := range props {
+		prop, _ := p.([]interface{})
+		if len(prop) < 4 || prop[0] != name {
+			continue
+		}
+		if v, ok := prop[3].(string); ok {
+			return v
+		}
+	}
+	return ""
+}
+
+// formatGeo returns the geoip details of an address if any.
+func formatGeo(ip string) string {
+	gi := geo.locate(ip)
+	if gi == (geoInfo{}) {
+		return ""
+	}
+	return fmt.Sprintf("location: %s\ngeo asn : %s\n", gi.location(), strings.TrimSpace(gi.asn+" "+gi.org))
+}
+
+// formatNIC returns the configured MAC address of a target and its
+// vendor if any.
+func formatNIC(ip string) string {
+	cfg := dbs.getConfig(ip)
+	if cfg == nil || cfg.mac == "" {
+		return ""
+	}
+	mac, err := net.ParseMAC(cfg.mac)
+	if err != nil {
+		return ""
+	}
+	return "mac     : " + formatMAC(mac) + "\n"
+}
+
+// format returns the details as displayed into the popup.
+func (info *rdapInfo) format(ip string) string {
+	na := func(v string) string {
+		if v == "" {
+			return "n/a"
+		}
+		return v
+	}
+	return fmt.Sprintf("address : %s\nprefix  : %s\nrange   : %s\nnetwork : %s\norg     : %s\ncountry : %s\nasn     : %s\nabuse   : %s\nsource  : %s",
+		ip, na(info.prefix), na(info.rangeOf), na(info.network), na(info.org), na(info.country), na(info.asn), na(info.abuse), info.source)
+}
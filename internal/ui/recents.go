@@ -0,0 +1,122 @@
+package ui
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/jroimartin/gocui"
+)
+
+// values remembered per input box.
+const MAXRECENTS = 20
+
+// recentInputs keeps the latest values entered into each input box,
+// most recent first, so they can be recalled instead of retyped.
+type recentInputs struct {
+	lock   *sync.Mutex
+	once   *sync.Once
+	values map[string][]string
+}
+
+// global recent values of the add, search and load boxes.
+var recents = &recentInputs{lock: &sync.Mutex{}, once: &sync.Once{}, values: make(map[string][]string)}
+
+// recentsFile returns the file which keeps the recent values across the
+// sessions, near the logs into the user cache folder.
+func recentsFile() string {
+	return filepath.Join(filepath.Dir(defaultLogFile()), "recents.json")
+}
+
+// load reads the recent values of the previous sessions once.
+func (ri *recentInputs) load() {
+	ri.once.Do(func() {
+		data, err := ioutil.ReadFile(recentsFile())
+		if err != nil {
+			if !os.IsNotExist(err) {
+				uiLog.Warn("Failed to read recent inputs", "err", err)
+			}
+			return
+		}
+		if err := json.Unmarshal(data, &ri.values); err != nil {
+			uiLog.Warn("Failed to parse recent inputs", "err", err)
+		}
+	})
+}
+
+// add records a value entered into a box and saves all values.
+func (ri *recentInputs) add(box, value string) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return
+	}
+	ri.load()
+
+	ri.lock.Lock()
+	values := []string{value}
+	for _, v := range ri.values[box] {
+		if v != value && len(values) < MAXRECENTS {
+			values = append(values, v)
+		}
+	}
+	ri.values[box] = values
+	data, err := json.MarshalIndent(ri.values, "", "  ")
+	ri.lock.Unlock()
+	if err != nil {
+		uiLog.Warn("Failed to encode recent inputs", "err", err)
+		return
+	}
+
+	if err := os.MkdirAll(filepath.Dir(recentsFile()), 0755); err != nil {
+		uiLog.Warn("Failed to save recent inputs", "err", err)
+		return
+	}
+	if err := ioutil.WriteFile(recentsFile(), data, 0644); err != nil {
+		uiLog.Warn("Failed to save recent inputs", "err", err)
+	}
+}
+
+// list returns the recent values of a box, most recent first.
+func (ri *recentInputs) list(box string) []string {
+	ri.load()
+	ri.lock.Lock()
+	defer ri.lock.Unlock()
+	return append([]string(nil), ri.values[box]...)
+}
+
+// recallInput returns the <↑> (older) or <↓> (newer) handler of an
+// input box. It replaces the text of the box with the previous or next
+// recent value of the box, going back to an empty box after the newest.
+func recallInput(older bool) func(*gocui.Gui, *gocui.View) error {
+	return func(g *gocui.Gui, iv *gocui.View) error {
+		values := recents.list(iv.Name())
+		if len(values) == 0 {
+			return nil
+		}
+		text := strings.TrimSpace(iv.Buffer())
+		pos := -1
+		for i, v := range values {
+			if v == text {
+				pos = i
+				break
+			}
+		}
+		if older && pos < len(values)-1 {
+			pos++
+		} else if !older && pos >= 0 {
+			pos--
+		} else {
+			return nil
+		}
+		deleteInputMessage(g)
+		if pos < 0 {
+			setInputText(iv, "")
+			return nil
+		}
+		setInputText(iv, values[pos])
+		return nil
+	}
+}
@@ -0,0 +1,85 @@
+package ui
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/jeamon/pingo/internal/stats"
+)
+
+// runReport collects the results of a single ping run.
+type runReport struct {
+	ip        string
+	threshold int
+	start     time.Time
+	end       time.Time
+	results   stats.Summary
+	// samples are only kept for bounded runs, the endless
+	// ones would grow the memory until stopped.
+	bounded bool
+}
+
+// newRunReport starts a report for a ping run of requests count,
+// 0 for an endless one.
+func newRunReport(ip string, threshold, requests int) *runReport {
+	return &runReport{ip: ip, threshold: threshold, start: time.Now(), bounded: requests > 0}
+}
+
+// add records the result of a request.
+func (r *runReport) add(sp stats.Sample) {
+	if r.bounded {
+		r.results.Add(sp)
+		return
+	}
+	r.results.Account(sp)
+}
+
+// breaches returns the number of replies above the threshold.
+func (r *runReport) breaches() int {
+	if r.threshold <= 0 {
+		return 0
+	}
+	count := 0
+	for _, sp := range r.results.Samples() {
+		if sp.Success && sp.RTT > r.threshold {
+			count++
+		}
+	}
+	return count
+}
+
+// profile returns the number of requests sent, the loss and the
+// min, avg and max reply times of the run.
+func (r *runReport) profile() (int, float64, int, int, int) {
+	return r.results.Sent, r.results.Loss(), r.results.Min, r.results.Avg(), r.results.Max
+}
+
+// format builds the report text content.
+func (r *runReport) format() string {
+	sent, loss, min, avg, max := r.profile()
+	var b strings.Builder
+	fmt.Fprintf(&b, "target    : %s\n", r.ip)
+	fmt.Fprintf(&b, "started   : %s\n", r.start.Format("2006-01-02 15:04:05"))
+	fmt.Fprintf(&b, "ended     : %s\n", r.end.Format("2006-01-02 15:04:05"))
+	fmt.Fprintf(&b, "duration  : %s\n", r.end.Sub(r.start).Round(time.Second))
+	fmt.Fprintf(&b, "sent      : %d\n", sent)
+	fmt.Fprintf(&b, "received  : %d\n", r.results.Replies())
+	fmt.Fprintf(&b, "loss      : %.1f%%\n", loss)
+	fmt.Fprintf(&b, "min/avg/max/p95 : %d/%d/%d/%d ms\n", min, avg, max, r.results.Quantile(0.95))
+	fmt.Fprintf(&b, "threshold : %d ms\n", r.threshold)
+	fmt.Fprintf(&b, "breaches  : %d\n", r.breaches())
+	return b.String()
+}
+
+// write saves the report into the reports directory.
+func (r *runReport) write(dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	name := fmt.Sprintf("report_%s_%s.txt", strings.Replace(r.ip, ":", "-", -1), r.start.Format("20060102-150405"))
+	return ioutil.WriteFile(filepath.Join(dir, name), []byte(r.format()), 0644)
+}
@@ -0,0 +1,93 @@
+package ui
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// sequence numbers which differ between otherwise repeated lines such as
+// "From 10.0.0.1 icmp_seq=7 Destination Host Unreachable".
+var seqPattern = regexp.MustCompile(`(icmp_)?seq[= ]\d+`)
+
+// lineRing keeps the latest output lines of the target displayed into
+// the outputs view so long pings do not grow the view without bound.
+type lineRing struct {
+	lines []string
+	start int
+	count int
+	// lines dropped since the last reset.
+	truncated int
+	// size of the kept lines.
+	bytes int
+}
+
+// newLineRing creates a ring of size lines.
+func newLineRing(size int) *lineRing {
+	return &lineRing{lines: make([]string, size)}
+}
+
+// add appends a line and drops the oldest one once full.
+func (r *lineRing) add(line string) {
+	r.bytes += len(line)
+	if r.count < len(r.lines) {
+		r.lines[(r.start+r.count)%len(r.lines)] = line
+		r.count++
+		return
+	}
+	r.bytes -= len(r.lines[r.start])
+	r.lines[r.start] = line
+	r.start = (r.start + 1) % len(r.lines)
+	r.truncated++
+}
+
+// replaceLast changes the latest added line.
+func (r *lineRing) replaceLast(line string) {
+	if r.count == 0 {
+		return
+	}
+	last := (r.start + r.count - 1) % len(r.lines)
+	r.bytes += len(line) - len(r.lines[last])
+	r.lines[last] = line
+}
+
+// trim drops the n oldest lines.
+func (r *lineRing) trim(n int) {
+	if n > r.count {
+		n = r.count
+	}
+	for i := 0; i < n; i++ {
+		r.bytes -= len(r.lines[r.start])
+		r.lines[r.start] = ""
+		r.start = (r.start + 1) % len(r.lines)
+	}
+	r.count -= n
+	r.truncated += n
+}
+
+// reset empties the ring.
+func (r *lineRing) reset() {
+	for i := range r.lines {
+		r.lines[i] = ""
+	}
+	r.start, r.count, r.truncated, r.bytes = 0, 0, 0, 0
+}
+
+// content renders the kept lines the way they are written into the
+// outputs view, preceded by the number of truncated lines if any.
+func (r *lineRing) content() string {
+	var content strings.Builder
+	if r.truncated > 0 {
+		fmt.Fprintf(&content, "\n[... %d earlier lines truncated ...]", r.truncated)
+	}
+	for i := 0; i < r.count; i++ {
+		content.WriteString("\n" + r.lines[(r.start+i)%len(r.lines)])
+	}
+	return content.String()
+}
+
+// repeatKey returns what is compared to tell that an output line
+// repeats the previous one.
+func repeatKey(line string) string {
+	return seqPattern.ReplaceAllString(strings.TrimSpace(line), "")
+}
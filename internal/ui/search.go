@@ -0,0 +1,209 @@
+package ui
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/jroimartin/gocui"
+)
+
+const (
+	SEARCHRESULTS = "searchResults"
+
+	// targets listed under the search box.
+	MAXRESULTS = 10
+)
+
+// searchResult is a target matching the search box text with the
+// details the text was matched against.
+type searchResult struct {
+	ip      string
+	details string
+	score   int
+}
+
+// searchDetails returns the label, tags, reverse name and location of
+// an ip so the targets can be searched by label, tag, name, city,
+// country or network owner.
+func searchDetails(ip string) string {
+	var parts []string
+	if l := labelDetails(ip); l != "" {
+		parts = append(parts, l)
+	}
+	if ptr := enrich.info(ip).ptr; ptr != "" {
+		parts = append(parts, ptr)
+	}
+	gi := geo.locate(ip)
+	for _, p := range []string{gi.location(), gi.asn, gi.org} {
+		if p != "" {
+			parts = append(parts, p)
+		}
+	}
+	return strings.Join(parts, " | ")
+}
+
+// fuzzyScore tells whether all characters of pattern appear in order
+// into text, ignoring case. Consecutive characters and characters at
+// the start of a word score higher so closer matches rank first.
+func fuzzyScore(pattern, text string) (int, bool) {
+	pattern, text = strings.ToLower(pattern), strings.ToLower(text)
+	if strings.Contains(text, pattern) {
+		score := 4 * len(pattern)
+		if strings.HasPrefix(text, pattern) {
+			score += 10
+		}
+		return score + 10, true
+	}
+
+	score, last, i := 0, -2, 0
+	prev := ' '
+	for j, c := range text {
+		if i == len(pattern) {
+			break
+		}
+		r, size := utf8.DecodeRuneInString(pattern[i:])
+		if c == r {
+			score++
+			if j == last+1 {
+				score += 2
+			}
+			if strings.ContainsRune(" .:-|,", prev) {
+				score++
+			}
+			last, i = j, i+size
+		}
+		prev = c
+	}
+	return score, i == len(pattern)
+}
+
+// searchTargets returns the targets matching the search text, best
+// first. An empty text returns the recently searched targets.
+func searchTargets(text string) []searchResult {
+	var results []searchResult
+	if text = strings.TrimSpace(text); text == "" {
+		for _, ip := range recents.list("searchIP") {
+			if dbs.isExistsIP(ip) {
+				results = append(results, searchResult{ip: ip, details: searchDetails(ip)})
+			}
+		}
+	} else {
+		for _, ip := range dbs.getAllIPs() {
+			details := searchDetails(ip)
+			score, ok := fuzzyScore(text, ip)
+			if s, found := fuzzyScore(text, details); found && (!ok || s > score) {
+				score, ok = s, true
+			}
+			if ok {
+				results = append(results, searchResult{ip: ip, details: details, score: score})
+			}
+		}
+		sort.SliceStable(results, func(i, j int) bool {
+			return results[i].score > results[j].score
+		})
+	}
+	if len(results) > MAXRESULTS {
+		results = results[:MAXRESULTS]
+	}
+	return results
+}
+
+// searchEditor edits the search box text then filters the targets
+// listed under the box.
+func searchEditor(g *gocui.Gui) gocui.Editor {
+	return gocui.EditorFunc(func(iv *gocui.View, key gocui.Key, ch rune, mod gocui.Modifier) {
+		gocui.DefaultEditor.Edit(iv, key, ch, mod)
+		deleteInputMessage(g)
+		if err := updateSearchResults(g, iv); err != nil {
+			log.Println("Failed to update search results:", err)
+		}
+	})
+}
+
+// updateSearchResults lists under the search box the targets matching
+// its text. The first one is selected.
+func updateSearchResults(g *gocui.Gui, iv *gocui.View) error {
+	results := searchTargets(iv.Buffer())
+	if len(results) == 0 {
+		deleteSearchResults(g)
+		return nil
+	}
+
+	x0, _, x1, y1, err := g.ViewPosition(iv.Name())
+	if err != nil {
+		return nil
+	}
+	rv, err := g.SetView(SEARCHRESULTS, x0, y1, x1, y1+len(results)+1)
+	if err != nil && err != gocui.ErrUnknownView {
+		return err
+	}
+	if err == gocui.ErrUnknownView {
+		rv.FgColor = gocui.ColorYellow
+		rv.SelBgColor = gocui.ColorGreen
+		rv.SelFgColor = gocui.ColorBlack
+		rv.Highlight = true
+	}
+	if strings.TrimSpace(iv.Buffer()) == "" {
+		rv.Title = " Recent searches "
+	} else {
+		rv.Title = fmt.Sprintf(" %d matches ", len(results))
+	}
+	rv.Clear()
+	for _, r := range results {
+		if r.details == "" {
+			fmt.Fprintln(rv, r.ip)
+		} else {
+			fmt.Fprintf(rv, "%-15s  %s\n", r.ip, r.details)
+		}
+	}
+	rv.SetOrigin(0, 0)
+	rv.SetCursor(0, 0)
+	return nil
+}
+
+// moveSearchSelection returns the <↑> or <↓> handler of the search box
+// which moves the selection into the listed targets.
+func moveSearchSelection(dy int) func(*gocui.Gui, *gocui.View) error {
+	return func(g *gocui.Gui, iv *gocui.View) error {
+		rv, err := g.View(SEARCHRESULTS)
+		if err != nil {
+			return nil
+		}
+		count := 0
+		for _, l := range rv.BufferLines() {
+			if strings.TrimSpace(l) != "" {
+				count++
+			}
+		}
+		_, cy := rv.Cursor()
+		if cy+dy < 0 || cy+dy >= count {
+			return nil
+		}
+		rv.SetCursor(0, cy+dy)
+		return nil
+	}
+}
+
+// selectedSearchResult returns the ip of the selected listed target.
+func selectedSearchResult(g *gocui.Gui) string {
+	rv, err := g.View(SEARCHRESULTS)
+	if err != nil {
+		return ""
+	}
+	_, cy := rv.Cursor()
+	l, err := rv.Line(cy)
+	if err != nil || len(strings.Fields(l)) == 0 {
+		return ""
+	}
+	return strings.Fields(l)[0]
+}
+
+// deleteSearchResults removes the list of the search box if any.
+func deleteSearchResults(g *gocui.Gui) {
+	if err := g.DeleteView(SEARCHRESULTS); err != nil && err != gocui.ErrUnknownView {
+		log.Println("Failed to delete search results view:", err)
+	}
+}
@@ -0,0 +1,220 @@
+package ui
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"time"
+
+	"github.com/jeamon/pingo/internal/stats"
+)
+
+// version of the session dump format. It must be
+// increased on each breaking change of the layout.
+const SESSIONVERSION = 1
+
+// sessionDump is the JSON representation of the full session state.
+type sessionDump struct {
+	Version int          `json:"version"`
+	Created time.Time    `json:"created"`
+	Targets []targetDump `json:"targets"`
+	Events  []eventDump  `json:"events"`
+	Notes   []noteDump   `json:"annotations,omitempty"`
+}
+
+// targetDump holds everything known about a target.
+type targetDump struct {
+	IP      string       `json:"ip"`
+	Config  configDump   `json:"config"`
+	Stats   statsDump    `json:"stats"`
+	Samples []sampleDump `json:"samples"`
+	// per-minute aggregates of the samples older than the history.
+	Minutes []minuteDump `json:"minutes,omitempty"`
+	Geo     *geoDump     `json:"geo,omitempty"`
+}
+
+type geoDump struct {
+	Country string `json:"country,omitempty"`
+	City    string `json:"city,omitempty"`
+	ASN     string `json:"asn,omitempty"`
+	Org     string `json:"org,omitempty"`
+}
+
+type configDump struct {
+	Start     string `json:"start"`
+	Requests  int    `json:"requests"`
+	Threshold int    `json:"threshold"`
+	MaxLoss   int    `json:"max_loss,omitempty"`
+	Timeout   int    `json:"timeout"`
+	Interval  int    `json:"interval,omitempty"`
+	Size      int    `json:"size"`
+	Pattern   string `json:"pattern,omitempty"`
+	Backup    bool   `json:"backup"`
+	MaxHops   int    `json:"max_hops"`
+	Queries   int    `json:"queries"`
+	Protocol  string `json:"protocol"`
+	Numeric   bool   `json:"numeric"`
+	Probe     string `json:"probe,omitempty"`
+	QName     string `json:"qname,omitempty"`
+	QType     string `json:"qtype,omitempty"`
+	MAC       string `json:"mac,omitempty"`
+	Broadcast string `json:"broadcast,omitempty"`
+	Iperf     int    `json:"iperf,omitempty"`
+	Label     string `json:"label,omitempty"`
+	Tags      string `json:"tags,omitempty"`
+}
+
+type statsDump struct {
+	State     string  `json:"state"`
+	Sent      int     `json:"sent"`
+	Replies   int     `json:"replies"`
+	Fails     int     `json:"fails"`
+	Loss      float64 `json:"loss"`
+	Min       int     `json:"min_ms"`
+	Avg       int     `json:"avg_ms"`
+	Max       int     `json:"max_ms"`
+	Last      int     `json:"last_ms"`
+	Match     int     `json:"match"`
+	Above     int     `json:"above"`
+	Under     int     `json:"under"`
+	Anomalies int     `json:"anomalies"`
+}
+
+type minuteDump struct {
+	Time  time.Time `json:"time"`
+	Sent  int       `json:"sent"`
+	Fails int       `json:"fails"`
+	Min   int       `json:"min_ms"`
+	Max   int       `json:"max_ms"`
+	Sum   int       `json:"sum_ms"`
+}
+
+type sampleDump struct {
+	Time    time.Time `json:"time"`
+	Success bool      `json:"success"`
+	RTT     int       `json:"rtt_ms"`
+}
+
+type eventDump struct {
+	Time         time.Time `json:"time"`
+	IP           string    `json:"ip"`
+	State        string    `json:"state"`
+	Escalated    bool      `json:"escalated"`
+	Acknowledged bool      `json:"acknowledged"`
+}
+
+type noteDump struct {
+	Time time.Time `json:"time"`
+	IP   string    `json:"ip"`
+	Text string    `json:"text"`
+}
+
+// buildSessionDump collects the current session state.
+func buildSessionDump() *sessionDump {
+	dump := &sessionDump{Version: SESSIONVERSION, Created: time.Now()}
+
+	for _, ip := range dbs.getAllIPs() {
+		cfg, s := dbs.getConfig(ip), dbs.getStats(ip)
+		if cfg == nil || s == nil {
+			continue
+		}
+
+		t := targetDump{
+			IP: ip,
+			Config: configDump{
+				Start: cfg.start, Requests: cfg.requests, Threshold: cfg.threshold, MaxLoss: cfg.maxloss,
+				Timeout: cfg.timeout, Interval: cfg.interval, Size: cfg.size, Pattern: cfg.pattern, Backup: cfg.backup,
+				MaxHops: cfg.maxhops, Queries: cfg.queries, Protocol: cfg.protocol, Numeric: cfg.numeric,
+				Probe: cfg.probe, QName: cfg.qname, QType: cfg.qtype, MAC: cfg.mac, Broadcast: cfg.broadcast,
+				Iperf: cfg.iperf, Label: cfg.label, Tags: cfg.tags,
+			},
+			Stats: statsDump{
+				State: s.state, Sent: s.fails + s.replies(), Replies: s.replies(), Fails: s.fails,
+				Loss: s.loss(), Min: s.min, Avg: s.avg, Max: s.max, Last: s.last,
+				Match: s.match, Above: s.above, Under: s.under, Anomalies: s.anomalies,
+			},
+			Samples: []sampleDump{},
+		}
+		if gi := geo.locate(ip); gi != (geoInfo{}) {
+			t.Geo = &geoDump{Country: gi.country, City: gi.city, ASN: gi.asn, Org: gi.org}
+		}
+
+		for _, a := range dbs.getMinutes(ip) {
+			t.Minutes = append(t.Minutes, minuteDump{Time: a.Time, Sent: a.Sent, Fails: a.Fails, Min: a.Min, Max: a.Max, Sum: a.Sum})
+		}
+		for _, sp := range dbs.getHistory(ip) {
+			t.Samples = append(t.Samples, sampleDump{Time: sp.time, Success: sp.success, RTT: sp.rtt})
+		}
+		dump.Targets = append(dump.Targets, t)
+	}
+
+	for _, n := range center.list() {
+		dump.Events = append(dump.Events, eventDump{Time: n.alert.time, IP: n.alert.ip,
+			State: n.alert.state, Escalated: n.alert.escalated, Acknowledged: n.acked})
+	}
+
+	for _, a := range annotations.list() {
+		dump.Notes = append(dump.Notes, noteDump{Time: a.time, IP: a.ip, Text: a.text})
+	}
+
+	return dump
+}
+
+// exportSession writes the session state as indented JSON into a file.
+func exportSession(filename string) error {
+	data, err := json.MarshalIndent(buildSessionDump(), "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filename, data, 0644)
+}
+
+// importSession restores the targets of a session dump with their
+// configs, samples history (with its per-minute aggregates) and the
+// timeline notes. Statistics
+// restart on next ping.
+func importSession(filename string) error {
+	content, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+
+	var dump sessionDump
+	if err = json.Unmarshal(content, &dump); err != nil {
+		return err
+	}
+	if dump.Version > SESSIONVERSION {
+		return errors.New("unsupported session version")
+	}
+
+	for _, t := range dump.Targets {
+		if !isValidIP(t.IP) {
+			continue
+		}
+		dbs.addNewIP(t.IP)
+
+		c := t.Config
+		cfg := &config{start: "n/a", requests: c.Requests, threshold: c.Threshold, maxloss: c.MaxLoss, timeout: c.Timeout,
+			interval: c.Interval, size: c.Size, pattern: c.Pattern, backup: c.Backup, maxhops: c.MaxHops, queries: c.Queries, protocol: c.Protocol, numeric: c.Numeric,
+			probe: c.Probe, qname: c.QName, qtype: c.QType, mac: c.MAC, broadcast: c.Broadcast,
+			iperf: c.Iperf, label: c.Label, tags: normalizeTags(c.Tags)}
+		dbs.updateConfig(t.IP, cfg)
+		store.saveTarget(t.IP, cfg)
+
+		minutes := make([]stats.Aggregate, 0, len(t.Minutes))
+		for _, m := range t.Minutes {
+			minutes = append(minutes, stats.Aggregate{Time: m.Time, Sent: m.Sent, Fails: m.Fails, Min: m.Min, Max: m.Max, Sum: m.Sum})
+		}
+		dbs.restoreMinutes(t.IP, minutes)
+		for _, sp := range t.Samples {
+			dbs.addSample(sample{ip: t.IP, time: sp.Time, rtt: sp.RTT, success: sp.Success})
+		}
+	}
+
+	annotations.lock.Lock()
+	for _, n := range dump.Notes {
+		annotations.items = append(annotations.items, annotation{time: n.Time, ip: n.IP, text: n.Text})
+	}
+	annotations.lock.Unlock()
+	return nil
+}
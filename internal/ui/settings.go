@@ -0,0 +1,779 @@
+package ui
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync/atomic"
+
+	"github.com/jeamon/pingo/internal/probe"
+)
+
+// settings represents the global program configuration
+// loaded at startup from a JSON file (pingo.json).
+type settings struct {
+	Alerts alertsSettings `json:"alerts"`
+	SMTP   smtpSettings   `json:"smtp"`
+	Exec   execSettings   `json:"exec"`
+	Syslog syslogSettings `json:"syslog"`
+	SNMP   snmpSettings   `json:"snmp"`
+	HTTP   httpSettings   `json:"http"`
+	GRPC   grpcSettings   `json:"grpc"`
+	// file to dump the session state into on exit.
+	SessionFile string `json:"session_file"`
+	// maximum number of samples kept per target.
+	History int `json:"history"`
+	// maximum number of per-minute aggregates kept per target of
+	// the samples older than the history. 0 discards them.
+	HistoryMinutes int `json:"history_minutes"`
+	// maximum number of lines kept into the outputs view.
+	OutputLines int `json:"output_lines"`
+	// MB used by the samples history and the output lines beyond
+	// which the oldest are trimmed. 0 disables the guard.
+	MemoryLimit int `json:"memory_limit"`
+	// engine of the ping probes : auto, native or system.
+	Engine string `json:"engine"`
+	// windows code page of the outputs of the system commands.
+	// 0 uses the one of the console and 65001 reads them as UTF-8.
+	CodePage int `json:"codepage"`
+	// collapse the repeated output lines into the last one.
+	CollapseRepeats bool `json:"collapse_repeats"`
+	// seconds between two statistics summaries sent to sinks.
+	Interval int                 `json:"interval"`
+	Influx   influxSettings      `json:"influx"`
+	Graphite metricsSinkSettings `json:"graphite"`
+	Statsd   metricsSinkSettings `json:"statsd"`
+	// file or named pipe to stream each result as JSON line.
+	Stream  string          `json:"stream"`
+	Store   storeSettings   `json:"store"`
+	Backup  backupSettings  `json:"backup"`
+	Reports reportsSettings `json:"reports"`
+	Enrich  enrichSettings  `json:"enrich"`
+	// maximum concurrent traceroutes of a parallel trace.
+	Parallel int              `json:"parallel"`
+	DNS      dnsSettings      `json:"dns"`
+	Scan     scanSettings     `json:"scan"`
+	TLS      tlsSettings      `json:"tls"`
+	SNMPPoll snmpPollSettings `json:"snmp_poll"`
+	Iperf    iperfSettings    `json:"iperf"`
+	// looking-glasses queried for the BGP routes of an address.
+	LookingGlass []lookingGlassSettings `json:"looking_glass"`
+	// named sequences of actions run against a target.
+	Playbooks []playbookSettings `json:"playbooks"`
+	Deadlines deadlinesSettings  `json:"deadlines"`
+	Baseline  baselineSettings   `json:"baseline"`
+	Subnet    subnetSettings     `json:"subnet"`
+	// configs applied to the targets of common device types.
+	Templates []templateSettings `json:"templates"`
+	// other pingo instances compared with this one.
+	Vantages []vantageSettings `json:"vantages"`
+	Queues   queuesSettings    `json:"queues"`
+}
+
+// alertsSettings defines how a target state change is detected.
+type alertsSettings struct {
+	// consecutive failures before a target is considered down.
+	DownAfter int `json:"down_after"`
+	// minutes to wait before re-alerting a target down.
+	Cooldown int `json:"cooldown"`
+	// state changes within flap window (minutes) to consider a target flapping.
+	FlapCount  int `json:"flap_count"`
+	FlapWindow int `json:"flap_window"`
+	// notifiers names to use (all enabled if empty).
+	Notify     []string           `json:"notify"`
+	Escalation escalationSettings `json:"escalation"`
+	// alert when the traceroute path to a target changes.
+	PathChange bool `json:"path_change"`
+	// minutes a recovered target stays highlighted into the ips list.
+	// 0 keeps it until acknowledged.
+	HighlightClear int            `json:"highlight_clear"`
+	Digest         digestSettings `json:"digest"`
+	Outage         outageSettings `json:"outage"`
+}
+
+// outageSettings defines the collapse of the targets of a subnet down
+// together into a single outage alert.
+type outageSettings struct {
+	// targets of a subnet down together making an outage.
+	// 0 disables the correlation.
+	Min int `json:"min"`
+	// seconds a down alert is held to wait for other targets.
+	Window int `json:"window"`
+}
+
+// subnetSettings defines the prefix lengths grouping the targets
+// into subnets.
+type subnetSettings struct {
+	Prefix  int `json:"prefix"`
+	Prefix6 int `json:"prefix6"`
+}
+
+// escalationSettings defines notifiers to use after a sustained downtime.
+type escalationSettings struct {
+	// minutes down before escalating. 0 disables escalation.
+	After  int      `json:"after"`
+	Notify []string `json:"notify"`
+}
+
+// digestSettings defines the periodic summary of the targets.
+type digestSettings struct {
+	// hours between two digests. 0 disables the digest.
+	Every int `json:"every"`
+	// worst targets listed into the digest.
+	Worst  int      `json:"worst"`
+	Notify []string `json:"notify"`
+}
+
+// smtpSettings defines the mail server used to send alerts emails.
+type smtpSettings struct {
+	Enabled  bool     `json:"enabled"`
+	Host     string   `json:"host"`
+	Port     int      `json:"port"`
+	Username string   `json:"username"`
+	Password string   `json:"password"`
+	From     string   `json:"from"`
+	To       []string `json:"to"`
+	// seconds to wait collecting alerts before sending one email.
+	Batch int `json:"batch"`
+}
+
+// execSettings defines a custom command to run on each alert.
+type execSettings struct {
+	Enabled bool   `json:"enabled"`
+	Command string `json:"command"`
+	// maximum seconds allowed for the command to complete.
+	Timeout int `json:"timeout"`
+}
+
+// syslogSettings defines the syslog server to forward alerts to.
+type syslogSettings struct {
+	Enabled bool `json:"enabled"`
+	// transport protocol : udp or tcp.
+	Network string `json:"network"`
+	Address string `json:"address"`
+	// facility code (16 for local0).
+	Facility int `json:"facility"`
+}
+
+// snmpSettings defines the destination of SNMPv2c traps.
+type snmpSettings struct {
+	Enabled     bool   `json:"enabled"`
+	Destination string `json:"destination"`
+	Community   string `json:"community"`
+	// base enterprise oid of the traps.
+	OID string `json:"oid"`
+}
+
+// snmpPollSettings defines how the devices agents are polled.
+type snmpPollSettings struct {
+	// v2c or v3.
+	Version   string `json:"version"`
+	Community string `json:"community"`
+	Port      int    `json:"port"`
+	// seconds to wait for each response.
+	Timeout int `json:"timeout"`
+	// SNMPv3 user. Authentication (md5 or sha) and privacy (des or aes)
+	// are enabled when their passwords are set.
+	User         string `json:"user"`
+	AuthProtocol string `json:"auth_protocol"`
+	AuthPassword string `json:"auth_password"`
+	PrivProtocol string `json:"priv_protocol"`
+	PrivPassword string `json:"priv_password"`
+}
+
+// iperfSettings defines the throughput tests run with iperf3.
+type iperfSettings struct {
+	// iperf3 program name or path.
+	Path string `json:"path"`
+	// seconds of each upload and download test.
+	Duration int `json:"duration"`
+	// parallel client streams.
+	Streams int `json:"streams"`
+}
+
+// lookingGlassSettings defines a looking-glass endpoint.
+type lookingGlassSettings struct {
+	Name string `json:"name"`
+	// url queried with {ip} replaced by the address.
+	URL string `json:"url"`
+	// "ripestat" for the RIPEstat looking-glass JSON API or
+	// "text" to display the plain (or html) outputs as is.
+	Format string `json:"format"`
+}
+
+// templateSettings defines the configs of the targets of a common
+// device type, applied to them when they are added.
+type templateSettings struct {
+	Name     string `json:"name"`
+	Requests int    `json:"requests"`
+	// milliseconds between two requests.
+	Interval  int    `json:"interval"`
+	Timeout   int    `json:"timeout"`
+	Size      int    `json:"size"`
+	Pattern   string `json:"pattern"`
+	Threshold int    `json:"threshold"`
+	MaxLoss   int    `json:"max_loss"`
+	MaxHops   int    `json:"max_hops"`
+	Queries   int    `json:"queries"`
+	Protocol  string `json:"protocol"`
+	Backup    bool   `json:"backup"`
+	// tags of the targets added with the template.
+	Tags []string `json:"tags"`
+}
+
+// vantageSettings defines another pingo instance probing the same
+// targets from another site.
+type vantageSettings struct {
+	Name string `json:"name"`
+	// base url of the web dashboard of the instance.
+	URL string `json:"url"`
+}
+
+// queuesSettings defines the pool of probe workers and the sizes of
+// the internal queues with the policy once the samples one is full.
+type queuesSettings struct {
+	// targets probed at once without ui (daemon and headless
+	// modes). 0 probes all targets at once.
+	Workers int `json:"workers"`
+	// results waiting for the sinks, alerts waiting for the
+	// notifiers and events waiting for each ui view.
+	Samples int `json:"samples"`
+	Alerts  int `json:"alerts"`
+	UI      int `json:"ui"`
+	// block (the probes wait), drop-oldest or drop-newest.
+	Policy string `json:"policy"`
+}
+
+// playbookSettings defines the ordered actions of a playbook :
+// "ping [count]", "trace", "dns [types]", "scan [ports]",
+// "cert [port] [server name]" and "arp".
+type playbookSettings struct {
+	Name  string   `json:"name"`
+	Steps []string `json:"steps"`
+}
+
+// deadlinesSettings bounds the probes whatever the os command options.
+type deadlinesSettings struct {
+	// seconds without ping output counted as a failed request.
+	// 0 disables the probe and bounded ping deadlines.
+	Probe int `json:"probe"`
+	// minutes a traceroute or mtr round may run. 0 disables it.
+	Run int `json:"run"`
+}
+
+// baselineSettings defines the learning of the normal latency of each
+// target and the replies highlighted as anomalies.
+type baselineSettings struct {
+	// standard deviations from the baseline making an anomaly.
+	// 0 disables the detection.
+	Sigma float64 `json:"sigma"`
+	// replies learned before detecting anomalies.
+	Warmup int `json:"warmup"`
+	// replies the baseline mostly depends on.
+	Window int `json:"window"`
+}
+
+// httpSettings defines the embedded web server serving the dashboard,
+// /metrics and /ws.
+type httpSettings struct {
+	Enabled bool   `json:"enabled"`
+	Address string `json:"address"`
+}
+
+// grpcSettings defines the gRPC control API server.
+type grpcSettings struct {
+	Enabled bool   `json:"enabled"`
+	Address string `json:"address"`
+}
+
+// influxSettings defines where to write line protocol data. A non
+// empty file takes precedence over the InfluxDB server url.
+type influxSettings struct {
+	Enabled bool   `json:"enabled"`
+	File    string `json:"file"`
+	URL     string `json:"url"`
+	// api version : 1 or 2.
+	Version  int    `json:"version"`
+	Database string `json:"database"`
+	Username string `json:"username"`
+	Password string `json:"password"`
+	Org      string `json:"org"`
+	Bucket   string `json:"bucket"`
+	Token    string `json:"token"`
+	// targets to write (all if empty).
+	Targets []string `json:"targets"`
+}
+
+// metricsSinkSettings defines a Graphite or StatsD server.
+type metricsSinkSettings struct {
+	Enabled bool   `json:"enabled"`
+	Address string `json:"address"`
+	Prefix  string `json:"prefix"`
+}
+
+// storeSettings defines the SQLite database persisting the session.
+type storeSettings struct {
+	Enabled bool   `json:"enabled"`
+	Path    string `json:"path"`
+	// days the samples and events are kept. 0 keeps them forever.
+	Retention int `json:"retention"`
+}
+
+// backupSettings defines where per-ip outputs backup files are written.
+type backupSettings struct {
+	Dir string `json:"dir"`
+	// maximum size in MB of a backup file before rotation.
+	MaxSize int `json:"max_size"`
+}
+
+// reportsSettings defines where summary files of completed pings are written.
+type reportsSettings struct {
+	Enabled bool   `json:"enabled"`
+	Dir     string `json:"dir"`
+}
+
+// enrichSettings defines the lookups made on each traceroute hop
+// and the geoip databases also used for the targets details.
+type enrichSettings struct {
+	// resolve hops reverse names and origin ASN.
+	Enabled bool `json:"enabled"`
+	// path of a MaxMind country or city database (.mmdb).
+	GeoIP string `json:"geoip"`
+	// path of a MaxMind ASN database (.mmdb).
+	GeoIPASN string `json:"geoip_asn"`
+	// base url of the RDAP service queried for ownership details.
+	RDAP string `json:"rdap"`
+	// url or path of the IEEE MAC addresses registry (oui.csv).
+	OUI string `json:"oui"`
+}
+
+// dnsSettings defines the server used by the dns lookups.
+type dnsSettings struct {
+	// server address (port 53 by default). Empty means the system resolver.
+	Resolver string `json:"resolver"`
+	// maximum seconds to wait for each query.
+	Timeout int `json:"timeout"`
+}
+
+// scanSettings defines the tcp ports scans.
+type scanSettings struct {
+	// default ports list : top100 and/or comma-separated ports and ranges.
+	Ports string `json:"ports"`
+	// maximum concurrent connections attempts.
+	Parallel int `json:"parallel"`
+	// milliseconds to wait for each connection.
+	Timeout int `json:"timeout"`
+}
+
+// tlsSettings defines the certificates checks.
+type tlsSettings struct {
+	// alert when a certificate expires within these days.
+	WarnDays int `json:"warn_days"`
+	// certificates checked periodically as "ip[:port] [sni]".
+	Checks []string `json:"checks"`
+	// hours between two periodic checks.
+	Every int `json:"every"`
+}
+
+// active holds the global settings. A reload replaces them as a
+// whole so each reader works on the snapshot from getSettings.
+var active atomic.Value
+
+func init() {
+	active.Store(defaultSettings())
+}
+
+// getSettings returns the current global settings. They are shared
+// so must not be modified.
+func getSettings() *settings {
+	return active.Load().(*settings)
+}
+
+// setSettings replaces the global settings.
+func setSettings(s *settings) {
+	active.Store(s)
+}
+
+// defaultSettings returns the configuration used when no file is provided.
+func defaultSettings() *settings {
+	return &settings{
+		Alerts: alertsSettings{
+			DownAfter:      3,
+			Cooldown:       5,
+			FlapCount:      4,
+			FlapWindow:     10,
+			HighlightClear: 5,
+		},
+		SMTP: smtpSettings{
+			Port:  25,
+			Batch: 60,
+		},
+		Exec: execSettings{
+			Timeout: 30,
+		},
+		Syslog: syslogSettings{
+			Network:  "udp",
+			Address:  "127.0.0.1:514",
+			Facility: 16,
+		},
+		SNMP: snmpSettings{
+			Destination: "127.0.0.1:162",
+			Community:   "public",
+			OID:         "1.3.6.1.4.1.8072.9999.1",
+		},
+		HTTP: httpSettings{
+			Address: "127.0.0.1:9595",
+		},
+		GRPC: grpcSettings{
+			Address: "127.0.0.1:9596",
+		},
+		History:        10000,
+		HistoryMinutes: 7 * 24 * 60,
+		OutputLines:    5000,
+		MemoryLimit:    512,
+		Engine:         ENGINEAUTO,
+		Interval:       60,
+		Influx: influxSettings{
+			URL:      "http://127.0.0.1:8086",
+			Version:  1,
+			Database: "pingo",
+		},
+		Graphite: metricsSinkSettings{
+			Address: "127.0.0.1:2003",
+			Prefix:  "pingo",
+		},
+		Statsd: metricsSinkSettings{
+			Address: "127.0.0.1:8125",
+			Prefix:  "pingo",
+		},
+		Store: storeSettings{
+			Path:      "pingo.db",
+			Retention: 30,
+		},
+		Backup: backupSettings{
+			Dir:     ".",
+			MaxSize: 10,
+		},
+		Reports: reportsSettings{
+			Dir: "reports",
+		},
+		Enrich: enrichSettings{
+			Enabled: true,
+			RDAP:    "https://rdap.org",
+			OUI:     "https://standards-oui.ieee.org/oui/oui.csv",
+		},
+		Parallel: 4,
+		DNS: dnsSettings{
+			Timeout: 5,
+		},
+		Scan: scanSettings{
+			Ports:    "top100",
+			Parallel: 50,
+			Timeout:  1000,
+		},
+		TLS: tlsSettings{
+			WarnDays: 14,
+			Every:    12,
+		},
+		SNMPPoll: snmpPollSettings{
+			Version:      "v2c",
+			Community:    "public",
+			Port:         161,
+			Timeout:      3,
+			AuthProtocol: "sha",
+			PrivProtocol: "aes",
+		},
+		Iperf: iperfSettings{
+			Path:     "iperf3",
+			Duration: 10,
+			Streams:  1,
+		},
+		LookingGlass: []lookingGlassSettings{
+			{
+				Name:   "RIPEstat",
+				URL:    "https://stat.ripe.net/data/looking-glass/data.json?resource={ip}",
+				Format: "ripestat",
+			},
+		},
+		Playbooks: []playbookSettings{
+			{
+				Name:  "triage",
+				Steps: []string{"ping 10", "trace", "dns all", "scan 22,80,443"},
+			},
+		},
+		Deadlines: deadlinesSettings{
+			Probe: 10,
+			Run:   15,
+		},
+		Baseline: baselineSettings{
+			Sigma:  3,
+			Warmup: 30,
+			Window: 300,
+		},
+		Subnet: subnetSettings{
+			Prefix:  24,
+			Prefix6: 64,
+		},
+		Templates: []templateSettings{
+			{
+				Name:      "WAN router",
+				Interval:  500,
+				Size:      64,
+				Threshold: 80,
+				MaxHops:   20,
+			},
+			{
+				Name:      "LAN switch",
+				Threshold: 5,
+				MaxHops:   5,
+			},
+		},
+		Vantages: []vantageSettings{},
+		Queues: queuesSettings{
+			Samples: 1000,
+			Alerts:  100,
+			UI:      BUSQUEUESIZE,
+			Policy:  POLICYDROPNEWEST,
+		},
+	}
+}
+
+// loadSettings reads the JSON configuration file and fill the defaults
+// settings with its content. Missing file means to use defaults values.
+func loadSettings(filename string) *settings {
+	s, _ := readSettings(filename)
+	return s
+}
+
+// readSettings fills the defaults settings with the content of the
+// JSON configuration file. It returns the defaults and false when the
+// file is missing or invalid.
+func readSettings(filename string) (*settings, bool) {
+	s := defaultSettings()
+
+	content, err := ioutil.ReadFile(filename)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			settingLog.Error("Failed to read settings file", "file", filename, "err", err)
+			showError("Failed to read %s : %v", filename, err)
+		}
+		return s, false
+	}
+
+	if err = json.Unmarshal(content, s); err != nil {
+		settingLog.Error("Failed to parse settings file", "file", filename, "err", err)
+		showError("Failed to parse %s, the default settings are used : %v", filename, err)
+		return defaultSettings(), false
+	}
+
+	// ensure minimal sane values.
+	if s.Alerts.DownAfter <= 0 {
+		s.Alerts.DownAfter = 3
+	}
+
+	if s.Alerts.Cooldown < 0 {
+		s.Alerts.Cooldown = 0
+	}
+
+	if s.Alerts.FlapWindow <= 0 {
+		s.Alerts.FlapWindow = 10
+	}
+
+	if s.Alerts.HighlightClear < 0 {
+		s.Alerts.HighlightClear = 0
+	}
+
+	if s.Alerts.Digest.Worst <= 0 {
+		s.Alerts.Digest.Worst = DIGESTWORST
+	}
+
+	if s.SMTP.Batch <= 0 {
+		s.SMTP.Batch = 60
+	}
+
+	if s.Exec.Timeout <= 0 {
+		s.Exec.Timeout = 30
+	}
+
+	if s.History <= 0 {
+		s.History = 10000
+	}
+
+	if s.HistoryMinutes < 0 {
+		s.HistoryMinutes = 0
+	}
+
+	if s.MemoryLimit < 0 {
+		s.MemoryLimit = 0
+	}
+
+	if s.OutputLines <= 0 {
+		s.OutputLines = 5000
+	}
+
+	switch s.Engine = strings.ToLower(s.Engine); s.Engine {
+	case ENGINEAUTO, ENGINENATIVE, ENGINESYSTEM:
+	default:
+		s.Engine = ENGINEAUTO
+	}
+
+	if !probe.KnownCodePage(s.CodePage) {
+		s.CodePage = 0
+	}
+
+	if s.Interval <= 0 {
+		s.Interval = 60
+	}
+
+	if s.Backup.Dir == "" {
+		s.Backup.Dir = "."
+	}
+
+	if s.Store.Retention < 0 {
+		s.Store.Retention = 0
+	}
+
+	if s.Backup.MaxSize <= 0 {
+		s.Backup.MaxSize = 10
+	}
+
+	if s.Parallel <= 0 {
+		s.Parallel = 4
+	}
+
+	if s.Enrich.RDAP == "" {
+		s.Enrich.RDAP = "https://rdap.org"
+	}
+
+	if s.Scan.Ports == "" {
+		s.Scan.Ports = "top100"
+	}
+
+	if s.Scan.Parallel <= 0 {
+		s.Scan.Parallel = 50
+	}
+
+	if s.Scan.Timeout <= 0 {
+		s.Scan.Timeout = 1000
+	}
+
+	if s.Iperf.Path == "" {
+		s.Iperf.Path = "iperf3"
+	}
+
+	if s.Iperf.Duration <= 0 {
+		s.Iperf.Duration = 10
+	}
+
+	if s.Iperf.Streams <= 0 {
+		s.Iperf.Streams = 1
+	}
+
+	if s.SNMPPoll.Version != "v3" {
+		s.SNMPPoll.Version = "v2c"
+	}
+
+	if s.SNMPPoll.Port <= 0 {
+		s.SNMPPoll.Port = 161
+	}
+
+	if s.SNMPPoll.Timeout <= 0 {
+		s.SNMPPoll.Timeout = 3
+	}
+
+	if s.TLS.WarnDays < 0 {
+		s.TLS.WarnDays = 14
+	}
+
+	if s.TLS.Every <= 0 {
+		s.TLS.Every = 12
+	}
+
+	if s.Queues.Workers < 0 {
+		s.Queues.Workers = 0
+	}
+
+	if s.Queues.Samples <= 0 {
+		s.Queues.Samples = 1000
+	}
+
+	if s.Queues.Alerts <= 0 {
+		s.Queues.Alerts = 100
+	}
+
+	if s.Queues.UI <= 0 {
+		s.Queues.UI = BUSQUEUESIZE
+	}
+
+	switch s.Queues.Policy = strings.ToLower(s.Queues.Policy); s.Queues.Policy {
+	case POLICYBLOCK, POLICYDROPOLDEST, POLICYDROPNEWEST:
+	default:
+		s.Queues.Policy = POLICYDROPNEWEST
+	}
+
+	if s.DNS.Timeout <= 0 {
+		s.DNS.Timeout = 5
+	}
+
+	if s.Deadlines.Probe < 0 {
+		s.Deadlines.Probe = 0
+	}
+
+	if s.Deadlines.Run < 0 {
+		s.Deadlines.Run = 0
+	}
+
+	if s.Alerts.Outage.Window <= 0 {
+		s.Alerts.Outage.Window = 30
+	}
+
+	if s.Subnet.Prefix <= 0 || s.Subnet.Prefix > 32 {
+		s.Subnet.Prefix = 24
+	}
+
+	if s.Subnet.Prefix6 <= 0 || s.Subnet.Prefix6 > 128 {
+		s.Subnet.Prefix6 = 64
+	}
+
+	if s.Baseline.Sigma < 0 {
+		s.Baseline.Sigma = 0
+	}
+
+	if s.Baseline.Warmup <= 0 {
+		s.Baseline.Warmup = 30
+	}
+
+	if s.Baseline.Window < s.Baseline.Warmup {
+		s.Baseline.Window = s.Baseline.Warmup
+	}
+
+	if s.Syslog.Network != "tcp" {
+		s.Syslog.Network = "udp"
+	}
+
+	if s.Syslog.Facility < 0 || s.Syslog.Facility > 23 {
+		s.Syslog.Facility = 16
+	}
+
+	return s, true
+}
+
+// reloadSettings reads again the configuration file and applies the
+// settings read on each use : alerts, baseline, deadlines, templates,
+// interval, history limits and policy of the samples queue. The other
+// settings, used to start the sinks, servers and queues, need a
+// restart. The current settings are kept if the file is invalid.
+func reloadSettings(filename string) bool {
+	s, ok := readSettings(filename)
+	if !ok {
+		return false
+	}
+	next := *getSettings()
+	next.Alerts, next.Baseline, next.Deadlines, next.Templates = s.Alerts, s.Baseline, s.Deadlines, s.Templates
+	next.Interval, next.History, next.HistoryMinutes, next.OutputLines = s.Interval, s.History, s.HistoryMinutes, s.OutputLines
+	next.Queues.Policy = s.Queues.Policy
+	setSettings(&next)
+	settingLog.Info("Settings reloaded", "file", filename)
+	return true
+}
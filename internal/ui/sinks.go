@@ -0,0 +1,120 @@
+package ui
+
+import (
+	"time"
+)
+
+// sample represents the result of a single probe.
+type sample struct {
+	ip      string
+	time    time.Time
+	rtt     int
+	success bool
+}
+
+// sink defines any destination of probes results. It receives each
+// sample and periodically the summarized statistics of each target.
+type sink interface {
+	sample(sp sample)
+	summary(ip string, s stat, t time.Time)
+}
+
+// samples to be delivered to sinks, sized by initQueues.
+var samplesChan = make(chan sample, 1000)
+
+// closed on exit once the last queued samples were handed to
+// the sinks, so they can write their pending data and stop.
+var sinksDrained = make(chan struct{})
+
+// publishSample records a probe result and queues it for the sinks.
+// It only blocks the caller with the block policy of the queue.
+func publishSample(ip string, rtt int, success bool) {
+	sp := sample{ip: ip, time: time.Now(), rtt: rtt, success: success}
+	dbs.addSample(sp)
+	queueSample(sp)
+}
+
+// buildSinks returns the list of results sinks enabled into settings.
+func buildSinks() []sink {
+	cfgs := getSettings()
+	var sinks []sink
+	if cfgs.Influx.Enabled {
+		sinks = append(sinks, newInfluxSink(cfgs.Influx))
+	}
+
+	if cfgs.Graphite.Enabled {
+		sinks = append(sinks, newGraphiteSink(cfgs.Graphite))
+	}
+
+	if cfgs.Statsd.Enabled {
+		sinks = append(sinks, newStatsdSink(cfgs.Statsd))
+	}
+
+	if cfgs.Stream != "" {
+		sinks = append(sinks, newNDJSONSink(cfgs.Stream))
+	}
+
+	if store != nil {
+		sinks = append(sinks, store)
+	}
+
+	// websocket clients and attached uis.
+	sinks = append(sinks, hub)
+
+	// StreamSamples callers of the grpc api.
+	if cfgs.GRPC.Enabled {
+		sinks = append(sinks, feed)
+	}
+	return sinks
+}
+
+// samplesDispatcher forwards each sample to all sinks and
+// sends to them the statistics summary at each interval.
+func samplesDispatcher(sinks []sink, interval int) {
+	ticker := time.NewTicker(time.Duration(interval) * time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case sp := <-samplesChan:
+			for _, sk := range sinks {
+				sk.sample(sp)
+			}
+		case now := <-ticker.C:
+			for _, ip := range dbs.getAllIPs() {
+				s := dbs.getStats(ip)
+				if s == nil || s.fails+s.replies() == 0 {
+					continue
+				}
+				for _, sk := range sinks {
+					sk.summary(ip, *s, now)
+				}
+			}
+		case <-exit:
+			for {
+				select {
+				case sp := <-samplesChan:
+					for _, sk := range sinks {
+						sk.sample(sp)
+					}
+				default:
+					close(sinksDrained)
+					return
+				}
+			}
+		}
+	}
+}
+
+// isTargetSelected tells if an ip is part of a sink targets list.
+// An empty list means all targets.
+func isTargetSelected(ip string, targets []string) bool {
+	if len(targets) == 0 {
+		return true
+	}
+	for _, t := range targets {
+		if t == ip {
+			return true
+		}
+	}
+	return false
+}
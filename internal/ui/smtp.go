@@ -0,0 +1,147 @@
+package ui
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// SMTPTIMEOUT bounds the delivery of an email batch.
+	SMTPTIMEOUT = 30 * time.Second
+	// SMTPFLUSHTIMEOUT bounds the delivery of the last batch on exit.
+	SMTPFLUSHTIMEOUT = 5 * time.Second
+)
+
+// smtpNotifier sends alerts by email. Alerts received within the
+// batch window are grouped into a single email to avoid mail storms.
+type smtpNotifier struct {
+	cfg   smtpSettings
+	queue chan alert
+}
+
+// newSMTPNotifier creates an email notifier and starts its batch worker.
+func newSMTPNotifier(cfg smtpSettings) *smtpNotifier {
+	n := &smtpNotifier{cfg: cfg, queue: make(chan alert, 100)}
+	wg.Add(1)
+	go n.run()
+	return n
+}
+
+// notify queues an alert for the next email batch.
+func (n *smtpNotifier) notify(a alert) {
+	select {
+	case n.queue <- a:
+	default:
+		alertsLog.Warn("Email alerts queue full, dropped alert", "notifier", "smtp", "alert", a)
+	}
+}
+
+// run collects alerts until the batch window expires then sends them.
+func (n *smtpNotifier) run() {
+	defer wg.Done()
+	defer recoverPanic("smtpNotifier.run")
+	var batch []alert
+	var timer <-chan time.Time
+	for {
+		select {
+		case a := <-n.queue:
+			batch = append(batch, a)
+			if timer == nil {
+				timer = time.After(time.Duration(n.cfg.Batch) * time.Second)
+			}
+		case <-timer:
+			if err := n.send(batch, SMTPTIMEOUT); err != nil {
+				alertsLog.Error("Failed to send alerts email", "notifier", "smtp", "err", err)
+			}
+			batch, timer = nil, nil
+		case <-exit:
+			if len(batch) > 0 {
+				if err := n.send(batch, SMTPFLUSHTIMEOUT); err != nil {
+					alertsLog.Error("Failed to send alerts email", "notifier", "smtp", "err", err)
+				}
+			}
+			return
+		}
+	}
+}
+
+// send builds a single email summarizing all alerts of the batch and
+// delivers it within timeout, upgrading to TLS when the server offers
+// STARTTLS. An unreachable or stalled server cannot hold the notifier.
+func (n *smtpNotifier) send(batch []alert, timeout time.Duration) error {
+	if len(batch) == 0 {
+		return nil
+	}
+
+	var targets []string
+	var body strings.Builder
+	for _, a := range batch {
+		target := fmt.Sprintf("%s %s", a.ip, a.state)
+		if a.state == STATEDIGEST {
+			target = a.state
+		}
+		targets = append(targets, target)
+		fmt.Fprintf(&body, "%s\r\n", a)
+	}
+
+	subject := fmt.Sprintf("[pingo] %d alert(s): %s", len(batch), strings.Join(targets, ", "))
+	msg := fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\nDate: %s\r\n\r\n%s",
+		n.cfg.From, strings.Join(n.cfg.To, ", "), subject,
+		time.Now().Format(time.RFC1123Z), body.String())
+
+	addr := net.JoinHostPort(n.cfg.Host, strconv.Itoa(n.cfg.Port))
+	conn, err := net.DialTimeout("tcp", addr, timeout)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if err := conn.SetDeadline(time.Now().Add(timeout)); err != nil {
+		return err
+	}
+
+	c, err := smtp.NewClient(conn, n.cfg.Host)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(&tls.Config{ServerName: n.cfg.Host}); err != nil {
+			return err
+		}
+	}
+	if n.cfg.Username != "" {
+		if ok, _ := c.Extension("AUTH"); !ok {
+			return errors.New("smtp server does not support authentication")
+		}
+		if err := c.Auth(smtp.PlainAuth("", n.cfg.Username, n.cfg.Password, n.cfg.Host)); err != nil {
+			return err
+		}
+	}
+
+	if err := c.Mail(n.cfg.From); err != nil {
+		return err
+	}
+	for _, to := range n.cfg.To {
+		if err := c.Rcpt(to); err != nil {
+			return err
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write([]byte(msg)); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
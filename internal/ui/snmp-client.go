@@ -0,0 +1,696 @@
+package ui
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/des"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/sha1"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"math/rand"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// BER types of the values returned by agents and the PDUs used by
+// the snmp client.
+const (
+	BERIPADDRESS = 0x40
+	BERCOUNTER32 = 0x41
+	BERGAUGE32   = 0x42
+	BERCOUNTER64 = 0x46
+	BERNOSUCHOBJ = 0x80
+	BERNOSUCHINS = 0x81
+	BERENDOFMIB  = 0x82
+
+	SNMPGET      = 0xa0
+	SNMPRESPONSE = 0xa2
+	SNMPGETBULK  = 0xa5
+	SNMPREPORT   = 0xa8
+)
+
+// SNMPv3 message flags.
+const (
+	USMAUTH       = 0x01
+	USMPRIV       = 0x02
+	USMREPORTABLE = 0x04
+)
+
+// usm statistics oids returned into the reports of an agent.
+var usmReports = map[string]string{
+	"1.3.6.1.6.3.15.1.1.1.0": "unsupported security level",
+	"1.3.6.1.6.3.15.1.1.2.0": "not in time window",
+	"1.3.6.1.6.3.15.1.1.3.0": "unknown user name",
+	"1.3.6.1.6.3.15.1.1.4.0": "unknown engine id",
+	"1.3.6.1.6.3.15.1.1.5.0": "wrong digest (check the auth password)",
+	"1.3.6.1.6.3.15.1.1.6.0": "decryption error (check the priv password)",
+}
+
+// errors status names of a response pdu.
+var snmpErrors = []string{"noError", "tooBig", "noSuchName", "badValue", "readOnly", "genErr",
+	"noAccess", "wrongType", "wrongLength", "wrongEncoding", "wrongValue", "noCreation",
+	"inconsistentValue", "resourceUnavailable", "commitFailed", "undoFailed", "authorizationError",
+	"notWritable", "inconsistentName"}
+
+var errNotInTimeWindow = errors.New("snmp agent replied : not in time window")
+
+// snmpVarbind is an oid and its BER encoded value.
+type snmpVarbind struct {
+	oid   string
+	tag   byte
+	value []byte
+}
+
+// uint returns the value of an integer, counter, gauge or timeticks.
+func (vb snmpVarbind) uint() uint64 {
+	var v uint64
+	for _, b := range vb.value {
+		v = v<<8 | uint64(b)
+	}
+	return v
+}
+
+// String returns the value as displayed.
+func (vb snmpVarbind) String() string {
+	switch vb.tag {
+	case BEROCTETSTR:
+		return string(vb.value)
+	case BERINTEGER:
+		var v int64
+		for i, b := range vb.value {
+			if i == 0 && b&0x80 != 0 {
+				v = -1
+			}
+			v = v<<8 | int64(b)
+		}
+		return strconv.FormatInt(v, 10)
+	case BERCOUNTER32, BERGAUGE32, BERTIMETICKS, BERCOUNTER64:
+		return strconv.FormatUint(vb.uint(), 10)
+	case BERIPADDRESS:
+		return net.IP(vb.value).String()
+	case BEROID:
+		return decodeOID(vb.value)
+	case BERNOSUCHOBJ, BERNOSUCHINS:
+		return "n/a"
+	}
+	return fmt.Sprintf("%x", vb.value)
+}
+
+// exists tells if the agent returned a value for the oid.
+func (vb snmpVarbind) exists() bool {
+	return vb.tag != BERNOSUCHOBJ && vb.tag != BERNOSUCHINS && vb.tag != BERENDOFMIB && vb.tag != BERNULL
+}
+
+// berRead decodes the first type-length-value element of data.
+func berRead(data []byte) (byte, []byte, []byte, error) {
+	if len(data) < 2 {
+		return 0, nil, nil, errors.New("truncated ber element")
+	}
+	tag, l, data := data[0], int(data[1]), data[2:]
+	if l&0x80 != 0 {
+		n := l & 0x7f
+		if n == 0 || n > 4 || len(data) < n {
+			return 0, nil, nil, errors.New("invalid ber length")
+		}
+		l = 0
+		for _, b := range data[:n] {
+			l = l<<8 | int(b)
+		}
+		data = data[n:]
+	}
+	if l > len(data) {
+		return 0, nil, nil, errors.New("truncated ber element")
+	}
+	return tag, data[:l], data[l:], nil
+}
+
+// berReadAll decodes the successive elements of a sequence, checking
+// their types when expected ones are given (0 accepts any type).
+func berReadAll(data []byte, tags ...byte) ([][]byte, error) {
+	var values [][]byte
+	for i := 0; len(data) > 0; i++ {
+		tag, value, rest, err := berRead(data)
+		if err != nil {
+			return nil, err
+		}
+		if i < len(tags) && tags[i] != 0 && tags[i] != tag {
+			return nil, fmt.Errorf("unexpected ber type 0x%02x", tag)
+		}
+		values = append(values, value)
+		data = rest
+	}
+	if len(values) < len(tags) {
+		return nil, errors.New("truncated ber sequence")
+	}
+	return values, nil
+}
+
+// berInt returns the value of a positive integer element.
+func berInt(value []byte) int {
+	return int(snmpVarbind{value: value}.uint())
+}
+
+// decodeOID returns the dotted form of an encoded object identifier.
+func decodeOID(value []byte) string {
+	if len(value) == 0 {
+		return ""
+	}
+	parts := []string{strconv.Itoa(int(value[0]) / 40), strconv.Itoa(int(value[0]) % 40)}
+	var id uint64
+	for _, b := range value[1:] {
+		id = id<<7 | uint64(b&0x7f)
+		if b&0x80 == 0 {
+			parts = append(parts, strconv.FormatUint(id, 10))
+			id = 0
+		}
+	}
+	return strings.Join(parts, ".")
+}
+
+// snmpClient queries an agent using SNMPv2c or SNMPv3 with the user
+// security model (noAuthNoPriv, authNoPriv or authPriv depending on
+// the configured passwords).
+type snmpClient struct {
+	cfg  snmpPollSettings
+	conn net.Conn
+
+	// SNMPv3 authoritative engine discovered and the localized keys.
+	engineID   []byte
+	boots      int
+	engineTime int
+	discovered time.Time
+	authKey    []byte
+	privKey    []byte
+	salt       uint64
+}
+
+// newSNMPClient connects to the agent of an ip. The SNMPv3 engine
+// is discovered first to localize the keys.
+func newSNMPClient(ip string, cfg snmpPollSettings) (*snmpClient, error) {
+	conn, err := net.Dial("udp", net.JoinHostPort(ip, strconv.Itoa(cfg.Port)))
+	if err != nil {
+		return nil, err
+	}
+	c := &snmpClient{cfg: cfg, conn: conn, salt: rand.Uint64()}
+	if cfg.Version != "v3" {
+		return c, nil
+	}
+
+	if cfg.User == "" {
+		conn.Close()
+		return nil, errors.New("snmpv3 user is not configured")
+	}
+	if cfg.PrivPassword != "" && cfg.AuthPassword == "" {
+		conn.Close()
+		return nil, errors.New("snmpv3 privacy requires an auth password")
+	}
+	if err = c.discover(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+// close releases the client socket.
+func (c *snmpClient) close() {
+	c.conn.Close()
+}
+
+// get returns the values of some oids.
+func (c *snmpClient) get(oids ...string) ([]snmpVarbind, error) {
+	return c.request(SNMPGET, 0, 0, oids)
+}
+
+// walk returns all values under a root oid using bulk requests.
+func (c *snmpClient) walk(root string) ([]snmpVarbind, error) {
+	var all []snmpVarbind
+	next := root
+	for {
+		vbs, err := c.request(SNMPGETBULK, 0, 25, []string{next})
+		if err != nil {
+			return nil, err
+		}
+		if len(vbs) == 0 {
+			return all, nil
+		}
+		for _, vb := range vbs {
+			if !strings.HasPrefix(vb.oid, root+".") || vb.tag == BERENDOFMIB {
+				return all, nil
+			}
+			all = append(all, vb)
+		}
+		// the agent must progress to avoid looping forever.
+		if last := vbs[len(vbs)-1].oid; last != next {
+			next = last
+		} else {
+			return all, nil
+		}
+	}
+}
+
+// request sends a pdu and returns the response varbinds. The bulk
+// parameters are ignored for the other pdus types.
+func (c *snmpClient) request(pduType byte, nonRepeaters, maxRepetitions int, oids []string) ([]snmpVarbind, error) {
+	vbs, err := c.exchange(pduType, nonRepeaters, maxRepetitions, oids)
+	if err == errNotInTimeWindow {
+		// the report refreshed the engine boots and time.
+		vbs, err = c.exchange(pduType, nonRepeaters, maxRepetitions, oids)
+	}
+	return vbs, err
+}
+
+// exchange sends a single request and waits for its response.
+func (c *snmpClient) exchange(pduType byte, nonRepeaters, maxRepetitions int, oids []string) ([]snmpVarbind, error) {
+	var varbinds []byte
+	for _, oid := range oids {
+		encoded, err := berOID(oid)
+		if err != nil {
+			return nil, err
+		}
+		varbinds = append(varbinds, berTLV(BERSEQUENCE, append(encoded, berTLV(BERNULL, nil)...))...)
+	}
+
+	requestID := int(rand.Int31())
+	pdu := berTLV(BERINTEGER, berUint(uint64(requestID)))
+	pdu = append(pdu, berTLV(BERINTEGER, berUint(uint64(nonRepeaters)))...)
+	pdu = append(pdu, berTLV(BERINTEGER, berUint(uint64(maxRepetitions)))...)
+	pdu = append(pdu, berTLV(BERSEQUENCE, varbinds)...)
+	pdu = berTLV(pduType, pdu)
+
+	var msg []byte
+	var err error
+	if c.cfg.Version == "v3" {
+		msg, err = c.buildV3(pdu, requestID, c.securityFlags())
+	} else {
+		// version 1 stands for SNMPv2c.
+		msg = berTLV(BERINTEGER, berUint(1))
+		msg = append(msg, berTLV(BEROCTETSTR, []byte(c.cfg.Community))...)
+		msg = berTLV(BERSEQUENCE, append(msg, pdu...))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		packet, err := c.roundTrip(msg)
+		if err != nil {
+			return nil, err
+		}
+
+		if c.cfg.Version == "v3" {
+			pdu, err = c.parseV3(packet, requestID)
+		} else {
+			pdu, err = parseV2c(packet)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		tag, value, _, err := berRead(pdu)
+		if err != nil {
+			return nil, err
+		}
+		id, vbs, err := parsePDU(value)
+		if err != nil {
+			return nil, err
+		}
+		// late response of a previous request. A report of an
+		// undecryptable request may not carry its id.
+		if id != requestID && tag != SNMPREPORT {
+			continue
+		}
+
+		switch tag {
+		case SNMPRESPONSE:
+			return vbs, nil
+		case SNMPREPORT:
+			if len(vbs) > 0 {
+				if reason, ok := usmReports[vbs[0].oid]; ok {
+					if vbs[0].oid == "1.3.6.1.6.3.15.1.1.2.0" {
+						return nil, errNotInTimeWindow
+					}
+					return nil, errors.New("snmp agent replied : " + reason)
+				}
+			}
+			return nil, errors.New("snmp agent replied with a report")
+		default:
+			return nil, fmt.Errorf("unexpected snmp pdu type 0x%02x", tag)
+		}
+	}
+}
+
+// roundTrip writes a message and reads the next datagram received.
+func (c *snmpClient) roundTrip(msg []byte) ([]byte, error) {
+	c.conn.SetDeadline(time.Now().Add(time.Duration(c.cfg.Timeout) * time.Second))
+	if _, err := c.conn.Write(msg); err != nil {
+		return nil, err
+	}
+	buf := make([]byte, 65535)
+	n, err := c.conn.Read(buf)
+	if err != nil {
+		if ne, ok := err.(net.Error); ok && ne.Timeout() {
+			return nil, errors.New("no response from the snmp agent (check version and credentials)")
+		}
+		return nil, err
+	}
+	return buf[:n], nil
+}
+
+// parseV2c returns the pdu of a SNMPv2c message.
+func parseV2c(packet []byte) ([]byte, error) {
+	_, msg, _, err := berRead(packet)
+	if err != nil {
+		return nil, err
+	}
+	// version, community then the pdu.
+	for i := 0; i < 2; i++ {
+		if _, _, msg, err = berRead(msg); err != nil {
+			return nil, err
+		}
+	}
+	return msg, nil
+}
+
+// parsePDU returns the request id and the varbinds of a pdu and
+// fails if it reports an error.
+func parsePDU(pdu []byte) (int, []snmpVarbind, error) {
+	fields, err := berReadAll(pdu, BERINTEGER, BERINTEGER, BERINTEGER, BERSEQUENCE)
+	if err != nil {
+		return 0, nil, err
+	}
+	id := berInt(fields[0])
+	if status := berInt(fields[1]); status != 0 {
+		name := strconv.Itoa(status)
+		if status < len(snmpErrors) {
+			name = snmpErrors[status]
+		}
+		return id, nil, fmt.Errorf("snmp agent replied %s (index %d)", name, berInt(fields[2]))
+	}
+
+	list, err := berReadAll(fields[3])
+	if err != nil {
+		return 0, nil, err
+	}
+	var vbs []snmpVarbind
+	for _, item := range list {
+		tag, oid, rest, err := berRead(item)
+		if err != nil || tag != BEROID {
+			return 0, nil, errors.New("invalid snmp varbind")
+		}
+		vtag, value, _, err := berRead(rest)
+		if err != nil {
+			return 0, nil, err
+		}
+		vbs = append(vbs, snmpVarbind{oid: decodeOID(oid), tag: vtag, value: value})
+	}
+	return id, vbs, nil
+}
+
+// securityFlags returns the SNMPv3 security level of the requests.
+func (c *snmpClient) securityFlags() byte {
+	flags := byte(USMREPORTABLE)
+	if c.cfg.AuthPassword != "" {
+		flags |= USMAUTH
+	}
+	if c.cfg.PrivPassword != "" {
+		flags |= USMPRIV
+	}
+	return flags
+}
+
+// discover sends an empty unauthenticated request so the agent
+// reports its engine id, boots and time then localizes the keys.
+func (c *snmpClient) discover() error {
+	requestID := int(rand.Int31())
+	pdu := berTLV(BERINTEGER, berUint(uint64(requestID)))
+	pdu = append(pdu, berTLV(BERINTEGER, berUint(0))...)
+	pdu = append(pdu, berTLV(BERINTEGER, berUint(0))...)
+	pdu = append(pdu, berTLV(BERSEQUENCE, nil)...)
+
+	msg, err := c.buildV3(berTLV(SNMPGET, pdu), requestID, USMREPORTABLE)
+	if err != nil {
+		return err
+	}
+	packet, err := c.roundTrip(msg)
+	if err != nil {
+		return err
+	}
+	if _, err = c.parseV3(packet, requestID); err != nil {
+		return err
+	}
+	if len(c.engineID) == 0 {
+		return errors.New("snmp agent did not report its engine id")
+	}
+
+	if c.cfg.AuthPassword != "" {
+		newHash := usmHash(c.cfg.AuthProtocol)
+		c.authKey = localizeKey(newHash, c.cfg.AuthPassword, c.engineID)
+		if c.cfg.PrivPassword != "" {
+			c.privKey = localizeKey(newHash, c.cfg.PrivPassword, c.engineID)
+		}
+	}
+	return nil
+}
+
+// usmHash returns the hash function of an auth protocol.
+func usmHash(protocol string) func() hash.Hash {
+	if strings.EqualFold(protocol, "md5") {
+		return md5.New
+	}
+	return sha1.New
+}
+
+// localizeKey derives the key of a password for an engine (RFC 3414).
+func localizeKey(newHash func() hash.Hash, password string, engineID []byte) []byte {
+	h := newHash()
+	buf := make([]byte, 64)
+	for i, n := 0, 0; n < 1048576; n += 64 {
+		for j := range buf {
+			buf[j] = password[i%len(password)]
+			i++
+		}
+		h.Write(buf)
+	}
+	ku := h.Sum(nil)
+
+	h.Reset()
+	h.Write(ku)
+	h.Write(engineID)
+	h.Write(ku)
+	return h.Sum(nil)
+}
+
+// buildV3 encodes a SNMPv3 message, encrypting and signing it
+// according to the security flags.
+func (c *snmpClient) buildV3(pdu []byte, msgID int, flags byte) ([]byte, error) {
+	engineTime := c.engineTime
+	if !c.discovered.IsZero() {
+		engineTime += int(time.Since(c.discovered) / time.Second)
+	}
+
+	scoped := berTLV(BEROCTETSTR, c.engineID)
+	scoped = append(scoped, berTLV(BEROCTETSTR, nil)...)
+	scoped = berTLV(BERSEQUENCE, append(scoped, pdu...))
+
+	var privParams []byte
+	data := scoped
+	if flags&USMPRIV != 0 {
+		var err error
+		if data, privParams, err = c.encrypt(scoped, engineTime); err != nil {
+			return nil, err
+		}
+		data = berTLV(BEROCTETSTR, data)
+	}
+
+	header := berTLV(BERINTEGER, berUint(uint64(msgID)))
+	header = append(header, berTLV(BERINTEGER, berUint(65507))...)
+	header = append(header, berTLV(BEROCTETSTR, []byte{flags})...)
+	header = append(header, berTLV(BERINTEGER, berUint(3))...)
+
+	var authParams []byte
+	if flags&USMAUTH != 0 {
+		authParams = make([]byte, 12)
+	}
+	// the engine discovery is anonymous.
+	user := ""
+	if c.engineID != nil {
+		user = c.cfg.User
+	}
+	params := berTLV(BEROCTETSTR, c.engineID)
+	params = append(params, berTLV(BERINTEGER, berUint(uint64(c.boots)))...)
+	params = append(params, berTLV(BERINTEGER, berUint(uint64(engineTime)))...)
+	params = append(params, berTLV(BEROCTETSTR, []byte(user))...)
+	// offset of the digest placeholder from the end of the parameters.
+	tail := len(berTLV(BEROCTETSTR, privParams)) + len(authParams)
+	params = append(params, berTLV(BEROCTETSTR, authParams)...)
+	params = append(params, berTLV(BEROCTETSTR, privParams)...)
+	params = berTLV(BERSEQUENCE, params)
+
+	msg := berTLV(BERINTEGER, berUint(3))
+	msg = append(msg, berTLV(BERSEQUENCE, header)...)
+	msg = append(msg, berTLV(BEROCTETSTR, params)...)
+	// offset of the digest placeholder from the end of the message.
+	tail += len(data)
+	msg = berTLV(BERSEQUENCE, append(msg, data...))
+
+	if flags&USMAUTH != 0 {
+		mac := hmac.New(usmHash(c.cfg.AuthProtocol), c.authKey)
+		mac.Write(msg)
+		copy(msg[len(msg)-tail:], mac.Sum(nil)[:12])
+	}
+	return msg, nil
+}
+
+// parseV3 checks a SNMPv3 message, records the engine parameters it
+// carries and returns its decrypted pdu.
+func (c *snmpClient) parseV3(packet []byte, msgID int) ([]byte, error) {
+	_, msg, _, err := berRead(packet)
+	if err != nil {
+		return nil, err
+	}
+	fields, err := berReadAll(msg, BERINTEGER, BERSEQUENCE, BEROCTETSTR, 0)
+	if err != nil {
+		return nil, err
+	}
+	if berInt(fields[0]) != 3 {
+		return nil, errors.New("snmp agent replied with another version")
+	}
+	header, err := berReadAll(fields[1], BERINTEGER, BERINTEGER, BEROCTETSTR, BERINTEGER)
+	if err != nil {
+		return nil, err
+	}
+	if berInt(header[0]) != msgID || len(header[2]) != 1 {
+		return nil, errors.New("unexpected snmpv3 message")
+	}
+	flags := header[2][0]
+
+	_, params, _, err := berRead(fields[2])
+	if err != nil {
+		return nil, err
+	}
+	usm, err := berReadAll(params, BEROCTETSTR, BERINTEGER, BERINTEGER, BEROCTETSTR, BEROCTETSTR, BEROCTETSTR)
+	if err != nil {
+		return nil, err
+	}
+
+	if flags&USMAUTH != 0 && c.authKey != nil {
+		digest := usm[4]
+		if len(digest) != 12 {
+			return nil, errors.New("invalid snmpv3 digest")
+		}
+		// the digest is computed with its own bytes zeroed.
+		offset := cap(packet) - cap(digest)
+		signed := append([]byte(nil), packet...)
+		copy(signed[offset:offset+12], make([]byte, 12))
+		mac := hmac.New(usmHash(c.cfg.AuthProtocol), c.authKey)
+		mac.Write(signed)
+		if !hmac.Equal(mac.Sum(nil)[:12], digest) {
+			return nil, errors.New("snmp response authentication failed")
+		}
+	}
+
+	c.engineID = append([]byte(nil), usm[0]...)
+	c.boots, c.engineTime, c.discovered = berInt(usm[1]), berInt(usm[2]), time.Now()
+
+	// the scoped pdu or its encrypted form.
+	data := fields[3]
+	if flags&USMPRIV != 0 {
+		if c.privKey == nil {
+			return nil, errors.New("unexpected encrypted snmp response")
+		}
+		plain, err := c.decrypt(data, usm[5], berInt(usm[1]), berInt(usm[2]))
+		if err != nil {
+			return nil, err
+		}
+		if _, data, _, err = berRead(plain); err != nil {
+			return nil, err
+		}
+	}
+
+	// context engine id, context name then the pdu.
+	for i := 0; i < 2; i++ {
+		if _, _, data, err = berRead(data); err != nil {
+			return nil, err
+		}
+	}
+	return data, nil
+}
+
+// encrypt ciphers a scoped pdu with DES-CBC or AES-128-CFB and
+// returns it with the salt to send as privacy parameters.
+func (c *snmpClient) encrypt(scoped []byte, engineTime int) ([]byte, []byte, error) {
+	c.salt++
+	if strings.EqualFold(c.cfg.PrivProtocol, "des") {
+		salt := make([]byte, 8)
+		binary.BigEndian.PutUint32(salt, uint32(c.boots))
+		binary.BigEndian.PutUint32(salt[4:], uint32(c.salt))
+		block, err := des.NewCipher(c.privKey[:8])
+		if err != nil {
+			return nil, nil, err
+		}
+		iv := make([]byte, 8)
+		for i := range iv {
+			iv[i] = c.privKey[8+i] ^ salt[i]
+		}
+		// pad to a multiple of the block size.
+		data := append(append([]byte(nil), scoped...), make([]byte, (8-len(scoped)%8)%8)...)
+		cipher.NewCBCEncrypter(block, iv).CryptBlocks(data, data)
+		return data, salt, nil
+	}
+
+	salt := make([]byte, 8)
+	binary.BigEndian.PutUint64(salt, c.salt)
+	block, err := aes.NewCipher(c.privKey[:16])
+	if err != nil {
+		return nil, nil, err
+	}
+	data := make([]byte, len(scoped))
+	cipher.NewCFBEncrypter(block, aesIV(c.boots, engineTime, salt)).XORKeyStream(data, scoped)
+	return data, salt, nil
+}
+
+// decrypt deciphers a scoped pdu received with its privacy parameters.
+func (c *snmpClient) decrypt(data, salt []byte, boots, engineTime int) ([]byte, error) {
+	if len(salt) != 8 {
+		return nil, errors.New("invalid snmpv3 privacy parameters")
+	}
+	if strings.EqualFold(c.cfg.PrivProtocol, "des") {
+		if len(data)%8 != 0 {
+			return nil, errors.New("invalid snmpv3 encrypted data")
+		}
+		block, err := des.NewCipher(c.privKey[:8])
+		if err != nil {
+			return nil, err
+		}
+		iv := make([]byte, 8)
+		for i := range iv {
+			iv[i] = c.privKey[8+i] ^ salt[i]
+		}
+		plain := make([]byte, len(data))
+		cipher.NewCBCDecrypter(block, iv).CryptBlocks(plain, data)
+		return plain, nil
+	}
+
+	block, err := aes.NewCipher(c.privKey[:16])
+	if err != nil {
+		return nil, err
+	}
+	plain := make([]byte, len(data))
+	cipher.NewCFBDecrypter(block, aesIV(boots, engineTime, salt)).XORKeyStream(plain, data)
+	return plain, nil
+}
+
+// aesIV returns the initialization vector of AES-128-CFB (RFC 3826).
+func aesIV(boots, engineTime int, salt []byte) []byte {
+	iv := make([]byte, 16)
+	binary.BigEndian.PutUint32(iv, uint32(boots))
+	binary.BigEndian.PutUint32(iv[4:], uint32(engineTime))
+	copy(iv[8:], salt)
+	return iv
+}
@@ -0,0 +1,228 @@
+package ui
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jroimartin/gocui"
+)
+
+const (
+	SNMPPOLL = "snmpPoll"
+
+	SWIDTH  = 90
+	SHEIGHT = 22
+)
+
+// standard mib-2 oids of the quick-poll.
+const (
+	sysDescrOID    = "1.3.6.1.2.1.1.1.0"
+	sysNameOID     = "1.3.6.1.2.1.1.5.0"
+	ifDescrOID     = "1.3.6.1.2.1.2.2.1.2"
+	ifOperOID      = "1.3.6.1.2.1.2.2.1.8"
+	ifInErrorsOID  = "1.3.6.1.2.1.2.2.1.14"
+	ifOutErrorsOID = "1.3.6.1.2.1.2.2.1.20"
+	ifNameOID      = "1.3.6.1.2.1.31.1.1.1.1"
+)
+
+// ifOperStatus values names.
+var ifStatuses = []string{"", "up", "down", "testing", "unknown", "dormant", "absent", "lowerdown"}
+
+// deviceInfo holds the quick-poll results of a device.
+type deviceInfo struct {
+	name       string
+	descr      string
+	uptime     time.Duration
+	interfaces []ifaceInfo
+}
+
+// ifaceInfo holds the status and errors counters of an interface.
+type ifaceInfo struct {
+	index     string
+	name      string
+	status    string
+	inErrors  uint64
+	outErrors uint64
+}
+
+// displaySNMPView polls in background the focused ip agent and
+// shows its identity and interfaces errors into a popup.
+func displaySNMPView(g *gocui.Gui, ipv *gocui.View) error {
+	cfgs := getSettings()
+	_, cy := ipv.Cursor()
+	l, err := ipv.Line(cy)
+	if err != nil || len(strings.Fields(l)) < 2 {
+		return nil
+	}
+	ip := strings.Fields(l)[1]
+
+	maxX, maxY := g.Size()
+	sv, err := g.SetView(SNMPPOLL, (maxX-SWIDTH)/2, (maxY-SHEIGHT)/2, (maxX+SWIDTH)/2, (maxY+SHEIGHT)/2)
+	if err != nil && err != gocui.ErrUnknownView {
+		log.Println("Failed to create snmp view:", err)
+		return err
+	}
+	if err == gocui.ErrUnknownView {
+		sv.FgColor = gocui.ColorYellow
+		sv.Editable = false
+		for _, key := range []gocui.Key{gocui.KeyEsc, gocui.KeyCtrlQ} {
+			if err := g.SetKeybinding(SNMPPOLL, key, gocui.ModNone, closeSNMPView); err != nil {
+				log.Println("Failed to bind keys to snmp view:", err)
+				return err
+			}
+		}
+	}
+	sv.Title = fmt.Sprintf(" SNMP %s [%s] | Esc: close ", cfgs.SNMPPoll.Version, ip)
+	sv.Clear()
+	fmt.Fprintf(sv, "Polling %s ...", ip)
+	if _, err := g.SetCurrentView(SNMPPOLL); err != nil {
+		log.Println("Failed to set focus on snmp view:", err)
+		return err
+	}
+
+	go func() {
+		defer recoverPanic("pollDevice")
+		info, err := pollDevice(ip, cfgs.SNMPPoll)
+		g.Update(func(g *gocui.Gui) error {
+			sv, verr := g.View(SNMPPOLL)
+			// closed or showing another device meanwhile.
+			if verr != nil || !strings.Contains(sv.Title, "["+ip+"]") {
+				return nil
+			}
+			sv.Clear()
+			if err != nil {
+				fmt.Fprintf(sv, "Failed to poll %s : %v", ip, err)
+				return nil
+			}
+			_, height := sv.Size()
+			fmt.Fprint(sv, info.format(height))
+			return nil
+		})
+	}()
+	return nil
+}
+
+// closeSNMPView closes the device details popup.
+func closeSNMPView(g *gocui.Gui, sv *gocui.View) error {
+	g.DeleteKeybindings(sv.Name())
+	if err := g.DeleteView(sv.Name()); err != nil {
+		log.Println("Failed to delete snmp view:", err)
+		return err
+	}
+	return setCurrentDefaultView(g)
+}
+
+// pollDevice fetches the system identity then walks the interfaces
+// table of the agent of an ip.
+func pollDevice(ip string, cfg snmpPollSettings) (*deviceInfo, error) {
+	c, err := newSNMPClient(ip, cfg)
+	if err != nil {
+		return nil, err
+	}
+	defer c.close()
+
+	vbs, err := c.get(sysNameOID, sysUpTimeOID, sysDescrOID)
+	if err != nil {
+		return nil, err
+	}
+	info := &deviceInfo{}
+	for _, vb := range vbs {
+		if !vb.exists() {
+			continue
+		}
+		switch vb.oid {
+		case sysNameOID:
+			info.name = vb.String()
+		case sysUpTimeOID:
+			info.uptime = time.Duration(vb.uint()) * 10 * time.Millisecond
+		case sysDescrOID:
+			info.descr = strings.TrimSpace(strings.SplitN(vb.String(), "\n", 2)[0])
+		}
+	}
+
+	ifaces := make(map[string]*ifaceInfo)
+	var order []string
+	columns := []struct {
+		oid string
+		set func(*ifaceInfo, snmpVarbind)
+	}{
+		{ifDescrOID, func(i *ifaceInfo, vb snmpVarbind) { i.name = vb.String() }},
+		// ifName is shorter than ifDescr when supported.
+		{ifNameOID, func(i *ifaceInfo, vb snmpVarbind) {
+			if vb.String() != "" {
+				i.name = vb.String()
+			}
+		}},
+		{ifOperOID, func(i *ifaceInfo, vb snmpVarbind) {
+			if s := int(vb.uint()); s < len(ifStatuses) {
+				i.status = ifStatuses[s]
+			}
+		}},
+		{ifInErrorsOID, func(i *ifaceInfo, vb snmpVarbind) { i.inErrors = vb.uint() }},
+		{ifOutErrorsOID, func(i *ifaceInfo, vb snmpVarbind) { i.outErrors = vb.uint() }},
+	}
+	for _, col := range columns {
+		vbs, err := c.walk(col.oid)
+		if err != nil {
+			return nil, err
+		}
+		for _, vb := range vbs {
+			index := strings.TrimPrefix(vb.oid, col.oid+".")
+			iface, ok := ifaces[index]
+			if !ok {
+				iface = &ifaceInfo{index: index}
+				ifaces[index] = iface
+				order = append(order, index)
+			}
+			col.set(iface, vb)
+		}
+	}
+
+	for _, index := range order {
+		info.interfaces = append(info.interfaces, *ifaces[index])
+	}
+	// the interfaces with most errors first.
+	sort.SliceStable(info.interfaces, func(i, j int) bool {
+		a, b := info.interfaces[i], info.interfaces[j]
+		return a.inErrors+a.outErrors > b.inErrors+b.outErrors
+	})
+	return info, nil
+}
+
+// format returns the details as displayed into a popup of height lines.
+func (info *deviceInfo) format(height int) string {
+	var b strings.Builder
+	days := int(info.uptime.Hours()) / 24
+	hours := int(info.uptime.Hours()) % 24
+	minutes := int(info.uptime.Minutes()) % 60
+	up := 0
+	for _, i := range info.interfaces {
+		if i.status == "up" {
+			up++
+		}
+	}
+
+	fmt.Fprintf(&b, "name    : %s\n", info.name)
+	fmt.Fprintf(&b, "system  : %s\n", info.descr)
+	fmt.Fprintf(&b, "uptime  : %dd %02dh %02dm\n", days, hours, minutes)
+	fmt.Fprintf(&b, "ifaces  : %d (%d up)\n\n", len(info.interfaces), up)
+	fmt.Fprintf(&b, "%-6s %-40s %-10s %12s %12s\n", "index", "interface", "status", "in errors", "out errors")
+
+	// keep the header and the truncation line.
+	rows := height - 7
+	for n, i := range info.interfaces {
+		if n == rows && len(info.interfaces) > rows+1 {
+			fmt.Fprintf(&b, "... %d more interfaces", len(info.interfaces)-rows)
+			break
+		}
+		name := i.name
+		if len(name) > 40 {
+			name = name[:39] + "~"
+		}
+		fmt.Fprintf(&b, "%-6s %-40s %-10s %12d %12d\n", i.index, name, i.status, i.inErrors, i.outErrors)
+	}
+	return b.String()
+}
@@ -0,0 +1,205 @@
+package ui
+
+import (
+	"fmt"
+	"math/rand"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// BER types used to build SNMP messages.
+const (
+	BERINTEGER   = 0x02
+	BEROCTETSTR  = 0x04
+	BERNULL      = 0x05
+	BEROID       = 0x06
+	BERSEQUENCE  = 0x30
+	BERTIMETICKS = 0x43
+	SNMPV2TRAP   = 0xa7
+)
+
+var (
+	// standard varbinds of every SNMPv2 trap.
+	sysUpTimeOID   = "1.3.6.1.2.1.1.3.0"
+	snmpTrapOIDOID = "1.3.6.1.6.3.1.1.4.1.0"
+
+	// program start used to compute sysUpTime.
+	startTime = time.Now()
+)
+
+// snmpNotifier sends SNMPv2c traps on each target state change.
+// Trap OID is <oid>.1 for down, <oid>.2 for up, <oid>.4 for a
+// traceroute path change and <oid>.5 for a certificate expiring
+// soon. Details are sent as varbinds <oid>.3.1 (target) <oid>.3.2 (state) and
+// <oid>.3.3 (loss percentage).
+type snmpNotifier struct {
+	cfg   snmpSettings
+	queue chan alert
+}
+
+// newSNMPNotifier creates a trap notifier and starts its worker.
+func newSNMPNotifier(cfg snmpSettings) *snmpNotifier {
+	n := &snmpNotifier{cfg: cfg, queue: make(chan alert, 100)}
+	wg.Add(1)
+	go n.run()
+	return n
+}
+
+// notify queues an alert for sending.
+func (n *snmpNotifier) notify(a alert) {
+	select {
+	case n.queue <- a:
+	default:
+		alertsLog.Warn("SNMP traps queue full, dropped alert", "notifier", "snmp", "alert", a)
+	}
+}
+
+// run sends a trap for each queued alert.
+func (n *snmpNotifier) run() {
+	defer wg.Done()
+	defer recoverPanic("snmpNotifier.run")
+	for {
+		select {
+		case a := <-n.queue:
+			if err := n.send(a); err != nil {
+				alertsLog.Error("Failed to send snmp trap", "notifier", "snmp", "err", err)
+			}
+		case <-exit:
+			return
+		}
+	}
+}
+
+// send builds and writes the trap datagram to the destination.
+func (n *snmpNotifier) send(a alert) error {
+	packet, err := n.buildTrap(a)
+	if err != nil {
+		return err
+	}
+
+	conn, err := net.DialTimeout("udp", n.cfg.Destination, 5*time.Second)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	_, err = conn.Write(packet)
+	return err
+}
+
+// buildTrap encodes the SNMPv2c trap message of an alert.
+func (n *snmpNotifier) buildTrap(a alert) ([]byte, error) {
+	trapOID := n.cfg.OID + ".2"
+	switch a.state {
+	case STATEDOWN:
+		trapOID = n.cfg.OID + ".1"
+	case STATEPATH:
+		trapOID = n.cfg.OID + ".4"
+	case STATECERT:
+		trapOID = n.cfg.OID + ".5"
+	case STATEDIGEST:
+		trapOID = n.cfg.OID + ".6"
+	case STATEOUTAGE:
+		trapOID = n.cfg.OID + ".7"
+	}
+
+	uptime := uint32(time.Since(startTime) / (10 * time.Millisecond))
+	bindings := []struct {
+		oid   string
+		value []byte
+	}{
+		{sysUpTimeOID, berTLV(BERTIMETICKS, berUint(uint64(uptime)))},
+		{snmpTrapOIDOID, nil},
+		{n.cfg.OID + ".3.1", berTLV(BEROCTETSTR, []byte(a.ip))},
+		{n.cfg.OID + ".3.2", berTLV(BEROCTETSTR, []byte(a.state))},
+		{n.cfg.OID + ".3.3", berTLV(BEROCTETSTR, []byte(fmt.Sprintf("%.1f", a.stats.loss())))},
+	}
+
+	value, err := berOID(trapOID)
+	if err != nil {
+		return nil, err
+	}
+	bindings[1].value = value
+
+	var varbinds []byte
+	for _, b := range bindings {
+		oid, err := berOID(b.oid)
+		if err != nil {
+			return nil, err
+		}
+		varbinds = append(varbinds, berTLV(BERSEQUENCE, append(oid, b.value...))...)
+	}
+
+	pdu := berTLV(BERINTEGER, berUint(uint64(rand.Int31())))
+	pdu = append(pdu, berTLV(BERINTEGER, berUint(0))...)
+	pdu = append(pdu, berTLV(BERINTEGER, berUint(0))...)
+	pdu = append(pdu, berTLV(BERSEQUENCE, varbinds)...)
+
+	// version 1 stands for SNMPv2c.
+	msg := berTLV(BERINTEGER, berUint(1))
+	msg = append(msg, berTLV(BEROCTETSTR, []byte(n.cfg.Community))...)
+	msg = append(msg, berTLV(SNMPV2TRAP, pdu)...)
+
+	return berTLV(BERSEQUENCE, msg), nil
+}
+
+// berTLV encodes a type-length-value element.
+func berTLV(tag byte, value []byte) []byte {
+	out := []byte{tag}
+	l := len(value)
+	if l < 128 {
+		out = append(out, byte(l))
+	} else {
+		var lb []byte
+		for l > 0 {
+			lb = append([]byte{byte(l & 0xff)}, lb...)
+			l >>= 8
+		}
+		out = append(out, 0x80|byte(len(lb)))
+		out = append(out, lb...)
+	}
+	return append(out, value...)
+}
+
+// berUint encodes a positive integer value with minimal bytes.
+func berUint(v uint64) []byte {
+	out := []byte{byte(v & 0xff)}
+	for v >>= 8; v > 0; v >>= 8 {
+		out = append([]byte{byte(v & 0xff)}, out...)
+	}
+	// keep the value positive.
+	if out[0]&0x80 != 0 {
+		out = append([]byte{0}, out...)
+	}
+	return out
+}
+
+// berOID encodes a dotted object identifier.
+func berOID(oid string) ([]byte, error) {
+	parts := strings.Split(strings.Trim(oid, "."), ".")
+	if len(parts) < 2 {
+		return nil, fmt.Errorf("invalid oid %q", oid)
+	}
+
+	ids := make([]uint64, len(parts))
+	for i, p := range parts {
+		id, err := strconv.ParseUint(p, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid oid %q", oid)
+		}
+		ids[i] = id
+	}
+
+	out := []byte{byte(ids[0]*40 + ids[1])}
+	for _, id := range ids[2:] {
+		enc := []byte{byte(id & 0x7f)}
+		for id >>= 7; id > 0; id >>= 7 {
+			enc = append([]byte{byte(id&0x7f) | 0x80}, enc...)
+		}
+		out = append(out, enc...)
+	}
+
+	return berTLV(BEROID, out), nil
+}
@@ -0,0 +1,194 @@
+package ui
+
+import (
+	"time"
+
+	sqlstore "github.com/jeamon/pingo/internal/store"
+)
+
+// STOREPRUNE is the period of the removal of the expired rows.
+const STOREPRUNE = time.Hour
+
+// sqlStore persists targets, configs, samples and events into
+// a SQLite database so they survive across program runs. The
+// in-memory databases remain the working set used by the views.
+type sqlStore struct {
+	db      *sqlstore.DB
+	samples chan sample
+}
+
+// persistent store. nil when disabled.
+var store *sqlStore
+
+// openStore opens (or creates) the SQLite database file and
+// migrates its tables to the current schema.
+func openStore(cfg storeSettings) (*sqlStore, error) {
+	db, err := sqlstore.Open(cfg.Path, time.Duration(cfg.Retention)*24*time.Hour)
+	if err != nil {
+		return nil, err
+	}
+
+	st := &sqlStore{db: db, samples: make(chan sample, 1000)}
+	st.prune()
+	wg.Add(1)
+	go st.run()
+	return st, nil
+}
+
+// prune removes the samples and events older than the retention.
+func (st *sqlStore) prune() {
+	if err := st.db.Prune(); err != nil {
+		storeLog.Error("Failed to remove expired rows", "err", err)
+	}
+}
+
+// load fills the in-memory databases with persisted targets and
+// their latest samples then the notification center with events.
+func (st *sqlStore) load(db *databases) error {
+	cfgs := getSettings()
+	targets, err := st.db.Targets()
+	if err != nil {
+		return err
+	}
+
+	for _, t := range targets {
+		if !isValidIP(t.IP) || db.isExistsIP(t.IP) {
+			continue
+		}
+		db.addIP(t.IP)
+		db.updateConfig(t.IP, &config{start: "n/a", requests: t.Requests, threshold: t.Threshold, timeout: t.Timeout,
+			size: t.Size, backup: t.Backup, maxhops: t.MaxHops, queries: t.Queries, protocol: t.Protocol, numeric: t.Numeric,
+			probe: t.Probe, qname: t.QName, qtype: t.QType, mac: t.MAC, broadcast: t.Broadcast, iperf: t.Iperf,
+			pattern: t.Pattern, interval: t.Interval, maxloss: t.MaxLoss, label: t.Label, tags: t.Tags})
+		db.initStats(t.IP)
+	}
+
+	for _, ip := range db.getAllIPs() {
+		history, err := st.querySamples(ip, time.Time{}, time.Now(), cfgs.History)
+		if err != nil {
+			return err
+		}
+		for _, sp := range history {
+			db.addSample(sp)
+		}
+	}
+
+	events, err := st.db.Events()
+	if err != nil {
+		return err
+	}
+
+	for _, e := range events {
+		a := alert{ip: e.IP, time: e.Time, state: e.State, escalated: e.Escalated}
+		center.lock.Lock()
+		center.items = append(center.items, &notification{alert: a, acked: true})
+		center.lock.Unlock()
+	}
+	return nil
+}
+
+// querySamples returns at most limit latest samples of an ip between
+// two dates in chronological order. It serves historical queries.
+func (st *sqlStore) querySamples(ip string, from, to time.Time, limit int) ([]sample, error) {
+	rows, err := st.db.Samples(ip, from, to, limit)
+	if err != nil {
+		return nil, err
+	}
+	samples := make([]sample, 0, len(rows))
+	for _, r := range rows {
+		samples = append(samples, sample{ip: r.IP, time: r.Time, rtt: r.RTT, success: r.Success})
+	}
+	return samples, nil
+}
+
+// saveTarget inserts or updates a target and its config.
+func (st *sqlStore) saveTarget(ip string, cfg *config) {
+	if st == nil || cfg == nil {
+		return
+	}
+	err := st.db.SaveTarget(sqlstore.Target{IP: ip, Requests: cfg.requests, Threshold: cfg.threshold, Timeout: cfg.timeout,
+		Size: cfg.size, Backup: cfg.backup, MaxHops: cfg.maxhops, Queries: cfg.queries, Protocol: cfg.protocol, Numeric: cfg.numeric,
+		Probe: cfg.probe, QName: cfg.qname, QType: cfg.qtype, MAC: cfg.mac, Broadcast: cfg.broadcast, Iperf: cfg.iperf,
+		Pattern: cfg.pattern, Interval: cfg.interval, MaxLoss: cfg.maxloss, Label: cfg.label, Tags: cfg.tags})
+	if err != nil {
+		storeLog.Error("Failed to persist target", "target", ip, "err", err)
+	}
+}
+
+// deleteTarget removes a target with its samples and events within a
+// single transaction so its alerts are not loaded back.
+func (st *sqlStore) deleteTarget(ip string) {
+	if st == nil {
+		return
+	}
+	if err := st.db.DeleteTarget(ip); err != nil {
+		storeLog.Error("Failed to delete persisted target", "target", ip, "err", err)
+	}
+}
+
+// saveEvent records a fired alert.
+func (st *sqlStore) saveEvent(a alert) {
+	if st == nil {
+		return
+	}
+	if err := st.db.SaveEvent(sqlstore.Event{IP: a.ip, Time: a.time, State: a.state, Escalated: a.escalated}); err != nil {
+		storeLog.Error("Failed to persist event", "target", a.ip, "err", err)
+	}
+}
+
+// sample queues a probe result for the next batch insert.
+func (st *sqlStore) sample(sp sample) {
+	select {
+	case st.samples <- sp:
+	default:
+		count(&counters.droppedSamples)
+		storeLog.Warn("Store queue full, dropped sample", "target", sp.ip)
+	}
+}
+
+// summary is not persisted since it is computed from samples.
+func (st *sqlStore) summary(ip string, s stat, t time.Time) {}
+
+// run inserts queued samples every few seconds within a single
+// transaction, removes the expired rows and closes the database
+// on exit.
+func (st *sqlStore) run() {
+	defer wg.Done()
+	defer recoverPanic("sqlStore.run")
+	var batch []sample
+	ticker := time.NewTicker(5 * time.Second)
+	defer ticker.Stop()
+	pruner := time.NewTicker(STOREPRUNE)
+	defer pruner.Stop()
+	for {
+		select {
+		case <-pruner.C:
+			st.prune()
+		case sp := <-st.samples:
+			batch = append(batch, sp)
+		case <-ticker.C:
+			if err := st.insertSamples(batch); err != nil {
+				storeLog.Error("Failed to persist samples", "err", err)
+			}
+			batch = nil
+		case <-sinksDrained:
+			for len(st.samples) > 0 {
+				batch = append(batch, <-st.samples)
+			}
+			if err := st.insertSamples(batch); err != nil {
+				storeLog.Error("Failed to persist samples", "err", err)
+			}
+			st.db.Close()
+			return
+		}
+	}
+}
+
+// insertSamples writes a batch of samples.
+func (st *sqlStore) insertSamples(batch []sample) error {
+	rows := make([]sqlstore.Sample, 0, len(batch))
+	for _, sp := range batch {
+		rows = append(rows, sqlstore.Sample{IP: sp.ip, Time: sp.time, Success: sp.success, RTT: sp.rtt})
+	}
+	return st.db.InsertSamples(rows)
+}
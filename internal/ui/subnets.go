@@ -0,0 +1,166 @@
+package ui
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/jroimartin/gocui"
+)
+
+const (
+	SUBNETS = "subnets"
+
+	SUBNETSWIDTH  = 100
+	SUBNETSHEIGHT = 20
+)
+
+// subnetSummary aggregates the statistics of the targets of a subnet.
+type subnetSummary struct {
+	subnet  string
+	targets int
+	down    int
+	sent    int
+	fails   int
+	// replies and their summed average time to weight the average.
+	replies int
+	rttSum  int
+	// lossiest target of the subnet.
+	worst     string
+	worstLoss float64
+}
+
+// loss returns the percentage of failed requests of the subnet.
+func (s *subnetSummary) loss() float64 {
+	if s.sent == 0 {
+		return 0
+	}
+	return float64(s.fails) * 100 / float64(s.sent)
+}
+
+// avg returns the average reply time of the subnet targets.
+func (s *subnetSummary) avg() int {
+	if s.replies == 0 {
+		return 0
+	}
+	return s.rttSum / s.replies
+}
+
+// String formats a subnet into a line of the subnets view.
+func (s *subnetSummary) String() string {
+	line := fmt.Sprintf("%-20s %3d targets  %3d down  loss %5.1f%%  avg %4d ms", s.subnet, s.targets, s.down, s.loss(), s.avg())
+	if s.worstLoss > 0 {
+		line += fmt.Sprintf("  worst %s (%.1f%%)", s.worst, s.worstLoss)
+	}
+	return line
+}
+
+// summarizeSubnets groups the targets by subnet, the lossiest first.
+func summarizeSubnets() []*subnetSummary {
+	groups := make(map[string]*subnetSummary)
+	var list []*subnetSummary
+	for _, ip := range dbs.getAllIPs() {
+		subnet := subnetOf(ip)
+		g, ok := groups[subnet]
+		if !ok {
+			g = &subnetSummary{subnet: subnet}
+			groups[subnet] = g
+			list = append(list, g)
+		}
+		g.targets++
+		s := dbs.getStats(ip)
+		if s == nil {
+			continue
+		}
+		if s.state == STATEDOWN {
+			g.down++
+		}
+		g.sent += s.fails + s.replies()
+		g.fails += s.fails
+		g.replies += s.replies()
+		g.rttSum += s.avg * s.replies()
+		if loss := s.loss(); loss > g.worstLoss {
+			g.worst, g.worstLoss = ip, loss
+		}
+	}
+	sort.SliceStable(list, func(i, j int) bool {
+		if list[i].down != list[j].down {
+			return list[i].down > list[j].down
+		}
+		return list[i].loss() > list[j].loss()
+	})
+	return list
+}
+
+// displaySubnetsView lists the subnets of the targets with their
+// aggregated statistics to see which site is affected.
+func displaySubnetsView(g *gocui.Gui, ipv *gocui.View) error {
+	maxX, maxY := g.Size()
+	sv, err := g.SetView(SUBNETS, (maxX-SUBNETSWIDTH)/2, (maxY-SUBNETSHEIGHT)/2, (maxX+SUBNETSWIDTH)/2, (maxY+SUBNETSHEIGHT)/2)
+	if err != nil && err != gocui.ErrUnknownView {
+		log.Println("Failed to create subnets view:", err)
+		return err
+	}
+	if err == gocui.ErrUnknownView {
+		sv.FgColor = gocui.ColorYellow
+		sv.SelBgColor = gocui.ColorGreen
+		sv.SelFgColor = gocui.ColorBlack
+		sv.Highlight = true
+		sv.Editable = false
+		sv.Wrap = false
+
+		bindings := []struct {
+			key     interface{}
+			handler func(*gocui.Gui, *gocui.View) error
+		}{
+			{gocui.KeyEnter, filterSubnet},
+			{gocui.KeyArrowUp, outMoveCursorUp},
+			{gocui.KeyArrowDown, outMoveCursorDown},
+			{gocui.KeyCtrlQ, closeSubnetsView},
+			{gocui.KeyEsc, closeSubnetsView},
+		}
+		for _, b := range bindings {
+			if err := g.SetKeybinding(SUBNETS, b.key, gocui.ModNone, b.handler); err != nil {
+				log.Println("Failed to bind keys to subnets view:", err)
+				return err
+			}
+		}
+	}
+	sv.Title = " Subnets | Enter: filter the list - Esc: close "
+	sv.Clear()
+	sv.SetCursor(0, 0)
+	sv.SetOrigin(0, 0)
+	for _, s := range summarizeSubnets() {
+		fmt.Fprintln(sv, s)
+	}
+	if _, err := g.SetCurrentView(SUBNETS); err != nil {
+		log.Println("Failed to set focus on subnets view:", err)
+		return err
+	}
+	return nil
+}
+
+// closeSubnetsView closes the subnets popup.
+func closeSubnetsView(g *gocui.Gui, sv *gocui.View) error {
+	g.DeleteKeybindings(sv.Name())
+	if err := g.DeleteView(sv.Name()); err != nil {
+		log.Println("Failed to delete subnets view:", err)
+		return err
+	}
+	return setCurrentDefaultView(g)
+}
+
+// filterSubnet filters the ips list on the focused subnet.
+func filterSubnet(g *gocui.Gui, sv *gocui.View) error {
+	_, cy := sv.Cursor()
+	l, err := sv.Line(cy)
+	if err != nil || len(strings.Fields(l)) == 0 {
+		return nil
+	}
+	subnet := strings.Fields(l)[0]
+	if err := closeSubnetsView(g, sv); err != nil {
+		return err
+	}
+	return filter.set("subnet=" + subnet)
+}
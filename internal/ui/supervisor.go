@@ -0,0 +1,243 @@
+package ui
+
+import (
+	"fmt"
+	"log"
+	"runtime"
+	"runtime/debug"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jroimartin/gocui"
+)
+
+const (
+	DEBUGVIEW = "debug"
+
+	DEBUGWIDTH  = 100
+	DEBUGHEIGHT = 26
+
+	// a routine panicking more than MAXRESTARTS times within
+	// RESTARTWINDOW is broken and crashes the program.
+	MAXRESTARTS   = 5
+	RESTARTWINDOW = time.Minute
+	// pause before starting again a crashed routine.
+	RESTARTDELAY = time.Second
+)
+
+// states of a supervised routine.
+const (
+	ROUTINERUNNING    = "running"
+	ROUTINERESTARTING = "restarting"
+)
+
+// routineStatus is the lifecycle of a supervised routine.
+type routineStatus struct {
+	name     string
+	state    string
+	started  time.Time
+	restarts int
+	// last recovered panic and the times of the latest ones.
+	panic  string
+	panics []time.Time
+}
+
+// supervisor tracks the long-lived routines until they return and
+// starts again the ones which panicked.
+type supervisor struct {
+	lock     *sync.Mutex
+	next     int
+	routines map[int]*routineStatus
+}
+
+// global supervisor of the routines.
+var routines = &supervisor{lock: &sync.Mutex{}, routines: make(map[int]*routineStatus)}
+
+// supervise runs fn into a tracked routine accounted by the group if
+// any. A panic is logged and fn is started again unless the program
+// exits or the routine keeps crashing. Routines needing cleanup on
+// restart must defer it themselves.
+func supervise(group *sync.WaitGroup, name string, fn func()) {
+	if group != nil {
+		group.Add(1)
+	}
+	id := routines.track(name)
+	go func() {
+		if group != nil {
+			defer group.Done()
+		}
+		defer routines.untrack(id)
+		for {
+			r, stack := protect(fn)
+			if r == nil {
+				return
+			}
+			if !routines.crashed(id, r) {
+				crash(name, r, stack)
+			}
+			logs.Error("Routine panic, restarting it", "routine", name, "panic", fmt.Sprint(r), "stack", string(stack))
+			showError("Routine %s crashed and restarts : %v", name, r)
+
+			select {
+			case <-exit:
+				return
+			case <-time.After(RESTARTDELAY):
+			}
+			routines.restarted(id)
+		}
+	}()
+}
+
+// protect runs fn and returns its recovered panic with its stack.
+func protect(fn func()) (r interface{}, stack []byte) {
+	defer func() {
+		if r = recover(); r != nil {
+			stack = debug.Stack()
+		}
+	}()
+	fn()
+	return nil, nil
+}
+
+// track registers a new running routine.
+func (sv *supervisor) track(name string) int {
+	sv.lock.Lock()
+	defer sv.lock.Unlock()
+	sv.next++
+	sv.routines[sv.next] = &routineStatus{name: name, state: ROUTINERUNNING, started: time.Now()}
+	return sv.next
+}
+
+// untrack forgets a routine which returned.
+func (sv *supervisor) untrack(id int) {
+	sv.lock.Lock()
+	delete(sv.routines, id)
+	sv.lock.Unlock()
+}
+
+// crashed accounts a panic of a routine. It tells whether the routine
+// can restart or panicked too often lately.
+func (sv *supervisor) crashed(id int, r interface{}) bool {
+	sv.lock.Lock()
+	defer sv.lock.Unlock()
+	rs := sv.routines[id]
+	now := time.Now()
+	recent := []time.Time{now}
+	for _, t := range rs.panics {
+		if now.Sub(t) < RESTARTWINDOW {
+			recent = append(recent, t)
+		}
+	}
+	rs.state, rs.panic, rs.panics = ROUTINERESTARTING, fmt.Sprint(r), recent
+	return len(recent) <= MAXRESTARTS
+}
+
+// restarted marks a crashed routine as running again.
+func (sv *supervisor) restarted(id int) {
+	sv.lock.Lock()
+	rs := sv.routines[id]
+	rs.state, rs.started = ROUTINERUNNING, time.Now()
+	rs.restarts++
+	sv.lock.Unlock()
+}
+
+// list returns a copy of the tracked routines sorted by name.
+func (sv *supervisor) list() []routineStatus {
+	sv.lock.Lock()
+	list := make([]routineStatus, 0, len(sv.routines))
+	for _, rs := range sv.routines {
+		list = append(list, *rs)
+	}
+	sv.lock.Unlock()
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].name == list[j].name {
+			return list[i].started.Before(list[j].started)
+		}
+		return list[i].name < list[j].name
+	})
+	return list
+}
+
+// formatDebug builds the content of the debug view : the runtime
+// statistics, the queues and the supervised routines.
+func formatDebug() string {
+	cfgs := getSettings()
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "goroutines : %d - heap : %.1f MB - gc cycles : %d\n", runtime.NumGoroutine(), float64(mem.HeapAlloc)/(1<<20), mem.NumGC)
+	fmt.Fprintf(&b, "engine     : %s (%s setting)\n", engineName(), cfgs.Engine)
+	fmt.Fprintf(&b, "queues     : samples %d/%d (%s) - alerts %d/%d - ui %d\n", len(samplesChan), cap(samplesChan), cfgs.Queues.Policy,
+		len(alertsChan), cap(alertsChan), bus.depth())
+	busy, size, waiting := workers.usage()
+	if size > 0 {
+		fmt.Fprintf(&b, "workers    : %d/%d busy - %d waiting\n", busy, size, waiting)
+	} else {
+		fmt.Fprintf(&b, "workers    : unbounded\n")
+	}
+	fmt.Fprintf(&b, "dropped    : samples %d - alerts %d - lines %d - events %d\n",
+		atomic.LoadUint64(&counters.droppedSamples), atomic.LoadUint64(&counters.droppedAlerts),
+		atomic.LoadUint64(&counters.droppedLines), atomic.LoadUint64(&counters.droppedEvents))
+	fmt.Fprintf(&b, "blocked    : samples %d\n", atomic.LoadUint64(&counters.blockedSamples))
+	samples, minutes, outputs := memoryUsage()
+	limit := "none"
+	if cfgs.MemoryLimit > 0 {
+		limit = fmt.Sprintf("%d MB", cfgs.MemoryLimit)
+	}
+	fmt.Fprintf(&b, "memory     : samples %.1f MB - minutes %.1f MB - outputs %.1f MB - limit %s - trims %d\n\n",
+		float64(samples)/(1<<20), float64(minutes)/(1<<20), float64(outputs)/(1<<20), limit, atomic.LoadUint64(&counters.memoryTrims))
+
+	fmt.Fprintf(&b, "%-28s %-10s %10s %8s  %s\n", "ROUTINE", "STATE", "UPTIME", "RESTARTS", "LAST PANIC")
+	for _, rs := range routines.list() {
+		fmt.Fprintf(&b, "%-28s %-10s %10s %8d  %s\n", rs.name, rs.state, time.Since(rs.started).Round(time.Second), rs.restarts, rs.panic)
+	}
+	return b.String()
+}
+
+// displayDebugView shows the runtime statistics and the status of the
+// supervised routines. F12 refreshes it.
+func displayDebugView(g *gocui.Gui, cv *gocui.View) error {
+	maxX, maxY := g.Size()
+	dv, err := g.SetView(DEBUGVIEW, (maxX-DEBUGWIDTH)/2, (maxY-DEBUGHEIGHT)/2, (maxX+DEBUGWIDTH)/2, (maxY+DEBUGHEIGHT)/2)
+	if err != nil && err != gocui.ErrUnknownView {
+		log.Println("Failed to create debug view:", err)
+		return err
+	}
+	if err == gocui.ErrUnknownView {
+		dv.Title = " Debug | F12: refresh - Esc: close "
+		dv.FgColor = gocui.ColorYellow
+		dv.Editable = false
+		dv.Wrap = false
+		for _, key := range []gocui.Key{gocui.KeyEsc, gocui.KeyCtrlQ} {
+			if err := g.SetKeybinding(DEBUGVIEW, key, gocui.ModNone, closeDebugView); err != nil {
+				log.Println("Failed to bind keys to debug view:", err)
+				return err
+			}
+		}
+		if err := g.SetKeybinding(DEBUGVIEW, gocui.KeyF12, gocui.ModNone, displayDebugView); err != nil {
+			log.Println("Failed to bind keys to debug view:", err)
+			return err
+		}
+	}
+	dv.Clear()
+	fmt.Fprint(dv, formatDebug())
+	if _, err := g.SetCurrentView(DEBUGVIEW); err != nil {
+		log.Println("Failed to set focus on debug view:", err)
+		return err
+	}
+	return nil
+}
+
+// closeDebugView closes the debug popup.
+func closeDebugView(g *gocui.Gui, dv *gocui.View) error {
+	g.DeleteKeybindings(dv.Name())
+	if err := g.DeleteView(dv.Name()); err != nil {
+		log.Println("Failed to delete debug view:", err)
+		return err
+	}
+	return setCurrentDefaultView(g)
+}
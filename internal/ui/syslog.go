@@ -0,0 +1,124 @@
+package ui
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"time"
+)
+
+const (
+	// syslog severities used for state changes.
+	SYSLOGWARNING = 4
+	SYSLOGNOTICE  = 5
+)
+
+// syslogNotifier forwards alerts to a local or remote syslog
+// server using RFC5424 format over UDP or TCP transport.
+type syslogNotifier struct {
+	cfg      syslogSettings
+	hostname string
+	conn     net.Conn
+	queue    chan alert
+}
+
+// newSyslogNotifier creates a syslog notifier and starts its worker.
+func newSyslogNotifier(cfg syslogSettings) *syslogNotifier {
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		hostname = "-"
+	}
+	n := &syslogNotifier{cfg: cfg, hostname: hostname, queue: make(chan alert, 100)}
+	wg.Add(1)
+	go n.run()
+	return n
+}
+
+// notify queues an alert for forwarding.
+func (n *syslogNotifier) notify(a alert) {
+	select {
+	case n.queue <- a:
+	default:
+		alertsLog.Warn("Syslog queue full, dropped alert", "notifier", "syslog", "alert", a)
+	}
+}
+
+// run sends each queued alert and closes the connection on exit.
+func (n *syslogNotifier) run() {
+	defer wg.Done()
+	defer recoverPanic("syslogNotifier.run")
+	for {
+		select {
+		case a := <-n.queue:
+			if err := n.send(n.format(a)); err != nil {
+				alertsLog.Error("Failed to forward alert to syslog", "notifier", "syslog", "err", err)
+			}
+		case <-exit:
+			if n.conn != nil {
+				n.conn.Close()
+			}
+			return
+		}
+	}
+}
+
+// format builds the RFC5424 message of an alert.
+func (n *syslogNotifier) format(a alert) string {
+	severity := SYSLOGNOTICE
+	if a.state == STATEDOWN || a.state == STATECERT || a.state == STATEOUTAGE {
+		severity = SYSLOGWARNING
+	}
+	pri := n.cfg.Facility*8 + severity
+	sd := fmt.Sprintf(`[pingo@32473 target="%s" state="%s" loss="%.1f" fails="%d"]`,
+		a.ip, a.state, a.stats.loss(), a.stats.fails)
+
+	if a.state == STATEPATH {
+		return fmt.Sprintf("<%d>1 %s %s pingo %d PATH %s %s path changed : %s",
+			pri, a.time.Format(time.RFC3339), n.hostname, os.Getpid(), sd, a.ip, a.details)
+	}
+
+	if a.state == STATECERT {
+		return fmt.Sprintf("<%d>1 %s %s pingo %d CERT %s %s certificate %s",
+			pri, a.time.Format(time.RFC3339), n.hostname, os.Getpid(), sd, a.ip, a.details)
+	}
+
+	if a.state == STATEOUTAGE {
+		return fmt.Sprintf("<%d>1 %s %s pingo %d OUTAGE %s %s group outage : %s",
+			pri, a.time.Format(time.RFC3339), n.hostname, os.Getpid(), sd, a.ip, a.details)
+	}
+
+	if a.state == STATEDIGEST {
+		return fmt.Sprintf("<%d>1 %s %s pingo %d DIGEST - digest of the %s",
+			pri, a.time.Format(time.RFC3339), n.hostname, os.Getpid(), a.details)
+	}
+
+	return fmt.Sprintf("<%d>1 %s %s pingo %d STATE %s %s is %s",
+		pri, a.time.Format(time.RFC3339), n.hostname, os.Getpid(), sd, a.ip, a.state)
+}
+
+// send writes a message to the server and redials once on failure.
+// TCP transport uses octet-counting framing as defined by RFC6587.
+func (n *syslogNotifier) send(msg string) error {
+	if n.cfg.Network == "tcp" {
+		msg = fmt.Sprintf("%d %s", len(msg), msg)
+	}
+
+	var err error
+	for i := 0; i < 2; i++ {
+		if n.conn == nil {
+			n.conn, err = net.DialTimeout(n.cfg.Network, n.cfg.Address, 5*time.Second)
+			if err != nil {
+				n.conn = nil
+				continue
+			}
+		}
+
+		n.conn.SetWriteDeadline(time.Now().Add(5 * time.Second))
+		if _, err = n.conn.Write([]byte(msg)); err == nil {
+			return nil
+		}
+		n.conn.Close()
+		n.conn = nil
+	}
+	return err
+}
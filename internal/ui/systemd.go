@@ -0,0 +1,41 @@
+package ui
+
+import (
+	"net"
+	"os"
+	"strconv"
+	"time"
+)
+
+// sdNotify sends a state like READY=1 to the systemd service manager
+// when pingo runs as a Type=notify service. It does nothing otherwise.
+func sdNotify(state string) {
+	socket := os.Getenv("NOTIFY_SOCKET")
+	if socket == "" {
+		return
+	}
+	// names starting with @ are abstract sockets.
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: socket, Net: "unixgram"})
+	if err != nil {
+		logs.Warn("Failed to notify systemd", "socket", socket, "err", err)
+		return
+	}
+	defer conn.Close()
+	if _, err = conn.Write([]byte(state)); err != nil {
+		logs.Warn("Failed to notify systemd", "socket", socket, "err", err)
+	}
+}
+
+// watchdogInterval returns the interval between two keep-alive pings
+// of the systemd watchdog (WatchdogSec), half of its timeout, or 0 if
+// it is not enabled for pingo.
+func watchdogInterval() time.Duration {
+	if pid := os.Getenv("WATCHDOG_PID"); pid != "" && pid != strconv.Itoa(os.Getpid()) {
+		return 0
+	}
+	usec, err := strconv.ParseInt(os.Getenv("WATCHDOG_USEC"), 10, 64)
+	if err != nil || usec <= 0 {
+		return 0
+	}
+	return time.Duration(usec) * time.Microsecond / 2
+}
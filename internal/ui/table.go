@@ -0,0 +1,235 @@
+package ui
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jroimartin/gocui"
+)
+
+const OUTPUTSHEADER = "outputsHeader"
+
+// outputsTable is the last hops table displayed into the outputs view
+// in its original order. Like tableSort, it is only used from the ui.
+var outputsTable string
+
+// tableSort is the column (from 1) the hops tables are sorted by. The
+// rows keep their original order with the column 0.
+var tableSort struct {
+	column  int
+	reverse bool
+}
+
+// tableColumn is the label of a column of a table header and its
+// position into the header line.
+type tableColumn struct {
+	label      string
+	start, end int
+}
+
+// isTableHeader tells whether a line is the header row of one of the
+// traceroute, mtr or parallel traceroute tables.
+func isTableHeader(line string) bool {
+	for _, field := range strings.Fields(line) {
+		if field == "Loss%" {
+			return true
+		}
+	}
+	return false
+}
+
+// lineFields returns the words of a line with their positions.
+func lineFields(line string) []tableColumn {
+	var fields []tableColumn
+	start := -1
+	for i := 0; i <= len(line); i++ {
+		if i < len(line) && line[i] != ' ' {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start >= 0 {
+			fields = append(fields, tableColumn{line[start:i], start, i})
+			start = -1
+		}
+	}
+	return fields
+}
+
+// tableColumns returns the columns of a header row. A parenthesized
+// unit like "RTTs (ms)" belongs to the column before.
+func tableColumns(header string) []tableColumn {
+	var columns []tableColumn
+	for _, f := range lineFields(header) {
+		if strings.HasPrefix(f.label, "(") && len(columns) > 0 {
+			last := &columns[len(columns)-1]
+			last.label, last.end = header[last.start:f.end], f.end
+			continue
+		}
+		columns = append(columns, f)
+	}
+	return columns
+}
+
+// tableCell returns the word of a row below a column of the header.
+// Right or left aligned, the values overlap the column label.
+func tableCell(row string, c tableColumn) string {
+	for _, f := range lineFields(row) {
+		if f.start < c.end && f.end > c.start {
+			return f.label
+		}
+	}
+	return ""
+}
+
+// lessCells orders the numbers before the texts and puts the missing
+// values like * or ??? last.
+func lessCells(a, b string) bool {
+	x, xerr := strconv.ParseFloat(strings.TrimSuffix(a, "%"), 64)
+	y, yerr := strconv.ParseFloat(strings.TrimSuffix(b, "%"), 64)
+	switch {
+	case xerr == nil && yerr == nil:
+		return x < y
+	case xerr == nil || yerr == nil:
+		return xerr == nil
+	}
+	if missing(a) != missing(b) {
+		return missing(b)
+	}
+	return a < b
+}
+
+// missing tells whether a cell holds no value.
+func missing(cell string) bool {
+	return cell == "" || strings.Trim(cell, "*?") == ""
+}
+
+// sortTable sorts the rows below each header of a table text by the
+// selected column and marks the header with the sort order.
+func sortTable(text string) string {
+	if tableSort.column == 0 {
+		return text
+	}
+	lines := strings.Split(text, "\n")
+	for i := 0; i < len(lines); i++ {
+		if !isTableHeader(lines[i]) {
+			continue
+		}
+		columns := tableColumns(lines[i])
+		if tableSort.column > len(columns) {
+			continue
+		}
+		c := columns[tableSort.column-1]
+		order := "asc"
+		if tableSort.reverse {
+			order = "desc"
+		}
+		lines[i] += fmt.Sprintf("  [sorted by %s %s]", c.label, order)
+
+		// the rows end with the first blank line.
+		end := i + 1
+		for end < len(lines) && strings.TrimSpace(lines[end]) != "" {
+			end++
+		}
+		rows := lines[i+1 : end]
+		sort.SliceStable(rows, func(a, b int) bool {
+			x, y := tableCell(rows[a], c), tableCell(rows[b], c)
+			if tableSort.reverse && !missing(x) && !missing(y) {
+				return lessCells(y, x)
+			}
+			return lessCells(x, y)
+		})
+		i = end
+	}
+	return strings.Join(lines, "\n")
+}
+
+// sortOutputsTable returns the handler of the key sorting the hops
+// table of the outputs view by a column. Selecting the sorted column
+// again reverses the order.
+func sortOutputsTable(column int) func(*gocui.Gui, *gocui.View) error {
+	return func(g *gocui.Gui, ov *gocui.View) error {
+		if outputsTable == "" {
+			return nil
+		}
+		if column > 0 && column == tableSort.column {
+			tableSort.reverse = !tableSort.reverse
+		} else {
+			tableSort.column, tableSort.reverse = column, false
+		}
+
+		// keep the outputs appended after the table.
+		lines := ov.BufferLines()
+		n := strings.Count(outputsTable, "\n")
+		text := sortTable(outputsTable)
+		if len(lines) > n+1 {
+			text += "\n" + strings.Join(lines[n+1:], "\n")
+		}
+		ov.Clear()
+		fmt.Fprint(ov, text)
+		return nil
+	}
+}
+
+// layoutTableHeader pins the header row of the hops table over the
+// first line of the outputs view once it scrolled out of sight. The
+// pin is shrunk to nothing otherwise, since a blank view would still
+// hide that line.
+func layoutTableHeader(g *gocui.Gui) error {
+	v, err := g.View(OUTPUTS)
+	if err != nil {
+		return nil
+	}
+	x0, y0, x1, _, err := g.ViewPosition(OUTPUTS)
+	if err != nil {
+		return nil
+	}
+	header := pinnedHeader(v)
+	if header == "" {
+		x1 = x0 + 1
+	}
+	hv, err := g.SetView(OUTPUTSHEADER, x0, y0, x1, y0+2)
+	if err != nil && err != gocui.ErrUnknownView {
+		log.Println("Failed to create table header view:", err)
+		return err
+	}
+	if err == gocui.ErrUnknownView {
+		hv.Frame = false
+		hv.FgColor = gocui.ColorYellow
+	}
+	hv.Clear()
+	fmt.Fprint(hv, header)
+	return nil
+}
+
+// pinnedHeader returns the header row of the table whose rows are at
+// the top of the view while the header itself is scrolled above.
+func pinnedHeader(v *gocui.View) string {
+	// the wrapped lines no longer match the buffer lines.
+	if v.Wrap {
+		return ""
+	}
+	_, height := v.Size()
+	lines := v.BufferLines()
+	ox, oy := v.Origin()
+	if v.Autoscroll && len(lines) > height {
+		// the view scrolls to its end on next draw.
+		oy = len(lines) - height
+	}
+	if oy <= 0 || oy >= len(lines) || strings.TrimSpace(lines[oy]) == "" {
+		return ""
+	}
+	for i := oy - 1; i >= 0 && strings.TrimSpace(lines[i]) != ""; i-- {
+		if isTableHeader(lines[i]) {
+			if ox >= len(lines[i]) {
+				return ""
+			}
+			return lines[i][ox:]
+		}
+	}
+	return ""
+}
@@ -0,0 +1,96 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+)
+
+// separator of the template name after the addresses to add, like
+// "10.0.0.1, 10.0.0.2 @ WAN router".
+const TEMPLATESEP = "@"
+
+// templateOf returns the configured template of a name regardless of
+// its case, nil if unknown.
+func templateOf(name string) *templateSettings {
+	cfgs := getSettings()
+	for i := range cfgs.Templates {
+		if strings.EqualFold(cfgs.Templates[i].Name, strings.TrimSpace(name)) {
+			return &cfgs.Templates[i]
+		}
+	}
+	return nil
+}
+
+// splitTemplate separates the addresses to add from the name of the
+// template to apply to them. The template is nil when none is given.
+func splitTemplate(input string) (string, *templateSettings, error) {
+	i := strings.LastIndex(input, TEMPLATESEP)
+	if i < 0 {
+		return input, nil, nil
+	}
+	name := strings.TrimSpace(input[i+len(TEMPLATESEP):])
+	t := templateOf(name)
+	if t == nil {
+		return input[:i], nil, fmt.Errorf("unknown template %s (use %s)", name, strings.Join(templateNames(), ", "))
+	}
+	return input[:i], t, nil
+}
+
+// templateNames returns the names of the configured templates.
+func templateNames() []string {
+	cfgs := getSettings()
+	names := make([]string, 0, len(cfgs.Templates))
+	for _, t := range cfgs.Templates {
+		names = append(names, t.Name)
+	}
+	return names
+}
+
+// config builds the configs of a target from the template. Invalid
+// values are dropped like when editing the configs.
+func (t *templateSettings) config() *config {
+	cfg := &config{start: "n/a", backup: t.Backup}
+	if t.Requests > 0 {
+		cfg.requests = t.Requests
+	}
+	if t.Interval > 0 {
+		cfg.interval = t.Interval
+	}
+	if t.Timeout > 0 {
+		cfg.timeout = t.Timeout
+	}
+	if t.Size > 0 {
+		cfg.size = t.Size
+	}
+	if p := strings.ToLower(t.Pattern); isValidPattern(p) {
+		cfg.pattern = p
+	}
+	if t.Threshold > 0 {
+		cfg.threshold = t.Threshold
+	}
+	if t.MaxLoss > 0 && t.MaxLoss <= 100 {
+		cfg.maxloss = t.MaxLoss
+	}
+	if t.MaxHops > 0 && t.MaxHops <= 255 {
+		cfg.maxhops = t.MaxHops
+	}
+	if t.Queries > 0 && t.Queries <= 10 {
+		cfg.queries = t.Queries
+	}
+	switch p := strings.ToLower(t.Protocol); p {
+	case "icmp", "udp", "tcp":
+		cfg.protocol = p
+	}
+	cfg.tags = normalizeTags(strings.Join(t.Tags, ","))
+	return cfg
+}
+
+// applyTemplate replaces the configs of a listed ip by the template ones.
+func (db *databases) applyTemplate(ip string, t *templateSettings) {
+	if !db.isExistsIP(ip) {
+		return
+	}
+	cfg := t.config()
+	db.updateConfig(ip, cfg)
+	store.saveTarget(ip, cfg)
+}
@@ -0,0 +1,88 @@
+//go:build linux
+// +build linux
+
+package ui
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strconv"
+	"syscall"
+	"testing"
+
+	"golang.org/x/sys/unix"
+)
+
+// ENVTESTTERMINAL marks the tests process run into a pseudo terminal.
+const ENVTESTTERMINAL = "PINGO_TEST_TERMINAL"
+
+// runInTerminal runs the tests again into a new pseudo terminal where
+// gocui opens its screen. The master side is passed as the fd 3 of
+// the tests process to type the keys. Without pseudo terminal, the
+// tests run as is and the views ones are skipped.
+func runInTerminal(m *testing.M) int {
+	if os.Getenv(ENVTESTTERMINAL) != "" {
+		terminal = os.NewFile(3, "ptmx")
+		// the screen drawn is not read.
+		go io.Copy(ioutil.Discard, terminal)
+		return m.Run()
+	}
+
+	master, slave, err := openTerminal()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Failed to open a pseudo terminal, the views tests are skipped:", err)
+		return m.Run()
+	}
+	defer master.Close()
+	defer slave.Close()
+
+	cmd := exec.Command(os.Args[0], os.Args[1:]...)
+	cmd.Env = append(os.Environ(), ENVTESTTERMINAL+"=1", "TERM=xterm")
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = slave, os.Stdout, os.Stderr
+	cmd.ExtraFiles = []*os.File{master}
+	// the terminal becomes the controlling one, opened by termbox.
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true, Setctty: true}
+	if err = cmd.Run(); err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return exitErr.ExitCode()
+		}
+		fmt.Fprintln(os.Stderr, "Failed to run the tests into a pseudo terminal:", err)
+		return 1
+	}
+	return 0
+}
+
+// openTerminal opens a new pseudo terminal.
+func openTerminal() (*os.File, *os.File, error) {
+	master, err := os.OpenFile("/dev/ptmx", os.O_RDWR, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	fd := int(master.Fd())
+	if err = unix.IoctlSetPointerInt(fd, unix.TIOCSPTLCK, 0); err != nil {
+		master.Close()
+		return nil, nil, err
+	}
+	n, err := unix.IoctlGetInt(fd, unix.TIOCGPTN)
+	if err != nil {
+		master.Close()
+		return nil, nil, err
+	}
+
+	slave, err := os.OpenFile("/dev/pts/"+strconv.Itoa(n), os.O_RDWR|unix.O_NOCTTY, 0)
+	if err != nil {
+		master.Close()
+		return nil, nil, err
+	}
+	return master, slave, nil
+}
+
+// resizeTerminal sets the size of the pseudo terminal, read by the
+// gui at its next frame.
+func resizeTerminal(width, height int) error {
+	return unix.IoctlSetWinsize(int(terminal.Fd()), unix.TIOCSWINSZ, &unix.Winsize{Col: uint16(width), Row: uint16(height)})
+}
@@ -0,0 +1,20 @@
+//go:build !linux
+// +build !linux
+
+package ui
+
+import (
+	"errors"
+	"testing"
+)
+
+// runInTerminal runs the tests as is, the views ones being skipped
+// without pseudo terminal.
+func runInTerminal(m *testing.M) int {
+	return m.Run()
+}
+
+// resizeTerminal is not supported without pseudo terminal.
+func resizeTerminal(width, height int) error {
+	return errors.New("no pseudo terminal")
+}
@@ -0,0 +1,60 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// folder of the Android shared storage linked into the Termux home
+// by termux-setup-storage.
+const TERMUXSTORAGE = "storage/shared"
+
+// termux tells whether pingo runs into Termux on Android.
+var termux = runtime.GOOS == "android" || os.Getenv("TERMUX_VERSION") != "" ||
+	strings.Contains(os.Getenv("PREFIX"), "com.termux")
+
+// setupTermux adapts the environment and the default settings to
+// Termux so pingo runs on a phone. The Android system commands are
+// used when the Termux packages (inetutils, traceroute) are missing,
+// the shell is the Termux one since /bin/sh does not exist and the
+// outputs backups and the reports default to the pingo folder of the
+// shared storage (if set up) to be reachable from the phone apps.
+func setupTermux(s *settings) {
+	if !termux {
+		return
+	}
+	logs.Info("Termux environment detected", "prefix", os.Getenv("PREFIX"))
+
+	os.Setenv("PATH", os.Getenv("PATH")+string(os.PathListSeparator)+"/system/bin")
+	if os.Getenv("SHELL") == "" {
+		if prefix := os.Getenv("PREFIX"); prefix != "" {
+			LinuxShell = filepath.Join(prefix, "bin", "sh")
+		} else {
+			LinuxShell = "/system/bin/sh"
+		}
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return
+	}
+	shared := filepath.Join(home, TERMUXSTORAGE)
+	if info, err := os.Stat(shared); err != nil || !info.IsDir() {
+		return
+	}
+	defaults := defaultSettings()
+	if s.Backup.Dir == defaults.Backup.Dir {
+		s.Backup.Dir = filepath.Join(shared, "pingo")
+	}
+	if s.Reports.Dir == defaults.Reports.Dir {
+		s.Reports.Dir = filepath.Join(shared, "pingo", defaults.Reports.Dir)
+	}
+}
+
+// rawSocketsDenied tells whether the probes needing raw sockets, like
+// the icmp and tcp traceroutes, cannot run : Termux without root.
+func rawSocketsDenied() bool {
+	return termux && os.Geteuid() != 0
+}
@@ -0,0 +1,234 @@
+package ui
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/jroimartin/gocui"
+)
+
+// traceDump is the JSON representation of the traceroute
+// and MTR results of a target.
+type traceDump struct {
+	Version  int       `json:"version"`
+	Target   string    `json:"target"`
+	Source   string    `json:"source"`
+	Exported time.Time `json:"exported"`
+	Trace    *pathDump `json:"traceroute,omitempty"`
+	MTR      *mtrDump  `json:"mtr,omitempty"`
+}
+
+type pathDump struct {
+	Time        time.Time `json:"time"`
+	Hops        []hopDump `json:"hops"`
+	PathChanges []string  `json:"path_changes,omitempty"`
+}
+
+type hopDump struct {
+	Hop     int       `json:"hop"`
+	Address string    `json:"address,omitempty"`
+	Host    string    `json:"host,omitempty"`
+	ASN     string    `json:"asn,omitempty"`
+	Country string    `json:"country,omitempty"`
+	RTTs    []float64 `json:"rtts_ms"`
+	Lost    int       `json:"lost"`
+	Loss    float64   `json:"loss_percent"`
+}
+
+type mtrDump struct {
+	Rounds int          `json:"rounds"`
+	Hops   []mtrHopDump `json:"hops"`
+}
+
+type mtrHopDump struct {
+	Hop     int     `json:"hop"`
+	Address string  `json:"address,omitempty"`
+	Host    string  `json:"host,omitempty"`
+	ASN     string  `json:"asn,omitempty"`
+	Country string  `json:"country,omitempty"`
+	Sent    int     `json:"sent"`
+	Lost    int     `json:"lost"`
+	Loss    float64 `json:"loss_percent"`
+	Last    float64 `json:"last_ms"`
+	Avg     float64 `json:"avg_ms"`
+	Best    float64 `json:"best_ms"`
+	Worst   float64 `json:"worst_ms"`
+}
+
+// exportTraceInputView displays a temporary input box to enter the
+// filename prefix of the traceroute reports of the outputs view ip.
+func exportTraceInputView(g *gocui.Gui, cv *gocui.View) error {
+	maxX, maxY := g.Size()
+
+	const name = "exportTrace"
+
+	// construct the input box and position at the center of the screen.
+	if inputView, err := g.SetView(name, maxX/2-25, maxY/2, maxX/2+25, maxY/2+2); err != nil {
+		if err != gocui.ErrUnknownView {
+			log.Println("Failed to display input view: ", err)
+			return err
+		}
+
+		inputView.Title = " Export Trace Report (Enter Name Prefix) "
+		inputView.FgColor = gocui.ColorYellow
+		inputView.SelBgColor = gocui.ColorBlack
+		inputView.SelFgColor = gocui.ColorYellow
+		inputView.Editable = true
+
+		if _, err := g.SetCurrentView(name); err != nil {
+			log.Println(err)
+			return err
+		}
+		g.Cursor = true
+		inputView.Highlight = true
+		// bind Enter key to processInput function.
+		if err := g.SetKeybinding(name, gocui.KeyEnter, gocui.ModNone, processInput); err != nil {
+			log.Println(err)
+			return err
+		}
+
+		// bind Ctrl+Q and Escape keys to close the input box.
+		if err := g.SetKeybinding(name, gocui.KeyCtrlQ, gocui.ModNone, closeInputView); err != nil {
+			log.Println(err)
+			return err
+		}
+
+		if err := g.SetKeybinding(name, gocui.KeyEsc, gocui.ModNone, closeInputView); err != nil {
+			log.Println(err)
+			return err
+		}
+
+		target := strings.Replace(currentOutputsIP, ":", "-", -1)
+		inputView.Write([]byte(fmt.Sprintf("trace_%s_%s", target, time.Now().Format("20060102-150405"))))
+		inputView.SetCursor(len(inputView.Buffer())-1, 0)
+	}
+	return nil
+}
+
+// exportTrace writes the latest traceroute and MTR results of an ip
+// into <prefix>.json and a human-readable report into <prefix>.txt.
+func exportTrace(ip, prefix string) {
+	dump := buildTraceDump(ip)
+	if dump.Trace == nil && dump.MTR == nil {
+		probeLog.Warn("No traceroute results to export", "target", ip)
+		showError("No traceroute results of %s to export", ip)
+		return
+	}
+
+	data, err := json.MarshalIndent(dump, "", "  ")
+	if err == nil {
+		err = ioutil.WriteFile(prefix+".json", data, 0644)
+	}
+	if err != nil {
+		probeLog.Error("Failed to export traceroute results", "target", ip, "err", err)
+		showError("Failed to export traceroute results : %v", err)
+	}
+
+	if err = ioutil.WriteFile(prefix+".txt", []byte(formatTraceReport(dump)), 0644); err != nil {
+		probeLog.Error("Failed to export traceroute report", "target", ip, "err", err)
+		showError("Failed to export traceroute report : %v", err)
+	}
+}
+
+// buildTraceDump collects the latest traceroute and MTR results of an ip.
+func buildTraceDump(ip string) traceDump {
+	source, _ := os.Hostname()
+	dump := traceDump{Version: SESSIONVERSION, Target: ip, Source: source, Exported: time.Now()}
+
+	if tr := traces.get(ip); tr != nil {
+		p := &pathDump{Time: tr.start, Hops: []hopDump{}, PathChanges: tr.changes}
+		for _, h := range tr.hops {
+			info := enrich.info(h.address)
+			host := h.host
+			if (host == "" || host == h.address) && info.ptr != "" {
+				host = info.ptr
+			}
+			rtts := h.rtts
+			if rtts == nil {
+				rtts = []float64{}
+			}
+			p.Hops = append(p.Hops, hopDump{Hop: h.number, Address: h.address, Host: host,
+				ASN: info.asn, Country: info.country, RTTs: rtts, Lost: h.lost, Loss: h.loss()})
+		}
+		dump.Trace = p
+	}
+
+	if m := traces.getMTR(ip); m != nil {
+		md := &mtrDump{Rounds: m.rounds, Hops: []mtrHopDump{}}
+		for _, hs := range m.sortedHops() {
+			info := enrich.info(hs.address)
+			host := hs.host
+			if (host == "" || host == hs.address) && info.ptr != "" {
+				host = info.ptr
+			}
+			md.Hops = append(md.Hops, mtrHopDump{Hop: hs.number, Address: hs.address, Host: host,
+				ASN: info.asn, Country: info.country, Sent: hs.sent, Lost: hs.lost, Loss: hs.loss(),
+				Last: hs.last, Avg: hs.avg(), Best: hs.best, Worst: hs.worst})
+		}
+		dump.MTR = md
+	}
+
+	return dump
+}
+
+// formatTraceReport renders the results as plain text suitable
+// to be pasted into a carrier or provider support ticket.
+func formatTraceReport(dump traceDump) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Path report to %s\n", dump.Target)
+	fmt.Fprintf(&b, "Source host : %s\n", dump.Source)
+	fmt.Fprintf(&b, "Generated   : %s\n", dump.Exported.Format("2006-01-02 15:04:05 MST"))
+
+	if t := dump.Trace; t != nil {
+		fmt.Fprintf(&b, "\n=== Traceroute (%s) ===\n\n", t.Time.Format("2006-01-02 15:04:05 MST"))
+		fmt.Fprintf(&b, "%4s  %-40s %-10s %-3s %6s  %s\n", "Hop", "Address", "ASN", "CC", "Loss%", "RTTs (ms)")
+		for _, h := range t.Hops {
+			var rtts []string
+			for _, rtt := range h.RTTs {
+				rtts = append(rtts, fmt.Sprintf("%.1f", rtt))
+			}
+			for i := 0; i < h.Lost; i++ {
+				rtts = append(rtts, "*")
+			}
+			fmt.Fprintf(&b, "%4d  %-40s %-10s %-3s %5.1f%%  %s\n", h.Hop, hopLabel(h.Address, h.Host),
+				h.ASN, h.Country, h.Loss, strings.Join(rtts, " "))
+		}
+		if len(t.PathChanges) > 0 {
+			b.WriteString("\nChanges since the previous traceroute :\n")
+			for _, change := range t.PathChanges {
+				b.WriteString("  " + change + "\n")
+			}
+		}
+	}
+
+	if m := dump.MTR; m != nil {
+		fmt.Fprintf(&b, "\n=== MTR (%d rounds) ===\n\n", m.Rounds)
+		fmt.Fprintf(&b, "%4s  %-40s %-10s %-3s %6s %5s %7s %7s %7s %7s\n",
+			"Hop", "Address", "ASN", "CC", "Loss%", "Snt", "Last", "Avg", "Best", "Worst")
+		for _, h := range m.Hops {
+			fmt.Fprintf(&b, "%4d  %-40s %-10s %-3s %5.1f%% %5d %7.1f %7.1f %7.1f %7.1f\n",
+				h.Hop, hopLabel(h.Address, h.Host), h.ASN, h.Country, h.Loss, h.Sent, h.Last, h.Avg, h.Best, h.Worst)
+		}
+	}
+
+	return b.String()
+}
+
+// hopLabel returns the host with its address or ??? for a silent hop.
+func hopLabel(address, host string) string {
+	label := address
+	if address == "" {
+		label = "???"
+	} else if host != "" && host != address {
+		label = fmt.Sprintf("%s (%s)", host, address)
+	}
+	if len(label) > 40 {
+		label = label[:40]
+	}
+	return label
+}
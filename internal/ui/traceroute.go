@@ -0,0 +1,433 @@
+package ui
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// hop represents a parsed traceroute output line.
+type hop struct {
+	number  int
+	address string
+	host    string
+	rtts    []float64
+	lost    int
+	// probes sent when reported by the tool (pathping).
+	// rtts then only holds the average time of the hop.
+	sent int
+}
+
+// traceResult holds the parsed hops of a single traceroute run.
+type traceResult struct {
+	ip    string
+	start time.Time
+	hops  []hop
+	// non-hop output lines (header, errors).
+	notes []string
+	// differences from the previous run. marks are
+	// keyed by hop number : + for added, ~ for changed.
+	previous *traceResult
+	marks    map[int]string
+	changes  []string
+}
+
+// traceStore keeps the latest traceroute result and
+// the latest MTR session statistics of each ip.
+type traceStore struct {
+	results map[string]*traceResult
+	mtrs    map[string]*mtrSession
+	lock    *sync.RWMutex
+}
+
+// latest traceroute results.
+var traces = &traceStore{
+	results: make(map[string]*traceResult),
+	mtrs:    make(map[string]*mtrSession),
+	lock:    &sync.RWMutex{},
+}
+
+// save records the result of a completed traceroute and returns
+// the previous result of the same ip or nil.
+func (ts *traceStore) save(tr *traceResult) *traceResult {
+	ts.lock.Lock()
+	defer ts.lock.Unlock()
+	previous := ts.results[tr.ip]
+	ts.results[tr.ip] = tr
+	return previous
+}
+
+// get returns the latest traceroute result of an ip or nil.
+func (ts *traceStore) get(ip string) *traceResult {
+	ts.lock.RLock()
+	defer ts.lock.RUnlock()
+	return ts.results[ip]
+}
+
+// saveMTR records a copy of the statistics of an MTR session.
+func (ts *traceStore) saveMTR(m *mtrSession) {
+	ts.lock.Lock()
+	ts.mtrs[m.ip] = m.snapshot()
+	ts.lock.Unlock()
+}
+
+// getMTR returns the latest MTR session statistics of an ip or nil.
+func (ts *traceStore) getMTR(ip string) *mtrSession {
+	ts.lock.RLock()
+	defer ts.lock.RUnlock()
+	return ts.mtrs[ip]
+}
+
+// newTraceResult creates an empty traceroute result.
+func newTraceResult(ip string) *traceResult {
+	return &traceResult{ip: ip, start: time.Now()}
+}
+
+// add appends a hop. Continuation lines of unix traceroute (same hop
+// answered by several routers) are merged into the existing hop.
+func (tr *traceResult) add(h hop) {
+	if n := len(tr.hops); n > 0 && tr.hops[n-1].number == h.number {
+		last := &tr.hops[n-1]
+		last.rtts = append(last.rtts, h.rtts...)
+		last.lost += h.lost
+		if last.address == "" {
+			last.address, last.host = h.address, h.host
+		}
+		return
+	}
+	tr.hops = append(tr.hops, h)
+}
+
+// parse adds a traceroute or pathping output line to the result.
+func (tr *traceResult) parse(line string) {
+	if h, ok := parsePathpingLine(line); ok {
+		tr.update(h)
+	} else if h, ok := parseHopLine(line); ok {
+		tr.add(h)
+	} else if strings.TrimSpace(line) != "" && !isPathpingNoise(line) {
+		tr.notes = append(tr.notes, strings.TrimSpace(line))
+	}
+}
+
+// update replaces the hop with the same number or appends it. It
+// is used for pathping statistics which follow its route discovery.
+func (tr *traceResult) update(h hop) {
+	for i := range tr.hops {
+		if tr.hops[i].number == h.number {
+			if h.host == "" {
+				h.host = tr.hops[i].host
+			}
+			tr.hops[i] = h
+			return
+		}
+	}
+	tr.hops = append(tr.hops, h)
+}
+
+// addresses returns the responding addresses of the hops.
+func (tr *traceResult) addresses() []string {
+	var addresses []string
+	for _, h := range tr.hops {
+		if h.address != "" {
+			addresses = append(addresses, h.address)
+		}
+	}
+	return addresses
+}
+
+// hopsByNumber returns the responding address of each hop number.
+func (tr *traceResult) hopsByNumber() map[int]string {
+	addresses := make(map[int]string, len(tr.hops))
+	for _, h := range tr.hops {
+		addresses[h.number] = h.address
+	}
+	return addresses
+}
+
+// compare records the differences between the hops of the previous run and
+// this one : hops added or removed and hops answered by another router with
+// its origin ASN. Non-responding hops are not considered as a change.
+func (tr *traceResult) compare(previous *traceResult) {
+	tr.previous, tr.marks, tr.changes = previous, make(map[int]string), nil
+	if previous == nil {
+		return
+	}
+
+	before, after := previous.hopsByNumber(), tr.hopsByNumber()
+	last := 0
+	for n := range before {
+		if n > last {
+			last = n
+		}
+	}
+	for n := range after {
+		if n > last {
+			last = n
+		}
+	}
+
+	for n := 1; n <= last; n++ {
+		old, hadOld := before[n]
+		cur, hasCur := after[n]
+		switch {
+		case hasCur && !hadOld:
+			tr.marks[n] = "+"
+			tr.changes = append(tr.changes, fmt.Sprintf("hop %d added : %s", n, orStar(cur)))
+		case hadOld && !hasCur:
+			tr.changes = append(tr.changes, fmt.Sprintf("hop %d removed : %s", n, orStar(old)))
+		case old != "" && cur != "" && old != cur:
+			tr.marks[n] = "~"
+			change := fmt.Sprintf("hop %d changed : %s -> %s", n, old, cur)
+			if oldASN, curASN := enrich.info(old).asn, enrich.info(cur).asn; oldASN != curASN && oldASN != "" && curASN != "" {
+				change += fmt.Sprintf(" (transit %s -> %s)", oldASN, curASN)
+			}
+			tr.changes = append(tr.changes, change)
+		}
+	}
+}
+
+// orStar returns the address or a star for a non-responding hop.
+func orStar(address string) string {
+	if address == "" {
+		return "*"
+	}
+	return address
+}
+
+// format renders the hops as an aligned table. The reverse name, ASN
+// and country columns are added when hops enrichment is enabled. Hops
+// which differ from the previous run are marked on the first column.
+func (tr *traceResult) format() string {
+	infos := make([]hopInfo, len(tr.hops))
+	hosts := make([]string, len(tr.hops))
+	addrWidth, hostWidth := len("Address"), len("Host")
+	for i, h := range tr.hops {
+		infos[i], hosts[i] = enrich.info(h.address), h.host
+		// use the reverse name when traceroute did not resolve it.
+		if (h.host == "" || h.host == h.address) && infos[i].ptr != "" {
+			hosts[i] = infos[i].ptr
+		}
+		if len(h.address) > addrWidth {
+			addrWidth = len(h.address)
+		}
+		if len(hosts[i]) > hostWidth {
+			hostWidth = len(hosts[i])
+		}
+	}
+
+	var b strings.Builder
+	for _, note := range tr.notes {
+		b.WriteString(note + "\n")
+	}
+	b.WriteString("\n")
+	fmt.Fprintf(&b, "  %4s  %-*s  %-*s  ", "Hop", addrWidth, "Address", hostWidth, "Host")
+	if enrich != nil {
+		fmt.Fprintf(&b, "%-9s  %-2s  ", "ASN", "CC")
+	}
+	fmt.Fprintf(&b, "%6s  %s\n", "Loss%", "RTTs (ms)")
+	for i, h := range tr.hops {
+		mark := tr.marks[h.number]
+		if mark == "" {
+			mark = " "
+		}
+		fmt.Fprintf(&b, "%s %4d  %-*s  %-*s  ", mark, h.number, addrWidth, orStar(h.address), hostWidth, hosts[i])
+		if enrich != nil {
+			fmt.Fprintf(&b, "%-9s  %-2s  ", infos[i].asn, infos[i].country)
+		}
+		fmt.Fprintf(&b, "%5.1f%%  %s\n", h.loss(), h.formatRTTs())
+	}
+
+	if tr.previous != nil {
+		if len(tr.changes) == 0 {
+			fmt.Fprintf(&b, "\nSame path as the previous run of %s.\n", tr.previous.start.Format("2006-01-02 15:04:05"))
+		} else {
+			fmt.Fprintf(&b, "\nPath changed since the previous run of %s :\n", tr.previous.start.Format("2006-01-02 15:04:05"))
+			for _, change := range tr.changes {
+				b.WriteString("  " + change + "\n")
+			}
+		}
+	}
+	return b.String()
+}
+
+// probes returns the number of probes sent to a hop.
+func (h hop) probes() int {
+	if h.sent > 0 {
+		return h.sent
+	}
+	return len(h.rtts) + h.lost
+}
+
+// loss returns the percentage of lost probes of a hop.
+func (h hop) loss() float64 {
+	total := h.probes()
+	if total == 0 {
+		return 0
+	}
+	return float64(h.lost) * 100 / float64(total)
+}
+
+// formatRTTs returns the probes times of a hop with a star for each lost probe.
+// Hops reported with statistics display their average time and losses count.
+func (h hop) formatRTTs() string {
+	if h.sent > 0 {
+		avg := fmt.Sprintf("%7s", "*")
+		if len(h.rtts) > 0 {
+			avg = fmt.Sprintf("%7.1f", h.rtts[0])
+		}
+		return fmt.Sprintf("%s (avg - lost %d/%d)", avg, h.lost, h.sent)
+	}
+
+	values := make([]string, 0, len(h.rtts)+h.lost)
+	for _, rtt := range h.rtts {
+		values = append(values, fmt.Sprintf("%7.1f", rtt))
+	}
+	for i := 0; i < h.lost; i++ {
+		values = append(values, fmt.Sprintf("%7s", "*"))
+	}
+	return strings.Join(values, " ")
+}
+
+// parseHopAddress extracts the address of a hops table row. The row
+// starts with an optional change mark then the hop number. MTR rows
+// display the address between parentheses after the hop name.
+func parseHopAddress(row string) (string, bool) {
+	fields := strings.Fields(row)
+	if len(fields) > 0 && (fields[0] == "+" || fields[0] == "~") {
+		fields = fields[1:]
+	}
+	if len(fields) < 2 {
+		return "", false
+	}
+	if _, err := strconv.Atoi(fields[0]); err != nil {
+		return "", false
+	}
+
+	for _, f := range fields[1:] {
+		f = strings.Trim(f, "()")
+		if net.ParseIP(f) != nil {
+			return f, true
+		}
+	}
+	return "", false
+}
+
+// parseHopLine extracts the hop details from a traceroute (unix) or
+// tracert (windows) output line. false means it is not a hop line.
+// <  2  router.lan (192.168.1.1)  0.390 ms  0.362 ms *>
+// <  3    12 ms    11 ms    <1 ms  host.example [10.0.0.1]>
+// <  4     *        *        *     Request timed out.>
+func parseHopLine(line string) (hop, bool) {
+	fields := strings.Fields(line)
+	if len(fields) < 2 {
+		return hop{}, false
+	}
+
+	number, err := strconv.Atoi(fields[0])
+	if err != nil || number <= 0 {
+		return hop{}, false
+	}
+
+	h := hop{number: number}
+	var name string
+	for i := 1; i < len(fields); i++ {
+		f := fields[i]
+		switch {
+		case f == "*":
+			h.lost++
+
+		case f == "ms" || strings.HasPrefix(f, "!"):
+			// units and unix annotations (!H, !N ...).
+
+		case isRTTField(fields, i):
+			value := strings.TrimSuffix(strings.TrimPrefix(f, "<"), "ms")
+			rtt, _ := strconv.ParseFloat(value, 64)
+			h.rtts = append(h.rtts, rtt)
+
+		case strings.HasPrefix(f, "(") && strings.HasSuffix(f, ")"),
+			strings.HasPrefix(f, "[") && strings.HasSuffix(f, "]"):
+			if h.address == "" {
+				h.address = strings.Trim(f, "()[]")
+				h.host = name
+			}
+
+		case isValidIP(f):
+			if h.address == "" {
+				h.address = f
+			}
+
+		default:
+			name = f
+		}
+	}
+
+	// request timed out lines only contain stars.
+	if h.address == "" && len(h.rtts) > 0 && name != "" {
+		h.address = name
+	}
+
+	return h, true
+}
+
+// parsePathpingLine extracts the statistics of a hop from a pathping
+// (windows) computed statistics line. The loss is the one measured
+// from the source to the hop. false means it is not a statistics line.
+// <  2   10ms     0/ 100 =  0%     0/ 100 =  0%  router.lan [10.0.0.1]>
+// <  5  ---     100/ 100 =100%   100/ 100 =100%  10.0.0.9>
+func parsePathpingLine(line string) (hop, bool) {
+	// join the percentage to its sign to get stable fields.
+	fields := strings.Fields(strings.Replace(strings.Replace(line, "/ ", "/", -1), "= ", "=", -1))
+	if len(fields) < 5 || !strings.Contains(fields[2], "/") || !strings.HasPrefix(fields[3], "=") {
+		return hop{}, false
+	}
+
+	number, err := strconv.Atoi(fields[0])
+	if err != nil || number <= 0 {
+		return hop{}, false
+	}
+
+	counts := strings.Split(fields[2], "/")
+	lost, err1 := strconv.Atoi(counts[0])
+	sent, err2 := strconv.Atoi(counts[1])
+	if err1 != nil || err2 != nil || sent <= 0 {
+		return hop{}, false
+	}
+
+	h := hop{number: number, lost: lost, sent: sent}
+	if fields[1] != "---" {
+		if rtt, err := strconv.ParseFloat(strings.TrimSuffix(fields[1], "ms"), 64); err == nil {
+			h.rtts = []float64{rtt}
+		}
+	}
+
+	// the address follows the node/link loss fields.
+	last := fields[len(fields)-1]
+	if strings.HasPrefix(last, "[") && strings.HasSuffix(last, "]") {
+		h.address = strings.Trim(last, "[]")
+		h.host = fields[len(fields)-2]
+	} else if isValidIP(last) {
+		h.address = last
+	}
+	return h, true
+}
+
+// isPathpingNoise tells if a line is a pathping link statistics line
+// or the source host line (hop 0) which are not kept as notes.
+func isPathpingNoise(line string) bool {
+	fields := strings.Fields(line)
+	return len(fields) > 0 && (fields[0] == "0" || fields[len(fields)-1] == "|")
+}
+
+// isRTTField tells if the field at position i is a time value
+// like <0.390 ms> or <12ms> or <<1 ms>.
+func isRTTField(fields []string, i int) bool {
+	f := fields[i]
+	if !strings.HasSuffix(f, "ms") && (i+1 >= len(fields) || fields[i+1] != "ms") {
+		return false
+	}
+	_, err := strconv.ParseFloat(strings.TrimSuffix(strings.TrimPrefix(f, "<"), "ms"), 64)
+	return err == nil
+}
@@ -0,0 +1,457 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jroimartin/gocui"
+
+	"github.com/jeamon/pingo/internal/probe"
+	"github.com/jeamon/pingo/internal/stats"
+)
+
+// WAITTIMEOUT is how long the tests wait for the main loop.
+const WAITTIMEOUT = 5 * time.Second
+
+// UPDATEWAIT is how long the updates queued by the handlers are
+// given to reach the main loop before the ones of the tests.
+const UPDATEWAIT = 20 * time.Millisecond
+
+// KEYSYNC is typed after the keys of a test : once its handler ran,
+// the main loop handled all the keys typed before.
+const KEYSYNC = gocui.KeyCtrlG
+
+// keyInputs are the terminal inputs of the arrow keys, the other
+// keys typed by the tests being their control code.
+var keyInputs = map[gocui.Key]string{
+	gocui.KeyArrowUp:    "\x1bOA",
+	gocui.KeyArrowDown:  "\x1bOB",
+	gocui.KeyArrowRight: "\x1bOC",
+	gocui.KeyArrowLeft:  "\x1bOD",
+}
+
+var (
+	// master side of the pseudo terminal of the tests, nil without.
+	terminal *os.File
+
+	// gui shared by the tests, opened once on the terminal.
+	testGui     *gocui.Gui
+	testGuiErr  error
+	testGuiOnce sync.Once
+	// error of the main loop once it ended.
+	testLoop = make(chan error, 1)
+	// the KEYSYNC key was handled.
+	synced = make(chan struct{}, 1)
+
+	// prober of the program, restored before each test.
+	defaultProber = newProber
+)
+
+func TestMain(m *testing.M) {
+	os.Exit(runInTerminal(m))
+}
+
+// testUI drives the views and keybindings of the ui through a gocui
+// Gui opened on the pseudo terminal of the tests : the keys are typed
+// into the terminal and handled by its main loop, and the views are
+// read from that loop between two frames.
+type testUI struct {
+	t *testing.T
+	g *gocui.Gui
+}
+
+// viewState is a view as laid out by the last frame.
+type viewState struct {
+	buffer         string
+	title          string
+	x0, y0, x1, y1 int
+	ox, oy         int
+}
+
+// resetState resets the package state the tests touch as a program
+// just started : default settings and queues, no targets, filter,
+// ranking, baselines, runs or notifications, a new events bus, the
+// program prober, and the recent inputs saved into a temporary cache.
+func resetState(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	setSettings(defaultSettings())
+	initQueues()
+
+	dbs = newDatabases()
+	filter = &ipsFilter{lock: &sync.RWMutex{}}
+	ranking = &health{lock: &sync.RWMutex{}}
+	baselines = &baselineStore{lock: &sync.Mutex{}, items: make(map[string]*stats.Baseline)}
+	runs = &runStore{lock: &sync.RWMutex{}, runs: make(map[string][]runSummary)}
+	center = &notificationCenter{lock: &sync.RWMutex{}}
+	recents = &recentInputs{lock: &sync.Mutex{}, once: &sync.Once{}, values: make(map[string][]string)}
+	clock = &probeClock{lock: &sync.Mutex{}}
+	bus = &uiBus{lock: &sync.RWMutex{}, subs: make(map[int][]*subscription)}
+
+	ipsChangedChan = make(chan struct{}, 1)
+	ipToPingChan = make(chan string, 1)
+	ipToTraceChan = make(chan string, 1)
+	ipToMTRChan = make(chan string, 1)
+	currentOnPingIP, currentOutputsIP = "", ""
+	newProber = defaultProber
+}
+
+// openTestGui opens the gui of the tests with the options and the
+// layout of the program then runs its main loop.
+func openTestGui() (*gocui.Gui, error) {
+	g, err := gocui.NewGui(gocui.OutputNormal)
+	if err != nil {
+		return nil, err
+	}
+	g.Highlight = true
+	g.InputEsc = true
+	g.SetManagerFunc(layout)
+	go func() { testLoop <- g.MainLoop() }()
+	return g, nil
+}
+
+// newTestUI resets the package state then builds the main views of a
+// width x height screen with the ips list focused.
+func newTestUI(t *testing.T, width, height int) *testUI {
+	t.Helper()
+	if terminal == nil {
+		t.Skip("no pseudo terminal to open the gui")
+	}
+	// the first frame is drawn at the size of the terminal.
+	if err := resizeTerminal(width, height); err != nil {
+		t.Fatalf("resize: %v", err)
+	}
+	testGuiOnce.Do(func() { testGui, testGuiErr = openTestGui() })
+	if testGuiErr != nil {
+		t.Fatalf("gui: %v", testGuiErr)
+	}
+	resetState(t)
+
+	ui := &testUI{t: t, g: testGui}
+	// the new size is read by the frame drawn after this update.
+	ui.update(func(g *gocui.Gui) error { return nil })
+	ui.update(func(g *gocui.Gui) error {
+		// DeleteView shifts the views listed by Views.
+		var names []string
+		for _, v := range g.Views() {
+			names = append(names, v.Name())
+		}
+		for _, name := range names {
+			g.DeleteKeybindings(name)
+			g.DeleteView(name)
+		}
+		g.DeleteKeybindings("")
+		if err := g.SetKeybinding("", KEYSYNC, gocui.ModNone, func(*gocui.Gui, *gocui.View) error {
+			select {
+			case synced <- struct{}{}:
+			default:
+			}
+			return nil
+		}); err != nil {
+			return err
+		}
+		if maxX, maxY := g.Size(); maxX != width || maxY != height {
+			return fmt.Errorf("screen of %dx%d", maxX, maxY)
+		}
+		return setupViews(g)
+	})
+	ui.flush()
+	return ui
+}
+
+// update runs f into the main loop and waits for it. An error of f
+// fails the test but does not end the loop shared by the tests.
+func (ui *testUI) update(f func(*gocui.Gui) error) {
+	ui.t.Helper()
+	done := make(chan error, 1)
+	ui.g.Update(func(g *gocui.Gui) error {
+		done <- f(g)
+		return nil
+	})
+	select {
+	case err := <-done:
+		if err != nil {
+			ui.t.Fatalf("update: %v", err)
+		}
+	case err := <-testLoop:
+		ui.t.Fatalf("main loop ended: %v", err)
+	case <-time.After(WAITTIMEOUT):
+		ui.t.Fatal("main loop blocked")
+	}
+}
+
+// flush waits for the keys typed to be handled with the updates they
+// queued then refreshes the ips list once changed as the ips watcher
+// does.
+func (ui *testUI) flush() {
+	ui.t.Helper()
+	ui.write(string(rune(KEYSYNC)))
+	select {
+	case <-synced:
+	case err := <-testLoop:
+		ui.t.Fatalf("main loop ended: %v", err)
+	case <-time.After(WAITTIMEOUT):
+		ui.t.Fatal("keys not handled")
+	}
+	// g.Update queues its updates from a routine.
+	time.Sleep(UPDATEWAIT)
+	ui.update(func(g *gocui.Gui) error { return nil })
+	select {
+	case <-ipsChangedChan:
+		ui.update(updateIPsView)
+	default:
+	}
+}
+
+// write types an input into the terminal.
+func (ui *testUI) write(input string) {
+	ui.t.Helper()
+	if _, err := terminal.WriteString(input); err != nil {
+		ui.t.Fatalf("terminal: %v", err)
+	}
+}
+
+// press types a key, or a character when key is 0, into the current
+// view : its keybindings run or else its editor.
+func (ui *testUI) press(key gocui.Key, ch rune) {
+	ui.t.Helper()
+	switch input, ok := keyInputs[key]; {
+	case ok:
+		ui.write(input)
+	case key == 0:
+		ui.write(string(ch))
+	default:
+		ui.write(string(rune(key)))
+	}
+	ui.flush()
+}
+
+// typeText types the characters of a text.
+func (ui *testUI) typeText(text string) {
+	ui.t.Helper()
+	for _, ch := range text {
+		if ch == ' ' {
+			ui.press(gocui.KeySpace, 0)
+			continue
+		}
+		ui.press(0, ch)
+	}
+}
+
+// addTargets lists ips like the <CTRL+A> box.
+func (ui *testUI) addTargets(ips ...string) {
+	ui.t.Helper()
+	for _, ip := range ips {
+		dbs.addNewIP(ip)
+	}
+	refreshIPs()
+	ui.flush()
+}
+
+// view returns the state of a view of the ui.
+func (ui *testUI) view(name string) viewState {
+	ui.t.Helper()
+	var vs viewState
+	ui.update(func(g *gocui.Gui) error {
+		v, err := g.View(name)
+		if err != nil {
+			return fmt.Errorf("view %s: %v", name, err)
+		}
+		vs.buffer, vs.title = v.Buffer(), v.Title
+		vs.x0, vs.y0, vs.x1, vs.y1, _ = g.ViewPosition(name)
+		vs.ox, vs.oy = v.Origin()
+		return nil
+	})
+	return vs
+}
+
+// current returns the name of the focused view.
+func (ui *testUI) current() string {
+	ui.t.Helper()
+	var name string
+	ui.update(func(g *gocui.Gui) error {
+		if v := g.CurrentView(); v != nil {
+			name = v.Name()
+		}
+		return nil
+	})
+	return name
+}
+
+// fakeProber returns scripted samples.
+type fakeProber struct {
+	samples []probe.Sample
+	results chan probe.Sample
+}
+
+func (p *fakeProber) Start(ctx context.Context) error {
+	p.results = make(chan probe.Sample, len(p.samples))
+	for _, sp := range p.samples {
+		p.results <- sp
+	}
+	close(p.results)
+	return nil
+}
+
+func (p *fakeProber) Stop() {}
+
+func (p *fakeProber) Results() <-chan probe.Sample {
+	return p.results
+}
+
+func TestLayoutViews(t *testing.T) {
+	ui := newTestUI(t, 160, 50)
+	for _, name := range []string{IPLIST, OUTPUTS, CONFIG, STATS, INFOS} {
+		ui.view(name)
+	}
+	if ui.current() != IPLIST {
+		t.Fatalf("focus on %q, want %q", ui.current(), IPLIST)
+	}
+	if v := ui.view(OUTPUTS); v.x0 != IPSWIDTH+1 || v.y0 != 0 || v.x1 != 159 || v.y1 != 49 {
+		t.Fatalf("outputs view at %d,%d %d,%d", v.x0, v.y0, v.x1, v.y1)
+	}
+	if infos := ui.view(INFOS).buffer; !strings.Contains(infos, "F1 Help") {
+		t.Fatalf("infos view without help hint: %q", infos)
+	}
+}
+
+func TestLayoutSmallTerminal(t *testing.T) {
+	ui := newTestUI(t, 80, 24)
+	ui.addTargets("10.0.0.1")
+	ui.update(func(g *gocui.Gui) error {
+		v, err := g.View(CONFIG)
+		if err != nil {
+			return err
+		}
+		fmt.Fprint(v, dbs.formatIPConfig("10.0.0.1"))
+		return nil
+	})
+	if v := ui.view(CONFIG); v.y1-v.y0 != 7 {
+		t.Fatalf("configs view from %d to %d", v.y0, v.y1)
+	}
+	// the fields beyond its height are scrolled into the view.
+	ui.press(gocui.KeyTab, 0)
+	ui.press(gocui.KeyTab, 0)
+	for i := 0; i < 20; i++ {
+		ui.press(gocui.KeyArrowDown, 0)
+	}
+	if oy := ui.view(CONFIG).oy; oy != 15-6 {
+		t.Fatalf("configs view scrolled to %d, want 9", oy)
+	}
+	if position := ui.view(CONFIGPOSITION).buffer; !strings.Contains(position, "15/15") {
+		t.Fatalf("configs position %q", position)
+	}
+	ui.press(gocui.KeyTab, 0)
+	ui.press(gocui.KeyTab, 0)
+
+	ui.press(gocui.KeyCtrlE, 0)
+	if v := ui.view("editIPConfig"); v.y0 < 0 || v.y1 > 23 || v.y1-v.y0 != 21 {
+		t.Fatalf("edit box from %d to %d", v.y0, v.y1)
+	}
+}
+
+func TestAddTargetsDialog(t *testing.T) {
+	ui := newTestUI(t, 160, 50)
+	ui.press(gocui.KeyCtrlA, 0)
+	if ui.current() == IPLIST {
+		t.Fatal("add dialog not focused")
+	}
+	ui.typeText("10.0.0.1, 10.0.0.2")
+	ui.press(gocui.KeyEnter, 0)
+
+	if ui.current() != IPLIST {
+		t.Fatalf("focus on %q after adding, want %q", ui.current(), IPLIST)
+	}
+	if ips := dbs.getAllIPs(); len(ips) != 2 {
+		t.Fatalf("targets %v, want 2", ips)
+	}
+	list := ui.view(IPLIST).buffer
+	if !strings.Contains(list, "10.0.0.1") || !strings.Contains(list, "10.0.0.2") {
+		t.Fatalf("ips list %q", list)
+	}
+}
+
+func TestEditConfigDialog(t *testing.T) {
+	ui := newTestUI(t, 160, 50)
+	ui.addTargets("10.0.0.1")
+
+	ui.press(gocui.KeyCtrlE, 0)
+	if edit := ui.view("editIPConfig").buffer; !strings.Contains(edit, "tags     :") {
+		t.Fatalf("edit box %q", edit)
+	}
+	// down to the tags line, the last one, then to its end.
+	for i := 0; i < 19; i++ {
+		ui.press(gocui.KeyArrowDown, 0)
+	}
+	for i := 0; i < len("tags     : "); i++ {
+		ui.press(gocui.KeyArrowRight, 0)
+	}
+	ui.typeText("Core wan")
+	ui.press(gocui.KeyEnter, 0)
+
+	if cfg := dbs.getConfig("10.0.0.1"); cfg.tags != "core,wan" {
+		t.Fatalf("tags %q, want core,wan", cfg.tags)
+	}
+	if ui.current() != IPLIST {
+		t.Fatalf("focus on %q after editing, want %q", ui.current(), IPLIST)
+	}
+}
+
+func TestFilterDialog(t *testing.T) {
+	ui := newTestUI(t, 160, 50)
+	ui.addTargets("10.0.0.1", "10.0.0.2")
+	cfg := dbs.getConfig("10.0.0.2")
+	cfg.tags = "core"
+	dbs.updateConfig("10.0.0.2", cfg)
+
+	ui.press(0, 'F')
+	ui.typeText("tag=core")
+	ui.press(gocui.KeyEnter, 0)
+
+	list := ui.view(IPLIST)
+	if strings.Contains(list.buffer, "10.0.0.1") || !strings.Contains(list.buffer, "10.0.0.2") {
+		t.Fatalf("filtered ips list %q", list.buffer)
+	}
+	if !strings.Contains(list.title, "1/2") {
+		t.Fatalf("filtered ips list title %q", list.title)
+	}
+}
+
+func TestPingFakeProber(t *testing.T) {
+	ui := newTestUI(t, 160, 50)
+	ui.addTargets("10.0.0.1")
+	now := time.Now()
+	newProber = func(ip string) probe.Prober {
+		return &fakeProber{samples: []probe.Sample{
+			{Target: ip, Time: now, RTT: 10, Success: true, Line: "reply 10 ms"},
+			{Target: ip, Time: now, RTT: -1, Line: "Request timeout"},
+			{Target: ip, Time: now, RTT: 30, Success: true, Line: "reply 30 ms"},
+		}}
+	}
+	outputs := bus.subscribe(100, EVOUTPUT)
+
+	// the scheduler runs the ping queued by <Enter>.
+	ui.press(gocui.KeyEnter, 0)
+	select {
+	case ip := <-ipToPingChan:
+		executePing(ip, context.Background())
+	case <-time.After(time.Second):
+		t.Fatal("no ping queued")
+	}
+
+	var lines []string
+	for _, e := range outputs.drain() {
+		lines = append(lines, e.text)
+	}
+	if got := strings.Join(lines, "\n"); !strings.Contains(got, "reply 10 ms") || !strings.Contains(got, "Request timeout") {
+		t.Fatalf("outputs %q", got)
+	}
+	s := dbs.getStats("10.0.0.1")
+	if s == nil || s.fails != 1 || s.min != 10 || s.max != 30 {
+		t.Fatalf("stats %+v", s)
+	}
+}
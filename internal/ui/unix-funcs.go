@@ -0,0 +1,140 @@
+//go:build !windows
+// +build !windows
+
+package ui
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/jeamon/pingo/internal/probe"
+)
+
+// buildPingCommand constructs full command to run. The ping should
+// run indefinitely by default unless a requests is defined. The
+// arguments are passed as is to the program without any shell and
+// the timeout flag follows the system ping (seconds on linux and
+// milliseconds on macOS).
+func buildPingCommand(ip string, ctx context.Context) (string, *exec.Cmd) {
+	dbs.markStarted(ip)
+	cfg := dbs.getConfig(ip)
+	opts := probe.Options{Count: cfg.requests, Interval: cfg.interval, Timeout: cfg.timeout, Size: cfg.size, Pattern: cfg.pattern}
+	return strconv.Itoa(cfg.threshold), probe.Command(ctx, ip, opts)
+}
+
+// replyTimeout returns the time the ping waits for each reply.
+func replyTimeout(cfg *config) time.Duration {
+	return time.Duration(cfg.timeout) * time.Second
+}
+
+// buildDNSProber constructs the dns probe of an ip which queries
+// the configured name and type.
+func buildDNSProber(ip string) probe.Prober {
+	dbs.markStarted(ip)
+	cfg := dbs.getConfig(ip)
+	return probe.NewDNSProber(ip, cfg.dnsQName(), cfg.dnsQType(), probe.Options{Count: cfg.requests, Interval: cfg.interval, Timeout: cfg.timeout})
+}
+
+// buildICMPProber constructs the native icmp probe of an ip sending
+// the echo requests without the system ping command.
+func buildICMPProber(ip string) probe.Prober {
+	dbs.markStarted(ip)
+	cfg := dbs.getConfig(ip)
+	return probe.NewICMPProber(ip, icmpMode, probe.Options{Count: cfg.requests, Interval: cfg.interval, Timeout: cfg.timeout, Size: cfg.size, Pattern: cfg.pattern})
+}
+
+// readNeighbor returns the MAC address and the state of an ip into
+// the neighbors table using ip neigh on linux or arp and ndp on BSD
+// systems. An empty MAC address means the ip is not resolved.
+func readNeighbor(ip string, ctx context.Context) (string, string, error) {
+	if path, err := exec.LookPath("ip"); err == nil {
+		cmd := exec.CommandContext(ctx, path, "neigh", "show", "to", ip)
+		prepareCommand(cmd)
+		out, err := cmd.Output()
+		if err != nil {
+			return "", "", err
+		}
+		for _, line := range strings.Split(string(out), "\n") {
+			// 192.168.1.1 dev eth0 lladdr aa:bb:cc:dd:ee:ff REACHABLE
+			if fields := strings.Fields(line); len(fields) > 1 && fields[0] == ip {
+				return findMAC(line), fields[len(fields)-1], nil
+			}
+		}
+		return "", "", nil
+	}
+
+	name := "arp"
+	if strings.Contains(ip, ":") {
+		name = "ndp"
+	}
+	cmd := exec.CommandContext(ctx, name, "-n", ip)
+	prepareCommand(cmd)
+	// the command fails when the ip has no entry.
+	out, _ := cmd.Output()
+	for _, line := range strings.Split(string(out), "\n") {
+		// ? (192.168.1.1) at 0:1b:2c:d:ee:f on en0 ifscope [ethernet]
+		if strings.Contains(line, "("+ip+")") || strings.HasPrefix(line, ip+" ") || strings.HasPrefix(line, ip+"%") {
+			return findMAC(line), "", nil
+		}
+	}
+	return "", "", nil
+}
+
+// hasTerminal tells whether the controlling terminal the ui draws on can be opened.
+func hasTerminal() bool {
+	f, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	if err != nil {
+		return false
+	}
+	f.Close()
+	return true
+}
+
+// prepareCommand runs the command into its own process group so it
+// can be killed with all its children and does not receive the
+// terminal signals before pingo handles them.
+func prepareCommand(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
+
+// attachProcess does nothing since the process group is set on start.
+func attachProcess(p *os.Process) {}
+
+// killProcessTree kills the process group of a command.
+func killProcessTree(p *os.Process) {
+	if err := syscall.Kill(-p.Pid, syscall.SIGKILL); err != nil {
+		p.Kill()
+	}
+}
+
+// shutdownSignals are the signals which gracefully stop pingo.
+var shutdownSignals = []os.Signal{os.Interrupt, syscall.SIGTERM, syscall.SIGHUP}
+
+// serviceControl returns a channel closed once the daemon receives
+// SIGTERM or interrupt signal, a channel notified on each SIGHUP to
+// reload the settings and a function to call once stopped.
+func serviceControl() (<-chan struct{}, <-chan struct{}, func()) {
+	stop := make(chan struct{})
+	reload := make(chan struct{}, 1)
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
+	go func() {
+		for sig := range signals {
+			if sig != syscall.SIGHUP {
+				close(stop)
+				return
+			}
+			select {
+			case reload <- struct{}{}:
+			default:
+			}
+		}
+	}()
+	return stop, reload, func() { signal.Stop(signals) }
+}
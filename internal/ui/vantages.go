@@ -0,0 +1,209 @@
+package ui
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/jroimartin/gocui"
+)
+
+const (
+	VANTAGES = "vantages"
+
+	VANTAGESWIDTH  = 90
+	VANTAGESHEIGHT = 16
+
+	// name of this instance into the comparison.
+	LOCALVANTAGE = "local"
+)
+
+// vantageView is the state of a target seen from a vantage point.
+type vantageView struct {
+	name  string
+	state string
+	sent  int
+	loss  float64
+	avg   int
+	// not probed by the vantage or failed to query it.
+	err error
+}
+
+// String formats a vantage into a line of the comparison view.
+func (v *vantageView) String() string {
+	if v.err != nil {
+		return fmt.Sprintf("%-20s %s", v.name, v.err)
+	}
+	state := v.state
+	if state == STATEUNKNOWN {
+		state = "-"
+	}
+	return fmt.Sprintf("%-20s %-8s %6d  loss %5.1f%%  avg %4d ms", v.name, state, v.sent, v.loss, v.avg)
+}
+
+// queryVantage fetches the state of a target from the web dashboard
+// api of the pingo instance of a vantage.
+func queryVantage(vs vantageSettings, ip string) *vantageView {
+	v := &vantageView{name: vs.Name}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimRight(vs.URL, "/")+"/api/state", nil)
+	if err != nil {
+		v.err = err
+		return v
+	}
+	req.Header.Set("User-Agent", "pingo")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		v.err = err
+		return v
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		v.err = fmt.Errorf("vantage replied %s", resp.Status)
+		return v
+	}
+
+	var state dashboardState
+	if err = json.NewDecoder(resp.Body).Decode(&state); err != nil {
+		v.err = err
+		return v
+	}
+	for _, t := range state.Targets {
+		if t.Target == ip {
+			v.state, v.sent, v.loss, v.avg = t.State, t.Sent, t.Loss, t.Avg
+			return v
+		}
+	}
+	v.err = fmt.Errorf("not probed from this vantage")
+	return v
+}
+
+// localVantage returns the state of a target seen from this instance.
+func localVantage(ip string) *vantageView {
+	v := &vantageView{name: LOCALVANTAGE, state: STATEUNKNOWN}
+	if s := dbs.getStats(ip); s != nil {
+		v.state, v.sent, v.loss, v.avg = s.state, s.fails+s.replies(), s.loss(), s.avg
+	}
+	return v
+}
+
+// vantagesVerdict tells from the states of a target seen from each
+// vantage whether the target itself or the path of some vantages to
+// it is failing.
+func vantagesVerdict(views []*vantageView) string {
+	var up, down []string
+	for _, v := range views {
+		if v.err != nil {
+			continue
+		}
+		switch v.state {
+		case STATEUP:
+			up = append(up, v.name)
+		case STATEDOWN:
+			down = append(down, v.name)
+		}
+	}
+
+	switch {
+	case len(up)+len(down) < 2:
+		return "not enough vantages probing this target to compare."
+	case len(up) == 0:
+		return "target down : unreachable from all vantages."
+	case len(down) == 0:
+		return "target up : reachable from all vantages."
+	}
+	return fmt.Sprintf("path issue : down from %s while up from %s.", strings.Join(down, ", "), strings.Join(up, ", "))
+}
+
+// displayVantagesView compares in background the state of the focused
+// ip from this instance and from the pingo instances of the configured
+// vantages to distinguish a target down from a link down.
+func displayVantagesView(g *gocui.Gui, ipv *gocui.View) error {
+	cfgs := getSettings()
+	_, cy := ipv.Cursor()
+	l, err := ipv.Line(cy)
+	if err != nil || len(strings.Fields(l)) < 2 {
+		return nil
+	}
+	ip := strings.Fields(l)[1]
+
+	maxX, maxY := g.Size()
+	vv, err := g.SetView(VANTAGES, (maxX-VANTAGESWIDTH)/2, (maxY-VANTAGESHEIGHT)/2, (maxX+VANTAGESWIDTH)/2, (maxY+VANTAGESHEIGHT)/2)
+	if err != nil && err != gocui.ErrUnknownView {
+		log.Println("Failed to create vantages view:", err)
+		return err
+	}
+	if err == gocui.ErrUnknownView {
+		vv.FgColor = gocui.ColorYellow
+		vv.Editable = false
+		vv.Wrap = false
+		for _, key := range []gocui.Key{gocui.KeyEsc, gocui.KeyCtrlQ} {
+			if err := g.SetKeybinding(VANTAGES, key, gocui.ModNone, closeVantagesView); err != nil {
+				log.Println("Failed to bind keys to vantages view:", err)
+				return err
+			}
+		}
+	}
+	vv.Title = fmt.Sprintf(" Vantages of [%s] | Esc: close ", ip)
+	vv.Clear()
+	if len(cfgs.Vantages) == 0 {
+		fmt.Fprint(vv, "No vantage configured. Add the other pingo instances into the vantages settings.")
+	} else {
+		fmt.Fprintf(vv, "Querying %d vantage(s) for %s ...", len(cfgs.Vantages), ip)
+	}
+	if _, err := g.SetCurrentView(VANTAGES); err != nil {
+		log.Println("Failed to set focus on vantages view:", err)
+		return err
+	}
+	if len(cfgs.Vantages) == 0 {
+		return nil
+	}
+
+	go func() {
+		defer recoverPanic("queryVantages")
+		views := make([]*vantageView, len(cfgs.Vantages)+1)
+		views[0] = localVantage(ip)
+		done := make(chan struct{})
+		for i, vs := range cfgs.Vantages {
+			go func(i int, vs vantageSettings) {
+				defer func() { done <- struct{}{} }()
+				views[i+1] = queryVantage(vs, ip)
+			}(i, vs)
+		}
+		for range cfgs.Vantages {
+			<-done
+		}
+		g.Update(func(g *gocui.Gui) error {
+			vv, verr := g.View(VANTAGES)
+			// closed or showing another address meanwhile.
+			if verr != nil || !strings.Contains(vv.Title, "["+ip+"]") {
+				return nil
+			}
+			vv.Clear()
+			fmt.Fprintf(vv, "%-20s %-8s %6s\n", "VANTAGE", "STATE", "SENT")
+			for _, v := range views {
+				fmt.Fprintln(vv, v)
+			}
+			fmt.Fprintf(vv, "\n%s\n", vantagesVerdict(views))
+			return nil
+		})
+	}()
+	return nil
+}
+
+// closeVantagesView closes the vantages popup.
+func closeVantagesView(g *gocui.Gui, vv *gocui.View) error {
+	g.DeleteKeybindings(vv.Name())
+	if err := g.DeleteView(vv.Name()); err != nil {
+		log.Println("Failed to delete vantages view:", err)
+		return err
+	}
+	return setCurrentDefaultView(g)
+}
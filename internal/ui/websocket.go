@@ -0,0 +1,299 @@
+package ui
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// websocket frames opcodes.
+const (
+	WSTEXT  = 0x1
+	WSCLOSE = 0x8
+	WSPING  = 0x9
+	WSPONG  = 0xa
+)
+
+// magic value used to compute the websocket handshake accept key.
+const wsGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// liveEvent is the JSON message streamed to websocket clients.
+// Type is sample (probe result), state (alert), output (line)
+// targets (list of monitored targets), focus (target pinged by the ui)
+// or note (annotation of the timeline).
+type liveEvent struct {
+	Type   string      `json:"type"`
+	Time   time.Time   `json:"time"`
+	Target string      `json:"target"`
+	Data   interface{} `json:"data"`
+}
+
+type liveSample struct {
+	Success bool `json:"success"`
+	RTT     int  `json:"rtt_ms"`
+}
+
+type liveState struct {
+	State   string  `json:"state"`
+	Loss    float64 `json:"loss"`
+	Fails   int     `json:"fails"`
+	Details string  `json:"details,omitempty"`
+}
+
+type liveNote struct {
+	Text string `json:"text"`
+}
+
+// liveHub broadcasts events to all connected websocket clients.
+// Slow clients miss events instead of blocking the publishers.
+type liveHub struct {
+	lock    *sync.Mutex
+	clients map[chan []byte]struct{}
+}
+
+// live events broadcaster.
+var hub = &liveHub{lock: &sync.Mutex{}, clients: make(map[chan []byte]struct{})}
+
+// subscribe registers a new client and returns its events queue.
+func (h *liveHub) subscribe() chan []byte {
+	c := make(chan []byte, 256)
+	h.lock.Lock()
+	h.clients[c] = struct{}{}
+	h.lock.Unlock()
+	return c
+}
+
+// unsubscribe removes a client.
+func (h *liveHub) unsubscribe(c chan []byte) {
+	h.lock.Lock()
+	delete(h.clients, c)
+	h.lock.Unlock()
+}
+
+// publish encodes an event once and queues it to each client.
+func (h *liveHub) publish(e liveEvent) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	if len(h.clients) == 0 {
+		return
+	}
+
+	data, err := json.Marshal(e)
+	if err != nil {
+		return
+	}
+	for c := range h.clients {
+		select {
+		case c <- data:
+		default:
+			count(&counters.droppedEvents)
+		}
+	}
+}
+
+// publishOutput streams an output line of a target with its rtt
+// threshold and parsed result.
+func (h *liveHub) publishOutput(e ProbeEvent) {
+	h.publish(liveEvent{Type: "output", Time: time.Now(), Target: e.Target, Data: e})
+}
+
+// publishFocus streams the target being pinged by the ui.
+func (h *liveHub) publishFocus(ip string) {
+	h.publish(liveEvent{Type: "focus", Time: time.Now(), Target: ip})
+}
+
+// publishTargets streams the list of monitored targets.
+func (h *liveHub) publishTargets(ips []string) {
+	h.publish(liveEvent{Type: "targets", Time: time.Now(), Data: ips})
+}
+
+// publishNote streams an annotation of the timeline.
+func (h *liveHub) publishNote(a annotation) {
+	h.publish(liveEvent{Type: "note", Time: a.time, Target: a.ip, Data: liveNote{Text: a.text}})
+}
+
+// publishAlert streams a state change of a target.
+func (h *liveHub) publishAlert(a alert) {
+	h.publish(liveEvent{Type: "state", Time: a.time, Target: a.ip,
+		Data: liveState{State: a.state, Loss: a.stats.loss(), Fails: a.stats.fails, Details: a.details}})
+}
+
+// sample streams a probe result. The hub is used as a results sink.
+func (h *liveHub) sample(sp sample) {
+	h.publish(liveEvent{Type: "sample", Time: sp.time, Target: sp.ip, Data: liveSample{Success: sp.success, RTT: sp.rtt}})
+}
+
+// summary is not streamed, clients compute it from the samples.
+func (h *liveHub) summary(ip string, s stat, t time.Time) {}
+
+// wsConn is a server side websocket connection.
+type wsConn struct {
+	conn net.Conn
+	rw   *bufio.ReadWriter
+	lock *sync.Mutex
+}
+
+// wsHandler upgrades the connection to websocket then streams the live
+// events until the client leaves or the program exits. Cross-origin
+// requests are rejected so that any visited website cannot read them.
+func wsHandler(w http.ResponseWriter, r *http.Request) {
+	if !strings.EqualFold(r.Header.Get("Upgrade"), "websocket") || r.Header.Get("Sec-WebSocket-Key") == "" {
+		http.Error(w, "websocket upgrade expected", http.StatusBadRequest)
+		return
+	}
+
+	if origin := r.Header.Get("Origin"); origin != "" {
+		if u, err := url.Parse(origin); err != nil || u.Host != r.Host {
+			http.Error(w, "cross-origin request denied", http.StatusForbidden)
+			return
+		}
+	}
+
+	hj, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "websocket not supported", http.StatusInternalServerError)
+		return
+	}
+
+	conn, rw, err := hj.Hijack()
+	if err != nil {
+		logs.Error("Failed to upgrade websocket", "subsystem", "http", "err", err)
+		return
+	}
+	defer conn.Close()
+	// drop server timeouts of the http request.
+	conn.SetDeadline(time.Time{})
+
+	sum := sha1.Sum([]byte(r.Header.Get("Sec-WebSocket-Key") + wsGUID))
+	rw.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n")
+	rw.WriteString("Sec-WebSocket-Accept: " + base64.StdEncoding.EncodeToString(sum[:]) + "\r\n\r\n")
+	if err = rw.Flush(); err != nil {
+		return
+	}
+
+	ws := &wsConn{conn: conn, rw: rw, lock: &sync.Mutex{}}
+	events := hub.subscribe()
+	defer hub.unsubscribe(events)
+
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		ws.readLoop()
+	}()
+
+	ticker := time.NewTicker(30 * time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case data := <-events:
+			err = ws.write(WSTEXT, data)
+		case <-ticker.C:
+			err = ws.write(WSPING, nil)
+		case <-closed:
+			return
+		case <-exit:
+			ws.write(WSCLOSE, []byte{0x03, 0xe9})
+			return
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// write sends a single unmasked frame.
+func (ws *wsConn) write(opcode byte, payload []byte) error {
+	header := []byte{0x80 | opcode}
+	n := len(payload)
+	switch {
+	case n < 126:
+		header = append(header, byte(n))
+	case n <= 0xffff:
+		header = append(header, 126, 0, 0)
+		binary.BigEndian.PutUint16(header[2:], uint16(n))
+	default:
+		header = append(header, 127, 0, 0, 0, 0, 0, 0, 0, 0)
+		binary.BigEndian.PutUint64(header[2:], uint64(n))
+	}
+
+	ws.lock.Lock()
+	defer ws.lock.Unlock()
+	ws.conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+	ws.rw.Write(header)
+	ws.rw.Write(payload)
+	return ws.rw.Flush()
+}
+
+// readLoop consumes the client frames, answers pings and
+// returns once the client closes the connection.
+func (ws *wsConn) readLoop() {
+	for {
+		opcode, payload, err := ws.readFrame()
+		if err != nil {
+			return
+		}
+		switch opcode {
+		case WSPING:
+			ws.write(WSPONG, payload)
+		case WSCLOSE:
+			ws.write(WSCLOSE, payload)
+			return
+		}
+	}
+}
+
+// readFrame reads a single masked client frame.
+func (ws *wsConn) readFrame() (byte, []byte, error) {
+	var head [2]byte
+	if _, err := io.ReadFull(ws.rw, head[:]); err != nil {
+		return 0, nil, err
+	}
+
+	opcode := head[0] & 0x0f
+	length := uint64(head[1] & 0x7f)
+	switch length {
+	case 126:
+		var ext [2]byte
+		if _, err := io.ReadFull(ws.rw, ext[:]); err != nil {
+			return 0, nil, err
+		}
+		length = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err := io.ReadFull(ws.rw, ext[:]); err != nil {
+			return 0, nil, err
+		}
+		length = binary.BigEndian.Uint64(ext[:])
+	}
+
+	// clients only send small control or text messages.
+	if length > 1<<20 {
+		return 0, nil, errors.New("websocket frame too large")
+	}
+
+	var mask [4]byte
+	if head[1]&0x80 != 0 {
+		if _, err := io.ReadFull(ws.rw, mask[:]); err != nil {
+			return 0, nil, err
+		}
+	}
+
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(ws.rw, payload); err != nil {
+		return 0, nil, err
+	}
+	for i := range payload {
+		payload[i] ^= mask[i%4]
+	}
+	return opcode, payload, nil
+}
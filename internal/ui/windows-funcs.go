@@ -0,0 +1,290 @@
+//go:build windows
+// +build windows
+
+package ui
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+	"golang.org/x/sys/windows/svc"
+
+	"github.com/jeamon/pingo/internal/probe"
+)
+
+// buildPingCommand constructs full command to run. The ping should
+// run indefinitely by default unless a requests is defined. The
+// arguments are passed as is to the program without cmd. The pattern
+// and interval configs are ignored since the windows ping has no such
+// options.
+func buildPingCommand(ip string, ctx context.Context) (string, *exec.Cmd) {
+	dbs.markStarted(ip)
+	cfg := dbs.getConfig(ip)
+
+	args := []string{ip}
+
+	if cfg.requests > 0 {
+		args = append(args, "-n", strconv.Itoa(cfg.requests))
+	} else {
+		args = append(args, "-t")
+	}
+
+	if cfg.timeout > 0 {
+		args = append(args, "-w", strconv.Itoa(cfg.timeout))
+	}
+
+	if cfg.size > 0 {
+		args = append(args, "-l", strconv.Itoa(cfg.size))
+	}
+
+	return strconv.Itoa(cfg.threshold), exec.CommandContext(ctx, "ping", args...)
+}
+
+// replyTimeout returns the time the ping waits for each reply.
+func replyTimeout(cfg *config) time.Duration {
+	return time.Duration(cfg.timeout) * time.Millisecond
+}
+
+// buildDNSProber constructs the dns probe of an ip which queries
+// the configured name and type. The timeout config in milliseconds is rounded up to seconds.
+func buildDNSProber(ip string) probe.Prober {
+	dbs.markStarted(ip)
+	cfg := dbs.getConfig(ip)
+	return probe.NewDNSProber(ip, cfg.dnsQName(), cfg.dnsQType(), probe.Options{Count: cfg.requests, Interval: cfg.interval, Timeout: (cfg.timeout + 999) / 1000})
+}
+
+// buildICMPProber constructs the native icmp probe of an ip sending
+// the echo requests without the system ping command. The timeout config in milliseconds is rounded up to seconds.
+func buildICMPProber(ip string) probe.Prober {
+	dbs.markStarted(ip)
+	cfg := dbs.getConfig(ip)
+	return probe.NewICMPProber(ip, icmpMode, probe.Options{Count: cfg.requests, Interval: cfg.interval, Timeout: (cfg.timeout + 999) / 1000, Size: cfg.size, Pattern: cfg.pattern})
+}
+
+// privilegesHint tells how to allow the native icmp engine.
+const privilegesHint = "run pingo as administrator"
+
+// buildTracerouteCommand constructs full tracert command to run with
+// the focused ip options. Tracert only sends 3 ICMP probes per hop so
+// the queries and protocol options are ignored. The pathping protocol
+// runs pathping instead to get the loss statistics of each hop.
+func buildTracerouteCommand(ip string, ctx context.Context) *exec.Cmd {
+	cfg := dbs.getConfig(ip)
+	if cfg.protocol == "pathping" {
+		return buildPathpingCommand(ip, cfg, ctx)
+	}
+
+	var args []string
+
+	if cfg.maxhops > 0 {
+		args = append(args, "-h", strconv.Itoa(cfg.maxhops))
+	}
+
+	if cfg.timeout > 0 {
+		args = append(args, "-w", strconv.Itoa(cfg.timeout))
+	}
+
+	if cfg.numeric {
+		args = append(args, "-d")
+	}
+
+	args = append(args, ip)
+
+	return exec.CommandContext(ctx, "tracert", args...)
+}
+
+// buildPathpingCommand constructs full pathping command to run. The
+// queries option is the number of probes sent to each hop.
+func buildPathpingCommand(ip string, cfg *config, ctx context.Context) *exec.Cmd {
+	var args []string
+
+	if cfg.maxhops > 0 {
+		args = append(args, "-h", strconv.Itoa(cfg.maxhops))
+	}
+
+	if cfg.queries > 0 {
+		args = append(args, "-q", strconv.Itoa(cfg.queries))
+	}
+
+	if cfg.timeout > 0 {
+		args = append(args, "-w", strconv.Itoa(cfg.timeout))
+	}
+
+	if cfg.numeric {
+		args = append(args, "-n")
+	}
+
+	args = append(args, ip)
+
+	return exec.CommandContext(ctx, "pathping", args...)
+}
+
+// job object holding all the started commands. Windows kills
+// them once pingo exits, even if it crashed.
+var (
+	job     windows.Handle
+	jobOnce sync.Once
+)
+
+// processesJob creates once the job object killing its
+// processes when its last handle is closed.
+func processesJob() windows.Handle {
+	jobOnce.Do(func() {
+		h, err := windows.CreateJobObject(nil, nil)
+		if err != nil {
+			probeLog.Warn("Failed to create job object", "err", err)
+			return
+		}
+		info := windows.JOBOBJECT_EXTENDED_LIMIT_INFORMATION{}
+		info.BasicLimitInformation.LimitFlags = windows.JOB_OBJECT_LIMIT_KILL_ON_JOB_CLOSE
+		if _, err = windows.SetInformationJobObject(h, windows.JobObjectExtendedLimitInformation,
+			uintptr(unsafe.Pointer(&info)), uint32(unsafe.Sizeof(info))); err != nil {
+			probeLog.Warn("Failed to set job object limits", "err", err)
+		}
+		job = h
+	})
+	return job
+}
+
+// readNeighbor returns the MAC address and the type or state of an
+// ip into the neighbors table using arp for IPv4 and netsh for IPv6.
+// An empty MAC address means the ip is not resolved.
+func readNeighbor(ip string, ctx context.Context) (string, string, error) {
+	cmd := exec.CommandContext(ctx, "arp", "-a", ip)
+	if strings.Contains(ip, ":") {
+		cmd = exec.CommandContext(ctx, "netsh", "interface", "ipv6", "show", "neighbors")
+	}
+	prepareCommand(cmd)
+	// arp fails when the ip has no entry.
+	out, _ := cmd.Output()
+	out, _ = ioutil.ReadAll(decodeOutput(bytes.NewReader(out)))
+	for _, line := range strings.Split(string(out), "\n") {
+		//   192.168.1.1           aa-bb-cc-dd-ee-ff     dynamic
+		if fields := strings.Fields(line); len(fields) > 2 && fields[0] == ip {
+			return findMAC(line), fields[len(fields)-1], nil
+		}
+	}
+	return "", "", nil
+}
+
+// hasTerminal tells whether the console the ui draws on can be opened.
+func hasTerminal() bool {
+	f, err := os.OpenFile("CONOUT$", os.O_RDWR, 0)
+	if err != nil {
+		return false
+	}
+	f.Close()
+	return true
+}
+
+// prepareCommand runs the command into its own console process
+// group so it does not receive the Ctrl+C event before pingo.
+func prepareCommand(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{CreationFlags: syscall.CREATE_NEW_PROCESS_GROUP}
+}
+
+// attachProcess adds a started command to the job object
+// so its children are also killed with it.
+func attachProcess(p *os.Process) {
+	j := processesJob()
+	if j == 0 {
+		return
+	}
+	h, err := windows.OpenProcess(windows.PROCESS_SET_QUOTA|windows.PROCESS_TERMINATE, false, uint32(p.Pid))
+	if err != nil {
+		return
+	}
+	defer windows.CloseHandle(h)
+	if err = windows.AssignProcessToJobObject(j, h); err != nil {
+		probeLog.Warn("Failed to assign process to job object", "pid", p.Pid, "err", err)
+	}
+}
+
+// killProcessTree kills a command then all the processes
+// of the job object, including the children of commands.
+func killProcessTree(p *os.Process) {
+	p.Kill()
+	if j := processesJob(); j != 0 {
+		windows.TerminateJobObject(j, 1)
+	}
+}
+
+// shutdownSignals are the signals which gracefully stop pingo.
+var shutdownSignals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+
+// serviceControl returns a channel closed once the daemon is asked to
+// stop, a channel notified on each request to reload the settings and
+// a function to call once stopped. When started by the windows services
+// manager, stop, shutdown and parameters change (sc control pingo
+// paramchange) requests are handled. Otherwise the interrupt signal of
+// the console is used.
+func serviceControl() (<-chan struct{}, <-chan struct{}, func()) {
+	stop := make(chan struct{})
+	reload := make(chan struct{}, 1)
+	interactive, err := svc.IsAnInteractiveSession()
+	if err != nil || interactive {
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, shutdownSignals...)
+		go func() {
+			<-signals
+			close(stop)
+		}()
+		return stop, reload, func() { signal.Stop(signals) }
+	}
+
+	stopped := make(chan struct{})
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+		if err := svc.Run("pingo", &serviceHandler{stop: stop, reload: reload, stopped: stopped}); err != nil {
+			logs.Error("Failed to run as windows service", "err", err)
+		}
+	}()
+	return stop, reload, func() {
+		close(stopped)
+		<-finished
+	}
+}
+
+// serviceHandler reports the daemon status to the windows services manager.
+type serviceHandler struct {
+	stop    chan struct{}
+	reload  chan struct{}
+	stopped chan struct{}
+}
+
+// Execute marks the service running and waits for a stop or shutdown
+// request. A parameters change request reloads the settings. The service is reported stopped once the daemon stopped.
+func (h *serviceHandler) Execute(args []string, r <-chan svc.ChangeRequest, s chan<- svc.Status) (bool, uint32) {
+	s <- svc.Status{State: svc.StartPending}
+	s <- svc.Status{State: svc.Running, Accepts: svc.AcceptStop | svc.AcceptShutdown | svc.AcceptParamChange}
+	for c := range r {
+		switch c.Cmd {
+		case svc.Interrogate:
+			s <- c.CurrentStatus
+		case svc.ParamChange:
+			select {
+			case h.reload <- struct{}{}:
+			default:
+			}
+			s <- c.CurrentStatus
+		case svc.Stop, svc.Shutdown:
+			s <- svc.Status{State: svc.StopPending}
+			close(h.stop)
+			<-h.stopped
+			return false, 0
+		}
+	}
+	return false, 0
+}
@@ -0,0 +1,81 @@
+package ui
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/jroimartin/gocui"
+)
+
+// targets to wake up.
+var wakeChan = make(chan string, 1)
+
+// wakeTarget requests to send a Wake-on-LAN magic
+// packet to the MAC address of the focused ip.
+func wakeTarget(g *gocui.Gui, ipv *gocui.View) error {
+	_, cy := ipv.Cursor()
+	l, err := ipv.Line(cy)
+	if err != nil || len(strings.Fields(l)) < 2 {
+		return nil
+	}
+	ip := strings.Fields(l)[1]
+
+	bus.publish(EVTITLE, fmt.Sprintf(" Wake-on-LAN [%s] Outputs ", ip))
+	wakeChan <- ip
+	// reset since no ping.
+	currentOnPingIP = ""
+	currentOutputsIP = ip
+	return nil
+}
+
+// executeWake sends the magic packet to the configured broadcast
+// address of a target and displays the outcome into the outputs view.
+func executeWake(ip string, ctx context.Context) {
+	defer recoverPanic("executeWake")
+	cfg := dbs.getConfig(ip)
+	if cfg == nil || cfg.mac == "" {
+		bus.publish(EVOUTPUT, fmt.Sprintf("No MAC address configured for %s. Set its mac with <CTRL+E>.", ip))
+		return
+	}
+
+	mac, err := net.ParseMAC(cfg.mac)
+	if err != nil {
+		bus.publish(EVOUTPUT, "Invalid MAC address : "+err.Error())
+		return
+	}
+	address := net.JoinHostPort(cfg.wolBroadcast(), "9")
+
+	if err = sendMagicPacket(ctx, mac, address); err != nil {
+		bus.publish(EVOUTPUT, fmt.Sprintf("Failed to send magic packet to %s via %s : %v", mac, address, err))
+		return
+	}
+	bus.publish(EVOUTPUT, fmt.Sprintf("Magic packet sent to %s via %s.", formatMAC(mac), address))
+	bus.publish(EVOUTPUT, "Press <P> to ping the host while it boots.")
+}
+
+// wolBroadcast returns the address the magic packets are sent to.
+func (cfg *config) wolBroadcast() string {
+	if cfg.broadcast == "" {
+		return "255.255.255.255"
+	}
+	return cfg.broadcast
+}
+
+// sendMagicPacket sends over udp the 6 bytes of 0xff followed by
+// 16 repetitions of the MAC address to wake up a host.
+func sendMagicPacket(ctx context.Context, mac net.HardwareAddr, address string) error {
+	packet := append(bytes.Repeat([]byte{0xff}, 6), bytes.Repeat(mac, 16)...)
+
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "udp4", address)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	_, err = conn.Write(packet)
+	return err
+}
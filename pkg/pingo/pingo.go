@@ -0,0 +1,115 @@
+// Package pingo is the probing and statistics engine of the pingo
+// program. It runs the system ping command against a target, parses
+// each reply into a Sample and summarizes them into Stats so other Go
+// programs can monitor targets without the terminal ui.
+//
+//	var stats pingo.Stats
+//	err := pingo.Ping(ctx, "8.8.8.8", pingo.Options{Count: 5}, func(line string, s *pingo.Sample) {
+//		if s != nil {
+//			stats.Add(*s)
+//		}
+//	})
+package pingo
+
+import (
+	"context"
+
+	"github.com/jeamon/pingo/internal/probe"
+	"github.com/jeamon/pingo/internal/stats"
+)
+
+// Options defines the ping command parameters. Zero values
+// keep the system ping defaults and Count 0 pings forever.
+type Options = probe.Options
+
+// Sample is the result of a single request. RTT is
+// in milliseconds and -1 when the request failed.
+type Sample = probe.Sample
+
+// Prober runs probes against a target and delivers their results.
+// Results is valid once started and is closed when the probing ends,
+// either by itself (bounded count) or after Stop or ctx cancellation.
+type Prober = probe.Prober
+
+// ExecProber runs an external command, by default the system ping,
+// and turns each of its output lines into a sample.
+type ExecProber = probe.ExecProber
+
+// ICMPProber sends ICMP echo requests itself without the system ping
+// command, through the raw or datagram sockets of its Mode.
+type ICMPProber = probe.ICMPProber
+
+// DNSProber sends a real DNS query each second to a target server
+// and measures its response time.
+type DNSProber = probe.DNSProber
+
+// sockets an ICMPProber can send its echo requests with.
+const (
+	ICMPRAW   = probe.ICMPRAW
+	ICMPDGRAM = probe.ICMPDGRAM
+)
+
+// DNSTypes lists the records types a DNSProber can query.
+var DNSTypes = probe.DNSTypes
+
+// NewExecProber returns a prober running the system ping command.
+func NewExecProber(target string, opts Options) *ExecProber {
+	return probe.NewExecProber(target, opts)
+}
+
+// NewICMPProber returns a prober sending echo requests through the
+// sockets of mode.
+func NewICMPProber(target, mode string, opts Options) *ICMPProber {
+	return probe.NewICMPProber(target, mode, opts)
+}
+
+// NewDNSProber returns a prober querying a DNS server.
+func NewDNSProber(target, qname, qtype string, opts Options) *DNSProber {
+	return probe.NewDNSProber(target, qname, qtype, opts)
+}
+
+// ICMPMode returns the kind of ICMP sockets the process may open,
+// empty when none is allowed so the system ping command must be used.
+func ICMPMode() string {
+	return probe.ICMPMode()
+}
+
+// ParseDetails returns the ttl and the size in bytes of a reply
+// output line, 0 for the ones not found.
+func ParseDetails(line string) (int, int) {
+	return probe.ParseDetails(line)
+}
+
+// Ping runs the system ping command against target and calls handle
+// with each output line and its sample, nil for lines without result.
+// It returns once the command ends or the context is cancelled.
+func Ping(ctx context.Context, target string, opts Options, handle func(line string, s *Sample)) error {
+	return probe.Ping(ctx, target, opts, handle)
+}
+
+// Stats summarizes the samples of a target. It exposes the counters
+// of a stats.Summary which does not keep the samples, so it suits the
+// endless pings.
+type Stats struct {
+	Sent  int
+	Fails int
+	// round-trip times in milliseconds.
+	Min  int
+	Avg  int
+	Max  int
+	Last int
+
+	summary stats.Summary
+}
+
+// Add accounts a sample into the statistics.
+func (s *Stats) Add(sp Sample) {
+	s.summary.Account(stats.Sample{Time: sp.Time, RTT: sp.RTT, Success: sp.Success})
+	s.Sent, s.Fails = s.summary.Sent, s.summary.Fails
+	s.Min, s.Avg, s.Max, s.Last = s.summary.Min, s.summary.Avg(), s.summary.Max, s.summary.Last
+}
+
+// Loss returns the percentage of failed requests.
+func (s *Stats) Loss() float64 {
+	return s.summary.Loss()
+}
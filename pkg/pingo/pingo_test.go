@@ -0,0 +1,25 @@
+package pingo
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStats(t *testing.T) {
+	now := time.Now()
+	var s Stats
+	for _, sp := range []Sample{
+		{Time: now, RTT: 20, Success: true},
+		{Time: now, RTT: -1},
+		{Time: now, RTT: 10, Success: true},
+		{Time: now, RTT: 30, Success: true},
+	} {
+		s.Add(sp)
+	}
+	if s.Sent != 4 || s.Fails != 1 || s.Min != 10 || s.Avg != 20 || s.Max != 30 || s.Last != 30 {
+		t.Fatalf("stats %+v", s)
+	}
+	if loss := s.Loss(); loss != 25 {
+		t.Fatalf("loss %v, want 25", loss)
+	}
+}